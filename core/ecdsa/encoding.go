@@ -0,0 +1,172 @@
+package ecdsa
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+// derSignature is the ASN.1 structure of a DER encoded signature.
+type derSignature struct {
+	R, S *big.Int
+}
+
+// MarshalDER encodes the signature as the ASN.1 DER sequence of the integers r and s, as used by
+// X.509 and Go's crypto/ecdsa, where r is the x-coordinate of R reduced modulo the group order.
+// s is encoded as is.
+func (sig Signature) MarshalDER() ([]byte, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("ecdsa: nil signature")
+	}
+	r, err := sig.R.XScalar().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	s, err := sig.S.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(derSignature{
+		R: new(big.Int).SetBytes(r),
+		S: new(big.Int).SetBytes(s),
+	})
+}
+
+// MarshalCompact encodes the signature as r ‖ s, each as many bytes as the group order, with s
+// normalized to the lower half of the group order as Bitcoin and Ethereum require.
+//
+// If withRecoveryID is set, the recovery id v of RecoverPublicKey is appended, which gives the
+// 65 byte r ‖ s ‖ v form of Ethereum for secp256k1.
+func (sig Signature) MarshalCompact(withRecoveryID bool) ([]byte, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("ecdsa: nil signature")
+	}
+	group := sig.R.Curve()
+	size := (group.Order().BitLen() + 7) / 8
+
+	// (R, s) and (-R, -s) are both valid, and only the x-coordinate of R is encoded
+	R, s := sig.R, group.NewScalar().Set(sig.S)
+	if s.IsOverHalfOrder() {
+		s.Negate()
+		R = R.Negate()
+	}
+
+	r, err := R.XScalar().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sb, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 2*size+1)
+	out = append(out, leftPad(r, size)...)
+	out = append(out, leftPad(sb, size)...)
+	if !withRecoveryID {
+		return out, nil
+	}
+
+	v, err := recoveryID(R)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, v), nil
+}
+
+// UnmarshalDER decodes a DER encoded signature of digest under the public key X.
+//
+// The encoding only holds the x-coordinate of R, so R is recomputed from X and digest, and an
+// error is returned if the signature is not valid for them.
+func UnmarshalDER(X curve.Point, digest, der []byte) (*Signature, error) {
+	var rs derSignature
+	rest, err := asn1.Unmarshal(der, &rs)
+	if err != nil {
+		return nil, fmt.Errorf("ecdsa: invalid DER signature: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("ecdsa: trailing data after DER signature")
+	}
+	return fromRS(X, digest, rs.R, rs.S)
+}
+
+// UnmarshalCompact decodes a signature of digest under the public key X in the compact r ‖ s
+// form, optionally followed by the recovery id v, as produced by MarshalCompact.
+//
+// R is recomputed from X and digest as in UnmarshalDER, and must match v if it is present.
+// s is not required to be normalized.
+func UnmarshalCompact(X curve.Point, digest, data []byte) (*Signature, error) {
+	size := (X.Curve().Order().BitLen() + 7) / 8
+	if len(data) != 2*size && len(data) != 2*size+1 {
+		return nil, fmt.Errorf("ecdsa: invalid length for compact signature: %d", len(data))
+	}
+
+	r := new(big.Int).SetBytes(data[:size])
+	s := new(big.Int).SetBytes(data[size : 2*size])
+	sig, err := fromRS(X, digest, r, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 2*size+1 {
+		v, err := recoveryID(sig.R)
+		if err != nil {
+			return nil, err
+		}
+		if v != data[2*size] {
+			return nil, fmt.Errorf("ecdsa: recovery id %d does not match the signature", data[2*size])
+		}
+	}
+	return sig, nil
+}
+
+// fromRS returns the signature (R, s) of digest under X whose R has the x-coordinate r, with
+// R = s⁻¹⋅(m⋅G + r⋅X) as in Verify.
+func fromRS(X curve.Point, digest []byte, r, s *big.Int) (*Signature, error) {
+	group := X.Curve()
+	order := group.Order().Big()
+	if r.Sign() <= 0 || r.Cmp(order) >= 0 || s.Sign() <= 0 || s.Cmp(order) >= 0 {
+		return nil, errors.New("ecdsa: signature values out of range")
+	}
+
+	rScalar := group.NewScalar().SetNat(new(saferith.Nat).SetBig(r, r.BitLen()))
+	sScalar := group.NewScalar().SetNat(new(saferith.Nat).SetBig(s, s.BitLen()))
+
+	m := curve.FromHash(group, digest)
+	sInv := group.NewScalar().Set(sScalar).Invert()
+	R := sInv.Act(m.ActOnBase().Add(rScalar.Act(X)))
+	if R.IsIdentity() || !R.XScalar().Equal(rScalar) {
+		return nil, errors.New("ecdsa: signature is not valid for the public key")
+	}
+	return &Signature{R: R, S: sScalar}, nil
+}
+
+// recoveryID returns the recovery id of R as defined by RecoverPublicKey: the parity of its
+// y-coordinate, plus 2 if its x-coordinate is not smaller than the group order.
+func recoveryID(R curve.Point) (byte, error) {
+	compressed, err := R.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	if len(compressed) == 0 || compressed[0] < 2 || compressed[0] > 3 {
+		return 0, errors.New("ecdsa: invalid point encoding")
+	}
+	v := compressed[0] - 2
+	if new(big.Int).SetBytes(compressed[1:]).Cmp(R.Curve().Order().Big()) >= 0 {
+		v |= 2
+	}
+	return v, nil
+}
+
+// leftPad returns b padded with leading zeros to size bytes.
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
@@ -0,0 +1,131 @@
+package ecdsa
+
+import (
+	"bytes"
+	stdecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestSignature_MarshalDER_Stdlib(t *testing.T) {
+	group := curve.P256{}
+	digest := sha256.Sum256([]byte("hello"))
+
+	priv, err := stdecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	X := group.NewPoint()
+	require.NoError(t, X.UnmarshalBinary(elliptic.MarshalCompressed(elliptic.P256(), priv.X, priv.Y)))
+	x := group.NewScalar().SetNat(new(saferith.Nat).SetBig(priv.D, priv.D.BitLen()))
+
+	// our signatures verify with crypto/ecdsa
+	sig := NewSignature(x, digest[:], nil)
+	der, err := sig.MarshalDER()
+	require.NoError(t, err)
+	assert.True(t, stdecdsa.VerifyASN1(&priv.PublicKey, digest[:], der))
+
+	// and signatures of crypto/ecdsa decode to valid signatures
+	der, err = stdecdsa.SignASN1(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	decoded, err := UnmarshalDER(X, digest[:], der)
+	require.NoError(t, err)
+	assert.True(t, decoded.Verify(X, digest[:]))
+	reencoded, err := decoded.MarshalDER()
+	require.NoError(t, err)
+	assert.Equal(t, der, reencoded)
+
+	other := sha256.Sum256([]byte("other"))
+	_, err = UnmarshalDER(X, other[:], der)
+	assert.Error(t, err)
+	_, err = UnmarshalDER(X, digest[:], append(der, 0))
+	assert.Error(t, err)
+}
+
+// TestSignature_Vector uses the P-256 signature of "sample" with SHA-256 from RFC 6979, A.2.5.
+func TestSignature_Vector(t *testing.T) {
+	group := curve.P256{}
+	digest := sha256.Sum256([]byte("sample"))
+	Ux := mustDecodeHex(t, "60FED4BA255A9D31C961EB74C6356D68C049B8923B61FA6CE669622E60F29FB6")
+	Uy := mustDecodeHex(t, "7903FE1008B8BC99A41AE9E95628BC64F2F1B20C2D7E9F5177A3C294D4462299")
+	r := mustDecodeHex(t, "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716")
+	s := mustDecodeHex(t, "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8")
+
+	X := group.NewPoint()
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), new(big.Int).SetBytes(Ux), new(big.Int).SetBytes(Uy))
+	require.NoError(t, X.UnmarshalBinary(compressed))
+
+	sig, err := UnmarshalCompact(X, digest[:], append(append([]byte{}, r...), s...))
+	require.NoError(t, err)
+	assert.True(t, sig.Verify(X, digest[:]))
+
+	// both integers have their top bit set, so they are prefixed with a zero byte
+	expectedDER := append([]byte{0x30, 0x46, 0x02, 0x21, 0x00}, r...)
+	expectedDER = append(expectedDER, 0x02, 0x21, 0x00)
+	expectedDER = append(expectedDER, s...)
+	der, err := sig.MarshalDER()
+	require.NoError(t, err)
+	assert.Equal(t, expectedDER, der)
+
+	// s is over half the order, so the compact form holds n - s
+	lowS := new(big.Int).Sub(group.Order().Big(), new(big.Int).SetBytes(s))
+	compact, err := sig.MarshalCompact(false)
+	require.NoError(t, err)
+	require.Len(t, compact, 64)
+	assert.Equal(t, r, compact[:32])
+	assert.Equal(t, lowS.FillBytes(make([]byte, 32)), compact[32:])
+
+	normalized, err := UnmarshalCompact(X, digest[:], compact)
+	require.NoError(t, err)
+	assert.True(t, normalized.Verify(X, digest[:]))
+}
+
+func TestSignature_MarshalCompact(t *testing.T) {
+	group := curve.Secp256k1{}
+	m := []byte("hello")
+
+	for i := 0; i < 8; i++ {
+		x := sample.Scalar(rand.Reader, group)
+		X := x.ActOnBase()
+		sig := NewSignature(x, m, nil)
+
+		rsv, err := sig.MarshalCompact(true)
+		require.NoError(t, err)
+		require.Len(t, rsv, 65)
+
+		// the recovery id is the one of the Ethereum encoding
+		eth, err := sig.SigEthereumRSV(X, m)
+		require.NoError(t, err)
+		assert.Equal(t, eth, rsv)
+
+		decoded, err := UnmarshalCompact(X, m, rsv)
+		require.NoError(t, err)
+		assert.True(t, decoded.Verify(X, m))
+		assert.False(t, decoded.S.IsOverHalfOrder())
+		recovered, err := decoded.RecoverPublicKey(m, rsv[64])
+		require.NoError(t, err)
+		assert.True(t, recovered.Equal(X))
+
+		compact, err := sig.MarshalCompact(false)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(rsv[:64], compact))
+
+		rsv[64] ^= 1
+		_, err = UnmarshalCompact(X, m, rsv)
+		assert.Error(t, err)
+	}
+}
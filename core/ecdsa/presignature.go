@@ -3,12 +3,18 @@ package ecdsa
 import (
 	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/lib/types"
 )
 
+// ErrPreSignatureUsed is returned when a PreSignature which already produced a signature share
+// is used again. Signing two messages with the same nonce would reveal the secret key.
+var ErrPreSignatureUsed = errors.New("presignature: already used")
+
 type PreSignature struct {
 	// ID is a random identifier for this specific presignature.
 	ID types.RID
@@ -22,6 +28,10 @@ type PreSignature struct {
 	KShare curve.Scalar
 	// ChiShare = χᵢ
 	ChiShare curve.Scalar
+
+	mtx sync.Mutex
+	// used is set once SignatureShare was called on this copy, and is never reset.
+	used bool
 }
 
 // Group returns the elliptic curve group associated with this PreSignature.
@@ -44,13 +54,33 @@ func EmptyPreSignature(group curve.Curve) *PreSignature {
 type SignatureShare = curve.Scalar
 
 // SignatureShare returns this party's share σᵢ = kᵢm+rχᵢ, where s = ∑ⱼσⱼ.
-func (sig *PreSignature) SignatureShare(hash []byte) curve.Scalar {
+//
+// A PreSignature can only produce a single share, any later call returns ErrPreSignatureUsed.
+// This only guards this copy in memory: decoding the encoding of the PreSignature made before this
+// call gives an unused copy. A persisted PreSignature must be consumed through a
+// PreSignatureStore, which deletes it before computing the share.
+func (sig *PreSignature) SignatureShare(hash []byte) (curve.Scalar, error) {
+	sig.mtx.Lock()
+	defer sig.mtx.Unlock()
+	if sig.used {
+		return nil, ErrPreSignatureUsed
+	}
+	sig.used = true
+
 	m := curve.FromHash(sig.Group(), hash)
 	r := sig.R.XScalar()
 	mk := m.Mul(sig.KShare)
 	rx := r.Mul(sig.ChiShare)
 	sigma := mk.Add(rx)
-	return sigma
+	return sigma, nil
+}
+
+// Used returns true if SignatureShare was already called on this PreSignature, or on the one it
+// was unmarshalled from if that one was marshalled after its use.
+func (sig *PreSignature) Used() bool {
+	sig.mtx.Lock()
+	defer sig.mtx.Unlock()
+	return sig.used
 }
 
 // Signature combines the given shares σⱼ and returns a pair (R,S), where S=∑ⱼσⱼ.
@@ -117,3 +147,91 @@ func (sig *PreSignature) SignerIDs() party.IDSlice {
 	}
 	return party.NewIDSlice(ids)
 }
+
+type preSignatureRaw struct {
+	ID       types.RID
+	R        cbor.RawMessage
+	RBar     cbor.RawMessage
+	S        cbor.RawMessage
+	KShare   cbor.RawMessage
+	ChiShare cbor.RawMessage
+	Used     bool
+}
+
+// MarshalBinary encodes the PreSignature, including whether it was already used.
+func (sig *PreSignature) MarshalBinary() ([]byte, error) {
+	sig.mtx.Lock()
+	defer sig.mtx.Unlock()
+
+	raw := preSignatureRaw{ID: sig.ID, Used: sig.used}
+	var err error
+	if raw.R, err = cbor.Marshal(sig.R); err != nil {
+		return nil, err
+	}
+	if raw.RBar, err = cbor.Marshal(sig.RBar); err != nil {
+		return nil, err
+	}
+	if raw.S, err = cbor.Marshal(sig.S); err != nil {
+		return nil, err
+	}
+	if raw.KShare, err = cbor.Marshal(sig.KShare); err != nil {
+		return nil, err
+	}
+	if raw.ChiShare, err = cbor.Marshal(sig.ChiShare); err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(raw)
+}
+
+// UnmarshalBinary decodes a PreSignature encoded with MarshalBinary. The receiver must have been
+// created with EmptyPreSignature.
+//
+// A PreSignature which was already used cannot be overwritten, so that unmarshalling an older
+// encoding into it does not reset its used flag. A fresh PreSignature decoded from that encoding
+// is unused, see PreSignatureStore.
+func (sig *PreSignature) UnmarshalBinary(data []byte) error {
+	sig.mtx.Lock()
+	defer sig.mtx.Unlock()
+
+	if sig.used {
+		return ErrPreSignatureUsed
+	}
+	if sig.R == nil {
+		return errors.New("presignature: UnmarshalBinary called without setting a group")
+	}
+	group := sig.R.Curve()
+
+	var raw preSignatureRaw
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	R := group.NewPoint()
+	if err := cbor.Unmarshal(raw.R, R); err != nil {
+		return err
+	}
+	RBar := party.EmptyPointMap(group)
+	if err := cbor.Unmarshal(raw.RBar, RBar); err != nil {
+		return err
+	}
+	S := party.EmptyPointMap(group)
+	if err := cbor.Unmarshal(raw.S, S); err != nil {
+		return err
+	}
+	KShare := group.NewScalar()
+	if err := cbor.Unmarshal(raw.KShare, KShare); err != nil {
+		return err
+	}
+	ChiShare := group.NewScalar()
+	if err := cbor.Unmarshal(raw.ChiShare, ChiShare); err != nil {
+		return err
+	}
+
+	sig.ID = raw.ID
+	sig.R = R
+	sig.RBar = RBar
+	sig.S = S
+	sig.KShare = KShare
+	sig.ChiShare = ChiShare
+	sig.used = raw.Used
+	return nil
+}
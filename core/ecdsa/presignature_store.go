@@ -0,0 +1,77 @@
+package ecdsa
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+// presignaturePartyID is the PartyID the presignatures are stored under in a PreSignatureStore.
+const presignaturePartyID = "presignature"
+
+// PreSignatureStore persists presignatures in a Keystore until they are used.
+//
+// The used flag of a PreSignature only guards the copy in memory: the encoding of an unused
+// PreSignature can always be decoded again and produce a second share. A PreSignatureStore
+// deletes the stored presignature before computing its share, so a presignature it holds signs a
+// single message, whatever the copies of its encoding, the restarts of the process and the number
+// of stores sharing the keystore.
+type PreSignatureStore struct {
+	ks    keystore.Keystore
+	group curve.Curve
+}
+
+// NewPreSignatureStore returns a PreSignatureStore keeping the presignatures over group in ks.
+// ks should be persistent, so that a presignature consumed before a crash stays consumed.
+func NewPreSignatureStore(ks keystore.Keystore, group curve.Curve) *PreSignatureStore {
+	return &PreSignatureStore{ks: ks, group: group}
+}
+
+func presignatureOpts(id types.RID) keyopts.Options {
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(id), "partyid", presignaturePartyID)
+	return opts
+}
+
+// Store persists sig under its ID. A used presignature cannot be stored.
+func (s *PreSignatureStore) Store(sig *PreSignature) error {
+	if sig.Used() {
+		return ErrPreSignatureUsed
+	}
+	if err := sig.ID.Validate(); err != nil {
+		return fmt.Errorf("presignature: %w", err)
+	}
+	data, err := sig.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	id := hex.EncodeToString(sig.ID)
+	return s.ks.Import(id, data, presignatureOpts(sig.ID))
+}
+
+// SignatureShare consumes the presignature stored under id, and returns its share σᵢ of the
+// signature of hash.
+//
+// The presignature is deleted from the keystore before the share is computed. It returns
+// ErrPreSignatureUsed if no presignature is stored under id, because it was already consumed or
+// was never stored, or if another caller consumes it concurrently.
+func (s *PreSignatureStore) SignatureShare(id types.RID, hash []byte) (curve.Scalar, error) {
+	data, err := keystore.Consume(s.ks, presignatureOpts(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPreSignatureUsed, err)
+	}
+	sig := EmptyPreSignature(s.group)
+	if err := sig.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sig.ID, id) {
+		return nil, errors.New("presignature: stored under another ID")
+	}
+	return sig.SignatureShare(hash)
+}
@@ -1,14 +1,22 @@
 package ecdsa
 
 import (
+	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	mrand "math/rand"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/party"
-	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
 )
 
 func generateShares(secret curve.Scalar, ids []party.ID) map[party.ID]curve.Scalar {
@@ -33,7 +41,11 @@ func generateShares(secret curve.Scalar, ids []party.ID) map[party.ID]curve.Scal
 func NewPreSignatures(group curve.Curve, N int) (x curve.Scalar, X curve.Point, preSignatures map[party.ID]*PreSignature) {
 	rand := mrand.New(mrand.NewSource(0))
 
-	partyIDs := test.PartyIDs(N)
+	// lib/test cannot be imported here, since it imports protocols/cmp/config which imports this package
+	partyIDs := make(party.IDSlice, N)
+	for i := range partyIDs {
+		partyIDs[i] = party.ID(rune('a' + i))
+	}
 
 	x = sample.Scalar(rand, group)
 	X = x.ActOnBase()
@@ -71,7 +83,11 @@ func TestPreSignature_Verify(t *testing.T) {
 	_, X, preSignatures := NewPreSignatures(group, N)
 	sigmaShares := make(map[party.ID]SignatureShare, N)
 	for id, preSignature := range preSignatures {
-		sigmaShares[id] = preSignature.SignatureShare(message)
+		sigma, err := preSignature.SignatureShare(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigmaShares[id] = sigma
 	}
 	for _, preSignature := range preSignatures {
 		signature := preSignature.Signature(sigmaShares)
@@ -92,7 +108,11 @@ func TestPreSignature_Fail(t *testing.T) {
 		if culprit == "" {
 			culprit = id
 		}
-		sigmaShares[id] = preSignature.SignatureShare(message)
+		sigma, err := preSignature.SignatureShare(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigmaShares[id] = sigma
 	}
 
 	sigmaShares[culprit].Invert()
@@ -108,3 +128,150 @@ func TestPreSignature_Fail(t *testing.T) {
 		}
 	}
 }
+
+func TestPreSignature_Persist(t *testing.T) {
+	N := 3
+	group := curve.Secp256k1{}
+	message := []byte("HELLO WORLD")
+	_, X, preSignatures := NewPreSignatures(group, N)
+
+	// presign phase: every party stores its presignature
+	dir := t.TempDir()
+	for id, preSignature := range preSignatures {
+		data, err := preSignature.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, string(id)), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reload := func(id party.ID) *PreSignature {
+		data, err := os.ReadFile(filepath.Join(dir, string(id)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		preSignature := EmptyPreSignature(group)
+		if err := preSignature.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		return preSignature
+	}
+
+	// online phase: the presignatures are reloaded in a fresh state, used, and stored again
+	sigmaShares := make(map[party.ID]SignatureShare, N)
+	reloaded := make(map[party.ID]*PreSignature, N)
+	for id := range preSignatures {
+		preSignature := reload(id)
+		if preSignature.Used() {
+			t.Fatal("presignature should not be used yet")
+		}
+		sigma, err := preSignature.SignatureShare(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigmaShares[id] = sigma
+		reloaded[id] = preSignature
+
+		data, err := preSignature.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, string(id)), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, preSignature := range reloaded {
+		if !preSignature.Signature(sigmaShares).Verify(X, message) {
+			t.Error("failed to validate signature of reloaded presignature")
+		}
+	}
+
+	for id, preSignature := range reloaded {
+		if _, err := preSignature.SignatureShare(message); !errors.Is(err, ErrPreSignatureUsed) {
+			t.Errorf("expected %v when signing twice, got %v", ErrPreSignatureUsed, err)
+		}
+
+		// a second reload keeps the used flag
+		if _, err := reload(id).SignatureShare([]byte("OTHER MESSAGE")); !errors.Is(err, ErrPreSignatureUsed) {
+			t.Errorf("expected %v after reload, got %v", ErrPreSignatureUsed, err)
+		}
+
+		// the used flag can't be reset by unmarshalling the original encoding
+		fresh, err := preSignatures[id].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := preSignature.UnmarshalBinary(fresh); !errors.Is(err, ErrPreSignatureUsed) {
+			t.Errorf("expected %v when unmarshalling into a used presignature, got %v", ErrPreSignatureUsed, err)
+		}
+	}
+}
+
+func TestPreSignatureStore(t *testing.T) {
+	N := 3
+	group := curve.Secp256k1{}
+	message := []byte("HELLO WORLD")
+	_, X, preSignatures := NewPreSignatures(group, N)
+
+	// the presignatures of all parties share the ID of their session
+	id, err := types.NewRID(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := make(map[party.ID]*PreSignatureStore, N)
+	for j, preSignature := range preSignatures {
+		preSignature.ID = id
+		stores[j] = NewPreSignatureStore(
+			keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()), group)
+		if err := stores[j].Store(preSignature); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// concurrent uses of a stored presignature produce a single share
+	sigmaShares := make(map[party.ID]SignatureShare, N)
+	for j, store := range stores {
+		var mtx sync.Mutex
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sigma, err := store.SignatureShare(id, message)
+				if err != nil {
+					if !errors.Is(err, ErrPreSignatureUsed) {
+						t.Error(err)
+					}
+					return
+				}
+				mtx.Lock()
+				defer mtx.Unlock()
+				if _, ok := sigmaShares[j]; ok {
+					t.Errorf("presignature of %s produced two shares", j)
+				}
+				sigmaShares[j] = sigma
+			}()
+		}
+		wg.Wait()
+	}
+	if len(sigmaShares) != N {
+		t.Fatalf("got %d shares, expected %d", len(sigmaShares), N)
+	}
+	for _, preSignature := range preSignatures {
+		if !preSignature.Signature(sigmaShares).Verify(X, message) {
+			t.Error("failed to validate signature of stored presignature")
+		}
+	}
+
+	// the consumed presignature can't be reloaded, also by another store on the same keystore
+	for j := range preSignatures {
+		if _, err := stores[j].SignatureShare(id, []byte("OTHER MESSAGE")); !errors.Is(err, ErrPreSignatureUsed) {
+			t.Errorf("expected %v after reload, got %v", ErrPreSignatureUsed, err)
+		}
+		if _, err := NewPreSignatureStore(stores[j].ks, group).SignatureShare(id, []byte("OTHER MESSAGE")); !errors.Is(err, ErrPreSignatureUsed) {
+			t.Errorf("expected %v from another store, got %v", ErrPreSignatureUsed, err)
+		}
+	}
+}
@@ -1,12 +1,23 @@
 package ecdsa
 
 import (
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 )
 
+// secp256k1P is the order of the base field of secp256k1.
+var secp256k1P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
 type Signature struct {
 	R curve.Point
 	S curve.Scalar
+	// RecoveryID is the recovery id of R for RecoverPublicKey. It is set by the signing
+	// protocols, and can be computed with ComputeRecoveryID.
+	RecoveryID byte
 }
 
 // EmptySignature returns a new signature with a given curve, ready to be unmarshalled.
@@ -32,43 +43,26 @@ func (sig Signature) Verify(X curve.Point, hash []byte) bool {
 	return R2.Equal(sig.R)
 }
 
-// get a signature in ethereum format
-func (sig Signature) SigEthereum() ([]byte, error) {
-	IsOverHalfOrder := sig.S.IsOverHalfOrder() // s-values greater than secp256k1n/2 are considered invalid
-
-	if IsOverHalfOrder {
-		sig.S.Negate()
-	}
-
-	r, err := sig.R.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-	s, err := sig.S.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-
-	rs := make([]byte, 0, 65)
-	rs = append(rs, r...)
-	rs = append(rs, s...)
-
-	if IsOverHalfOrder {
-		v := rs[0] - 2 // Convert to Ethereum signature format with 'recovery id' v at the end.
-		copy(rs, rs[1:])
-		rs[64] = v ^ 1
-	} else {
-		v := rs[0] - 2
-		copy(rs, rs[1:])
-		rs[64] = v
+// Digest returns the digest of msg signed by Verify, which is msg itself when newHash is nil.
+func Digest(msg []byte, newHash func() hash.Hash) []byte {
+	if newHash == nil {
+		return msg
 	}
+	h := newHash()
+	_, _ = h.Write(msg)
+	return h.Sum(nil)
+}
 
-	r[0] = rs[64] + 2
-	if err := sig.R.UnmarshalBinary(r); err != nil {
-		return nil, err
-	}
+// VerifyMessage is like Verify, but first hashes msg with newHash, as done by Digest.
+func (sig Signature) VerifyMessage(X curve.Point, msg []byte, newHash func() hash.Hash) bool {
+	return sig.Verify(X, Digest(msg, newHash))
+}
 
-	return rs, nil
+// SigEthereum returns the signature in the 65 byte r ‖ s ‖ v form used by Ethereum.
+//
+// Deprecated: use MarshalCompact(true), which produces the same encoding.
+func (sig Signature) SigEthereum() ([]byte, error) {
+	return sig.MarshalCompact(true)
 }
 
 func SignatureFromEth(sig [65]byte) (*Signature, error) {
@@ -93,3 +87,76 @@ func SignatureFromEth(sig [65]byte) (*Signature, error) {
 
 	return &signature, nil
 }
+
+// RecoverPublicKey recovers the public key X for which sig is a valid signature of digest.
+// Only the x-coordinate of R is used. The recoveryID encodes the parity of the y-coordinate
+// of R in its lowest bit, and whether the x-coordinate of R overflowed the group order in its
+// second bit, so that only values 0 to 3 are valid.
+func (sig Signature) RecoverPublicKey(digest []byte, recoveryID byte) (curve.Point, error) {
+	group := curve.Secp256k1{}
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("ecdsa: nil signature")
+	}
+	if _, ok := sig.R.Curve().(curve.Secp256k1); !ok {
+		return nil, errors.New("ecdsa: public key recovery is only supported for secp256k1")
+	}
+	if recoveryID > 3 {
+		return nil, fmt.Errorf("ecdsa: invalid recovery id %d", recoveryID)
+	}
+
+	r := sig.R.XScalar()
+	if r.IsZero() || sig.S.IsZero() {
+		return nil, errors.New("ecdsa: invalid signature")
+	}
+	rBytes, err := r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// x = r (+ n if the x-coordinate of R was reduced modulo the group order)
+	x := new(big.Int).SetBytes(rBytes)
+	if recoveryID&2 != 0 {
+		x.Add(x, group.Order().Big())
+		if x.Cmp(secp256k1P) >= 0 {
+			return nil, fmt.Errorf("ecdsa: invalid recovery id %d", recoveryID)
+		}
+	}
+
+	compressed := make([]byte, 33)
+	compressed[0] = 2 + recoveryID&1
+	x.FillBytes(compressed[1:])
+	R := group.NewPoint()
+	if err := R.UnmarshalBinary(compressed); err != nil {
+		return nil, fmt.Errorf("ecdsa: %w", err)
+	}
+
+	// X = r⁻¹⋅(s⋅R - m⋅G)
+	m := curve.FromHash(group, digest)
+	rInv := group.NewScalar().Set(r).Invert()
+	X := sig.S.Act(R).Sub(m.ActOnBase())
+	X = rInv.Act(X)
+	if X.IsIdentity() {
+		return nil, errors.New("ecdsa: recovered public key is the identity")
+	}
+	return X, nil
+}
+
+// ComputeRecoveryID returns the recovery id of the signature for RecoverPublicKey: the parity of
+// the y-coordinate of R, plus 2 if its x-coordinate is not smaller than the group order.
+func (sig Signature) ComputeRecoveryID() (byte, error) {
+	if sig.R == nil {
+		return 0, errors.New("ecdsa: nil signature")
+	}
+	return recoveryID(sig.R)
+}
+
+// SigEthereumRSV returns the signature in the 65 byte r ‖ s ‖ v format used by Ethereum,
+// with s normalized to the lower half of the group order, like MarshalCompact(true). Unlike
+// MarshalCompact, it fails if the signature is not valid for the public key X and digest, so
+// that the recovery id v is known to recover X.
+func (sig Signature) SigEthereumRSV(X curve.Point, digest []byte) ([]byte, error) {
+	if !sig.Verify(X, digest) {
+		return nil, errors.New("ecdsa: signature is not valid for the public key")
+	}
+	return sig.MarshalCompact(true)
+}
@@ -2,10 +2,13 @@ package ecdsa
 
 import (
 	"crypto/rand"
+	"math/big"
 	"testing"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func NewSignature(x curve.Scalar, hash []byte, k curve.Scalar) *Signature {
@@ -59,3 +62,134 @@ func TestSignature_Verify_Zero(t *testing.T) {
 		t.Error("zero R/S signature should not verify")
 	}
 }
+
+func TestSignature_RecoverPublicKey(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	for i := 0; i < 8; i++ {
+		m := []byte("recover me")
+		x := sample.Scalar(rand.Reader, group)
+		X := x.ActOnBase()
+		sig := NewSignature(x, m, nil)
+
+		// the recovery id is determined by the parity of R's y-coordinate;
+		// overflow of R's x-coordinate past the group order is negligible.
+		rBytes, err := sig.R.MarshalBinary()
+		require.NoError(t, err)
+		expected := rBytes[0] - 2
+		v, err := sig.ComputeRecoveryID()
+		require.NoError(t, err)
+		assert.Equal(t, expected, v)
+
+		for v := byte(0); v < 4; v++ {
+			recovered, err := sig.RecoverPublicKey(m, v)
+			if v == expected {
+				require.NoError(t, err)
+				assert.True(t, recovered.Equal(X), "recovery id %d should yield the public key", v)
+			} else if err == nil {
+				assert.False(t, recovered.Equal(X), "recovery id %d should not yield the public key", v)
+			}
+		}
+
+		_, err = sig.RecoverPublicKey(m, 4)
+		assert.Error(t, err)
+	}
+}
+
+func TestSignature_SigEthereumRSV(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	m := []byte("hello")
+	x := sample.Scalar(rand.Reader, group)
+	X := x.ActOnBase()
+	sig := NewSignature(x, m, nil)
+
+	rsv, err := sig.SigEthereumRSV(X, m)
+	require.NoError(t, err)
+	require.Len(t, rsv, 65)
+
+	s := group.NewScalar()
+	require.NoError(t, s.UnmarshalBinary(rsv[32:64]))
+	assert.False(t, s.IsOverHalfOrder())
+
+	recovered, err := Signature{R: sig.R, S: s}.RecoverPublicKey(m, rsv[64])
+	require.NoError(t, err)
+	assert.True(t, recovered.Equal(X))
+
+	_, err = sig.SigEthereumRSV(sample.Scalar(rand.Reader, group).ActOnBase(), m)
+	assert.Error(t, err)
+}
+
+// signatureWithRecoveryID returns a signature of m and its public key, such that the recovery id
+// of the signature is v.
+func signatureWithRecoveryID(t *testing.T, m []byte, v byte) (*Signature, curve.Point) {
+	t.Helper()
+	group := curve.Secp256k1{}
+
+	if v < 2 {
+		// R = k⁻¹⋅G, so negating k negates R and flips the parity of its y-coordinate
+		x := sample.Scalar(rand.Reader, group)
+		k := sample.Scalar(rand.Reader, group)
+		sig := NewSignature(x, m, k)
+		if id, err := sig.ComputeRecoveryID(); err == nil && id != v {
+			sig = NewSignature(x, m, k.Negate())
+		}
+		return sig, x.ActOnBase()
+	}
+
+	// An x-coordinate of R past the group order cannot be hit by sampling k, so R is chosen
+	// first, with r = x - n ≠ 0, and the public key is derived from it as X = r⁻¹⋅(s⋅R - m⋅G).
+	x := new(big.Int).Add(group.Order().Big(), big.NewInt(1))
+	compressed := make([]byte, 33)
+	compressed[0] = 2 + v&1
+	R := group.NewPoint()
+	for {
+		x.FillBytes(compressed[1:])
+		if err := R.UnmarshalBinary(compressed); err == nil {
+			break
+		}
+		x.Add(x, big.NewInt(1))
+	}
+	s := sample.Scalar(rand.Reader, group)
+	rInv := group.NewScalar().Set(R.XScalar()).Invert()
+	X := rInv.Act(s.Act(R).Sub(curve.FromHash(group, m).ActOnBase()))
+	return &Signature{R: R, S: s}, X
+}
+
+func TestSignature_RecoverPublicKey_RecoveryIDs(t *testing.T) {
+	m := []byte("recover me")
+
+	tests := []struct {
+		name string
+		v    byte
+	}{
+		{"even y", 0},
+		{"odd y", 1},
+		{"x past the group order, even y", 2},
+		{"x past the group order, odd y", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, X := signatureWithRecoveryID(t, m, tt.v)
+			require.True(t, sig.Verify(X, m))
+
+			v, err := sig.ComputeRecoveryID()
+			require.NoError(t, err)
+			require.Equal(t, tt.v, v)
+
+			recovered, err := sig.RecoverPublicKey(m, tt.v)
+			require.NoError(t, err)
+			assert.True(t, recovered.Equal(X))
+
+			for other := byte(0); other < 4; other++ {
+				if other == tt.v {
+					continue
+				}
+				recovered, err := sig.RecoverPublicKey(m, other)
+				if err == nil {
+					assert.False(t, recovered.Equal(X), "recovery id %d should not yield the public key", other)
+				}
+			}
+		})
+	}
+}
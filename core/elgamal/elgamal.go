@@ -14,16 +14,30 @@ type (
 
 // Encrypt returns the encryption of `message` as (L=nonce⋅G, M=message⋅G + nonce⋅public), as well as the `nonce`.
 func Encrypt(public PublicKey, message curve.Scalar) (*Ciphertext, Nonce) {
+	return EncryptPoint(public, message.ActOnBase())
+}
+
+// EncryptPoint returns the encryption of the point `message` as (L=nonce⋅G, M=message + nonce⋅public),
+// as well as the `nonce`.
+func EncryptPoint(public PublicKey, message curve.Point) (*Ciphertext, Nonce) {
 	group := public.Curve()
 	nonce := sample.Scalar(rand.Reader, group)
 	L := nonce.ActOnBase()
-	M := message.ActOnBase().Add(nonce.Act(public))
+	M := message.Add(nonce.Act(public))
 	return &Ciphertext{
 		L: L,
 		M: M,
 	}, nonce
 }
 
+// Decrypt returns the point M - secret⋅L encrypted by ct for the public key secret⋅G.
+//
+// A ciphertext carries no integrity check, so decrypting with another secret returns an
+// unrelated point rather than an error.
+func Decrypt(secret curve.Scalar, ct *Ciphertext) curve.Point {
+	return ct.M.Sub(secret.Act(ct.L))
+}
+
 func (Ciphertext) Domain() string {
 	return "ElGamal Ciphertext"
 }
@@ -29,9 +29,11 @@ func (Commitment) Domain() string {
 	return "Commitment"
 }
 
+// Validate checks that the commitment is non-zero, and has the length of either a BLAKE3 digest
+// or of a 256 bit digest such as SHA-256 and SHA3-256.
 func (c Commitment) Validate() error {
-	if l := len(c); l != DigestLengthBytes {
-		return fmt.Errorf("commitment: incorrect length (got %d, expected %d)", l, DigestLengthBytes)
+	if l := len(c); l != DigestLengthBytes && l != params.SecBytes {
+		return fmt.Errorf("commitment: incorrect length (got %d, expected %d or %d)", l, DigestLengthBytes, params.SecBytes)
 	}
 	for _, b := range c {
 		if b != 0 {
@@ -33,6 +33,11 @@ type Curve interface {
 	SafeScalarBytes() int
 	// Order returns a Modulus holding order of this group.
 	Order() *saferith.Modulus
+	// MultiScalarMult returns ∑ᵢ scalars[i]⋅points[i], which can be computed faster than
+	// each term on its own. It panics if the slices have different lengths.
+	//
+	// This runs in variable time, and should only be used with public scalars.
+	MultiScalarMult(scalars []Scalar, points []Point) Point
 }
 
 // Scalar represents a number modulo the order of some Elliptic Curve group.
@@ -46,6 +51,9 @@ type Curve interface {
 // with elements of the same type. It's perfectly fine to cast incoming elements
 // to your concrete type. This interface is not designed to be able to handle
 // different Scalar types, but we can't encode that in the type system.
+//
+// Since scalars hold secrets such as key shares and nonces, Add, Sub, Negate, Mul, Invert and
+// SetNat should run in constant time, as well as Equal and IsZero.
 type Scalar interface {
 	// This should encode the Scalar as Big Endian bytes, without failure.
 	encoding.BinaryMarshaler
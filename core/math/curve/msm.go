@@ -0,0 +1,105 @@
+package curve
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// multiScalarMultNaive returns ∑ᵢ scalars[i]⋅points[i], computing each term on its own.
+func multiScalarMultNaive(group Curve, scalars []Scalar, points []Point) Point {
+	checkMultiScalarMult(scalars, points)
+	sum := group.NewPoint()
+	for i := range scalars {
+		sum = sum.Add(scalars[i].Act(points[i]))
+	}
+	return sum
+}
+
+func checkMultiScalarMult(scalars []Scalar, points []Point) {
+	if len(scalars) != len(points) {
+		panic(fmt.Sprintf("curve.MultiScalarMult: %d scalars for %d points", len(scalars), len(points)))
+	}
+}
+
+// pippengerWindow returns the width in bits of the windows used to multiply n points.
+func pippengerWindow(n int) int {
+	c := bits.Len(uint(n)) - 2
+	if c < 2 {
+		return 2
+	}
+	if c > 12 {
+		return 12
+	}
+	return c
+}
+
+// scalarWindow returns the width bits of the big endian scalar b starting at bit, counting from
+// the least significant one.
+func scalarWindow(b *[32]byte, bit, width int) int {
+	var w int
+	for j := 0; j < width && bit+j < 256; j++ {
+		pos := bit + j
+		w |= int(b[31-pos/8]>>(pos%8)&1) << j
+	}
+	return w
+}
+
+// MultiScalarMult implements Curve with Pippenger's bucket method.
+//
+// The scalars are split into windows of c bits. For each window, starting from the most
+// significant one, the sum is doubled c times, and every point is added to the bucket of its
+// scalar's window, so that ∑ₖ k⋅bucketₖ, computed with running sums, is the contribution of the window.
+func (Secp256k1) MultiScalarMult(scalars []Scalar, points []Point) Point {
+	checkMultiScalarMult(scalars, points)
+	out := new(Secp256k1Point)
+	if len(points) == 0 {
+		return out
+	}
+
+	ss := make([][32]byte, len(scalars))
+	ps := make([]*secp256k1.JacobianPoint, len(points))
+	for i := range scalars {
+		ss[i] = secp256k1CastScalar(scalars[i]).value.Bytes()
+		ps[i] = &secp256k1CastPoint(points[i]).value
+	}
+
+	c := pippengerWindow(len(points))
+	buckets := make([]secp256k1.JacobianPoint, 1<<c)
+	var sum, tmp secp256k1.JacobianPoint
+	add := func(p *secp256k1.JacobianPoint, q *secp256k1.JacobianPoint) {
+		secp256k1.AddNonConst(p, q, &tmp)
+		p.Set(&tmp)
+	}
+	for bit := ((256+c-1)/c - 1) * c; bit >= 0; bit -= c {
+		for k := 0; k < c; k++ {
+			secp256k1.DoubleNonConst(&sum, &tmp)
+			sum.Set(&tmp)
+		}
+
+		for k := range buckets {
+			buckets[k] = secp256k1.JacobianPoint{}
+		}
+		for i := range ps {
+			if k := scalarWindow(&ss[i], bit, c); k != 0 {
+				add(&buckets[k], ps[i])
+			}
+		}
+
+		// ∑ₖ k⋅bucketₖ = ∑ₖ ∑_{l ≥ k} bucketₗ
+		var running, window secp256k1.JacobianPoint
+		for k := len(buckets) - 1; k > 0; k-- {
+			add(&running, &buckets[k])
+			add(&window, &running)
+		}
+		add(&sum, &window)
+	}
+	out.value.Set(&sum)
+	return out
+}
+
+// MultiScalarMult implements Curve.
+func (group P256) MultiScalarMult(scalars []Scalar, points []Point) Point {
+	return multiScalarMultNaive(group, scalars, points)
+}
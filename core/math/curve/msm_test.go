@@ -0,0 +1,69 @@
+package curve_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveMultiScalarMult accumulates scalars[i]⋅points[i] term by term.
+func naiveMultiScalarMult(group curve.Curve, scalars []curve.Scalar, points []curve.Point) curve.Point {
+	sum := group.NewPoint()
+	for i := range scalars {
+		sum = sum.Add(scalars[i].Act(points[i]))
+	}
+	return sum
+}
+
+func randomTerms(group curve.Curve, n int) ([]curve.Scalar, []curve.Point) {
+	scalars := make([]curve.Scalar, n)
+	points := make([]curve.Point, n)
+	for i := range scalars {
+		scalars[i] = sample.Scalar(rand.Reader, group)
+		points[i] = sample.Scalar(rand.Reader, group).ActOnBase()
+	}
+	return scalars, points
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, group := range []curve.Curve{curve.Secp256k1{}, curve.P256{}} {
+		for _, n := range []int{0, 1, 2, 7, 33, 200} {
+			scalars, points := randomTerms(group, n)
+			if n > 2 {
+				// zero scalars, identity points, and a point added to its negation
+				scalars[0] = group.NewScalar()
+				points[1] = group.NewPoint()
+				points[2] = points[3].Negate()
+				scalars[2] = group.NewScalar().Set(scalars[3])
+			}
+			expected := naiveMultiScalarMult(group, scalars, points)
+			assert.True(t, expected.Equal(group.MultiScalarMult(scalars, points)), "%s with %d terms", group.Name(), n)
+		}
+
+		assert.Panics(t, func() {
+			scalars, points := randomTerms(group, 3)
+			group.MultiScalarMult(scalars, points[:2])
+		})
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	group := curve.Secp256k1{}
+	for _, n := range []int{8, 33, 256} {
+		scalars, points := randomTerms(group, n)
+		b.Run(fmt.Sprintf("pippenger/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				group.MultiScalarMult(scalars, points)
+			}
+		})
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveMultiScalarMult(group, scalars, points)
+			}
+		})
+	}
+}
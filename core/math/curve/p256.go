@@ -0,0 +1,266 @@
+package curve
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"filippo.io/nistec"
+	"github.com/cronokirby/saferith"
+)
+
+var (
+	p256OrderNat, _ = new(saferith.Nat).SetHex("FFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551")
+	p256Order       = saferith.ModulusFromNat(p256OrderNat)
+	p256HalfOrder   = new(big.Int).Rsh(p256Order.Big(), 1)
+	p256PointBytes  = 33
+)
+
+// P256 is the NIST P-256 curve, also known as secp256r1.
+type P256 struct{}
+
+func (P256) NewPoint() Point {
+	return new(P256Point)
+}
+
+func (P256) NewBasePoint() Point {
+	return &P256Point{value: nistec.NewP256Point().SetGenerator()}
+}
+
+func (P256) NewScalar() Scalar {
+	return &P256Scalar{value: new(saferith.Nat).SetUint64(0).Resize(256)}
+}
+
+func (P256) ScalarBits() int {
+	return 256
+}
+
+func (P256) SafeScalarBytes() int {
+	return 32
+}
+
+func (P256) Order() *saferith.Modulus {
+	return p256Order
+}
+
+func (P256) Name() string {
+	return "secp256r1"
+}
+
+type P256Scalar struct {
+	value *saferith.Nat
+}
+
+func p256CastScalar(generic Scalar) *P256Scalar {
+	out, ok := generic.(*P256Scalar)
+	if !ok {
+		panic(fmt.Sprintf("failed to convert to p256Scalar: %v", generic))
+	}
+	return out
+}
+
+func (*P256Scalar) Curve() Curve {
+	return P256{}
+}
+
+func (s *P256Scalar) MarshalBinary() ([]byte, error) {
+	return s.value.FillBytes(make([]byte, 32)), nil
+}
+
+func (s *P256Scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return fmt.Errorf("invalid length for p256 scalar: %d", len(data))
+	}
+	value := new(saferith.Nat).SetBytes(data)
+	if _, _, lt := value.CmpMod(p256Order); lt != 1 {
+		return errors.New("invalid bytes for p256 scalar")
+	}
+	s.value = value.Resize(256)
+	return nil
+}
+
+func (s *P256Scalar) Add(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value = new(saferith.Nat).ModAdd(s.value, other.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Sub(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value = new(saferith.Nat).ModSub(s.value, other.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Mul(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value = new(saferith.Nat).ModMul(s.value, other.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Invert() Scalar {
+	s.value = new(saferith.Nat).ModInverse(s.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Negate() Scalar {
+	s.value = new(saferith.Nat).ModNeg(s.value, p256Order)
+	return s
+}
+
+func (s *P256Scalar) IsOverHalfOrder() bool {
+	return s.value.Big().Cmp(p256HalfOrder) > 0
+}
+
+func (s *P256Scalar) Equal(that Scalar) bool {
+	other := p256CastScalar(that)
+
+	return s.value.Eq(other.value) == 1
+}
+
+func (s *P256Scalar) IsZero() bool {
+	return s.value.EqZero() == 1
+}
+
+func (s *P256Scalar) Set(that Scalar) Scalar {
+	other := p256CastScalar(that)
+
+	s.value = new(saferith.Nat).SetNat(other.value)
+	return s
+}
+
+func (s *P256Scalar) SetNat(x *saferith.Nat) Scalar {
+	s.value = new(saferith.Nat).Mod(x, p256Order)
+	return s
+}
+
+func (s *P256Scalar) Act(that Point) Point {
+	other := p256CastPoint(that)
+	data, _ := s.MarshalBinary()
+	out, err := nistec.NewP256Point().ScalarMult(other.point(), data)
+	if err != nil {
+		panic(fmt.Sprintf("p256Scalar.Act: %v", err))
+	}
+	return &P256Point{value: out}
+}
+
+func (s *P256Scalar) ActOnBase() Point {
+	data, _ := s.MarshalBinary()
+	out, err := nistec.NewP256Point().ScalarBaseMult(data)
+	if err != nil {
+		panic(fmt.Sprintf("p256Scalar.ActOnBase: %v", err))
+	}
+	return &P256Point{value: out}
+}
+
+// P256Point is a point of P256. The zero value is the identity.
+//
+// The arithmetic is done by filippo.io/nistec, in constant time.
+type P256Point struct {
+	value *nistec.P256Point
+}
+
+// point returns the nistec point of p, which is nil for the zero value.
+func (p *P256Point) point() *nistec.P256Point {
+	if p == nil || p.value == nil {
+		return nistec.NewP256Point()
+	}
+	return p.value
+}
+
+func p256CastPoint(generic Point) *P256Point {
+	out, ok := generic.(*P256Point)
+	if !ok {
+		panic(fmt.Sprintf("failed to convert to p256Point: %v", generic))
+	}
+	return out
+}
+
+func (*P256Point) Curve() Curve {
+	return P256{}
+}
+
+// MarshalBinary returns the compressed SEC 1 encoding of the point, or 33 zero bytes for the identity.
+func (p *P256Point) MarshalBinary() ([]byte, error) {
+	data := p.point().BytesCompressed()
+	if len(data) != p256PointBytes {
+		return make([]byte, p256PointBytes), nil
+	}
+	return data, nil
+}
+
+func (p *P256Point) UnmarshalBinary(data []byte) error {
+	if len(data) != p256PointBytes {
+		return fmt.Errorf("invalid length for p256Point: %d", len(data))
+	}
+	if data[0] == 0 {
+		for _, b := range data[1:] {
+			if b != 0 {
+				return errors.New("p256Point.UnmarshalBinary: invalid identity encoding")
+			}
+		}
+		p.value = nistec.NewP256Point()
+		return nil
+	}
+	value, err := nistec.NewP256Point().SetBytes(data)
+	if err != nil {
+		return fmt.Errorf("p256Point.UnmarshalBinary: %w", err)
+	}
+	p.value = value
+	return nil
+}
+
+func (p *P256Point) Add(that Point) Point {
+	other := p256CastPoint(that)
+
+	return &P256Point{value: nistec.NewP256Point().Add(p.point(), other.point())}
+}
+
+func (p *P256Point) Sub(that Point) Point {
+	return p.Add(that.Negate())
+}
+
+func (p *P256Point) Set(that Point) Point {
+	other := p256CastPoint(that)
+
+	p.value = nistec.NewP256Point().Set(other.point())
+	return p
+}
+
+// Negate flips the sign of the y coordinate in the compressed encoding of p, since -(x, y) = (x, -y)
+// and y is never zero on P256.
+func (p *P256Point) Negate() Point {
+	data := p.point().BytesCompressed()
+	if len(data) != p256PointBytes {
+		return new(P256Point)
+	}
+	data[0] ^= 1
+	value, err := nistec.NewP256Point().SetBytes(data)
+	if err != nil {
+		panic(fmt.Sprintf("p256Point.Negate: %v", err))
+	}
+	return &P256Point{value: value}
+}
+
+func (p *P256Point) Equal(that Point) bool {
+	other := p256CastPoint(that)
+
+	return subtle.ConstantTimeCompare(p.point().Bytes(), other.point().Bytes()) == 1
+}
+
+func (p *P256Point) IsIdentity() bool {
+	return len(p.point().Bytes()) == 1
+}
+
+func (p *P256Point) XScalar() Scalar {
+	x, err := p.point().BytesX()
+	if err != nil {
+		return P256{}.NewScalar()
+	}
+	out := new(P256Scalar)
+	out.SetNat(new(saferith.Nat).SetBytes(x))
+	return out
+}
@@ -0,0 +1,69 @@
+package curve
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestP256Point_ActOnBase(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		pk, err := sk.PublicKey.ECDH()
+		require.NoError(t, err)
+		expected := pk.Bytes()
+
+		x := P256{}.NewScalar().SetNat(new(saferith.Nat).SetBytes(sk.D.Bytes()))
+		X := x.ActOnBase().(*P256Point)
+		assert.Equal(t, expected, X.point().Bytes())
+		assert.True(t, X.Equal(x.Act(P256{}.NewBasePoint())))
+
+		data, err := X.MarshalBinary()
+		require.NoError(t, err)
+		decoded := P256{}.NewPoint()
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.True(t, X.Equal(decoded))
+	}
+}
+
+func TestP256Point_Group(t *testing.T) {
+	identity := P256{}.NewPoint()
+	assert.True(t, identity.IsIdentity())
+	assert.True(t, P256{}.NewScalar().ActOnBase().IsIdentity())
+	assert.True(t, identity.Negate().IsIdentity())
+
+	data, err := identity.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 33), data)
+	decoded := P256{}.NewPoint()
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.IsIdentity())
+
+	var buf [32]byte
+	_, err = rand.Read(buf[:])
+	require.NoError(t, err)
+	x := P256{}.NewScalar().SetNat(new(saferith.Nat).SetBytes(buf[:]))
+	X := x.ActOnBase()
+	negX := P256{}.NewScalar().Set(x).Negate().ActOnBase()
+
+	assert.True(t, X.Negate().Equal(negX))
+	assert.True(t, X.Add(negX).IsIdentity())
+	assert.True(t, X.Sub(X).IsIdentity())
+	assert.True(t, X.Add(identity).Equal(X))
+	assert.True(t, identity.Add(X).Equal(X))
+	assert.True(t, X.Add(X).Equal(P256{}.NewScalar().Set(x).Add(x).ActOnBase()))
+	assert.False(t, X.Equal(negX))
+	assert.True(t, new(P256Point).Set(X).Equal(X))
+	assert.True(t, identity.XScalar().IsZero())
+
+	invalid := bytes.Repeat([]byte{0xff}, 33)
+	invalid[0] = 2
+	assert.Error(t, P256{}.NewPoint().UnmarshalBinary(invalid), "x coordinate out of range")
+}
@@ -67,6 +67,11 @@ func (Secp256k1) Name() string {
 	return "secp256k1"
 }
 
+// Secp256k1Scalar is a scalar of secp256k1.
+//
+// Add, Sub, Negate, Mul, Invert, SetNat, Set, Equal, IsZero and IsOverHalfOrder run in constant
+// time, so they may be used on secret scalars such as shares and nonces. Act and ActOnBase do not:
+// the scalar multiplication of the secp256k1 backend runs in variable time.
 type Secp256k1Scalar struct {
 	value secp256k1.ModNScalar
 }
@@ -125,8 +130,16 @@ func (s *Secp256k1Scalar) Mul(that Scalar) Scalar {
 	return s
 }
 
+// Invert sets s to s⁻¹, or leaves it 0 if s is 0.
+//
+// The inverse of the secp256k1 backend runs in variable time, so it is computed with saferith
+// instead, like for P256.
 func (s *Secp256k1Scalar) Invert() Scalar {
-	s.value.InverseNonConst()
+	var data [32]byte
+	s.value.PutBytes(&data)
+	inverse := new(saferith.Nat).ModInverse(new(saferith.Nat).SetBytes(data[:]), secp256k1Order)
+	inverse.FillBytes(data[:])
+	s.value.SetBytes(&data)
 	return s
 }
 
@@ -157,8 +170,9 @@ func (s *Secp256k1Scalar) Set(that Scalar) Scalar {
 }
 
 func (s *Secp256k1Scalar) SetNat(x *saferith.Nat) Scalar {
-	reduced := new(saferith.Nat).Mod(x, secp256k1Order)
-	s.value.SetByteSlice(reduced.Bytes())
+	var data [32]byte
+	new(saferith.Nat).Mod(x, secp256k1Order).FillBytes(data[:])
+	s.value.SetBytes(&data)
 	return s
 }
 
@@ -0,0 +1,67 @@
+package curve
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSecp256k1Scalar(t testing.TB) *Secp256k1Scalar {
+	var data [32]byte
+	_, err := rand.Read(data[:])
+	require.NoError(t, err)
+	return Secp256k1{}.NewScalar().SetNat(new(saferith.Nat).SetBytes(data[:])).(*Secp256k1Scalar)
+}
+
+func TestSecp256k1Scalar_Invert(t *testing.T) {
+	one := Secp256k1{}.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	for i := 0; i < 32; i++ {
+		x := randomSecp256k1Scalar(t)
+		var expected Secp256k1Scalar
+		expected.value.InverseValNonConst(&x.value)
+
+		inverse := Secp256k1{}.NewScalar().Set(x).Invert()
+		assert.True(t, inverse.Equal(&expected))
+		assert.True(t, inverse.Mul(x).Equal(one))
+	}
+	assert.True(t, Secp256k1{}.NewScalar().Invert().IsZero())
+	assert.True(t, Secp256k1{}.NewScalar().Set(one).Invert().Equal(one))
+}
+
+func TestSecp256k1Scalar_SetNat(t *testing.T) {
+	order := Secp256k1{}.Order().Nat()
+	one := new(saferith.Nat).SetUint64(1)
+	assert.True(t, Secp256k1{}.NewScalar().SetNat(order).IsZero())
+	x := new(saferith.Nat).Add(order, one, 512)
+	assert.True(t, Secp256k1{}.NewScalar().SetNat(x).Equal(Secp256k1{}.NewScalar().SetNat(one)))
+}
+
+func benchmarkSecp256k1Scalar(b *testing.B, op func(x *Secp256k1Scalar)) {
+	x := randomSecp256k1Scalar(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(x)
+	}
+}
+
+func BenchmarkSecp256k1Scalar_Add(b *testing.B) {
+	y := randomSecp256k1Scalar(b)
+	benchmarkSecp256k1Scalar(b, func(x *Secp256k1Scalar) { x.Add(y) })
+}
+
+func BenchmarkSecp256k1Scalar_Mul(b *testing.B) {
+	y := randomSecp256k1Scalar(b)
+	benchmarkSecp256k1Scalar(b, func(x *Secp256k1Scalar) { x.Mul(y) })
+}
+
+func BenchmarkSecp256k1Scalar_Invert(b *testing.B) {
+	benchmarkSecp256k1Scalar(b, func(x *Secp256k1Scalar) { x.Invert() })
+}
+
+func BenchmarkSecp256k1Scalar_SetNat(b *testing.B) {
+	n := new(saferith.Nat).SetUint64(1)
+	benchmarkSecp256k1Scalar(b, func(x *Secp256k1Scalar) { x.SetNat(n) })
+}
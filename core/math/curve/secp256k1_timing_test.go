@@ -0,0 +1,88 @@
+//go:build timing
+
+// The timing tests measure the running time of operations on secret scalars. They are noisy on
+// shared machines, so they only run with the timing build tag:
+//
+//	go test -tags timing -run ConstantTime ./core/math/curve/
+
+package curve
+
+import (
+	"crypto/rand"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cronokirby/saferith"
+	"github.com/stretchr/testify/require"
+)
+
+// timingClasses measures op on operands of two classes, a fixed operand and random ones, in
+// random order as in dudect, and returns Welch's t-statistic of the two timing distributions. A
+// large |t| means that the running time of op depends on its operand.
+func timingClasses(t *testing.T, fixed *Secp256k1Scalar, op func(x *Secp256k1Scalar)) float64 {
+	const samples, batch = 4000, 16
+	randoms := make([]*Secp256k1Scalar, 64)
+	for i := range randoms {
+		randoms[i] = randomSecp256k1Scalar(t)
+	}
+	classes := make([]byte, samples)
+	_, err := rand.Read(classes)
+	require.NoError(t, err)
+
+	var n [2]float64
+	var mean, m2 [2]float64
+	x := new(Secp256k1Scalar)
+	for i, c := range classes {
+		class := c & 1
+		start := time.Now()
+		for j := 0; j < batch; j++ {
+			if class == 0 {
+				x.value.Set(&fixed.value)
+			} else {
+				x.value.Set(&randoms[(i+j)%len(randoms)].value)
+			}
+			op(x)
+		}
+		d := float64(time.Since(start))
+		if i < samples/10 {
+			// warm up
+			continue
+		}
+		n[class]++
+		delta := d - mean[class]
+		mean[class] += delta / n[class]
+		m2[class] += delta * (d - mean[class])
+	}
+	v0, v1 := m2[0]/(n[0]-1), m2[1]/(n[1]-1)
+	return (mean[0] - mean[1]) / math.Sqrt(v0/n[0]+v1/n[1])
+}
+
+func TestSecp256k1Scalar_ConstantTime(t *testing.T) {
+	// dudect considers |t| > 10 as evidence of a leak; the threshold leaves room for noise
+	const threshold = 20
+	y := randomSecp256k1Scalar(t)
+	one := Secp256k1{}.NewScalar().SetNat(new(saferith.Nat).SetUint64(1)).(*Secp256k1Scalar)
+	var data [32]byte
+	tests := map[string]func(x *Secp256k1Scalar){
+		"Add":    func(x *Secp256k1Scalar) { x.Add(y) },
+		"Mul":    func(x *Secp256k1Scalar) { x.Mul(y) },
+		"Invert": func(x *Secp256k1Scalar) { x.Invert() },
+		"SetNat": func(x *Secp256k1Scalar) {
+			x.value.PutBytes(&data)
+			x.SetNat(new(saferith.Nat).SetBytes(data[:]))
+		},
+	}
+	for name, op := range tests {
+		t.Run(name, func(t *testing.T) {
+			// retry once, since the machine may be busy during a measurement
+			var stat float64
+			for try := 0; try < 2; try++ {
+				if stat = timingClasses(t, one, op); math.Abs(stat) < threshold {
+					return
+				}
+			}
+			t.Errorf("running time depends on the operand: t = %.1f", stat)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package polynomial
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/cronokirby/saferith"
@@ -10,6 +11,10 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 )
 
+// ErrExponentDegree is returned by UnmarshalBinaryWithMaxDegree for a polynomial of too large a
+// degree.
+var ErrExponentDegree = errors.New("exponent: degree too large")
+
 type rawExponentData struct {
 	IsConstant   bool
 	Coefficients []curve.Point
@@ -50,8 +55,24 @@ func NewPolynomialExponent(polynomial *Polynomial) *Exponent {
 	return p
 }
 
-// Evaluate returns F(x) = [secret + a₁•x + … + aₜ•xᵗ]•G.
+// Evaluate returns F(x) = [secret + a₁•x + … + aₜ•xᵗ]•G, as the multi-scalar multiplication of
+// the coefficients by the powers of x.
 func (p *Exponent) Evaluate(x curve.Scalar) curve.Point {
+	powers := make([]curve.Scalar, len(p.coefficients))
+	xPower := p.group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	if p.IsConstant {
+		// since we start at index 1 of the polynomial, x must be x and not 1
+		xPower.Mul(x)
+	}
+	for i := range p.coefficients {
+		powers[i] = p.group.NewScalar().Set(xPower)
+		xPower.Mul(x)
+	}
+	return p.group.MultiScalarMult(powers, p.coefficients)
+}
+
+// evaluateHorner evaluates the polynomial with Horner's method.
+func (p *Exponent) evaluateHorner(x curve.Scalar) curve.Point {
 	result := p.group.NewPoint()
 
 	for i := len(p.coefficients) - 1; i >= 0; i-- {
@@ -137,6 +158,20 @@ func Sum(polynomials []*Exponent) (*Exponent, error) {
 	return summed, nil
 }
 
+// AddConstant returns the polynomial F(X) + c.
+func (p *Exponent) AddConstant(c curve.Point) *Exponent {
+	q := p.copy()
+	switch {
+	case c.IsIdentity():
+	case q.IsConstant:
+		q.IsConstant = false
+		q.coefficients = append([]curve.Point{c}, q.coefficients...)
+	default:
+		q.coefficients[0] = q.coefficients[0].Add(c)
+	}
+	return q
+}
+
 func (p *Exponent) copy() *Exponent {
 	q := &Exponent{
 		group:        p.group,
@@ -194,26 +229,71 @@ func EmptyExponent(group curve.Curve) *Exponent {
 	return &Exponent{group: group}
 }
 
+// exponentByteOrder is the byte order of the number of coefficients which prefixes an encoded
+// Exponent. It is big-endian regardless of the platform, like the encoding of the points.
+var exponentByteOrder = binary.BigEndian
+
+// exponentDecMode returns the CBOR decoding mode of an Exponent with size coefficients, which
+// rejects arrays of more than size elements. CBOR does not allow a bound below 16.
+func exponentDecMode(size int) (cbor.DecMode, error) {
+	if size < 16 {
+		size = 16
+	}
+	return cbor.DecOptions{MaxArrayElements: size}.DecMode()
+}
+
 func (e *Exponent) UnmarshalBinary(data []byte) error {
 	if e == nil || e.group == nil {
 		return errors.New("can't unmarshal Exponent with no group")
 	}
 	group := e.group
-	size := binary.BigEndian.Uint32(data)
+	if len(data) < 4 {
+		return errors.New("exponent: encoding is too short")
+	}
+	size := exponentByteOrder.Uint32(data)
+	// every coefficient takes at least one byte, which bounds the allocation below
+	if uint64(size) > uint64(len(data)-4) {
+		return errors.New("exponent: invalid number of coefficients")
+	}
+	// the prefix also bounds the array decoded below, so that it cannot be longer than announced
+	dm, err := exponentDecMode(int(size))
+	if err != nil {
+		return err
+	}
 	e.coefficients = make([]curve.Point, int(size))
 	for i := 0; i < len(e.coefficients); i++ {
 		e.coefficients[i] = group.NewPoint()
 	}
 	rawExponent := rawExponentData{Coefficients: e.coefficients}
-	if err := cbor.Unmarshal(data[4:], &rawExponent); err != nil {
+	if err := dm.Unmarshal(data[4:], &rawExponent); err != nil {
 		return err
 	}
+	if len(rawExponent.Coefficients) != int(size) {
+		return errors.New("exponent: invalid number of coefficients")
+	}
 	e.group = group
 	e.coefficients = rawExponent.Coefficients
 	e.IsConstant = rawExponent.IsConstant
 	return nil
 }
 
+// UnmarshalBinaryWithMaxDegree is like UnmarshalBinary, but fails if the encoded polynomial has a
+// degree larger than maxDegree. The number of coefficients is checked before they are decoded, so
+// that an oversized encoding received from another party is rejected cheaply; UnmarshalBinary
+// makes sure that the encoding holds no more coefficients than it announces.
+func (e *Exponent) UnmarshalBinaryWithMaxDegree(data []byte, maxDegree int) error {
+	if len(data) >= 4 && uint64(exponentByteOrder.Uint32(data)) > uint64(maxDegree)+1 {
+		return fmt.Errorf("%w: more than %d coefficients", ErrExponentDegree, maxDegree+1)
+	}
+	if err := e.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if e.Degree() > maxDegree {
+		return fmt.Errorf("%w: %d, at most %d", ErrExponentDegree, e.Degree(), maxDegree)
+	}
+	return nil
+}
+
 func (e *Exponent) MarshalBinary() ([]byte, error) {
 	data, err := cbor.Marshal(rawExponentData{
 		IsConstant:   e.IsConstant,
@@ -224,7 +304,7 @@ func (e *Exponent) MarshalBinary() ([]byte, error) {
 	}
 	out := make([]byte, 4+len(data))
 	size := len(e.coefficients)
-	binary.BigEndian.PutUint32(out, uint32(size))
+	exponentByteOrder.PutUint32(out, uint32(size))
 	copy(out[4:], data)
 	return out, nil
 }
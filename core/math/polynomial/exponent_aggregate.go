@@ -0,0 +1,60 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ExponentAggregate is a running sum of polynomials in the exponent, one per party, so that
+// polynomials can be added as they are received instead of being summed all at once with Sum.
+// It is safe for concurrent use.
+type ExponentAggregate struct {
+	mtx     sync.Mutex
+	sum     *Exponent
+	parties map[party.ID]struct{}
+}
+
+func NewExponentAggregate() *ExponentAggregate {
+	return &ExponentAggregate{parties: make(map[party.ID]struct{})}
+}
+
+// Add adds the polynomial q of party id to the sum. The polynomial of a party is added only
+// once, later calls for the same party are ignored.
+func (a *ExponentAggregate) Add(id party.ID, q *Exponent) error {
+	if q == nil {
+		return errors.New("polynomial: nil exponent")
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if _, ok := a.parties[id]; ok {
+		return nil
+	}
+	if a.sum == nil {
+		a.sum = q.copy()
+	} else if err := a.sum.add(q); err != nil {
+		return err
+	}
+	a.parties[id] = struct{}{}
+	return nil
+}
+
+// Sum returns the sum of the polynomials of parties, which must be exactly the parties added.
+func (a *ExponentAggregate) Sum(parties []party.ID) (*Exponent, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	for _, id := range parties {
+		if _, ok := a.parties[id]; !ok {
+			return nil, fmt.Errorf("polynomial: missing exponent of party %s", id)
+		}
+	}
+	if len(parties) != len(a.parties) {
+		return nil, errors.New("polynomial: aggregate holds exponents of other parties")
+	}
+	return a.sum.copy(), nil
+}
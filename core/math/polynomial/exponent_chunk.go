@@ -0,0 +1,140 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+type rawExponentChunk struct {
+	// Degree is the degree of the whole Exponent.
+	Degree     int
+	IsConstant bool
+	// Offset is the index of the first coefficient of the chunk.
+	Offset       int
+	Coefficients []cbor.RawMessage
+}
+
+// Chunks encodes the coefficients of the Exponent in chunks of at most size coefficients each, so
+// that a transport can send a polynomial of high degree incrementally. The chunks must be given to
+// an ExponentDecoder in the same order.
+func (p *Exponent) Chunks(size int) ([][]byte, error) {
+	if size <= 0 {
+		return nil, errors.New("exponent: chunk size must be positive")
+	}
+	chunks := make([][]byte, 0, (len(p.coefficients)+size-1)/size)
+	for offset := 0; offset < len(p.coefficients); offset += size {
+		end := offset + size
+		if end > len(p.coefficients) {
+			end = len(p.coefficients)
+		}
+		raw := rawExponentChunk{
+			Degree:       p.Degree(),
+			IsConstant:   p.IsConstant,
+			Offset:       offset,
+			Coefficients: make([]cbor.RawMessage, 0, end-offset),
+		}
+		for _, c := range p.coefficients[offset:end] {
+			data, err := cbor.Marshal(c)
+			if err != nil {
+				return nil, err
+			}
+			raw.Coefficients = append(raw.Coefficients, data)
+		}
+		chunk, err := cbor.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// ExponentDecoder reassembles an Exponent from the chunks produced by Exponent.Chunks.
+type ExponentDecoder struct {
+	group  curve.Curve
+	degree int
+	// started is set once the first chunk was written, and fixes isConstant.
+	started      bool
+	isConstant   bool
+	coefficients []curve.Point
+}
+
+// NewExponentDecoder returns a decoder for an Exponent over group, which must have the given degree.
+func NewExponentDecoder(group curve.Curve, degree int) *ExponentDecoder {
+	return &ExponentDecoder{
+		group:  group,
+		degree: degree,
+	}
+}
+
+// Write decodes the next chunk, and returns an error if it does not extend the coefficients
+// received so far or does not belong to a polynomial of the expected degree.
+func (d *ExponentDecoder) Write(chunk []byte) error {
+	var raw rawExponentChunk
+	if err := cbor.Unmarshal(chunk, &raw); err != nil {
+		return err
+	}
+	if raw.Degree != d.degree {
+		return fmt.Errorf("exponent: chunk of a polynomial of degree %d, expected %d", raw.Degree, d.degree)
+	}
+	if d.started && raw.IsConstant != d.isConstant {
+		return errors.New("exponent: chunks disagree on the constant coefficient")
+	}
+	if raw.Offset != len(d.coefficients) {
+		return fmt.Errorf("exponent: chunk starts at coefficient %d, expected %d", raw.Offset, len(d.coefficients))
+	}
+	if len(raw.Coefficients) == 0 {
+		return errors.New("exponent: empty chunk")
+	}
+	if raw.Offset+len(raw.Coefficients) > coefficientCount(d.degree, raw.IsConstant) {
+		return errors.New("exponent: too many coefficients for the degree")
+	}
+
+	coefficients := make([]curve.Point, 0, len(raw.Coefficients))
+	for _, data := range raw.Coefficients {
+		c := d.group.NewPoint()
+		if err := cbor.Unmarshal(data, c); err != nil {
+			return err
+		}
+		if c.IsIdentity() {
+			return errors.New("exponent: coefficient is identity")
+		}
+		coefficients = append(coefficients, c)
+	}
+
+	d.started = true
+	d.isConstant = raw.IsConstant
+	d.coefficients = append(d.coefficients, coefficients...)
+	return nil
+}
+
+// Done returns true once all coefficients of the Exponent were received.
+func (d *ExponentDecoder) Done() bool {
+	return d.started && len(d.coefficients) == coefficientCount(d.degree, d.isConstant)
+}
+
+// Exponent returns the reassembled Exponent, or an error if some chunks are still missing.
+func (d *ExponentDecoder) Exponent() (*Exponent, error) {
+	if !d.Done() {
+		return nil, fmt.Errorf("exponent: received %d coefficients of a polynomial of degree %d", len(d.coefficients), d.degree)
+	}
+	coefficients := make([]curve.Point, len(d.coefficients))
+	copy(coefficients, d.coefficients)
+	return &Exponent{
+		group:        d.group,
+		IsConstant:   d.isConstant,
+		coefficients: coefficients,
+	}, nil
+}
+
+// coefficientCount returns the number of coefficients stored by an Exponent of the given degree,
+// which omits the constant one if it is the identity.
+func coefficientCount(degree int, isConstant bool) int {
+	if isConstant {
+		return degree
+	}
+	return degree + 1
+}
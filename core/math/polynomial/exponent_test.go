@@ -3,11 +3,13 @@ package polynomial
 import (
 	"crypto/rand"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,15 +30,34 @@ func TestExponent_Evaluate(t *testing.T) {
 		randomIndex := sample.Scalar(rand.Reader, group)
 
 		lhs = poly.Evaluate(randomIndex).ActOnBase()
-		rhs1 := polyExp.Evaluate(randomIndex)
+		rhs1 := polyExp.evaluateHorner(randomIndex)
 		rhs2 := polyExp.evaluateClassic(randomIndex)
+		rhs3 := polyExp.Evaluate(randomIndex)
 
 		require.Truef(t, lhs.Equal(rhs1), fmt.Sprint("base eval differs from horner", x))
 		require.Truef(t, lhs.Equal(rhs2), fmt.Sprint("base eval differs from classic", x))
 		require.Truef(t, rhs1.Equal(rhs2), fmt.Sprint("horner differs from classic", x))
+		require.Truef(t, lhs.Equal(rhs3), fmt.Sprint("base eval differs from multi-scalar multiplication", x))
 	}
 }
 
+func BenchmarkExponent_Evaluate(b *testing.B) {
+	group := curve.Secp256k1{}
+	polyExp := NewPolynomialExponent(NewPolynomial(group, 32, sample.Scalar(rand.Reader, group)))
+	x := sample.Scalar(rand.Reader, group)
+
+	b.Run("multi-scalar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			polyExp.Evaluate(x)
+		}
+	})
+	b.Run("horner", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			polyExp.evaluateHorner(x)
+		}
+	})
+}
+
 func TestSum(t *testing.T) {
 	group := curve.Secp256k1{}
 
@@ -84,3 +105,129 @@ func TestMarshall(t *testing.T) {
 	require.NoError(t, err, "failed to Unmarshal")
 	assert.True(t, polyExp.Equal(*polyExp2), "should be the same")
 }
+
+func TestExponent_ByteOrder(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	poly := NewPolynomial(group, 10, sample.Scalar(rand.Reader, group))
+	polyExp := NewPolynomialExponent(poly)
+	out, err := polyExp.MarshalBinary()
+	require.NoError(t, err)
+
+	// the number of coefficients is written most significant byte first
+	size := uint32(len(polyExp.coefficients))
+	assert.Equal(t, []byte{byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size)}, out[:4])
+
+	swapped := append([]byte{out[3], out[2], out[1], out[0]}, out[4:]...)
+	assert.Error(t, EmptyExponent(group).UnmarshalBinary(swapped))
+
+	polyExp2 := EmptyExponent(group)
+	require.NoError(t, polyExp2.UnmarshalBinary(out))
+	assert.True(t, polyExp.Equal(*polyExp2))
+}
+
+func TestExponent_UnmarshalBinaryWithMaxDegree(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	out, err := NewPolynomialExponent(NewPolynomial(group, 3, sample.Scalar(rand.Reader, group))).MarshalBinary()
+	require.NoError(t, err)
+	for _, maxDegree := range []int{3, 4} {
+		assert.NoError(t, EmptyExponent(group).UnmarshalBinaryWithMaxDegree(out, maxDegree))
+	}
+	assert.ErrorIs(t, EmptyExponent(group).UnmarshalBinaryWithMaxDegree(out, 2), ErrExponentDegree)
+
+	// the number of coefficients is rejected before the coefficients are decoded
+	oversized := append([]byte{0xff, 0xff, 0xff, 0xff}, make([]byte, 1<<16)...)
+	assert.ErrorIs(t, EmptyExponent(group).UnmarshalBinaryWithMaxDegree(oversized, 3), ErrExponentDegree)
+
+	// the coefficients must match the number announced by the prefix
+	out, err = NewPolynomialExponent(NewPolynomial(group, 20, sample.Scalar(rand.Reader, group))).MarshalBinary()
+	require.NoError(t, err)
+	for _, size := range []byte{4, 22} {
+		forged := append([]byte{0, 0, 0, size}, out[4:]...)
+		assert.Error(t, EmptyExponent(group).UnmarshalBinary(forged))
+		assert.Error(t, EmptyExponent(group).UnmarshalBinaryWithMaxDegree(forged, 21))
+	}
+
+	// a constant polynomial has degree len(coefficients)
+	constant := NewPolynomialExponent(NewPolynomial(group, 3, group.NewScalar()))
+	require.True(t, constant.IsConstant)
+	out, err = constant.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, EmptyExponent(group).UnmarshalBinaryWithMaxDegree(out, 2), ErrExponentDegree)
+}
+
+func TestExponent_Chunks(t *testing.T) {
+	group := curve.Secp256k1{}
+	degree := 10
+
+	for _, constant := range []curve.Scalar{sample.Scalar(rand.Reader, group), group.NewScalar()} {
+		polyExp := NewPolynomialExponent(NewPolynomial(group, degree, constant))
+
+		chunks, err := polyExp.Chunks(3)
+		require.NoError(t, err)
+		require.Len(t, chunks, (polyExp.Degree()+3)/3)
+
+		decoder := NewExponentDecoder(group, degree)
+		for _, chunk := range chunks {
+			assert.False(t, decoder.Done())
+			_, err := decoder.Exponent()
+			assert.Error(t, err, "incomplete polynomial should not be returned")
+			require.NoError(t, decoder.Write(chunk))
+		}
+		require.True(t, decoder.Done())
+		polyExp2, err := decoder.Exponent()
+		require.NoError(t, err)
+		assert.True(t, polyExp.Equal(*polyExp2), "should be the same")
+
+		// the chunks must be written in order
+		decoder = NewExponentDecoder(group, degree)
+		assert.Error(t, decoder.Write(chunks[1]))
+
+		// and belong to a polynomial of the expected degree
+		assert.Error(t, NewExponentDecoder(group, degree-1).Write(chunks[0]))
+	}
+
+	_, err := NewPolynomialExponent(NewPolynomial(group, degree, nil)).Chunks(0)
+	assert.Error(t, err)
+}
+
+func TestExponentAggregate(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	N := 10
+	Deg := 5
+
+	ids := make([]party.ID, N)
+	polysExp := make([]*Exponent, N)
+	for i := range polysExp {
+		ids[i] = party.ID(fmt.Sprint(i))
+		polysExp[i] = NewPolynomialExponent(NewPolynomial(group, Deg, sample.Scalar(rand.Reader, group)))
+	}
+	batch, err := Sum(polysExp)
+	require.NoError(t, err)
+
+	aggregate := NewExponentAggregate()
+	var wg sync.WaitGroup
+	for i := range polysExp {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, aggregate.Add(ids[i], polysExp[i]))
+		}(i)
+	}
+	wg.Wait()
+
+	_, err = aggregate.Sum(ids[1:])
+	assert.Error(t, err, "aggregate with an extra party")
+
+	// a party added twice is only counted once
+	require.NoError(t, aggregate.Add(ids[0], polysExp[0]))
+
+	incremental, err := aggregate.Sum(ids)
+	require.NoError(t, err)
+	assert.True(t, batch.Equal(*incremental), "incremental aggregate differs from Sum")
+
+	_, err = aggregate.Sum(append(ids, "extra"))
+	assert.Error(t, err, "aggregate missing a party")
+}
@@ -1,13 +1,17 @@
 package polynomial_test
 
 import (
+	"crypto/rand"
 	"testing"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/lib/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLagrange(t *testing.T) {
@@ -29,3 +33,41 @@ func TestLagrange(t *testing.T) {
 	assert.True(t, sumEven.Equal(one))
 	assert.True(t, sumOdd.Equal(one))
 }
+
+func TestReconstructSecret(t *testing.T) {
+	group := curve.Secp256k1{}
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, 2, secret)
+
+	allIDs := test.PartyIDs(5)
+	shares := make(map[party.ID]curve.Scalar, len(allIDs))
+	for _, id := range allIDs {
+		shares[id] = f.Evaluate(id.Scalar(group))
+	}
+	reconstructed, err := polynomial.ReconstructSecret(shares, group)
+	require.NoError(t, err)
+	assert.True(t, secret.Equal(reconstructed))
+
+	// any 3 shares determine a polynomial of degree 2, but 2 do not
+	delete(shares, allIDs[0])
+	delete(shares, allIDs[1])
+	reconstructed, err = polynomial.ReconstructSecret(shares, group)
+	require.NoError(t, err)
+	assert.True(t, secret.Equal(reconstructed))
+	delete(shares, allIDs[2])
+	reconstructed, err = polynomial.ReconstructSecret(shares, group)
+	require.NoError(t, err)
+	assert.False(t, secret.Equal(reconstructed))
+
+	_, err = polynomial.ReconstructSecret(nil, group)
+	assert.ErrorIs(t, err, polynomial.ErrNoShares)
+
+	// "\x00a" has the same index as "a"
+	duplicate := map[party.ID]curve.Scalar{"a": secret, "\x00a": secret}
+	_, err = polynomial.ReconstructSecret(duplicate, group)
+	assert.Error(t, err)
+
+	zero := map[party.ID]curve.Scalar{"a": secret, "\x00": secret}
+	_, err = polynomial.ReconstructSecret(zero, group)
+	assert.Error(t, err)
+}
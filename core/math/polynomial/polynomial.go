@@ -90,9 +90,12 @@ func (p *Polynomial) UnmarshalBinary(data []byte) error {
 	gnlen := int(data[0])
 	gn := string(data[1 : 1+gnlen])
 	var group curve.Curve
-	if gn == "secp256k1" {
+	switch gn {
+	case "secp256k1":
 		group = curve.Secp256k1{}
-	} else {
+	case "p256", "secp256r1":
+		group = curve.P256{}
+	default:
 		return errors.New("unsupported curve")
 	}
 	p.group = group
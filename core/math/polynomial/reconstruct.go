@@ -0,0 +1,50 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+var ErrNoShares = errors.New("polynomial: no shares to reconstruct from")
+
+// ReconstructSecret returns the constant f(0) of the polynomial f for which shares[j] = f(j), by
+// Lagrange interpolation at 0.
+//
+// The shares must be more than the degree of f, otherwise a different secret is returned without
+// error; callers knowing the threshold of the sharing must check the size of the quorum first.
+// Parties whose IDs map to the same index, or to index 0, are rejected.
+func ReconstructSecret(shares map[party.ID]curve.Scalar, group curve.Curve) (curve.Scalar, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+
+	ids := make([]party.ID, 0, len(shares))
+	seen := make(map[string]party.ID, len(shares))
+	for j, share := range shares {
+		if share == nil {
+			return nil, fmt.Errorf("polynomial: share of party %q is nil", j)
+		}
+		x := j.Scalar(group)
+		if x.IsZero() {
+			return nil, fmt.Errorf("polynomial: party %q has index 0", j)
+		}
+		xb, err := x.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := seen[string(xb)]; ok {
+			return nil, fmt.Errorf("polynomial: parties %q and %q have the same index", i, j)
+		}
+		seen[string(xb)] = j
+		ids = append(ids, j)
+	}
+
+	secret := group.NewScalar()
+	for j, l := range Lagrange(group, ids) {
+		secret.Add(l.Mul(shares[j]))
+	}
+	return secret, nil
+}
@@ -1,6 +1,7 @@
 package sample
 
 import (
+	"context"
 	"io"
 	"math"
 	"math/big"
@@ -160,8 +161,19 @@ func tryBlumPrime(rand io.Reader) *saferith.Nat {
 // p, q are safe primes ((p - 1) / 2 is also prime), and Blum primes (p = 3 mod 4)
 // n = pq.
 func Paillier(rand io.Reader, pl *pool.Pool) (p, q *saferith.Nat) {
+	p, q, _ = PaillierWithContext(context.Background(), rand, pl)
+	return
+}
+
+// PaillierWithContext is like Paillier, but stops searching for primes and returns the error of
+// ctx once it is done.
+func PaillierWithContext(ctx context.Context, rand io.Reader, pl *pool.Pool) (p, q *saferith.Nat, err error) {
 	reader := pool.NewLockedReader(rand)
 	results := pl.Search(2, func() interface{} {
+		// a non nil result ends the search, so that every worker returns promptly
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		q := tryBlumPrime(reader)
 		// You have to do this, because of how Go handles nil.
 		if q == nil {
@@ -169,6 +181,11 @@ func Paillier(rand io.Reader, pl *pool.Pool) (p, q *saferith.Nat) {
 		}
 		return q
 	})
+	for _, r := range results {
+		if err, ok := r.(error); ok {
+			return nil, nil, err
+		}
+	}
 	p, q = results[0].(*saferith.Nat), results[1].(*saferith.Nat)
-	return
+	return p, q, nil
 }
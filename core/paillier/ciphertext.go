@@ -75,6 +75,19 @@ func (ct *Ciphertext) WriteTo(w io.Writer) (int64, error) {
 	return int64(n), err
 }
 
+// ValidateCiphertextSizes returns true if all ciphertexts are set and fit in params.BytesCiphertext
+// bytes, the size of N² for the moduli accepted by ValidateN. Unlike PublicKey.ValidateCiphertexts,
+// it needs no key and only looks at the length of the ciphertexts, so that oversized ciphertexts
+// received from another party are rejected before they are processed.
+func ValidateCiphertextSizes(cts ...*Ciphertext) bool {
+	for _, ct := range cts {
+		if ct == nil || ct.c == nil || ct.c.TrueLen() > 8*params.BytesCiphertext {
+			return false
+		}
+	}
+	return true
+}
+
 // Domain implements hash.WriterToWithDomain, and separates this type within hash.Hash.
 func (*Ciphertext) Domain() string {
 	return "Paillier Ciphertext"
@@ -2,12 +2,14 @@ package paillier
 
 import (
 	"crypto/rand"
+	"math/big"
 	"testing"
 	"testing/quick"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,6 +59,19 @@ func TestCiphertextValidate(t *testing.T) {
 	assert.Error(t, err, "decrypting N^2 should fail")
 }
 
+func TestValidateCiphertextSizes(t *testing.T) {
+	ct, _ := paillierPublic.Enc(new(saferith.Int).SetUint64(42))
+	largest := &Ciphertext{new(saferith.Nat).Sub(paillierPublic.nSquared.Nat(), new(saferith.Nat).SetUint64(1), -1)}
+	assert.True(t, ValidateCiphertextSizes(ct, largest))
+	assert.True(t, ValidateCiphertextSizes())
+
+	oversized := make([]byte, params.BytesCiphertext+1)
+	oversized[0] = 1
+	assert.False(t, ValidateCiphertextSizes(ct, &Ciphertext{new(saferith.Nat).SetBytes(oversized)}))
+	assert.False(t, ValidateCiphertextSizes(ct, nil))
+	assert.False(t, ValidateCiphertextSizes(&Ciphertext{}))
+}
+
 func testEncDecRoundTrip(x uint64, xNeg bool) bool {
 	m := new(saferith.Int).SetUint64(x)
 	if xNeg {
@@ -195,3 +210,21 @@ func BenchmarkMulCiphertext(b *testing.B) {
 		resultCiphertext = c.Mul(paillierPublic, m)
 	}
 }
+
+func TestValidateN(t *testing.T) {
+	assert.NoError(t, ValidateN(paillierPublic.N()))
+	assert.ErrorIs(t, ValidateN(nil), ErrPaillierNil)
+	assert.ErrorIs(t, ValidateN(saferith.ModulusFromNat(paillierSecret.P())), ErrPaillierLength)
+
+	// p² and x³ for x = 11⋅2⁶⁷⁹ + 1 are odd, and have the length of a valid modulus
+	p := paillierSecret.P().Big()
+	x := new(big.Int).Lsh(big.NewInt(11), 679)
+	x.Add(x, big.NewInt(1))
+	for _, n := range []*big.Int{
+		new(big.Int).Mul(p, p),
+		new(big.Int).Exp(x, big.NewInt(3), nil),
+	} {
+		assert.Equal(t, params.BitsPaillier, n.BitLen())
+		assert.ErrorIs(t, ValidateN(saferith.ModulusFromBytes(n.Bytes())), ErrPaillierPower)
+	}
+}
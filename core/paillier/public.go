@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
@@ -16,6 +17,7 @@ var (
 	ErrPaillierLength = errors.New("wrong number bit length of Paillier modulus N")
 	ErrPaillierEven   = errors.New("modulus N is even")
 	ErrPaillierNil    = errors.New("modulus N is nil")
+	ErrPaillierPower  = errors.New("modulus N is a perfect power")
 )
 
 // PublicKey is a Paillier public key. It is represented by a modulus N.
@@ -29,6 +31,8 @@ type PublicKey struct {
 	nNat *saferith.Nat
 	// nPlusOne = n + 1
 	nPlusOne *saferith.Nat
+	// nHalf = (n-1)/2 bounds the absolute value of the messages
+	nHalf *saferith.Nat
 }
 
 // N is the public modulus making up this key.
@@ -50,12 +54,20 @@ func NewPublicKey(n *saferith.Modulus) *PublicKey {
 		nSquared: arith.ModulusFromN(nSquared),
 		nNat:     nNat,
 		nPlusOne: nPlusOne,
+		nHalf:    halfN(nNat),
 	}
 }
 
+// halfN returns (n-1)/2 for an odd n.
+func halfN(nNat *saferith.Nat) *saferith.Nat {
+	nHalf := new(saferith.Nat).SetNat(nNat)
+	return nHalf.Rsh(nHalf, 1, -1)
+}
+
 // ValidateN performs basic checks to make sure the modulus is valid:
 // - log₂(n) = params.BitsPaillier.
 // - n is odd.
+// - n is not a perfect power.
 func ValidateN(n *saferith.Modulus) error {
 	if n == nil {
 		return ErrPaillierNil
@@ -68,9 +80,49 @@ func ValidateN(n *saferith.Modulus) error {
 	if nBig.Bit(0) != 1 {
 		return ErrPaillierEven
 	}
+	if isPerfectPower(nBig) {
+		return ErrPaillierPower
+	}
 	return nil
 }
 
+// isPerfectPower returns true if n = xᵏ for some integers x and k ⩾ 2.
+//
+// It is enough to check prime exponents k ⩽ log₂(n), for which the integer kth root of n is computed
+// with Newton's method. N is public, so this need not run in constant time.
+func isPerfectPower(n *big.Int) bool {
+	bits := n.BitLen()
+	for k := 2; k <= bits; k++ {
+		kBig := big.NewInt(int64(k))
+		if !kBig.ProbablyPrime(0) {
+			continue
+		}
+		if new(big.Int).Exp(intRoot(n, k), kBig, nil).Cmp(n) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// intRoot returns ⌊n^(1/k)⌋ for n > 0.
+func intRoot(n *big.Int, k int) *big.Int {
+	kBig := big.NewInt(int64(k))
+	kMinus1 := big.NewInt(int64(k - 1))
+	// x₀ = 2^⌈log₂(n)/k⌉ ⩾ n^(1/k), from which the iterations decrease to the root
+	x := new(big.Int).Lsh(big.NewInt(1), uint((n.BitLen()+k-1)/k))
+	for {
+		// y = ((k-1)⋅x + n/xᵏ⁻¹)/k
+		y := new(big.Int).Exp(x, kMinus1, nil)
+		y.Quo(n, y)
+		y.Add(y, new(big.Int).Mul(x, kMinus1))
+		y.Quo(y, kBig)
+		if y.Cmp(x) >= 0 {
+			return x
+		}
+		x = y
+	}
+}
+
 // Enc returns the encryption of m under the public key pk.
 // The nonce used to encrypt is returned.
 //
@@ -87,17 +139,17 @@ func (pk PublicKey) Enc(m *saferith.Int) (*Ciphertext, *saferith.Nat) {
 //
 // The message m must be in the range [-(N-1)/2, …, (N-1)/2] and panics otherwise
 //
-// ct = (1+N)ᵐρᴺ (mod N²).
+// ct = (1+N)ᵐρᴺ (mod N²), where (1+N)ᵐ = 1 + m⋅N (mod N²) by the binomial theorem, which saves an
+// exponentiation.
 func (pk PublicKey) EncWithNonce(m *saferith.Int, nonce *saferith.Nat) *Ciphertext {
-	mAbs := m.Abs()
-	nHalf := new(saferith.Nat).SetNat(pk.nNat)
-	nHalf.Rsh(nHalf, 1, -1)
-	if gt, _, _ := mAbs.Cmp(nHalf); gt == 1 {
+	if gt, _, _ := m.Abs().Cmp(pk.nHalf); gt == 1 {
 		panic("paillier.Encrypt: tried to encrypt message outside of range [-(N-1)/2, …, (N-1)/2]")
 	}
 
-	// (N+1)ᵐ mod N²
-	c := pk.nSquared.ExpI(pk.nPlusOne, m)
+	nSquared := pk.nSquared.Modulus
+	// 1 + m⋅N mod N²
+	c := new(saferith.Nat).ModMul(m.Mod(nSquared), pk.nNat, nSquared)
+	c.ModAdd(c, new(saferith.Nat).SetUint64(1), nSquared)
 	// ρᴺ mod N²
 	rhoN := pk.nSquared.Exp(nonce, pk.nNat)
 	// (N+1)ᵐ rho ^ N
@@ -0,0 +1,54 @@
+package paillier
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicKey_EncWithNonce(t *testing.T) {
+	bound := new(saferith.Int).SetNat(paillierPublic.nHalf)
+	messages := []*saferith.Int{
+		new(saferith.Int),
+		new(saferith.Int).SetUint64(1),
+		new(saferith.Int).SetUint64(1).Neg(1),
+		bound,
+		new(saferith.Int).SetNat(paillierPublic.nHalf).Neg(1),
+		sample.IntervalLEps(rand.Reader),
+		sample.IntervalLEps(rand.Reader).Neg(1),
+	}
+	for _, m := range messages {
+		// ct = (1+N)ᵐρᴺ (mod N²), with the exponentiation of the definition
+		nonce := sample.UnitModN(rand.Reader, paillierPublic.N())
+		expected := paillierPublic.nSquared.ExpI(paillierPublic.nPlusOne, m)
+		expected.ModMul(expected, paillierPublic.nSquared.Exp(nonce, paillierPublic.nNat), paillierPublic.nSquared.Modulus)
+		ct := paillierPublic.EncWithNonce(m, nonce)
+		assert.True(t, (&Ciphertext{c: expected}).Equal(ct))
+
+		decrypted, err := paillierSecret.Dec(ct)
+		require.NoError(t, err)
+		assert.Equal(t, saferith.Choice(1), decrypted.Eq(m))
+
+		ct, nonce = paillierPublic.Enc(m)
+		decrypted, err = paillierSecret.Dec(ct)
+		require.NoError(t, err)
+		assert.Equal(t, saferith.Choice(1), decrypted.Eq(m))
+		assert.True(t, paillierPublic.EncWithNonce(m, nonce).Equal(ct))
+	}
+
+	tooLarge := new(saferith.Int).SetNat(new(saferith.Nat).Add(paillierPublic.nHalf, new(saferith.Nat).SetUint64(1), -1))
+	assert.Panics(t, func() { paillierPublic.EncWithNonce(tooLarge, sample.UnitModN(rand.Reader, paillierPublic.N())) })
+}
+
+func BenchmarkPublicKey_EncWithNonce(b *testing.B) {
+	m := sample.IntervalLEps(rand.Reader)
+	nonce := sample.UnitModN(rand.Reader, paillierPublic.N())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		paillierPublic.EncWithNonce(m, nonce)
+	}
+}
@@ -1,10 +1,12 @@
 package paillier
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
@@ -20,6 +22,7 @@ var (
 	ErrNotSafePrime     = errors.New("supposed prime factor is not a safe prime")
 	ErrPrimeNil         = errors.New("prime is nil")
 	ErrEmptyEncodedData = errors.New("encoded secret has empty data")
+	ErrInvalidEncoding  = errors.New("encoded secret is malformed")
 )
 
 // SecretKey is the secret key corresponding to a Public Paillier Key.
@@ -64,12 +67,30 @@ func KeyGen(pl *pool.Pool) (pk *PublicKey, sk *SecretKey) {
 	return
 }
 
+// KeyGenWithContext is like KeyGen, but gives up with the error of ctx once it is done.
+func KeyGenWithContext(ctx context.Context, pl *pool.Pool) (pk *PublicKey, sk *SecretKey, err error) {
+	sk, err = NewSecretKeyWithContext(ctx, pl)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sk.PublicKey, sk, nil
+}
+
 // NewSecretKey generates primes p and q suitable for the scheme, and returns the initialized SecretKey.
 func NewSecretKey(pl *pool.Pool) *SecretKey {
 	// TODO maybe we could take the reader as argument?
 	return NewSecretKeyFromPrimes(sample.Paillier(rand.Reader, pl))
 }
 
+// NewSecretKeyWithContext is like NewSecretKey, but stops the search for primes once ctx is done.
+func NewSecretKeyWithContext(ctx context.Context, pl *pool.Pool) (*SecretKey, error) {
+	p, q, err := sample.PaillierWithContext(ctx, rand.Reader, pl)
+	if err != nil {
+		return nil, err
+	}
+	return NewSecretKeyFromPrimes(p, q), nil
+}
+
 // NewSecretKeyFromPrimes generates a new SecretKey. Assumes that P and Q are prime.
 func NewSecretKeyFromPrimes(P, Q *saferith.Nat) *SecretKey {
 	oneNat := new(saferith.Nat).SetUint64(1)
@@ -101,6 +122,7 @@ func NewSecretKeyFromPrimes(P, Q *saferith.Nat) *SecretKey {
 			nSquared: nSquared,
 			nNat:     nNat,
 			nPlusOne: nPlusOne,
+			nHalf:    halfN(nNat),
 		},
 	}
 }
@@ -156,6 +178,12 @@ func (sk SecretKey) GeneratePedersen() (*pedersen.Parameters, *saferith.Nat) {
 	return ped, lambda
 }
 
+// secretByteOrder is the byte order of the 2 byte length prefixes of p and q in the encoding of a
+// SecretKey. It is fixed, rather than the one of the host, so that keys can be exchanged between
+// platforms, and is little-endian so that existing encodings remain readable. The primes themselves
+// are big-endian, as encoded by saferith.
+var secretByteOrder = binary.LittleEndian
+
 func (sk SecretKey) MarshalBinary() ([]byte, error) {
 	pbs, err := sk.p.MarshalBinary()
 	if err != nil {
@@ -167,11 +195,15 @@ func (sk SecretKey) MarshalBinary() ([]byte, error) {
 		return nil, err
 	}
 
+	if len(pbs) > math.MaxUint16 || len(qbs) > math.MaxUint16 {
+		return nil, ErrInvalidEncoding
+	}
+
 	pl := make([]byte, 2)
-	binary.LittleEndian.PutUint16(pl, uint16(len(pbs)))
+	secretByteOrder.PutUint16(pl, uint16(len(pbs)))
 
 	ql := make([]byte, 2)
-	binary.LittleEndian.PutUint16(ql, uint16(len(qbs)))
+	secretByteOrder.PutUint16(ql, uint16(len(qbs)))
 
 	buf := make([]byte, 0)
 	buf = append(buf, pl...)
@@ -187,24 +219,31 @@ func (sk *SecretKey) UnmarshalBinary(data []byte) error {
 		return ErrEmptyEncodedData
 	}
 
-	plb := data[:2]
-	pLen := binary.LittleEndian.Uint16(plb)
+	if len(data) < 2 {
+		return ErrInvalidEncoding
+	}
+	pLen := int(secretByteOrder.Uint16(data[:2]))
 	if pLen == 0 {
 		return ErrEmptyEncodedData
 	}
+	if len(data) < pLen+4 {
+		return ErrInvalidEncoding
+	}
 	p := new(saferith.Nat)
 	if err := p.UnmarshalBinary(data[2 : pLen+2]); err != nil {
 		return err
 	}
 
-	qlb := data[pLen+2 : pLen+4]
-	qLen := binary.LittleEndian.Uint16(qlb)
+	qLen := int(secretByteOrder.Uint16(data[pLen+2 : pLen+4]))
 	if qLen == 0 {
 		return ErrEmptyEncodedData
 	}
+	if len(data) != pLen+4+qLen {
+		return ErrInvalidEncoding
+	}
 
 	q := new(saferith.Nat)
-	if err := q.UnmarshalBinary(data[pLen+4 : pLen+4+qLen]); err != nil {
+	if err := q.UnmarshalBinary(data[pLen+4:]); err != nil {
 		return err
 	}
 
@@ -0,0 +1,26 @@
+package paillier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretKey_ByteOrder(t *testing.T) {
+	out, err := paillierSecret.MarshalBinary()
+	require.NoError(t, err)
+
+	// the length of p is written least significant byte first
+	pl := len(paillierSecret.p.Bytes())
+	assert.Equal(t, []byte{byte(pl), byte(pl >> 8)}, out[:2])
+
+	sk := new(SecretKey)
+	require.NoError(t, sk.UnmarshalBinary(out))
+	assert.True(t, sk.p.Eq(paillierSecret.p) == 1)
+	assert.True(t, sk.q.Eq(paillierSecret.q) == 1)
+
+	swapped := append([]byte{out[1], out[0]}, out[2:]...)
+	assert.ErrorIs(t, new(SecretKey).UnmarshalBinary(swapped), ErrInvalidEncoding)
+	assert.ErrorIs(t, new(SecretKey).UnmarshalBinary(out[:len(out)-1]), ErrInvalidEncoding)
+}
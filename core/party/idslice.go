@@ -16,6 +16,15 @@ func NewIDSlice(partyIDs []ID) IDSlice {
 	return ids
 }
 
+// SortedIDs returns a sorted copy of partyIDs.
+//
+// Loops writing to a transcript, or otherwise depending on the order of the parties, should
+// iterate over SortedIDs so that all parties process them in the same order, whatever the order
+// in which the IDs were given.
+func SortedIDs(partyIDs []ID) []ID {
+	return NewIDSlice(partyIDs)
+}
+
 // Contains returns true if partyIDs contains id.
 // Assumes that the IDSlice is valid.
 func (partyIDs IDSlice) Contains(ids ...ID) bool {
@@ -27,6 +36,12 @@ func (partyIDs IDSlice) Contains(ids ...ID) bool {
 	return true
 }
 
+// Equal returns true if partyIDs and other contain exactly the same IDs.
+// Assumes that both IDSlices are valid.
+func (partyIDs IDSlice) Equal(other IDSlice) bool {
+	return len(partyIDs) == len(other) && partyIDs.Contains(other...)
+}
+
 // Valid returns true if the IDSlice is sorted and does not contain any duplicates.
 func (partyIDs IDSlice) Valid() bool {
 	n := len(partyIDs)
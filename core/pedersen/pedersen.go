@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
@@ -16,6 +17,7 @@ const (
 	ErrNilFields    Error = "contains nil field"
 	ErrSEqualT      Error = "S cannot be equal to T"
 	ErrNotValidModN Error = "S and T must be in [1,…,N-1] and coprime to N"
+	ErrInvalidData  Error = "malformed encoding"
 )
 
 func (e Error) Error() string {
@@ -102,6 +104,10 @@ func (p Parameters) Verify(a, b, e *saferith.Int, S, T *saferith.Nat) bool {
 	return lhs.Eq(rhs) == 1
 }
 
+// paramsByteOrder is the byte order of the 2 byte length prefixes of N, s and t when encoding
+// Parameters. It is little-endian on every platform, as it always has been.
+var paramsByteOrder = binary.LittleEndian
+
 func (p Parameters) MarshalBiinary() ([]byte, error) {
 	nb, err := p.n.MarshalBinary()
 	if err != nil {
@@ -118,14 +124,18 @@ func (p Parameters) MarshalBiinary() ([]byte, error) {
 		return nil, err
 	}
 
+	if len(nb) > math.MaxUint16 || len(sb) > math.MaxUint16 || len(tb) > math.MaxUint16 {
+		return nil, ErrInvalidData
+	}
+
 	nlb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(nlb, uint16(len(nb)))
+	paramsByteOrder.PutUint16(nlb, uint16(len(nb)))
 
 	slb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(slb, uint16(len(sb)))
+	paramsByteOrder.PutUint16(slb, uint16(len(sb)))
 
 	tlb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(tlb, uint16(len(tb)))
+	paramsByteOrder.PutUint16(tlb, uint16(len(tb)))
 
 	buf := make([]byte, 0)
 	buf = append(buf, nlb...)
@@ -139,14 +149,34 @@ func (p Parameters) MarshalBiinary() ([]byte, error) {
 }
 
 func (p *Parameters) UnmarshalBiinary(data []byte) error {
-	nl := binary.LittleEndian.Uint16(data[:2])
-	nb := data[2 : 2+nl]
-
-	sl := binary.LittleEndian.Uint16(data[2+nl : 2+nl+2])
-	sb := data[2+nl+2 : 2+nl+2+sl]
-
-	tl := binary.LittleEndian.Uint16(data[2+nl+2+sl : 2+nl+2+sl+2])
-	tb := data[2+nl+2+sl+2 : 2+nl+2+sl+2+tl]
+	// next returns the next length-prefixed field of data.
+	next := func() ([]byte, error) {
+		if len(data) < 2 {
+			return nil, ErrInvalidData
+		}
+		l := int(paramsByteOrder.Uint16(data[:2]))
+		if len(data) < 2+l {
+			return nil, ErrInvalidData
+		}
+		field := data[2 : 2+l]
+		data = data[2+l:]
+		return field, nil
+	}
+	nb, err := next()
+	if err != nil {
+		return err
+	}
+	sb, err := next()
+	if err != nil {
+		return err
+	}
+	tb, err := next()
+	if err != nil {
+		return err
+	}
+	if len(data) != 0 {
+		return ErrInvalidData
+	}
 
 	n := arith.NewEmptyModulus()
 	if err := n.UnmarshalBinary(nb); err != nil {
@@ -48,3 +48,33 @@ func BenchmarkPedersenVerify(b *testing.B) {
 		resultBool = benchParams.Verify(x, y, e, S, T)
 	}
 }
+
+func TestParameters_ByteOrder(t *testing.T) {
+	out, err := benchParams.MarshalBiinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the length of N is written least significant byte first
+	nb, err := benchParams.n.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nl := len(nb)
+	if out[0] != byte(nl) || out[1] != byte(nl>>8) {
+		t.Fatalf("unexpected length prefix %x for %d bytes", out[:2], nl)
+	}
+
+	var p Parameters
+	if err := p.UnmarshalBiinary(out); err != nil {
+		t.Fatal(err)
+	}
+	if p.n.Modulus.Nat().Eq(benchN.Nat()) != 1 || p.s.Eq(benchParams.s) != 1 || p.t.Eq(benchParams.t) != 1 {
+		t.Fatal("parameters changed after encoding")
+	}
+
+	swapped := append([]byte{out[1], out[0]}, out[2:]...)
+	if err := new(Parameters).UnmarshalBiinary(swapped); err == nil {
+		t.Fatal("a byte-swapped length prefix should be rejected")
+	}
+}
@@ -114,9 +114,23 @@ func NewPool(count int) *Pool {
 	return &p
 }
 
+// NewSerialPool creates a pool without workers, running every function on the calling goroutine
+// in order, as a nil *Pool does.
+//
+// Search then returns the first count successes in the order f produces them, so that a run
+// sampling from a deterministic source is reproducible.
+func NewSerialPool() *Pool {
+	return &Pool{}
+}
+
+// serial returns true if p runs functions on the current goroutine.
+func (p *Pool) serial() bool {
+	return p == nil || p.workerCount == 0
+}
+
 // TearDown cleanly tears down a pool, closing channels, etc.
 func (p *Pool) TearDown() {
-	if p != nil {
+	if !p.serial() {
 		close(p.commands)
 	}
 }
@@ -128,7 +142,7 @@ func (p *Pool) TearDown() {
 //
 // The result will be an array containing the first count successes.
 func (p *Pool) Search(count int, f func() interface{}) []interface{} {
-	if p == nil {
+	if p.serial() {
 		return searchAlone(f, count)
 	}
 
@@ -162,7 +176,7 @@ func (p *Pool) Search(count int, f func() interface{}) []interface{} {
 //
 // The result will be a slice containing [f(0), f(1), ..., f(count - 1)].
 func (p *Pool) Parallelize(count int, f func(int) interface{}) []interface{} {
-	if p == nil {
+	if p.serial() {
 		return parallelizeAlone(f, count)
 	}
 
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/hash"
@@ -33,6 +35,17 @@ type Handler interface {
 	Accept(msg *Message)
 }
 
+// AbortPolicy determines how a MultiHandler reacts to invalid messages.
+type AbortPolicy int
+
+const (
+	// FailFast aborts the protocol as soon as a message fails verification.
+	FailFast AbortPolicy = iota
+	// CollectAll keeps verifying the messages of the other parties of the round after a failure,
+	// and aborts once all of them are received, reporting every party which sent an invalid message.
+	CollectAll
+)
+
 // MultiHandler represents an execution of a given protocol.
 // It provides a simple interface for the user to receive/deliver protocol messages.
 type MultiHandler struct {
@@ -44,11 +57,21 @@ type MultiHandler struct {
 	broadcast       map[round.Number]map[party.ID]*Message
 	broadcastHashes map[round.Number][]byte
 	out             chan *Message
-	mtx             sync.Mutex
+	policy          AbortPolicy
+	// failures holds the verification errors of the current round, when collecting all culprits.
+	failures map[party.ID]error
+	// roundStart is the time at which the current round started
+	roundStart time.Time
+	mtx        sync.Mutex
 }
 
 // NewMultiHandler expects a StartFunc for the desired protocol. It returns a handler that the user can interact with.
 func NewMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error) {
+	return NewMultiHandlerWithPolicy(create, sessionID, FailFast)
+}
+
+// NewMultiHandlerWithPolicy is like NewMultiHandler, but handles invalid messages according to policy.
+func NewMultiHandlerWithPolicy(create StartFunc, sessionID []byte, policy AbortPolicy) (*MultiHandler, error) {
 	r, err := create(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("protocol: failed to create round: %w", err)
@@ -60,6 +83,9 @@ func NewMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error)
 		broadcast:       newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
 		broadcastHashes: map[round.Number][]byte{},
 		out:             make(chan *Message, 2*r.N()),
+		policy:          policy,
+		failures:        map[party.ID]error{},
+		roundStart:      time.Now(),
 	}
 	h.finalize()
 	return h, nil
@@ -152,13 +178,11 @@ func (h *MultiHandler) Accept(msg *Message) {
 	}
 
 	if msg.Broadcast {
-		if err := h.verifyBroadcastMessage(msg); err != nil {
-			h.abort(err, msg.From)
+		if err := h.verifyBroadcastMessage(msg); err != nil && h.fail(err, msg.From) {
 			return
 		}
 	} else {
-		if err := h.verifyMessage(msg); err != nil {
-			h.abort(err, msg.From)
+		if err := h.verifyMessage(msg); err != nil && h.fail(err, msg.From) {
 			return
 		}
 	}
@@ -168,7 +192,7 @@ func (h *MultiHandler) Accept(msg *Message) {
 
 func (h *MultiHandler) verifyBroadcastMessage(msg *Message) error {
 	r, ok := h.rounds[msg.RoundNumber]
-	if !ok {
+	if !ok || h.failures[msg.From] != nil {
 		return nil
 	}
 
@@ -199,9 +223,9 @@ func (h *MultiHandler) verifyBroadcastMessage(msg *Message) error {
 
 // verifyMessage tries to handle a normal (non reliably broadcast) message for this current round.
 func (h *MultiHandler) verifyMessage(msg *Message) error {
-	// we simply return if we haven't reached the right round.
+	// we simply return if we haven't reached the right round, or if the sender already failed.
 	r, ok := h.rounds[msg.RoundNumber]
-	if !ok {
+	if !ok || h.failures[msg.From] != nil {
 		return nil
 	}
 
@@ -235,6 +259,10 @@ func (h *MultiHandler) finalize() {
 	if !h.receivedAll() {
 		return
 	}
+	if len(h.failures) > 0 {
+		h.abortCollected()
+		return
+	}
 	if !h.checkBroadcastHash() {
 		h.abort(errors.New("broadcast verification failed"))
 		return
@@ -281,6 +309,10 @@ func (h *MultiHandler) finalize() {
 	h.rounds[roundNumber] = r
 	h.currentRound = r
 
+	now := time.Now()
+	r.RecordRoundDuration(now.Sub(h.roundStart))
+	h.roundStart = now
+
 	// either we get the current round, the next one, or one of the two final ones
 	switch R := r.(type) {
 	// An abort happened
@@ -301,9 +333,7 @@ func (h *MultiHandler) finalize() {
 			if m == nil || id == r.SelfID() {
 				continue
 			}
-			// if false, we aborted and so we return
-			if err = h.verifyBroadcastMessage(m); err != nil {
-				h.abort(err, m.From)
+			if err = h.verifyBroadcastMessage(m); err != nil && h.fail(err, m.From) {
 				return
 			}
 		}
@@ -313,9 +343,7 @@ func (h *MultiHandler) finalize() {
 			if m == nil {
 				continue
 			}
-			// if false, we aborted and so we return
-			if err = h.verifyMessage(m); err != nil {
-				h.abort(err, m.From)
+			if err = h.verifyMessage(m); err != nil && h.fail(err, m.From) {
 				return
 			}
 		}
@@ -325,6 +353,32 @@ func (h *MultiHandler) finalize() {
 	h.finalize()
 }
 
+// fail handles an invalid message from culprit according to the abort policy,
+// and returns true if the protocol was aborted.
+func (h *MultiHandler) fail(err error, culprit party.ID) bool {
+	if h.policy != CollectAll {
+		h.abort(err, culprit)
+		return true
+	}
+	h.failures[culprit] = err
+	return false
+}
+
+// abortCollected aborts with all the failures collected in the current round.
+func (h *MultiHandler) abortCollected() {
+	culprits := make([]party.ID, 0, len(h.failures))
+	for id := range h.failures {
+		culprits = append(culprits, id)
+	}
+	culprits = party.NewIDSlice(culprits)
+
+	errs := make([]error, 0, len(culprits))
+	for _, id := range culprits {
+		errs = append(errs, fmt.Errorf("party %s: %w", id, h.failures[id]))
+	}
+	h.abort(errors.Join(errs...), culprits...)
+}
+
 func (h *MultiHandler) abort(err error, culprits ...party.ID) {
 	if err != nil {
 		h.err = &Error{
@@ -345,6 +399,13 @@ func (h *MultiHandler) abort(err error, culprits ...party.ID) {
 	close(h.out)
 }
 
+// EstimatedTimeRemaining returns an estimate of the time left until the protocol completes.
+func (h *MultiHandler) EstimatedTimeRemaining() time.Duration {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.currentRound.EstimatedTimeRemaining()
+}
+
 // Stop cancels the current execution of the protocol, and alerts the other users.
 func (h *MultiHandler) Stop() {
 	if h.err != nil || h.result != nil {
@@ -434,6 +495,14 @@ func (h *MultiHandler) store(msg *Message) {
 func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 	var content round.Content
 
+	// reject messages whose header does not match the round, before choosing the content
+	if msg.RoundNumber != r.Number() {
+		return round.Message{}, fmt.Errorf("message for round %d delivered to round %d", msg.RoundNumber, r.Number())
+	}
+	if msg.From == r.SelfID() || !r.PartyIDs().Contains(msg.From) {
+		return round.Message{}, fmt.Errorf("message from unexpected sender %q", msg.From)
+	}
+
 	// there are two possible content messages
 	if msg.Broadcast {
 		b, ok := r.(round.BroadcastRound)
@@ -445,6 +514,11 @@ func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 		content = r.MessageContent()
 	}
 
+	// reject messages whose header contradicts the content, before decoding the content
+	if err := checkEnvelope(msg, content); err != nil {
+		return round.Message{}, err
+	}
+
 	// unmarshal message
 	if err := cbor.Unmarshal(msg.Data, content); err != nil {
 		return round.Message{}, fmt.Errorf("failed to unmarshal: %w", err)
@@ -458,6 +532,46 @@ func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 	return roundMsg, nil
 }
 
+// skippedValue implements cbor.Unmarshaler by ignoring the encoded value.
+type skippedValue struct{}
+
+func (*skippedValue) UnmarshalCBOR([]byte) error { return nil }
+
+// checkEnvelope checks that the round number of msg is the one of content, and that msg.Data
+// only holds fields of content if content is encoded as a CBOR map. Only the keys of the encoded
+// content are read, so a message declaring the wrong round is rejected without decoding its values.
+func checkEnvelope(msg *Message, content round.Content) error {
+	if content.RoundNumber() != msg.RoundNumber {
+		return fmt.Errorf("message for round %d holds content of round %d", msg.RoundNumber, content.RoundNumber())
+	}
+
+	// contents with a custom encoding are only checked when they are decoded
+	if fieldEncoding(reflect.TypeOf(content)) != "cbor map" {
+		return nil
+	}
+
+	var keys map[string]skippedValue
+	if err := cbor.Unmarshal(msg.Data, &keys); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	fields := contentSchema(content).Fields
+	for key := range keys {
+		if !hasField(fields, key) {
+			return fmt.Errorf("message for round %d has unexpected field %q", msg.RoundNumber, key)
+		}
+	}
+	return nil
+}
+
+func hasField(fields []FieldSchema, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // checkBroadcastHash is run after receivedAll() and checks whether all provided verification hashes are correct.
 func (h *MultiHandler) checkBroadcastHash() bool {
 	number := h.currentRound.Number()
@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testContent2 struct {
+	Commitment []byte
+}
+
+func (testContent2) RoundNumber() round.Number { return 2 }
+
+type testContent3 struct {
+	Proof  []byte
+	Shares map[string][]byte
+}
+
+func (testContent3) RoundNumber() round.Number { return 3 }
+
+// testRound only implements the methods used to decode its P2P messages.
+type testRound struct {
+	round.Session
+	number  round.Number
+	content func() round.Content
+}
+
+func (r testRound) Number() round.Number          { return r.number }
+func (testRound) SelfID() party.ID                { return "self" }
+func (testRound) PartyIDs() party.IDSlice         { return party.IDSlice{"a", "self"} }
+func (r testRound) MessageContent() round.Content { return r.content() }
+
+func TestGetRoundMessage_Envelope(t *testing.T) {
+	round2 := testRound{number: 2, content: func() round.Content { return &testContent2{} }}
+
+	data2, err := cbor.Marshal(&testContent2{Commitment: []byte{1, 2, 3}})
+	require.NoError(t, err)
+	data3, err := cbor.Marshal(&testContent3{Proof: make([]byte, 1<<16)})
+	require.NoError(t, err)
+
+	msg, err := getRoundMessage(&Message{From: "a", RoundNumber: 2, Data: data2}, round2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, msg.Content.(*testContent2).Commitment)
+
+	// the header declares round 2, but the content is the one of round 3
+	_, err = getRoundMessage(&Message{From: "a", RoundNumber: 2, Data: data3}, round2)
+	assert.ErrorContains(t, err, "unexpected field")
+
+	// the header declares round 3, but the payload is the one of round 2
+	_, err = getRoundMessage(&Message{From: "a", RoundNumber: 3, Data: data2}, round2)
+	assert.ErrorContains(t, err, "message for round 3 delivered to round 2")
+
+	// the header declares round 3 for a round 3 payload, but round 2 is expected
+	_, err = getRoundMessage(&Message{From: "a", RoundNumber: 3, Data: data3}, round2)
+	assert.ErrorContains(t, err, "message for round 3 delivered to round 2")
+
+	// the content of the round does not match the header
+	round3 := testRound{number: 3, content: func() round.Content { return &testContent2{} }}
+	_, err = getRoundMessage(&Message{From: "a", RoundNumber: 3, Data: data2}, round3)
+	assert.ErrorContains(t, err, "holds content of round 2")
+
+	// senders must be one of the other parties of the session
+	_, err = getRoundMessage(&Message{From: "b", RoundNumber: 2, Data: data2}, round2)
+	assert.ErrorContains(t, err, "unexpected sender")
+	_, err = getRoundMessage(&Message{From: "self", RoundNumber: 2, Data: data2}, round2)
+	assert.ErrorContains(t, err, "unexpected sender")
+
+	_, err = getRoundMessage(&Message{From: "a", RoundNumber: 2, Data: []byte{0xff}}, round2)
+	assert.Error(t, err)
+}
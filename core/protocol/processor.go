@@ -1,6 +1,8 @@
 package protocol
 
 import (
+	"context"
+
 	"github.com/mr-shifu/mpc-lib/lib/round"
 )
 
@@ -9,6 +11,9 @@ type Processor interface {
 	GetRound(ID string) (round.Session, error)
 	StoreBroadcastMessage(ID string, msg round.Message) error
 	StoreMessage(ID string, msg round.Message) error
+	// Finalize must behave as FinalizeContext with context.Background().
 	Finalize(out chan<- *round.Message, ID string) (round.Session, error)
+	// FinalizeContext finalizes the current round of the session ID like round.Round.FinalizeContext.
+	FinalizeContext(ctx context.Context, out chan<- *round.Message, ID string) (round.Session, error)
 	CanFinalize(keyID string) (bool, error)
 }
@@ -0,0 +1,198 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
+)
+
+// ErrRoundTimeout is wrapped by the Error returned by Runner.Run when a round does not complete in time.
+var ErrRoundTimeout = errors.New("protocol: round timed out")
+
+// Runner drives a session of a Processor: it stores the messages it receives, finalizes each round
+// as soon as the round can be finalized, and aborts the session when a round does not complete
+// within the timeout, naming the parties whose messages are missing.
+type Runner struct {
+	proc     Processor
+	statemgr state.MPCStateManager
+	timeout  time.Duration
+}
+
+// NewRunner returns a Runner for the sessions of proc, whose states are kept by statemgr. Each round
+// must complete within timeout of the previous one, or of the start of Run for the first round.
+func NewRunner(proc Processor, statemgr state.MPCStateManager, timeout time.Duration) *Runner {
+	return &Runner{
+		proc:     proc,
+		statemgr: statemgr,
+		timeout:  timeout,
+	}
+}
+
+// Run drives the session ID, which must have been started with the Processor, until it outputs a
+// result or aborts. The messages of the other parties are read from in, and the messages of this
+// party are written to out, which should be drained concurrently. Rounds do not block on out, so
+// it must be buffered with room for the messages of a round.
+//
+// Run returns the *round.Output of the session. If a round aborts, a message fails to be stored, or
+// a round times out, the session is set aborted in the state manager and an Error is returned,
+// whose culprits are the offending parties; for a timeout they are the parties whose messages for
+// the round were not received, and the Error wraps ErrRoundTimeout. If ctx is done first, ctx.Err()
+// is returned and the session is left as it is.
+func (rn *Runner) Run(ctx context.Context, ID string, in <-chan round.Message, out chan<- *round.Message) (*round.Output, error) {
+	timer := time.NewTimer(rn.timeout)
+	defer timer.Stop()
+
+	for {
+		r, err := rn.advance(ctx, ID, out, timer)
+		if err != nil {
+			return nil, err
+		}
+		if output, ok := r.(*round.Output); ok {
+			return output, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			culprits, err := missingParties(r)
+			if err != nil {
+				return nil, rn.abort(ID, err)
+			}
+			return nil, rn.abort(ID, fmt.Errorf("round %d: %w", r.Number(), ErrRoundTimeout), culprits...)
+		case msg, ok := <-in:
+			if !ok {
+				return nil, errors.New("protocol: input closed before the session completed")
+			}
+			if err := rn.store(ID, r, msg); err != nil {
+				return nil, rn.abort(ID, err, msg.From)
+			}
+		}
+	}
+}
+
+// advance finalizes the rounds of session ID for as long as they can be finalized, restarting timer
+// every time a round completes, and returns the current round.
+func (rn *Runner) advance(ctx context.Context, ID string, out chan<- *round.Message, timer *time.Timer) (round.Session, error) {
+	for {
+		r, err := rn.proc.GetRound(ID)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := rn.proc.CanFinalize(ID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return r, nil
+		}
+
+		next, err := rn.proc.FinalizeContext(ctx, out, ID)
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return nil, ctxErr
+		}
+		if err != nil {
+			// the Processor names the parties at fault, e.g. the senders of messages it stored late
+			var perr Error
+			if errors.As(err, &perr) && len(perr.Culprits) > 0 {
+				return nil, rn.abort(ID, err, perr.Culprits...)
+			}
+			return nil, rn.abort(ID, err, r.SelfID())
+		}
+		switch next := next.(type) {
+		case *round.Abort:
+			return nil, rn.abort(ID, next.Err, next.Culprits...)
+		case *round.Output:
+			return next, nil
+		case nil:
+			return nil, rn.abort(ID, fmt.Errorf("round %d: no next round", r.Number()), r.SelfID())
+		}
+		if next.Number() == r.Number() {
+			return r, nil
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(rn.timeout)
+	}
+}
+
+// store passes msg to the Processor, verifying it first if it belongs to the current round r.
+// Messages for another party or for the rounds already finalized are ignored, and so are messages
+// received again, which a transport may deliver more than once: the first one is kept.
+func (rn *Runner) store(ID string, r round.Session, msg round.Message) error {
+	if msg.Content == nil {
+		return round.ErrInvalidContent
+	}
+	number := msg.Content.RoundNumber()
+	if number < r.Number() || (msg.To != "" && msg.To != r.SelfID()) {
+		return nil
+	}
+
+	var err error
+	if msg.Broadcast {
+		err = rn.proc.StoreBroadcastMessage(ID, msg)
+	} else {
+		err = rn.storeMessage(ID, r, msg)
+	}
+	if errors.Is(err, round.ErrDuplicateMessage) {
+		return nil
+	}
+	return err
+}
+
+// storeMessage passes the P2P message msg to the Processor.
+func (rn *Runner) storeMessage(ID string, r round.Session, msg round.Message) error {
+	if number := msg.Content.RoundNumber(); number == r.Number() {
+		if err := r.VerifyMessage(msg); err != nil {
+			return fmt.Errorf("round %d: %w", number, err)
+		}
+	}
+	return rn.proc.StoreMessage(ID, msg)
+}
+
+// abort sets the session ID aborted and returns the Error naming culprits.
+func (rn *Runner) abort(ID string, err error, culprits ...party.ID) error {
+	if serr := rn.statemgr.SetAborted(ID); serr != nil {
+		err = errors.Join(err, serr)
+	}
+	return Error{Culprits: culprits, Err: err}
+}
+
+// missingParties returns the parties from which r expects a broadcast or P2P message which has not
+// been received.
+func missingParties(r round.Session) ([]party.ID, error) {
+	broadcastsFrom, messagesFrom, err := r.ReceivedFrom(r.Number())
+	if err != nil {
+		return nil, err
+	}
+	broadcasts := make(map[party.ID]bool)
+	for _, j := range broadcastsFrom {
+		broadcasts[j] = true
+	}
+	messages := make(map[party.ID]bool)
+	for _, j := range messagesFrom {
+		messages[j] = true
+	}
+
+	b, ok := r.(round.BroadcastRound)
+	expectsBroadcast := ok && b.BroadcastContent() != nil
+	expectsMessage := r.MessageContent() != nil
+
+	var missing []party.ID
+	for _, j := range r.OtherPartyIDs() {
+		if (expectsBroadcast && !broadcasts[j]) || (expectsMessage && !messages[j]) {
+			missing = append(missing, j)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,148 @@
+package protocol
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// FieldSchema describes a single field of a round's message content.
+type FieldSchema struct {
+	// Name is the name of the field, which is also its key in the CBOR encoded content.
+	Name string
+	// Type is the Go type of the field.
+	Type string
+	// Encoding describes how the field is encoded on the wire.
+	Encoding string
+}
+
+// ContentSchema describes the wire format of a broadcast or P2P message content.
+type ContentSchema struct {
+	// Type is the Go type of the content.
+	Type string
+	// Reliable is true if a broadcast content must be reliably broadcast.
+	Reliable bool
+	Fields   []FieldSchema
+}
+
+// Schema describes the messages consumed by a single round of a protocol.
+// Contents are CBOR encoded as a map from field name to field value.
+type Schema struct {
+	ProtocolID string
+	Round      round.Number
+	// Broadcast is nil if the round does not expect a broadcast message.
+	Broadcast *ContentSchema
+	// Message is nil if the round does not expect a P2P message.
+	Message *ContentSchema
+}
+
+type schemaKey struct {
+	protocolID string
+	round      round.Number
+}
+
+var schemas = struct {
+	mtx      sync.RWMutex
+	contents map[schemaKey][]round.Content
+}{contents: make(map[schemaKey][]round.Content)}
+
+// RegisterMessageContent registers the message contents of a protocol, so that their schema
+// can be retrieved with MessageSchema. Each content is registered for the round returned by
+// its RoundNumber method.
+func RegisterMessageContent(protocolID string, contents ...round.Content) {
+	schemas.mtx.Lock()
+	defer schemas.mtx.Unlock()
+
+	for _, c := range contents {
+		key := schemaKey{protocolID, c.RoundNumber()}
+		schemas.contents[key] = append(schemas.contents[key], c)
+	}
+}
+
+// MessageSchema returns a description of the broadcast and P2P message contents expected
+// by the given round of a protocol, derived from their Go types.
+func MessageSchema(protocolID string, number round.Number) (Schema, error) {
+	schemas.mtx.RLock()
+	contents, ok := schemas.contents[schemaKey{protocolID, number}]
+	schemas.mtx.RUnlock()
+	if !ok {
+		return Schema{}, fmt.Errorf("protocol: no messages registered for %s round %d", protocolID, number)
+	}
+
+	s := Schema{
+		ProtocolID: protocolID,
+		Round:      number,
+	}
+	for _, c := range contents {
+		cs := contentSchema(c)
+		if b, ok := c.(round.BroadcastContent); ok {
+			cs.Reliable = b.Reliable()
+			s.Broadcast = cs
+		} else {
+			s.Message = cs
+		}
+	}
+	return s, nil
+}
+
+func contentSchema(c round.Content) *ContentSchema {
+	t := reflect.TypeOf(c)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	cs := &ContentSchema{Type: t.String()}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// embedded broadcast markers and unexported fields are not encoded
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		cs.Fields = append(cs.Fields, FieldSchema{
+			Name:     f.Name,
+			Type:     f.Type.String(),
+			Encoding: fieldEncoding(f.Type),
+		})
+	}
+	return cs
+}
+
+var (
+	cborMarshalerType   = reflect.TypeOf((*cbor.Marshaler)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+)
+
+// fieldEncoding describes how a value of type t is encoded by cbor.Marshal.
+func fieldEncoding(t reflect.Type) string {
+	switch {
+	case t.Implements(cborMarshalerType) || reflect.PointerTo(t).Implements(cborMarshalerType):
+		return "cbor (MarshalCBOR)"
+	case t.Implements(binaryMarshalerType) || reflect.PointerTo(t).Implements(binaryMarshalerType):
+		return "cbor byte string (MarshalBinary)"
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return fieldEncoding(t.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "cbor byte string"
+		}
+		return "cbor array of " + fieldEncoding(t.Elem())
+	case reflect.Map, reflect.Struct:
+		return "cbor map"
+	case reflect.String:
+		return "cbor text string"
+	case reflect.Bool:
+		return "cbor bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "cbor integer"
+	default:
+		return "cbor " + t.Kind().String()
+	}
+}
@@ -0,0 +1,93 @@
+// Package taproot implements the Schnorr signatures of BIP-340, used by Bitcoin Taproot outputs.
+package taproot
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+const (
+	// SignatureLen is the number of bytes in a Signature.
+	SignatureLen = 64
+	// PublicKeyLen is the number of bytes in a PublicKey.
+	PublicKeyLen = 32
+)
+
+// Signature is a BIP-340 signature: the x-coordinate of the commitment R, whose y-coordinate is
+// implicitly even, followed by the response z.
+type Signature []byte
+
+// PublicKey is a BIP-340 x-only public key: the x-coordinate of a point whose y-coordinate is
+// implicitly even.
+type PublicKey []byte
+
+// TaggedHash computes SHA256(SHA256(tag) || SHA256(tag) || data...), the hash function of
+// BIP-340 separated by tag.
+func TaggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	_, _ = h.Write(tagHash[:])
+	_, _ = h.Write(tagHash[:])
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// NewPublicKey returns the x-only public key of Y. The key is that of Y or of -Y, whichever has
+// an even y-coordinate.
+func NewPublicKey(Y *curve.Secp256k1Point) PublicKey {
+	return PublicKey(Y.XBytes())
+}
+
+// NewSignature encodes the commitment R and the response z as a Signature.
+// R must have an even y-coordinate.
+func NewSignature(R *curve.Secp256k1Point, z curve.Scalar) (Signature, error) {
+	if R.IsIdentity() || !R.HasEvenY() {
+		return nil, errors.New("taproot: commitment must have an even y-coordinate")
+	}
+	zBytes, err := z.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sig := make(Signature, 0, SignatureLen)
+	sig = append(sig, R.XBytes()...)
+	sig = append(sig, zBytes...)
+	return sig, nil
+}
+
+// Challenge computes the challenge e = H_BIP0340/challenge(x(R) || pk || m) mod n, where rx is
+// the x-coordinate of R.
+func Challenge(rx []byte, pk PublicKey, m []byte) curve.Scalar {
+	digest := TaggedHash("BIP0340/challenge", rx, pk, m)
+	return curve.Secp256k1{}.NewScalar().SetNat(new(saferith.Nat).SetBytes(digest))
+}
+
+// Verify checks that sig is a valid BIP-340 signature of m under pk.
+func (pk PublicKey) Verify(sig Signature, m []byte) bool {
+	if len(pk) != PublicKeyLen || len(sig) != SignatureLen {
+		return false
+	}
+	group := curve.Secp256k1{}
+
+	P, err := group.LiftX(pk)
+	if err != nil {
+		return false
+	}
+	z := group.NewScalar()
+	if err := z.UnmarshalBinary(sig[32:]); err != nil {
+		return false
+	}
+
+	// R = z⋅G - e⋅P
+	e := Challenge(sig[:32], pk, m)
+	R := z.ActOnBase().Sub(e.Act(P)).(*curve.Secp256k1Point)
+	if R.IsIdentity() || !R.HasEvenY() {
+		return false
+	}
+	// this also rejects an r which is not a field element
+	return string(R.XBytes()) == string(sig[:32])
+}
@@ -0,0 +1,82 @@
+package taproot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// Test vectors from BIP-340.
+func TestVerify_Vectors(t *testing.T) {
+	vectors := []struct {
+		publicKey, message, signature string
+		valid                         bool
+	}{
+		{
+			publicKey: "F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+			message:   "0000000000000000000000000000000000000000000000000000000000000000",
+			signature: "E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0",
+			valid:     true,
+		},
+		{
+			publicKey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			message:   "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			signature: "6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A",
+			valid:     true,
+		},
+		{
+			// public key not on the curve
+			publicKey: "EEFDEA4CDB677750A420FEE807EACF21EB9898AE79B9768766E4FAA04A2D4A34",
+			message:   "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			signature: "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B",
+			valid:     false,
+		},
+	}
+	for i, v := range vectors {
+		pk := PublicKey(mustDecode(t, v.publicKey))
+		sig := Signature(mustDecode(t, v.signature))
+		assert.Equal(t, v.valid, pk.Verify(sig, mustDecode(t, v.message)), "vector %d", i)
+	}
+}
+
+func TestNewSignature(t *testing.T) {
+	group := curve.Secp256k1{}
+	msg := []byte("hello")
+
+	for i := 0; i < 8; i++ {
+		// sign with the key and nonce of even y-coordinate, as BIP-340 does
+		x := sample.Scalar(rand.Reader, group)
+		Y := x.ActOnBase().(*curve.Secp256k1Point)
+		if !Y.HasEvenY() {
+			x.Negate()
+		}
+		k := sample.Scalar(rand.Reader, group)
+		R := k.ActOnBase().(*curve.Secp256k1Point)
+		if !R.HasEvenY() {
+			k.Negate()
+			R = R.Negate().(*curve.Secp256k1Point)
+		}
+		pk := NewPublicKey(Y)
+		z := Challenge(R.XBytes(), pk, msg).Mul(x).Add(k)
+
+		sig, err := NewSignature(R, z)
+		require.NoError(t, err)
+		require.Len(t, sig, SignatureLen)
+		assert.True(t, pk.Verify(sig, msg))
+		assert.False(t, pk.Verify(sig, []byte("other message")))
+
+		_, err = NewSignature(R.Negate().(*curve.Secp256k1Point), z)
+		assert.Error(t, err)
+	}
+}
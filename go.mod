@@ -2,13 +2,27 @@ module github.com/mr-shifu/mpc-lib
 
 go 1.21.4
 
-require github.com/stretchr/testify v1.8.4
+require (
+	filippo.io/edwards25519 v1.1.0
+	filippo.io/nistec v0.0.3
+	github.com/dgraph-io/badger v1.6.2
+	github.com/stretchr/testify v1.8.4
+	google.golang.org/grpc v1.64.1
+)
 
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/AndreasBriese/bbloom v0.0.0-20190825152654-46b345b51c96 // indirect
+	github.com/cespare/xxhash v1.1.0 // indirect
+	github.com/dgraph-io/ristretto v0.0.2 // indirect
+	github.com/dustin/go-humanize v1.0.0 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
-	golang.org/x/sys v0.17.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
 )
 
 require (
@@ -20,7 +34,7 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/zeebo/blake3 v0.2.3
-	golang.org/x/crypto v0.19.0
-	golang.org/x/sync v0.6.0
+	golang.org/x/crypto v0.24.0
+	golang.org/x/sync v0.7.0
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
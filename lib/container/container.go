@@ -0,0 +1,236 @@
+package container
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"golang.org/x/crypto/argon2"
+)
+
+// Version is the current version of the container format.
+const Version = 1
+
+// Algorithm identifies the signature scheme of the key held by a Container.
+type Algorithm string
+
+const (
+	// AlgorithmECDSASecp256k1 is a CMP threshold ECDSA key over secp256k1.
+	// The public key is a compressed point, and the share a 32 byte big endian scalar.
+	AlgorithmECDSASecp256k1 Algorithm = "ecdsa-secp256k1"
+	// AlgorithmEd25519 is a FROST threshold EdDSA key over Ed25519.
+	// The public key and the share use the canonical 32 byte encodings.
+	AlgorithmEd25519 Algorithm = "ed25519"
+)
+
+// Key derivation parameters for password encrypted shares, following the second recommended
+// option of RFC 9106.
+const (
+	kdfArgon2id      = "argon2id"
+	argon2Time       = 3
+	argon2Memory     = 64 * 1024
+	argon2Threads    = 4
+	argon2SaltLength = 16
+	aesKeyLength     = 32
+	gcmNonceLength   = 12
+	gcmTagLength     = 16
+)
+
+// Bounds on the key derivation parameters read from a container, so that decrypting an untrusted
+// container cannot exhaust memory or time. They allow stronger parameters than the defaults.
+const (
+	maxArgon2Time    = 16
+	maxArgon2Memory  = 1024 * 1024
+	maxArgon2Threads = 64
+)
+
+var (
+	ErrUnsupportedVersion   = errors.New("container: unsupported version")
+	ErrUnsupportedAlgorithm = errors.New("container: unsupported algorithm")
+	ErrPasswordRequired     = errors.New("container: share is encrypted")
+	ErrWrongPassword        = errors.New("container: wrong password or corrupted share")
+	ErrInvalidEncryption    = errors.New("container: invalid encryption parameters")
+)
+
+// Container holds the public key of a threshold key and the share of a single party, in a
+// versioned format similar to PKCS#8.
+type Container struct {
+	Version   int
+	Algorithm Algorithm
+	PartyID   party.ID
+	Threshold int
+	PublicKey []byte
+	// Share is the secret share of the party, or nil if the share is encrypted.
+	Share []byte
+	// Encrypted is the password encrypted secret share of the party, or nil if the share
+	// is not encrypted.
+	Encrypted *EncryptedShare `cbor:",omitempty"`
+}
+
+// rawContainer has the fields of Container without its marshalling methods.
+type rawContainer Container
+
+// EncryptedShare is a secret share encrypted with AES-256-GCM, under a key derived from a password.
+type EncryptedShare struct {
+	KDF        string
+	Salt       []byte
+	Time       uint32
+	Memory     uint32
+	Threads    uint8
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// New creates a container for the share of party id. If password is not empty, the share is
+// encrypted with a key derived from password.
+func New(algo Algorithm, id party.ID, threshold int, publicKey, share, password []byte) (*Container, error) {
+	if !algo.valid() {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	c := &Container{
+		Version:   Version,
+		Algorithm: algo,
+		PartyID:   id,
+		Threshold: threshold,
+		PublicKey: append([]byte(nil), publicKey...),
+	}
+	if len(password) == 0 {
+		c.Share = append([]byte(nil), share...)
+		return c, nil
+	}
+
+	enc := &EncryptedShare{
+		KDF:     kdfArgon2id,
+		Salt:    make([]byte, argon2SaltLength),
+		Time:    argon2Time,
+		Memory:  argon2Memory,
+		Threads: argon2Threads,
+	}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return nil, fmt.Errorf("container: failed to sample salt: %w", err)
+	}
+	aead, err := enc.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return nil, fmt.Errorf("container: failed to sample nonce: %w", err)
+	}
+	ad, err := c.associatedData()
+	if err != nil {
+		return nil, err
+	}
+	enc.Ciphertext = aead.Seal(nil, enc.Nonce, share, ad)
+	c.Encrypted = enc
+
+	return c, nil
+}
+
+// SecretShare returns the secret share of the party, decrypting it with password if it is encrypted.
+func (c *Container) SecretShare(password []byte) ([]byte, error) {
+	if c.Encrypted == nil {
+		return append([]byte(nil), c.Share...), nil
+	}
+	if len(password) == 0 {
+		return nil, ErrPasswordRequired
+	}
+	if err := c.Encrypted.validate(); err != nil {
+		return nil, err
+	}
+
+	aead, err := c.Encrypted.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := c.associatedData()
+	if err != nil {
+		return nil, err
+	}
+	share, err := aead.Open(nil, c.Encrypted.Nonce, c.Encrypted.Ciphertext, ad)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	return share, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *Container) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal((*rawContainer)(c))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (c *Container) UnmarshalBinary(data []byte) error {
+	var raw rawContainer
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	if !raw.Algorithm.valid() {
+		return ErrUnsupportedAlgorithm
+	}
+	if raw.Encrypted != nil {
+		if err := raw.Encrypted.validate(); err != nil {
+			return err
+		}
+	}
+	*c = Container(raw)
+	return nil
+}
+
+// associatedData binds the encrypted share to the public part of the container.
+func (c *Container) associatedData() ([]byte, error) {
+	return cbor.Marshal([]interface{}{c.Version, c.Algorithm, c.PartyID, c.Threshold, c.PublicKey})
+}
+
+// validate checks that the parameters of e are supported, and within the bounds of a container
+// which can be decrypted.
+func (e *EncryptedShare) validate() error {
+	if e.KDF != kdfArgon2id {
+		return fmt.Errorf("container: unsupported key derivation %q", e.KDF)
+	}
+	if len(e.Salt) < argon2SaltLength {
+		return fmt.Errorf("%w: salt of %d bytes", ErrInvalidEncryption, len(e.Salt))
+	}
+	if e.Time < 1 || e.Time > maxArgon2Time {
+		return fmt.Errorf("%w: %d argon2 passes", ErrInvalidEncryption, e.Time)
+	}
+	if e.Threads < 1 || e.Threads > maxArgon2Threads {
+		return fmt.Errorf("%w: %d argon2 threads", ErrInvalidEncryption, e.Threads)
+	}
+	// argon2 uses at least 8 KiB per thread
+	if e.Memory < 8*uint32(e.Threads) || e.Memory > maxArgon2Memory {
+		return fmt.Errorf("%w: %d KiB of argon2 memory", ErrInvalidEncryption, e.Memory)
+	}
+	if len(e.Nonce) != gcmNonceLength {
+		return fmt.Errorf("%w: nonce of %d bytes", ErrInvalidEncryption, len(e.Nonce))
+	}
+	if len(e.Ciphertext) < gcmTagLength {
+		return fmt.Errorf("%w: ciphertext of %d bytes", ErrInvalidEncryption, len(e.Ciphertext))
+	}
+	return nil
+}
+
+func (e *EncryptedShare) aead(password []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey(password, e.Salt, e.Time, e.Memory, e.Threads, aesKeyLength)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (algo Algorithm) valid() bool {
+	switch algo {
+	case AlgorithmECDSASecp256k1, AlgorithmEd25519:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer_RoundTrip(t *testing.T) {
+	publicKey := []byte("public key")
+	share := []byte("secret share")
+
+	tests := []struct {
+		name     string
+		password []byte
+	}{
+		{"plaintext", nil},
+		{"encrypted", []byte("correct horse battery staple")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(AlgorithmEd25519, "a", 1, publicKey, share, tt.password)
+			require.NoError(t, err)
+			if tt.password != nil {
+				assert.Nil(t, c.Share)
+				assert.NotContains(t, string(c.Encrypted.Ciphertext), string(share))
+			}
+
+			data, err := c.MarshalBinary()
+			require.NoError(t, err)
+			decoded := &Container{}
+			require.NoError(t, decoded.UnmarshalBinary(data))
+
+			assert.Equal(t, Version, decoded.Version)
+			assert.Equal(t, AlgorithmEd25519, decoded.Algorithm)
+			assert.EqualValues(t, "a", decoded.PartyID)
+			assert.Equal(t, 1, decoded.Threshold)
+			assert.Equal(t, publicKey, decoded.PublicKey)
+
+			got, err := decoded.SecretShare(tt.password)
+			require.NoError(t, err)
+			assert.Equal(t, share, got)
+		})
+	}
+}
+
+func TestContainer_WrongPassword(t *testing.T) {
+	c, err := New(AlgorithmECDSASecp256k1, "a", 1, []byte("public key"), []byte("secret share"), []byte("password"))
+	require.NoError(t, err)
+
+	_, err = c.SecretShare([]byte("wrong password"))
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	_, err = c.SecretShare(nil)
+	assert.ErrorIs(t, err, ErrPasswordRequired)
+
+	// the share is bound to the public part of the container
+	c.PartyID = "b"
+	_, err = c.SecretShare([]byte("password"))
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestContainer_Unsupported(t *testing.T) {
+	_, err := New("rsa", "a", 1, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	c, err := New(AlgorithmEd25519, "a", 1, nil, nil, nil)
+	require.NoError(t, err)
+	c.Version = Version + 1
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, (&Container{}).UnmarshalBinary(data), ErrUnsupportedVersion)
+}
+
+func TestContainer_InvalidEncryption(t *testing.T) {
+	password := []byte("password")
+	tests := []struct {
+		name   string
+		modify func(*EncryptedShare)
+	}{
+		{"kdf", func(e *EncryptedShare) { e.KDF = "scrypt" }},
+		{"short salt", func(e *EncryptedShare) { e.Salt = e.Salt[:4] }},
+		{"no passes", func(e *EncryptedShare) { e.Time = 0 }},
+		{"too many passes", func(e *EncryptedShare) { e.Time = maxArgon2Time + 1 }},
+		{"no threads", func(e *EncryptedShare) { e.Threads = 0 }},
+		{"too many threads", func(e *EncryptedShare) { e.Threads = maxArgon2Threads + 1 }},
+		{"too little memory", func(e *EncryptedShare) { e.Memory = 8*uint32(e.Threads) - 1 }},
+		{"too much memory", func(e *EncryptedShare) { e.Memory = maxArgon2Memory + 1 }},
+		{"short nonce", func(e *EncryptedShare) { e.Nonce = e.Nonce[:8] }},
+		{"long nonce", func(e *EncryptedShare) { e.Nonce = append(e.Nonce, 0) }},
+		{"truncated ciphertext", func(e *EncryptedShare) { e.Ciphertext = e.Ciphertext[:gcmTagLength-1] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(AlgorithmEd25519, "a", 1, []byte("public key"), []byte("secret share"), password)
+			require.NoError(t, err)
+			tt.modify(c.Encrypted)
+
+			data, err := c.MarshalBinary()
+			require.NoError(t, err)
+			assert.Error(t, (&Container{}).UnmarshalBinary(data))
+
+			// a container built in memory is checked before deriving the key
+			_, err = c.SecretShare(password)
+			assert.Error(t, err)
+		})
+	}
+}
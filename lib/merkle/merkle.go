@@ -0,0 +1,125 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+// Leaves and inner nodes are hashed with different prefixes, so that an inner node can not be
+// presented as a leaf (see RFC 6962, section 2.1).
+const (
+	leafPrefix byte = 0x00
+	nodePrefix byte = 0x01
+)
+
+var (
+	ErrEmptyBatch   = errors.New("merkle: empty batch")
+	ErrInvalidIndex = errors.New("merkle: invalid message index")
+)
+
+// Tree is a merkle tree over a batch of messages.
+//
+// If a level has an odd number of nodes, the last node is promoted to the next level unchanged
+// instead of being paired with a copy of itself.
+type Tree struct {
+	// levels[0] holds the leaf hashes, and the last level holds the root.
+	levels [][][]byte
+}
+
+// Step is a single sibling hash on the path from a leaf to the root.
+type Step struct {
+	Hash []byte
+	// Left is true if Hash is the left child of the parent node.
+	Left bool
+}
+
+// Proof proves the inclusion of a message in a Tree.
+type Proof struct {
+	Path []Step
+}
+
+// New computes the merkle tree over messages.
+func New(messages [][]byte) (*Tree, error) {
+	if len(messages) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	level := make([][]byte, len(messages))
+	for i, m := range messages {
+		level[i] = hashLeaf(m)
+	}
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &Tree{levels: levels}, nil
+}
+
+// Root returns the root hash of the tree, which is the message to be signed for the batch.
+func (t *Tree) Root() []byte {
+	root := t.levels[len(t.levels)-1][0]
+	return append([]byte(nil), root...)
+}
+
+// Proof returns the inclusion proof of the message at index.
+func (t *Tree) Proof(index int) (*Proof, error) {
+	if index < 0 || index >= len(t.levels[0]) {
+		return nil, ErrInvalidIndex
+	}
+
+	proof := &Proof{}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Path = append(proof.Path, Step{
+				Hash: append([]byte(nil), level[sibling]...),
+				Left: sibling < index,
+			})
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// Verify returns true if proof proves that message is included in the batch with the given root.
+func Verify(root, message []byte, proof *Proof) bool {
+	if proof == nil {
+		return false
+	}
+
+	h := hashLeaf(message)
+	for _, step := range proof.Path {
+		if step.Left {
+			h = hashNode(step.Hash, h)
+		} else {
+			h = hashNode(h, step.Hash)
+		}
+	}
+	return bytes.Equal(h, root)
+}
+
+func hashLeaf(message []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{leafPrefix})
+	_, _ = h.Write(message)
+	return h.Sum(nil)
+}
+
+func hashNode(left, right []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{nodePrefix})
+	_, _ = h.Write(left)
+	_, _ = h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batch(n int) [][]byte {
+	messages := make([][]byte, n)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("message %d", i))
+	}
+	return messages
+}
+
+func TestVerify(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		messages := batch(n)
+		tree, err := New(messages)
+		require.NoError(t, err)
+		root := tree.Root()
+
+		for i, m := range messages {
+			proof, err := tree.Proof(i)
+			require.NoError(t, err)
+			assert.True(t, Verify(root, m, proof), "message %d of %d should be included", i, n)
+			assert.False(t, Verify(root, []byte("not a member"), proof))
+		}
+	}
+}
+
+func TestVerify_WrongPosition(t *testing.T) {
+	messages := batch(4)
+	tree, err := New(messages)
+	require.NoError(t, err)
+
+	proof, err := tree.Proof(1)
+	require.NoError(t, err)
+	assert.False(t, Verify(tree.Root(), messages[0], proof))
+	assert.False(t, Verify(tree.Root(), messages[1], nil))
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(nil)
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+
+	tree, err := New(batch(2))
+	require.NoError(t, err)
+	_, err = tree.Proof(2)
+	assert.ErrorIs(t, err, ErrInvalidIndex)
+}
+
+func TestRoot_LastMessageNotDuplicated(t *testing.T) {
+	messages := batch(3)
+	tree3, err := New(messages)
+	require.NoError(t, err)
+	tree4, err := New(append(messages, messages[2]))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, tree3.Root(), tree4.Root())
+}
@@ -7,4 +7,6 @@ var (
 	ErrInvalidContent    = errors.New("content is not the right type")
 	ErrNotEnoughMessages = errors.New("not enough messages")
 	ErrOutChanFull       = errors.New("content is not the right type")
+	ErrRoundFinalized    = errors.New("round has already been finalized")
+	ErrDuplicateMessage  = errors.New("message was already received from this party")
 )
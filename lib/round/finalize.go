@@ -0,0 +1,35 @@
+package round
+
+// BeginFinalize marks the round with the given number as being finalized.
+// It returns ErrRoundFinalized if Finalize was already called for that round, which
+// protects the round's state against a caller invoking Finalize twice, possibly concurrently.
+//
+// Callers should defer EndFinalize right after a successful BeginFinalize, so that the mark
+// is released again when Finalize fails.
+func (h *Helper) BeginFinalize(number Number) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if h.finalized == nil {
+		h.finalized = make(map[Number]struct{})
+	}
+	if _, ok := h.finalized[number]; ok {
+		return ErrRoundFinalized
+	}
+	h.finalized[number] = struct{}{}
+	return nil
+}
+
+// EndFinalize releases the mark set by BeginFinalize if *err is non-nil, so that a failed
+// Finalize may be retried. A round which returned a next round, or aborted the session,
+// stays marked.
+func (h *Helper) EndFinalize(number Number, err *error) {
+	if err == nil || *err == nil {
+		return
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	delete(h.finalized, number)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/lib/types"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 )
 
 // Helper implements Session without Round, and can therefore be embedded in the first round of a protocol
@@ -35,6 +36,15 @@ type Helper struct {
 	hash hash.Hash
 
 	mtx sync.Mutex
+
+	// timeline holds the durations of completed rounds
+	timeline timeline
+
+	// finalized holds the numbers of the rounds whose Finalize has been called
+	finalized map[Number]struct{}
+
+	// msgmgr and bcstmgr hold the P2P and broadcast messages received by the rounds
+	msgmgr, bcstmgr message.MessageManager
 }
 
 // NewSession creates a new *Helper which can be embedded in the first Round,
@@ -49,18 +59,33 @@ func NewSession(ID string, info Info, sessionID []byte, pl *pool.Pool, h hash.Ha
 		return nil, errors.New("session: partyIDs invalid")
 	}
 
+	// a session needs at least this party, and every message count is derived from the number of
+	// parties
+	if len(partyIDs) == 0 {
+		return nil, errors.New("session: no parties")
+	}
+
 	// verify our ID is present
 	if !partyIDs.Contains(info.SelfID) {
 		return nil, errors.New("session: selfID not included in partyIDs")
 	}
 
+	// bound the number of parties
+	maxParties := info.MaxParties
+	if maxParties == 0 {
+		maxParties = DefaultMaxParties
+	}
+	if n := len(partyIDs); n > maxParties {
+		return nil, fmt.Errorf("session: number of parties %d exceeds the maximum of %d", n, maxParties)
+	}
+
 	// make sure the threshold is correct
 	if info.Threshold < 0 || info.Threshold > math.MaxUint32 {
 		return nil, fmt.Errorf("session: threshold %d is invalid", info.Threshold)
 	}
 
 	// the number of users satisfies the threshold
-	if n := len(partyIDs); n <= 0 || info.Threshold > n-1 {
+	if n := len(partyIDs); info.Threshold > n-1 {
 		return nil, fmt.Errorf("session: threshold %d is invalid for number of parties %d", info.Threshold, n)
 	}
 
@@ -117,6 +142,18 @@ func NewSession(ID string, info Info, sessionID []byte, pl *pool.Pool, h hash.Ha
 	}, nil
 }
 
+// ResumeSession returns the *Helper of a session which NewSession created before, and whose
+// hash state h was restored from its keystore. The session info is written into fresh, an
+// empty hash, only to recompute the SSID.
+func ResumeSession(ID string, info Info, sessionID []byte, pl *pool.Pool, fresh, h hash.Hash, auxInfo ...core_hash.WriterToWithDomain) (*Helper, error) {
+	helper, err := NewSession(ID, info, sessionID, pl, fresh, auxInfo...)
+	if err != nil {
+		return nil, err
+	}
+	helper.hash = h
+	return helper, nil
+}
+
 // HashForID returns a clone of the hash.Hash for this session, initialized with the given id.
 func (h *Helper) HashForID(id party.ID) hash.Hash {
 	h.mtx.Lock()
@@ -1,7 +1,9 @@
 package round_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
@@ -38,6 +40,15 @@ func TestNewSession(t *testing.T) {
 			curve.Secp256k1{},
 			true,
 		},
+		{
+			"no parties",
+			RNumber,
+			selfID,
+			nil,
+			0,
+			curve.Secp256k1{},
+			true,
+		},
 		{
 			"invalid selfID",
 			RNumber,
@@ -130,3 +141,136 @@ func TestNewSession(t *testing.T) {
 		})
 	}
 }
+
+func TestHelper_EstimatedTimeRemaining(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+
+	hash_ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	hash_mgr := hash.NewHashManager(hash_ks)
+
+	keyID := uuid.New().String()
+	opts := keyopts.Options{}
+	opts.Set("id", keyID, "partyid", string(partyIDs[0]))
+	h := hash_mgr.NewHasher("test", opts)
+
+	info := round.Info{
+		ProtocolID:       "TEST",
+		FinalRoundNumber: 5,
+		SelfID:           partyIDs[0],
+		PartyIDs:         partyIDs,
+		Threshold:        2,
+		Group:            curve.Secp256k1{},
+	}
+	helper, err := round.NewSession(keyID, info, nil, nil, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if eta := helper.EstimatedTimeRemaining(); eta != 0 {
+		t.Errorf("expected no estimate before any round completed, got %v", eta)
+	}
+
+	helper.RecordRoundDuration(1 * time.Second)
+	helper.RecordRoundDuration(3 * time.Second)
+
+	// average of 2s for each of the 3 remaining rounds
+	if eta := helper.EstimatedTimeRemaining(); eta != 6*time.Second {
+		t.Errorf("expected 6s remaining after 2 of 5 rounds, got %v", eta)
+	}
+
+	for i := 0; i < 3; i++ {
+		helper.RecordRoundDuration(2 * time.Second)
+	}
+	if eta := helper.EstimatedTimeRemaining(); eta != 0 {
+		t.Errorf("expected no time remaining after the final round, got %v", eta)
+	}
+	if n := len(helper.RoundDurations()); n != 5 {
+		t.Errorf("expected 5 recorded rounds, got %d", n)
+	}
+}
+
+func TestNewSession_MaxParties(t *testing.T) {
+	tests := []struct {
+		name       string
+		n          int
+		maxParties int
+		wantErr    bool
+	}{
+		{"default maximum", round.DefaultMaxParties, 0, false},
+		{"over default maximum", round.DefaultMaxParties + 1, 0, true},
+		{"custom maximum", 4, 4, false},
+		{"over custom maximum", 5, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partyIDs := test.PartyIDs(tt.n)
+
+			hash_ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+			hash_mgr := hash.NewHashManager(hash_ks)
+			opts := keyopts.Options{}
+			opts.Set("id", uuid.New().String(), "partyid", "a")
+			h := hash_mgr.NewHasher("test", opts)
+
+			info := round.Info{
+				ProtocolID:       "TEST",
+				FinalRoundNumber: 5,
+				SelfID:           partyIDs[0],
+				PartyIDs:         partyIDs,
+				Threshold:        1,
+				Group:            curve.Secp256k1{},
+				MaxParties:       tt.maxParties,
+			}
+			_, err := round.NewSession(uuid.New().String(), info, nil, nil, h)
+			if tt.wantErr == (err == nil) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestHelper_EndFinalize(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+
+	hash_ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	hash_mgr := hash.NewHashManager(hash_ks)
+
+	keyID := uuid.New().String()
+	opts := keyopts.Options{}
+	opts.Set("id", keyID, "partyid", string(partyIDs[0]))
+	h := hash_mgr.NewHasher("test", opts)
+
+	info := round.Info{
+		ProtocolID:       "TEST",
+		FinalRoundNumber: 5,
+		SelfID:           partyIDs[0],
+		PartyIDs:         partyIDs,
+		Threshold:        2,
+		Group:            curve.Secp256k1{},
+	}
+	helper, err := round.NewSession(keyID, info, nil, nil, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finalize := func(fail error) (err error) {
+		if err := helper.BeginFinalize(1); err != nil {
+			return err
+		}
+		defer helper.EndFinalize(1, &err)
+		return fail
+	}
+
+	// a failed Finalize releases the round so that it can be retried
+	failure := errors.New("finalize failed")
+	if err := finalize(failure); !errors.Is(err, failure) {
+		t.Fatalf("expected %v, got %v", failure, err)
+	}
+	if err := finalize(nil); err != nil {
+		t.Fatalf("expected a retry after a failed Finalize to succeed, got %v", err)
+	}
+
+	// a successful Finalize keeps the round marked
+	if err := finalize(nil); !errors.Is(err, round.ErrRoundFinalized) {
+		t.Fatalf("expected %v, got %v", round.ErrRoundFinalized, err)
+	}
+}
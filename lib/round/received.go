@@ -0,0 +1,81 @@
+package round
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+)
+
+// SetMessageManagers attaches the managers in which the rounds of the session record the P2P and
+// broadcast messages they receive, so that ReceivedFrom can report them.
+func (h *Helper) SetMessageManagers(msgmgr, bcstmgr message.MessageManager) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.msgmgr, h.bcstmgr = msgmgr, bcstmgr
+}
+
+// ReceivedFrom returns the other parties whose broadcast and P2P messages of round n this party
+// received, each ordered as OtherPartyIDs.
+//
+// The message managers only record that a message was received, not its content, so only the
+// senders can be reported. In particular the content of P2P messages, which may hold secret
+// shares, is never kept.
+func (h *Helper) ReceivedFrom(n Number) (broadcast, p2p []party.ID, err error) {
+	h.mtx.Lock()
+	msgmgr, bcstmgr := h.msgmgr, h.bcstmgr
+	h.mtx.Unlock()
+	if msgmgr == nil || bcstmgr == nil {
+		return nil, nil, errors.New("session: no message managers attached")
+	}
+
+	received := func(mgr message.MessageManager) []party.ID {
+		var from []party.ID
+		for _, j := range h.OtherPartyIDs() {
+			// the managers only fail to get a message which was not received
+			if _, err := mgr.Get(h.ID, int(n), string(j)); err == nil {
+				from = append(from, j)
+			}
+		}
+		return from
+	}
+	return received(bcstmgr), received(msgmgr), nil
+}
+
+// BeginStore reserves the broadcast or P2P message, as given by broadcast, of party from in
+// round n, and returns ErrDuplicateMessage if it was already recorded or is being stored.
+// Rounds call it before storing anything of a message, so that a replayed message, even one
+// received concurrently, cannot overwrite the keys stored for the first one.
+func (h *Helper) BeginStore(n Number, from party.ID, broadcast bool) error {
+	mgr := h.messageManager(broadcast)
+	if mgr == nil {
+		return nil
+	}
+
+	if !mgr.Reserve(h.ID, int(n), string(from)) {
+		return fmt.Errorf("round %d: %w: %s", n, ErrDuplicateMessage, from)
+	}
+	return nil
+}
+
+// EndStore releases the reservation made by BeginStore if *err is non-nil, so that a message
+// which failed to be stored may be received again. The reservation of a stored message is
+// released when the round records it.
+func (h *Helper) EndStore(n Number, from party.ID, broadcast bool, err *error) {
+	if err == nil || *err == nil {
+		return
+	}
+	if mgr := h.messageManager(broadcast); mgr != nil {
+		mgr.Release(h.ID, int(n), string(from))
+	}
+}
+
+func (h *Helper) messageManager(broadcast bool) message.MessageManager {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if broadcast {
+		return h.bcstmgr
+	}
+	return h.msgmgr
+}
@@ -1,5 +1,7 @@
 package round
 
+import "context"
+
 type Round interface {
 	// VerifyMessage handles an incoming Message and validates its content with regard to the protocol specification.
 	// The content argument can be cast to the appropriate type for this round without error check.
@@ -25,7 +27,15 @@ type Round interface {
 	// In the last round, Finalize should return
 	//   r.ResultRound(result), nil
 	// where result is the output of the protocol.
+	//
+	// Finalize must behave as FinalizeContext with context.Background().
 	Finalize(out chan<- *Message) (Session, error)
+
+	// FinalizeContext is Finalize, except that it stops early and returns ctx.Err() once ctx is done.
+	// Rounds check ctx before their expensive steps, such as generating or verifying proofs, so that a
+	// session which is no longer needed can be abandoned without waiting for the round to complete.
+	// Once ctx is done, the session should be treated as aborted.
+	FinalizeContext(ctx context.Context, out chan<- *Message) (Session, error)
 	
 	CanFinalize() bool
 
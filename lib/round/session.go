@@ -1,11 +1,17 @@
 package round
 
 import (
+	"time"
+
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
+// DefaultMaxParties is the maximum number of parties allowed in a session when Info.MaxParties is not set.
+// It bounds the quadratic cost of message handling in the number of parties.
+const DefaultMaxParties = 256
+
 type Info struct {
 	// ProtocolID is an identifier for this protocol
 	ProtocolID string
@@ -19,6 +25,9 @@ type Info struct {
 	Threshold int
 	// Group returns the group used for this protocol execution.
 	Group curve.Curve
+	// MaxParties is the maximum number of parties allowed to participate in this protocol.
+	// If it is 0, DefaultMaxParties is used.
+	MaxParties int
 }
 
 // Session represents the current execution of a round-based protocol.
@@ -46,4 +55,12 @@ type Session interface {
 	Threshold() int
 	// N returns the total number of parties participating in the protocol.
 	N() int
+	// RecordRoundDuration records the time it took to complete a round of this session.
+	RecordRoundDuration(d time.Duration)
+	// EstimatedTimeRemaining estimates the time left until the final round completes,
+	// based on the average duration of the rounds completed so far.
+	EstimatedTimeRemaining() time.Duration
+	// ReceivedFrom returns the other parties whose broadcast and P2P messages of round n were
+	// received.
+	ReceivedFrom(n Number) (broadcast, p2p []party.ID, err error)
 }
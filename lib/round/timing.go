@@ -0,0 +1,47 @@
+package round
+
+import (
+	"sync"
+	"time"
+)
+
+// timeline records how long each completed round of a session took.
+type timeline struct {
+	mtx       sync.Mutex
+	durations []time.Duration
+}
+
+// RecordRoundDuration records the time it took to complete a round of this session.
+// It should be called once for every finalized round.
+func (h *Helper) RecordRoundDuration(d time.Duration) {
+	h.timeline.mtx.Lock()
+	defer h.timeline.mtx.Unlock()
+	h.timeline.durations = append(h.timeline.durations, d)
+}
+
+// RoundDurations returns the recorded durations of all completed rounds, in order.
+func (h *Helper) RoundDurations() []time.Duration {
+	h.timeline.mtx.Lock()
+	defer h.timeline.mtx.Unlock()
+	return append([]time.Duration(nil), h.timeline.durations...)
+}
+
+// EstimatedTimeRemaining returns the average recorded round duration multiplied by the number
+// of rounds left until FinalRoundNumber. It returns 0 if no round has been recorded yet or if
+// all rounds have completed.
+func (h *Helper) EstimatedTimeRemaining() time.Duration {
+	h.timeline.mtx.Lock()
+	defer h.timeline.mtx.Unlock()
+
+	completed := len(h.timeline.durations)
+	remaining := int(h.info.FinalRoundNumber) - completed
+	if completed == 0 || remaining <= 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, d := range h.timeline.durations {
+		total += d
+	}
+	return total / time.Duration(completed) * time.Duration(remaining)
+}
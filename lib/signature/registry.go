@@ -0,0 +1,176 @@
+// Package signature encodes the signatures produced by the protocols in the formats expected by
+// different chains, selected by name.
+package signature
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/eddsa"
+	"github.com/mr-shifu/mpc-lib/core/taproot"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
+)
+
+// Names of the built-in formats.
+const (
+	// FormatDER is the ASN.1 DER encoding of an ECDSA signature.
+	FormatDER = "der"
+	// FormatRaw is the fixed size encoding of a signature: r ‖ s with a low s for ECDSA, R ‖ z
+	// for EdDSA, and the signature itself for BIP-340.
+	FormatRaw = "raw"
+	// FormatEthRSV is the 65 byte r ‖ s ‖ v encoding of an ECDSA signature used by Ethereum.
+	FormatEthRSV = "eth-rsv"
+	// FormatBIP340 is the 64 byte encoding of a BIP-340 Schnorr signature.
+	FormatBIP340 = "bip340"
+)
+
+var (
+	ErrUnknownFormat       = errors.New("signature: unknown format")
+	ErrUnsupportedType     = errors.New("signature: format does not support this signature type")
+	ErrFormatAlreadyExists = errors.New("signature: format already registered")
+)
+
+// Encoder encodes a signature in a given format. It returns ErrUnsupportedType for a signature
+// type it does not handle.
+type Encoder func(sig any) ([]byte, error)
+
+// EncoderRegistry maps format names to encoders. It is safe for concurrent use.
+type EncoderRegistry struct {
+	mtx      sync.RWMutex
+	encoders map[string]Encoder
+}
+
+// DefaultRegistry holds the built-in encoders, and is used by New.
+var DefaultRegistry = NewEncoderRegistry()
+
+// NewEncoderRegistry returns a registry holding the built-in encoders.
+func NewEncoderRegistry() *EncoderRegistry {
+	return &EncoderRegistry{
+		encoders: map[string]Encoder{
+			FormatDER:    encodeDER,
+			FormatRaw:    encodeRaw,
+			FormatEthRSV: encodeEthRSV,
+			FormatBIP340: encodeBIP340,
+		},
+	}
+}
+
+// Register adds the encoder enc for the format named format. A format can only be registered
+// once.
+func (r *EncoderRegistry) Register(format string, enc Encoder) error {
+	if format == "" || enc == nil {
+		return errors.New("signature: empty format name or nil encoder")
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.encoders[format]; ok {
+		return fmt.Errorf("%w: %s", ErrFormatAlreadyExists, format)
+	}
+	r.encoders[format] = enc
+	return nil
+}
+
+// Formats returns the sorted names of the registered formats.
+func (r *EncoderRegistry) Formats() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	formats := make([]string, 0, len(r.encoders))
+	for format := range r.encoders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Encode encodes sig in the format named format.
+func (r *EncoderRegistry) Encode(format string, sig any) ([]byte, error) {
+	r.mtx.RLock()
+	enc, ok := r.encoders[format]
+	r.mtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	return enc(sig)
+}
+
+// New wraps sig so that it can be encoded with the encoders of r.
+func (r *EncoderRegistry) New(sig any) Signature {
+	return Signature{value: sig, registry: r}
+}
+
+// Signature is a signature produced by a protocol, which can be encoded by format name.
+type Signature struct {
+	value    any
+	registry *EncoderRegistry
+}
+
+// New wraps sig so that it can be encoded with the encoders of DefaultRegistry. sig is the
+// result of a signing protocol: an ecdsa.Signature, an eddsa.Signature or result.EddsaSignature,
+// or a taproot.Signature.
+func New(sig any) Signature {
+	return DefaultRegistry.New(sig)
+}
+
+// Encode encodes the signature in the format named format.
+func (s Signature) Encode(format string) ([]byte, error) {
+	return s.registry.Encode(format, s.value)
+}
+
+func asECDSA(sig any) (*ecdsa.Signature, bool) {
+	switch sig := sig.(type) {
+	case *ecdsa.Signature:
+		return sig, sig != nil
+	case ecdsa.Signature:
+		return &sig, true
+	}
+	return nil, false
+}
+
+func encodeDER(sig any) ([]byte, error) {
+	if s, ok := asECDSA(sig); ok {
+		return s.MarshalDER()
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, sig)
+}
+
+func encodeEthRSV(sig any) ([]byte, error) {
+	if s, ok := asECDSA(sig); ok {
+		return s.MarshalCompact(true)
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, sig)
+}
+
+func encodeBIP340(sig any) ([]byte, error) {
+	if s, ok := sig.(taproot.Signature); ok {
+		if len(s) != taproot.SignatureLen {
+			return nil, fmt.Errorf("signature: invalid length for BIP-340 signature: %d", len(s))
+		}
+		return append([]byte(nil), s...), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, sig)
+}
+
+func encodeRaw(sig any) ([]byte, error) {
+	if s, ok := asECDSA(sig); ok {
+		return s.MarshalCompact(false)
+	}
+	switch s := sig.(type) {
+	case eddsa.Signature:
+		return append(s.R.Bytes(), s.Z.Bytes()...), nil
+	case *eddsa.Signature:
+		if s != nil {
+			return append(s.R.Bytes(), s.Z.Bytes()...), nil
+		}
+	case result.EddsaSignature:
+		return append(s.R().Bytes(), s.Z().Bytes()...), nil
+	case taproot.Signature:
+		return encodeBIP340(s)
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, sig)
+}
@@ -0,0 +1,112 @@
+package signature
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	ed "filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/eddsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/taproot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newECDSASignature(t *testing.T) (*ecdsa.Signature, curve.Point, []byte) {
+	group := curve.Secp256k1{}
+	digest := sha256.Sum256([]byte("hello"))
+	x := sample.Scalar(rand.Reader, group)
+	k := sample.Scalar(rand.Reader, group)
+
+	// R = k⁻¹⋅G and s = k⋅(m + r⋅x)
+	R := group.NewScalar().Set(k).Invert().ActOnBase()
+	m := curve.FromHash(group, digest[:])
+	s := R.XScalar().Mul(x).Add(m).Mul(k)
+	sig := &ecdsa.Signature{R: R, S: s}
+	require.True(t, sig.Verify(x.ActOnBase(), digest[:]))
+	return sig, x.ActOnBase(), digest[:]
+}
+
+func TestEncode_ECDSA(t *testing.T) {
+	sig, X, digest := newECDSASignature(t)
+
+	der, err := New(sig).Encode(FormatDER)
+	require.NoError(t, err)
+	decoded, err := ecdsa.UnmarshalDER(X, digest, der)
+	require.NoError(t, err)
+	assert.True(t, decoded.Verify(X, digest))
+
+	raw, err := New(*sig).Encode(FormatRaw)
+	require.NoError(t, err)
+	assert.Len(t, raw, 64)
+
+	rsv, err := New(sig).Encode(FormatEthRSV)
+	require.NoError(t, err)
+	require.Len(t, rsv, 65)
+	assert.Equal(t, raw, rsv[:64])
+	// the recovery id is checked against the signature
+	_, err = ecdsa.UnmarshalCompact(X, digest, rsv)
+	require.NoError(t, err)
+
+	_, err = New(sig).Encode(FormatBIP340)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestEncode_EdDSA(t *testing.T) {
+	z, err := ed.NewScalar().SetUniformBytes(make([]byte, 64))
+	require.NoError(t, err)
+	sig := eddsa.Signature{R: ed.NewGeneratorPoint(), Z: z}
+
+	raw, err := New(sig).Encode(FormatRaw)
+	require.NoError(t, err)
+	assert.Equal(t, append(sig.R.Bytes(), sig.Z.Bytes()...), raw)
+
+	_, err = New(sig).Encode(FormatDER)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestEncode_BIP340(t *testing.T) {
+	sig := make(taproot.Signature, taproot.SignatureLen)
+	_, _ = rand.Read(sig)
+
+	encoded, err := New(sig).Encode(FormatBIP340)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(sig), encoded)
+
+	raw, err := New(sig).Encode(FormatRaw)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(sig), raw)
+
+	_, err = New(sig[:32]).Encode(FormatBIP340)
+	assert.Error(t, err)
+}
+
+func TestEncoderRegistry(t *testing.T) {
+	r := NewEncoderRegistry()
+	assert.Equal(t, []string{FormatBIP340, FormatDER, FormatEthRSV, FormatRaw}, r.Formats())
+
+	_, err := r.New([]byte("sig")).Encode("hex")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	hexEncoder := func(sig any) ([]byte, error) {
+		b, ok := sig.([]byte)
+		if !ok {
+			return nil, ErrUnsupportedType
+		}
+		return []byte(string(b) + "!"), nil
+	}
+	require.NoError(t, r.Register("hex", hexEncoder))
+	assert.ErrorIs(t, r.Register("hex", hexEncoder), ErrFormatAlreadyExists)
+	assert.ErrorIs(t, r.Register(FormatDER, hexEncoder), ErrFormatAlreadyExists)
+
+	encoded, err := r.New([]byte("sig")).Encode("hex")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("sig!"), encoded)
+
+	// the default registry is not affected
+	_, err = New([]byte("sig")).Encode("hex")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -21,6 +21,7 @@ func GenerateConfig(group curve.Curve, N, T int, source io.Reader, pl *pool.Pool
 	public := make(map[party.ID]*config.Public, N)
 
 	f := polynomial.NewPolynomial(group, T, sample.Scalar(source, group))
+	F := polynomial.NewPolynomialExponent(f)
 
 	rid, err := types.NewRID(source)
 	if err != nil {
@@ -39,15 +40,16 @@ func GenerateConfig(group curve.Curve, N, T int, source io.Reader, pl *pool.Pool
 
 		ecdsaSecret := f.Evaluate(pid.Scalar(group))
 		configs[pid] = &config.Config{
-			Group:     group,
-			ID:        pid,
-			Threshold: T,
-			ECDSA:     ecdsaSecret,
-			ElGamal:   elGamalSecret,
-			Paillier:  paillierSecret,
-			RID:       rid.Copy(),
-			ChainKey:  chainKey.Copy(),
-			Public:    public,
+			Group:      group,
+			ID:         pid,
+			Threshold:  T,
+			ECDSA:      ecdsaSecret,
+			ElGamal:    elGamalSecret,
+			Paillier:   paillierSecret,
+			RID:        rid.Copy(),
+			ChainKey:   chainKey.Copy(),
+			Public:     public,
+			Polynomial: F,
 		}
 		X := ecdsaSecret.ActOnBase()
 		public[pid] = &config.Public{
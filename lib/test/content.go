@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// cborEmptyMap is the CBOR encoding of a struct without exported fields.
+var cborEmptyMap = []byte{0xa0}
+
+// ContentRoundTrip encodes content with CBOR and decodes it into empty, the uninitialized content returned
+// by the receiving round. It returns an error if a field of content is not recovered by the decoding.
+func ContentRoundTrip(content, empty round.Content) error {
+	if empty == nil {
+		return fmt.Errorf("%T: no content to decode into", content)
+	}
+	data, err := cbor.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("%T: failed to encode: %w", content, err)
+	}
+	if err = cbor.Unmarshal(data, empty); err != nil {
+		return fmt.Errorf("%T: failed to decode: %w", content, err)
+	}
+
+	// contents which encode themselves are compared as a whole
+	if _, ok := content.(encoding.BinaryMarshaler); ok {
+		return equalEncoding(fmt.Sprintf("%T", content), content, empty)
+	}
+
+	v, w := reflect.Indirect(reflect.ValueOf(content)), reflect.Indirect(reflect.ValueOf(empty))
+	if v.Type() != w.Type() {
+		return fmt.Errorf("%T: decoded into %T", content, empty)
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := fmt.Sprintf("%T.%s", content, field.Name)
+		if err := equalEncoding(name, v.Field(i).Interface(), w.Field(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// equalEncoding checks that a and b have the same encoding, and that the encoding of a is not empty.
+func equalEncoding(name string, a, b interface{}) error {
+	dataA, err := encode(a)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode: %w", name, err)
+	}
+	dataB, err := encode(b)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode decoded value: %w", name, err)
+	}
+	if bytes.Equal(dataA, cborEmptyMap) && !isZero(a) {
+		return fmt.Errorf("%s: %T has no exported fields and is not serializable", name, a)
+	}
+	if !bytes.Equal(dataA, dataB) {
+		return fmt.Errorf("%s: decoded value differs", name)
+	}
+	return nil
+}
+
+func isZero(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+func encode(v interface{}) ([]byte, error) {
+	if m, ok := v.(encoding.BinaryMarshaler); ok && !isZero(v) {
+		return m.MarshalBinary()
+	}
+	return cbor.Marshal(v)
+}
+
+// ContentChecker is a Rule which checks with ContentRoundTrip every content sent by the rounds it is applied to.
+type ContentChecker struct {
+	mtx sync.Mutex
+	// checked maps the type of the contents to the first error returned by ContentRoundTrip for this type
+	checked map[string]error
+}
+
+// ModifyBefore implements Rule.
+func (*ContentChecker) ModifyBefore(round.Session) {}
+
+// ModifyAfter implements Rule.
+func (*ContentChecker) ModifyAfter(round.Session) {}
+
+// ModifyContent implements Rule. It does not modify the content.
+func (c *ContentChecker) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	var empty round.Content
+	if _, ok := content.(round.BroadcastContent); ok {
+		if b, ok := rNext.(round.BroadcastRound); ok {
+			empty = b.BroadcastContent()
+		}
+	} else {
+		empty = rNext.MessageContent()
+	}
+	err := ContentRoundTrip(content, empty)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.checked == nil {
+		c.checked = map[string]error{}
+	}
+	name := fmt.Sprintf("%T", content)
+	if prev, ok := c.checked[name]; !ok || prev == nil {
+		c.checked[name] = err
+	}
+}
+
+// Check returns the result of the round trip of the contents with the same type as content.
+// It returns an error if no such content was sent.
+func (c *ContentChecker) Check(content round.Content) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	name := fmt.Sprintf("%T", content)
+	err, ok := c.checked[name]
+	if !ok {
+		return fmt.Errorf("%s: no content of this type was sent", name)
+	}
+	return err
+}
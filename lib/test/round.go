@@ -22,11 +22,43 @@ type Rule interface {
 	ModifyContent(rNext round.Session, to party.ID, content round.Content)
 }
 
+// group runs the functions finalizing the rounds and delivering messages.
+type group interface {
+	Go(f func() error)
+	Wait() error
+}
+
+// serialGroup runs each function on the calling goroutine as soon as it is added.
+type serialGroup struct {
+	err error
+}
+
+func (g *serialGroup) Go(f func() error) {
+	if g.err == nil {
+		g.err = f()
+	}
+}
+
+func (g *serialGroup) Wait() error {
+	return g.err
+}
+
+// Rounds finalizes the rounds of all parties concurrently, and delivers the resulting messages.
 func Rounds(rounds []round.Session, rule Rule) (error, bool) {
+	return runRounds(rounds, rule, &errgroup.Group{})
+}
+
+// SerialRounds is like Rounds, but finalizes the rounds one party after the other, in the order
+// of rounds, and delivers the messages one at a time. Together with a serial pool and a
+// deterministic source of randomness, it makes protocol runs reproducible.
+func SerialRounds(rounds []round.Session, rule Rule) (error, bool) {
+	return runRounds(rounds, rule, &serialGroup{})
+}
+
+func runRounds(rounds []round.Session, rule Rule, errGroup group) (error, bool) {
 	var (
 		err       error
 		roundType reflect.Type
-		errGroup  errgroup.Group
 		N         = len(rounds)
 		out       = make(chan *round.Message, N*(N+1))
 	)
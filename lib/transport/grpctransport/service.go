@@ -0,0 +1,70 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"google.golang.org/grpc"
+)
+
+const (
+	serviceName = "mpclib.transport.Transport"
+	sendMethod  = "/" + serviceName + "/Send"
+)
+
+// envelope is the message exchanged between two transports.
+type envelope struct {
+	// Epoch identifies the instance of the transport of From which sent the envelope.
+	Epoch uint64
+	// Seq numbers the messages sent by From to the receiving party in Epoch, starting from 1.
+	Seq       uint64
+	From, To  party.ID
+	Broadcast bool
+	Round     round.Number
+	// Content is the CBOR encoding of the content of the message.
+	Content []byte
+}
+
+// ack is the reply to an envelope.
+type ack struct{}
+
+// cborCodec encodes the envelopes with CBOR, so that no protobuf definitions are needed.
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v any) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v any) error { return cbor.Unmarshal(data, v) }
+func (cborCodec) Name() string                       { return "cbor" }
+
+// server is implemented by the Transport receiving the envelopes.
+type server interface {
+	receive(ctx context.Context, env *envelope) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*server)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Send", Handler: sendHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func sendHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	env := new(envelope)
+	if err := dec(env); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		if err := srv.(server).receive(ctx, req.(*envelope)); err != nil {
+			return nil, err
+		}
+		return &ack{}, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, env)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: sendMethod}
+	return interceptor(ctx, env, info, handler)
+}
@@ -0,0 +1,405 @@
+// Package grpctransport implements transport.Transport over gRPC.
+//
+// Each party runs a gRPC server receiving the messages of the other parties, and keeps a connection to the
+// server of every other party. The messages sent to a party are queued and sent one at a time, and a message
+// is sent again until the party acknowledges it, so that they are received in order even if the connection
+// is lost in between. gRPC reconnects to the party on its own. When either party restarts, the receiver
+// resumes from the first message it is sent again, so a message received just before a restart may be
+// received twice.
+//
+// The parties authenticate each other with mutual TLS: the certificate of a party must hold its ID as a DNS
+// name, and the messages received over a connection are only accepted from the party named by the certificate
+// of the other end.
+package grpctransport
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRetryInterval = 100 * time.Millisecond
+	// sendTimeout bounds a single attempt at sending a message.
+	sendTimeout = 10 * time.Second
+)
+
+// Config describes a Transport.
+type Config struct {
+	// Self is the party using the transport.
+	Self party.ID
+	// Listener accepts the connections of the other parties.
+	Listener net.Listener
+	// Peers maps the other parties to the addresses of their transports.
+	Peers map[party.ID]string
+	// Certificate is the TLS certificate of Self, presented to the other parties both by the server and
+	// by the connections to their servers. It must hold the ID of Self as a DNS name.
+	Certificate tls.Certificate
+	// RootCAs holds the authorities which issued the certificates of the parties.
+	RootCAs *x509.CertPool
+	// Insecure disables TLS, so that the senders of the messages are not authenticated. It must only be
+	// used when the network already authenticates the parties, or in tests.
+	Insecure bool
+	// ServerOptions are added to the options of the server receiving the messages.
+	ServerOptions []grpc.ServerOption
+	// DialOptions are added to the options of the connections to the other parties.
+	DialOptions []grpc.DialOption
+	// RetryInterval is the time waited before sending again a message which was not acknowledged.
+	// It defaults to 100ms.
+	RetryInterval time.Duration
+}
+
+// Transport is a transport.Transport over gRPC.
+type Transport struct {
+	self party.ID
+	// epoch identifies this instance of the transport of self, so that the other parties notice a
+	// restart and start the numbering of its messages again
+	epoch    uint64
+	insecure bool
+	server   *grpc.Server
+	peers    map[party.ID]*peer
+	retry    time.Duration
+
+	recv chan *round.Message
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// senders counts the goroutines sending the messages to the peers
+	senders sync.WaitGroup
+	// receiving counts the messages being pushed to recv
+	receiving sync.WaitGroup
+
+	mtx    sync.Mutex
+	closed bool
+}
+
+var _ transport.Transport = (*Transport)(nil)
+
+// peer holds the connection to another party, and the messages exchanged with it.
+type peer struct {
+	conn *grpc.ClientConn
+
+	// queue holds the envelopes which were not acknowledged yet, in order
+	mtx    sync.Mutex
+	seq    uint64
+	queue  []*envelope
+	notify chan struct{}
+
+	// lastSeq is the sequence number of the last envelope received from the party in lastEpoch
+	recvMtx   sync.Mutex
+	lastEpoch uint64
+	lastSeq   uint64
+}
+
+// New starts serving on cfg.Listener and connects to the other parties.
+func New(cfg Config) (*Transport, error) {
+	if cfg.Self == "" {
+		return nil, errors.New("grpctransport: empty party ID")
+	}
+	if cfg.Listener == nil {
+		return nil, errors.New("grpctransport: nil listener")
+	}
+	if _, ok := cfg.Peers[cfg.Self]; ok {
+		return nil, errors.New("grpctransport: peers contain self")
+	}
+
+	if !cfg.Insecure && (cfg.Certificate.Certificate == nil || cfg.RootCAs == nil) {
+		return nil, errors.New("grpctransport: missing TLS certificate or root CAs")
+	}
+	retry := cfg.RetryInterval
+	if retry <= 0 {
+		retry = defaultRetryInterval
+	}
+	var epoch [8]byte
+	if _, err := rand.Read(epoch[:]); err != nil {
+		return nil, fmt.Errorf("grpctransport: failed to sample epoch: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Transport{
+		self:     cfg.Self,
+		epoch:    binary.BigEndian.Uint64(epoch[:]),
+		insecure: cfg.Insecure,
+		peers:    make(map[party.ID]*peer, len(cfg.Peers)),
+		retry:    retry,
+		recv:     make(chan *round.Message, 16*(len(cfg.Peers)+1)),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for id, addr := range cfg.Peers {
+		creds := insecure.NewCredentials()
+		if !cfg.Insecure {
+			creds = credentials.NewTLS(&tls.Config{
+				Certificates: []tls.Certificate{cfg.Certificate},
+				RootCAs:      cfg.RootCAs,
+				ServerName:   string(id),
+				MinVersion:   tls.VersionTLS13,
+			})
+		}
+		dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, cfg.DialOptions...)
+		conn, err := grpc.NewClient(addr, dialOpts...)
+		if err != nil {
+			cancel()
+			t.closeConns()
+			return nil, fmt.Errorf("grpctransport: failed to connect to %s: %w", id, err)
+		}
+		t.peers[id] = &peer{conn: conn, notify: make(chan struct{}, 1)}
+	}
+
+	serverOpts := []grpc.ServerOption{grpc.ForceServerCodec(cborCodec{})}
+	if !cfg.Insecure {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cfg.Certificate},
+			ClientCAs:    cfg.RootCAs,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			MinVersion:   tls.VersionTLS13,
+		})))
+	}
+	serverOpts = append(serverOpts, cfg.ServerOptions...)
+	t.server = grpc.NewServer(serverOpts...)
+	t.server.RegisterService(&serviceDesc, t)
+	go func() { _ = t.server.Serve(cfg.Listener) }()
+
+	for _, p := range t.peers {
+		t.senders.Add(1)
+		go t.send(p)
+	}
+	return t, nil
+}
+
+// Send implements transport.Transport.
+// Messages which are still queued when the transport is closed are dropped.
+func (t *Transport) Send(to party.ID, msg *round.Message) error {
+	if msg == nil || msg.Content == nil {
+		return round.ErrNilFields
+	}
+	data, err := cbor.Marshal(msg.Content)
+	if err != nil {
+		return fmt.Errorf("grpctransport: failed to encode content: %w", err)
+	}
+
+	var recipients []*peer
+	if to == "" {
+		for _, p := range t.peers {
+			recipients = append(recipients, p)
+		}
+	} else {
+		p, ok := t.peers[to]
+		if !ok {
+			return fmt.Errorf("%w: %s", transport.ErrUnknownParty, to)
+		}
+		recipients = append(recipients, p)
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.closed {
+		return transport.ErrClosed
+	}
+	for _, p := range recipients {
+		p.push(&envelope{
+			Epoch:     t.epoch,
+			From:      t.self,
+			To:        msg.To,
+			Broadcast: msg.Broadcast,
+			Round:     msg.Content.RoundNumber(),
+			Content:   data,
+		})
+	}
+	return nil
+}
+
+// Recv implements transport.Transport.
+func (t *Transport) Recv() <-chan *round.Message {
+	return t.recv
+}
+
+// Close implements transport.Transport.
+func (t *Transport) Close() error {
+	t.mtx.Lock()
+	if t.closed {
+		t.mtx.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mtx.Unlock()
+
+	t.cancel()
+	t.server.Stop()
+	t.senders.Wait()
+	t.receiving.Wait()
+	close(t.recv)
+	return t.closeConns()
+}
+
+func (t *Transport) closeConns() error {
+	var errs []error
+	for _, p := range t.peers {
+		if err := p.conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send sends the envelopes queued for p, one at a time, until the transport is closed.
+func (t *Transport) send(p *peer) {
+	defer t.senders.Done()
+	for {
+		env := p.next()
+		if env == nil {
+			select {
+			case <-p.notify:
+				continue
+			case <-t.ctx.Done():
+				return
+			}
+		}
+
+		for {
+			ctx, cancel := context.WithTimeout(t.ctx, sendTimeout)
+			err := p.conn.Invoke(ctx, sendMethod, env, new(ack), grpc.ForceCodec(cborCodec{}), grpc.WaitForReady(true))
+			cancel()
+			// an invalid envelope will never be accepted, so it is dropped
+			if err == nil || status.Code(err) == codes.InvalidArgument {
+				break
+			}
+			select {
+			case <-time.After(t.retry):
+			case <-t.ctx.Done():
+				return
+			}
+		}
+		p.pop()
+	}
+}
+
+// receive pushes the message in env to recv, unless it was already received.
+func (t *Transport) receive(ctx context.Context, env *envelope) error {
+	if err := t.authenticate(ctx, env); err != nil {
+		return err
+	}
+
+	t.mtx.Lock()
+	if t.closed {
+		t.mtx.Unlock()
+		return status.Error(codes.Unavailable, transport.ErrClosed.Error())
+	}
+	p, ok := t.peers[env.From]
+	if !ok {
+		t.mtx.Unlock()
+		return status.Errorf(codes.InvalidArgument, "%s: %s", transport.ErrUnknownParty, env.From)
+	}
+	t.receiving.Add(1)
+	t.mtx.Unlock()
+	defer t.receiving.Done()
+
+	if env.Seq == 0 {
+		return status.Errorf(codes.InvalidArgument, "message from %s without sequence number", env.From)
+	}
+
+	p.recvMtx.Lock()
+	defer p.recvMtx.Unlock()
+	// the sender restarted, or this transport did and lost track of the sender: the sender sends
+	// the messages which were not acknowledged in order, so the first one received is the next
+	if env.Epoch != p.lastEpoch {
+		p.lastEpoch = env.Epoch
+		p.lastSeq = env.Seq - 1
+	}
+	// the acknowledgement of a message was lost, and it is sent again
+	if env.Seq <= p.lastSeq {
+		return nil
+	}
+	if env.Seq != p.lastSeq+1 {
+		return status.Errorf(codes.FailedPrecondition, "expected message %d from %s, got %d", p.lastSeq+1, env.From, env.Seq)
+	}
+
+	msg := &round.Message{
+		From:      env.From,
+		To:        env.To,
+		Broadcast: env.Broadcast,
+		Content:   &transport.RawContent{Number: env.Round, Data: env.Content},
+	}
+	select {
+	case t.recv <- msg:
+		p.lastSeq = env.Seq
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-t.ctx.Done():
+		return status.Error(codes.Unavailable, transport.ErrClosed.Error())
+	}
+}
+
+// authenticate checks that env was sent by the party named by the certificate of the connection,
+// and is addressed to this transport.
+func (t *Transport) authenticate(ctx context.Context, env *envelope) error {
+	if env.Broadcast && env.To != "" || !env.Broadcast && env.To != t.self {
+		return status.Errorf(codes.InvalidArgument, "message from %s is addressed to %q", env.From, env.To)
+	}
+	if t.insecure {
+		return nil
+	}
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unknown peer")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return status.Error(codes.Unauthenticated, "peer without a verified certificate")
+	}
+	if cert := info.State.VerifiedChains[0][0]; !slices.Contains(cert.DNSNames, string(env.From)) {
+		return status.Errorf(codes.PermissionDenied, "certificate of the peer does not name %s", env.From)
+	}
+	return nil
+}
+
+func (p *peer) push(env *envelope) {
+	p.mtx.Lock()
+	p.seq++
+	env.Seq = p.seq
+	p.queue = append(p.queue, env)
+	p.mtx.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the first envelope of the queue, or nil if it is empty.
+func (p *peer) next() *envelope {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if len(p.queue) == 0 {
+		return nil
+	}
+	return p.queue[0]
+}
+
+// pop removes the first envelope of the queue.
+func (p *peer) pop() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.queue[0] = nil
+	p.queue = p.queue[1:]
+}
@@ -0,0 +1,267 @@
+package grpctransport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/lib/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+type testContent struct {
+	Number round.Number
+	Index  int
+}
+
+func (c *testContent) RoundNumber() round.Number { return c.Number }
+
+func listen(t *testing.T, addr string) net.Listener {
+	lis, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	return lis
+}
+
+// certificates returns TLS certificates for the parties, issued by the returned roots.
+func certificates(t *testing.T, partyIDs ...party.ID) (map[party.ID]tls.Certificate, *x509.CertPool) {
+	certs, roots, err := test.TLSCertificates(partyIDs)
+	require.NoError(t, err)
+	return certs, roots
+}
+
+// newTransports starts a transport for each party, listening on the loopback interface.
+func newTransports(t *testing.T, partyIDs party.IDSlice) map[party.ID]*Transport {
+	certs, roots := certificates(t, partyIDs...)
+	listeners := make(map[party.ID]net.Listener, len(partyIDs))
+	for _, id := range partyIDs {
+		listeners[id] = listen(t, "127.0.0.1:0")
+	}
+
+	transports := make(map[party.ID]*Transport, len(partyIDs))
+	for _, id := range partyIDs {
+		peers := make(map[party.ID]string, len(partyIDs)-1)
+		for _, j := range partyIDs {
+			if j != id {
+				peers[j] = listeners[j].Addr().String()
+			}
+		}
+		tr, err := New(Config{Self: id, Listener: listeners[id], Peers: peers, Certificate: certs[id], RootCAs: roots})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tr.Close() })
+		transports[id] = tr
+	}
+	return transports
+}
+
+// receive returns the next message received by tr, decoded as a testContent.
+func receive(t *testing.T, tr transport.Transport) (*round.Message, *testContent) {
+	select {
+	case msg := <-tr.Recv():
+		require.NotNil(t, msg)
+		raw, ok := msg.Content.(*transport.RawContent)
+		require.True(t, ok)
+		content := &testContent{}
+		require.NoError(t, cbor.Unmarshal(raw.Data, content))
+		assert.Equal(t, raw.Number, content.Number)
+		return msg, content
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "no message received")
+		return nil, nil
+	}
+}
+
+func TestTransport_BroadcastInOrder(t *testing.T) {
+	partyIDs := party.IDSlice{"a", "b", "c"}
+	transports := newTransports(t, partyIDs)
+
+	const n = 50
+	for i := 0; i < n; i++ {
+		msg := &round.Message{From: "a", Broadcast: true, Content: &testContent{Number: 2, Index: i}}
+		require.NoError(t, transports["a"].Send("", msg))
+	}
+
+	for _, id := range []party.ID{"b", "c"} {
+		for i := 0; i < n; i++ {
+			msg, content := receive(t, transports[id])
+			assert.Equal(t, party.ID("a"), msg.From)
+			assert.True(t, msg.Broadcast)
+			assert.Equal(t, i, content.Index)
+		}
+	}
+}
+
+func TestTransport_P2P(t *testing.T) {
+	partyIDs := party.IDSlice{"a", "b", "c"}
+	transports := newTransports(t, partyIDs)
+
+	msg := &round.Message{From: "a", To: "c", Content: &testContent{Number: 3, Index: 7}}
+	require.NoError(t, transports["a"].Send("c", msg))
+
+	received, content := receive(t, transports["c"])
+	assert.Equal(t, party.ID("c"), received.To)
+	assert.False(t, received.Broadcast)
+	assert.Equal(t, 7, content.Index)
+
+	select {
+	case <-transports["b"].Recv():
+		assert.Fail(t, "message delivered to another party")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	err := transports["a"].Send("d", msg)
+	assert.ErrorIs(t, err, transport.ErrUnknownParty)
+}
+
+func TestTransport_Reconnect(t *testing.T) {
+	// reserve an address for b, which starts listening only after a has sent its messages
+	lis := listen(t, "127.0.0.1:0")
+	addrB := lis.Addr().String()
+	require.NoError(t, lis.Close())
+	lisA := listen(t, "127.0.0.1:0")
+	certs, roots := certificates(t, "a", "b")
+
+	a, err := New(Config{
+		Self: "a", Listener: lisA, Peers: map[party.ID]string{"b": addrB},
+		Certificate: certs["a"], RootCAs: roots, RetryInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer a.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, a.Send("b", &round.Message{From: "a", To: "b", Content: &testContent{Number: 2, Index: i}}))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	b, err := New(Config{
+		Self: "b", Listener: listen(t, addrB), Peers: map[party.ID]string{"a": lisA.Addr().String()},
+		Certificate: certs["b"], RootCAs: roots,
+	})
+	require.NoError(t, err)
+	defer b.Close()
+
+	for i := 0; i < 3; i++ {
+		_, content := receive(t, b)
+		assert.Equal(t, i, content.Index)
+	}
+}
+
+func TestTransport_Restart(t *testing.T) {
+	certs, roots := certificates(t, "a", "b")
+	lisA := listen(t, "127.0.0.1:0")
+	lisB := listen(t, "127.0.0.1:0")
+	addrA, addrB := lisA.Addr().String(), lisB.Addr().String()
+	newA := func(lis net.Listener) *Transport {
+		a, err := New(Config{
+			Self: "a", Listener: lis, Peers: map[party.ID]string{"b": addrB},
+			Certificate: certs["a"], RootCAs: roots, RetryInterval: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		return a
+	}
+	newB := func(lis net.Listener) *Transport {
+		b, err := New(Config{
+			Self: "b", Listener: lis, Peers: map[party.ID]string{"a": addrA},
+			Certificate: certs["b"], RootCAs: roots, RetryInterval: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		return b
+	}
+	send := func(a *Transport, i int) {
+		require.NoError(t, a.Send("b", &round.Message{From: "a", To: "b", Content: &testContent{Number: 2, Index: i}}))
+	}
+	// expect receives the message i, skipping the messages received again after a restart
+	expect := func(b *Transport, i int) {
+		for {
+			_, content := receive(t, b)
+			if content.Index == i {
+				return
+			}
+			require.Less(t, content.Index, i)
+		}
+	}
+
+	a, b := newA(lisA), newB(lisB)
+	for i := 0; i < 2; i++ {
+		send(a, i)
+		expect(b, i)
+	}
+
+	// b restarts without knowing the messages a already sent
+	require.NoError(t, b.Close())
+	b = newB(listen(t, addrB))
+	defer b.Close()
+	for i := 2; i < 4; i++ {
+		send(a, i)
+		expect(b, i)
+	}
+
+	// a restarts and numbers its messages from the start again
+	require.NoError(t, a.Close())
+	a = newA(listen(t, addrA))
+	defer a.Close()
+	for i := 4; i < 6; i++ {
+		send(a, i)
+		expect(b, i)
+	}
+}
+
+func TestTransport_Authentication(t *testing.T) {
+	certs, roots := certificates(t, "a", "b", "c")
+	lisB := listen(t, "127.0.0.1:0")
+	addrB := lisB.Addr().String()
+
+	_, err := New(Config{Self: "b", Listener: listen(t, "127.0.0.1:0")})
+	assert.Error(t, err, "TLS should be required")
+
+	// a and c never start a transport, their messages are sent directly below
+	b, err := New(Config{
+		Self: "b", Listener: lisB, Peers: map[party.ID]string{"a": "127.0.0.1:1", "c": "127.0.0.1:1"},
+		Certificate: certs["b"], RootCAs: roots,
+	})
+	require.NoError(t, err)
+	defer b.Close()
+
+	invoke := func(cert tls.Certificate, roots *x509.CertPool, env *envelope) error {
+		creds := credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: roots, ServerName: "b"})
+		conn, err := grpc.NewClient(addrB, grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return conn.Invoke(ctx, sendMethod, env, new(ack), grpc.ForceCodec(cborCodec{}))
+	}
+	content, err := cbor.Marshal(&testContent{Number: 2, Index: 1})
+	require.NoError(t, err)
+
+	err = invoke(certs["c"], roots, &envelope{Epoch: 1, Seq: 1, From: "a", To: "b", Round: 2, Content: content})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "c must not send messages as a")
+
+	err = invoke(certs["c"], roots, &envelope{Epoch: 1, Seq: 1, From: "c", To: "a", Round: 2, Content: content})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "b must not accept messages addressed to a")
+
+	err = invoke(certs["c"], roots, &envelope{Epoch: 1, Seq: 1, From: "c", To: "b", Broadcast: true, Round: 2, Content: content})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "broadcast messages must not be addressed")
+
+	otherCerts, otherRoots := certificates(t, "a")
+	err = invoke(otherCerts["a"], roots, &envelope{Epoch: 1, Seq: 1, From: "a", To: "b", Round: 2, Content: content})
+	assert.Error(t, err, "certificates of another authority must be rejected")
+	err = invoke(certs["a"], otherRoots, &envelope{Epoch: 1, Seq: 1, From: "a", To: "b", Round: 2, Content: content})
+	assert.Error(t, err, "b must present a certificate of the trusted authority")
+
+	require.NoError(t, invoke(certs["c"], roots, &envelope{Epoch: 1, Seq: 1, From: "c", To: "b", Round: 2, Content: content}))
+	msg, received := receive(t, b)
+	assert.Equal(t, party.ID("c"), msg.From)
+	assert.Equal(t, 1, received.Index)
+}
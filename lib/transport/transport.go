@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+var (
+	ErrClosed       = errors.New("transport: closed")
+	ErrUnknownParty = errors.New("transport: unknown party")
+)
+
+// Transport carries the messages output by the rounds of a session to the other parties.
+type Transport interface {
+	// Send delivers msg to the party to, or to all other parties if to is empty.
+	// Messages sent to the same party are received in the order they were sent.
+	Send(to party.ID, msg *round.Message) error
+
+	// Recv returns the channel on which the messages of the other parties are received.
+	// The content of these messages is a *RawContent, which Deliver decodes for the receiving round.
+	// The channel is closed by Close.
+	Recv() <-chan *round.Message
+
+	// Close stops sending and receiving messages.
+	Close() error
+}
+
+// RawContent is the content of a received message, before it is decoded for the round it belongs to.
+type RawContent struct {
+	Number round.Number
+	// Data is the CBOR encoding of the content.
+	Data []byte
+}
+
+// RoundNumber implements round.Content.
+func (c *RawContent) RoundNumber() round.Number { return c.Number }
+
+// EncodeContent returns the RawContent of content.
+func EncodeContent(content round.Content) (*RawContent, error) {
+	data, err := cbor.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("transport: failed to encode content: %w", err)
+	}
+	return &RawContent{Number: content.RoundNumber(), Data: data}, nil
+}
+
+// Deliver decodes the content of msg, as returned by Transport.Recv, for r and stores the message in r.
+// msg must belong to the round r.
+func Deliver(r round.Session, msg *round.Message) error {
+	raw, ok := msg.Content.(*RawContent)
+	if !ok {
+		return round.ErrInvalidContent
+	}
+	if raw.Number != r.Number() {
+		return fmt.Errorf("transport: message for round %d delivered to round %d", raw.Number, r.Number())
+	}
+
+	m := *msg
+	if m.Broadcast {
+		b, ok := r.(round.BroadcastRound)
+		if !ok {
+			return errors.New("transport: broadcast message but not broadcast round")
+		}
+		content := b.BroadcastContent()
+		if err := cbor.Unmarshal(raw.Data, content); err != nil {
+			return fmt.Errorf("transport: failed to decode content: %w", err)
+		}
+		m.Content = content
+		return b.StoreBroadcastMessage(m)
+	}
+
+	content := r.MessageContent()
+	if err := cbor.Unmarshal(raw.Data, content); err != nil {
+		return fmt.Errorf("transport: failed to decode content: %w", err)
+	}
+	m.Content = content
+	if err := r.VerifyMessage(m); err != nil {
+		return err
+	}
+	return r.StoreMessage(m)
+}
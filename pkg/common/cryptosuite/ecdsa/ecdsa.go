@@ -13,6 +13,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 )
 
 type ECDSAKey interface {
@@ -38,7 +39,7 @@ type ECDSAKey interface {
 
 	Mul(c curve.Scalar) curve.Scalar
 
-	AddKeys(keys ...ECDSAKey) curve.Scalar
+	AddKeys(keys ...ECDSAKey) (curve.Scalar, error)
 
 	CloneByMultiplier(c curve.Scalar) ECDSAKey
 
@@ -99,4 +100,8 @@ type ECDSAKeyManager interface {
 
 	// GetKey returns a ECDSA key by its SKI.
 	GetKey(opts keyopts.Options) (ECDSAKey, error)
+
+	// ValidateAll decodes all keys stored under the MPC KeyID in opts and checks
+	// that each private key matches its public key.
+	ValidateAll(opts keyopts.Options) ([]keystore.ValidationError, error)
 }
@@ -1,6 +1,7 @@
 package elgamal
 
 import (
+	"github.com/mr-shifu/mpc-lib/core/elgamal"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 )
@@ -20,6 +21,9 @@ type ElgamalKey interface {
 
 	PublicKeyRaw() curve.Point
 
+	// PrivateKeyRaw returns the Elgamal secret scalar, or nil if the key is not private.
+	PrivateKeyRaw() curve.Scalar
+
 	// Encrypt returns the encryption of `message` as ciphertext and nonce.
 	Encrypt(message curve.Scalar) ([]byte, curve.Scalar, error)
 }
@@ -34,6 +38,10 @@ type ElgamalKeyManager interface {
 	// GetKey returns a Elgamal key by its SKI.
 	GetKey(pts keyopts.Options) (ElgamalKey, error)
 
-	// Encrypt returns the encryption of `message` as ciphertext and nonce.
-	Encrypt(message curve.Scalar, opts keyopts.Options) ([]byte, curve.Scalar, error)
+	// Encrypt returns the encryption of the point `message` under the public key in opts.
+	Encrypt(opts keyopts.Options, message curve.Point) (*elgamal.Ciphertext, error)
+
+	// Decrypt returns the point encrypted by `ct` with the secret key in opts, or an error if only
+	// the public key is stored.
+	Decrypt(opts keyopts.Options, ct *elgamal.Ciphertext) (curve.Point, error)
 }
@@ -14,6 +14,9 @@ type Hash interface {
 	Clone() Hash
 	Commit(data ...interface{}) (core_hash.Commitment, core_hash.Decommitment, error)
 	Decommit(c core_hash.Commitment, d core_hash.Decommitment, data ...interface{}) bool
+	// Transcript returns the domain and bytes of every value written to the hash, in order, if the
+	// hash records its transcript, and nil otherwise.
+	Transcript() []core_hash.BytesWithDomain
 }
 
 type HashManager interface {
@@ -12,6 +12,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 )
 
 type PaillierKey interface {
@@ -29,6 +30,9 @@ type PaillierKey interface {
 
 	PublicKeyRaw() *pailliercore.PublicKey
 
+	// PrivateKeyRaw returns the Paillier secret key, or nil if the key is not private.
+	PrivateKeyRaw() *pailliercore.SecretKey
+
 	// Modulus returns an arith.Modulus for N.
 	Modulus() *arith.Modulus
 
@@ -41,7 +45,7 @@ type PaillierKey interface {
 	// EncryptWithNonce returns the encryption of `message` as ciphertext and nonce passed to function.
 	EncWithNonce(m *saferith.Int, nonce *saferith.Nat) *pailliercore.Ciphertext
 
-	// Decrypt returns the decryption of `ct` as ciphertext.
+	// Decrypt returns the decryption of `ct` as ciphertext, or an error if the key is not private.
 	Decode(ct *pailliercore.Ciphertext) (*saferith.Int, error)
 
 	// DecryptWithNonce returns the decryption of `ct` as ciphertext and nonce.
@@ -79,11 +83,20 @@ type PaillierKeyManager interface {
 	// ImportKey imports a Paillier key from its byte representation.
 	ImportKey(raw interface{}, opts keyopts.Options) (PaillierKey, error)
 
+	// ImportPublicKey imports the public key of another party, which has no secret part.
+	ImportPublicKey(pk *pailliercore.PublicKey, opts keyopts.Options) (PaillierKey, error)
+
+	// DeleteKey deletes the key of the party in opts only.
+	DeleteKey(opts keyopts.Options) error
+
+	// ListKeys returns the SKIs of the keys stored under the MPC KeyID in opts, indexed by PartyID.
+	ListKeys(opts keyopts.Options) (map[string]string, error)
+
 	// Encrypt returns the encryption of `message` as ciphertext and nonce generated by function.
-	Encode(m *saferith.Int, opts keyopts.Options) (*pailliercore.Ciphertext, *saferith.Nat)
+	Encode(m *saferith.Int, opts keyopts.Options) (*pailliercore.Ciphertext, *saferith.Nat, error)
 
 	// EncryptWithNonce returns the encryption of `message` as ciphertext and nonce passed to function.
-	EncWithNonce(m *saferith.Int, nonce *saferith.Nat, opts keyopts.Options) *pailliercore.Ciphertext
+	EncWithNonce(m *saferith.Int, nonce *saferith.Nat, opts keyopts.Options) (*pailliercore.Ciphertext, error)
 
 	// Decrypt returns the decryption of `ct` as ciphertext.
 	Decode(ct *pailliercore.Ciphertext, opts keyopts.Options) (*saferith.Int, error)
@@ -93,4 +106,8 @@ type PaillierKeyManager interface {
 
 	// ValidateCiphertexts returns true if all ciphertexts are valid.
 	ValidateCiphertexts(opts keyopts.Options, cts ...*pailliercore.Ciphertext) (bool, error)
+
+	// ValidateAll decodes all keys stored under the MPC KeyID in opts and checks that
+	// each modulus is valid and, for private keys, that N is the product of two Blum primes.
+	ValidateAll(opts keyopts.Options) ([]keystore.ValidationError, error)
 }
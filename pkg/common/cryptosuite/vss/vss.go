@@ -3,6 +3,7 @@ package vss
 import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 )
 
@@ -53,4 +54,15 @@ type VssKeyManager interface {
 	EvaluateByExponents(index curve.Scalar, opts keyopts.Options) (curve.Point, error)
 
 	SumExponents(optsList ...keyopts.Options) (VssKey, error)
+
+	// LagrangeBasis returns the Lagrange coefficients at 0 for all parties in partyIDs.
+	LagrangeBasis(partyIDs []party.ID) map[party.ID]curve.Scalar
+
+	// ExportShare returns a versioned and checksummed backup of the VSS share of a party,
+	// holding the secret share and its public commitment.
+	ExportShare(opts keyopts.Options) ([]byte, error)
+
+	// ImportShare restores the VSS share of a party from a backup returned by ExportShare, after
+	// verifying it against the stored exponents. The share also replaces the share of the MPC key.
+	ImportShare(data []byte, opts keyopts.Options) error
 }
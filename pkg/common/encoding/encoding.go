@@ -2,4 +2,17 @@ package encoding
 
 type KeyMarshaler interface {
 	Bytes() ([]byte, error)
-}
\ No newline at end of file
+}
+
+// PointMarshaler is implemented by points of curves with a canonical encoding which cannot fail,
+// e.g. *edwards25519.Point. This allows hashing and committing to points independently of the
+// curve backend. The points of backends other than edwards25519 must also implement PointDomain.
+type PointMarshaler interface {
+	Bytes() []byte
+}
+
+// PointDomain names the curve of a PointMarshaler, to separate the encodings of points of
+// different curves.
+type PointDomain interface {
+	Domain() string
+}
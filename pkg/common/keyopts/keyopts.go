@@ -8,6 +8,9 @@ type KeyData struct {
 type Options interface {
 	Set(kVs ...interface{}) (Options, error)
 	Get(key string) (interface{}, bool)
+	// Namespace returns the MPC KeyID of the options, namespaced by the protocol they are set for,
+	// so that the keys of protocols using the same KeyID are stored apart.
+	Namespace() string
 }
 
 // KeyOpts manages the storage of key metadata referred to by an ID (MPC KeyID).
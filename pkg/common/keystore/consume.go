@@ -0,0 +1,21 @@
+package keystore
+
+import "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+
+// Consume returns the key stored in ks under opts and deletes it, so that a key can only be
+// consumed once: Delete fails for a key which is not stored, and when several callers consume the
+// same key concurrently, only the one whose Delete succeeds gets it. The others get the error of
+// their Delete.
+//
+// The key is deleted before it is returned, so a key for single use, e.g. the nonce of a
+// presignature, is never used twice even if the caller crashes after using it.
+func Consume(ks Keystore, opts keyopts.Options) ([]byte, error) {
+	key, err := ks.Get(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ks.Delete(opts); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -4,12 +4,31 @@ import "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 
 type Keystore interface {
 	Import(keyID string, key []byte, opts keyopts.Options) error
+	// ImportBatch imports the keys of entries indexed by keyID, either all of them or none.
+	// Atomicity only holds within this Keystore, not across the keystores of several key managers.
+	ImportBatch(entries map[string]Entry) error
 	Update(key []byte, opts keyopts.Options) error
 	Get(opts keyopts.Options) ([]byte, error)
+	GetAll(opts keyopts.Options) (map[string][]byte, error)
 	Delete(opts keyopts.Options) error
+	// DeleteAll deletes all keys stored under the MPC KeyID in opts and returns the number of keys deleted.
+	DeleteAll(opts keyopts.Options) (int, error)
 	KeyAccessor(ski string, opts keyopts.Options) KeyAccessor
 }
 
+// Entry is a key imported by Keystore.ImportBatch, with the options it is stored under.
+type Entry struct {
+	Key  []byte
+	Opts keyopts.Options
+}
+
+// KeyManager is implemented by key managers backed by a Keystore.
+type KeyManager interface {
+	// PurgeSession deletes all keys stored under the MPC KeyID and the protocol of opts, e.g.
+	// after the session was aborted, and returns the number of keys deleted.
+	PurgeSession(opts keyopts.Options) (int, error)
+}
+
 type KeyAccessor interface {
 	Import(key []byte) error
 	Get() ([]byte, error)
@@ -0,0 +1,21 @@
+package keystore
+
+import "fmt"
+
+// ValidationError describes a stored key which failed to decode or is internally inconsistent.
+type ValidationError struct {
+	// KeyID is the MPC KeyID the key is stored under.
+	KeyID string
+	// PartyID is the party the key belongs to.
+	PartyID string
+	// Err is the underlying problem found with the key.
+	Err error
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("keystore: key %s of party %s is invalid: %v", e.KeyID, e.PartyID, e.Err)
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -7,6 +7,7 @@ import (
 	comm_commitment "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/commitment"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type CommitmentManager struct {
@@ -82,3 +83,8 @@ func (cm *CommitmentManager) Get(opts keyopts.Options) (comm_commitment.Commitme
 
 	return cmt, nil
 }
+
+// PurgeSession implements keystore.KeyManager.
+func (cm *CommitmentManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, cm.ks)
+}
@@ -36,15 +36,31 @@ func (key ECDSAKey) Mul(c curve.Scalar) curve.Scalar {
 	return key.group.NewScalar().Set(c).Mul(key.priv)
 }
 
-func (key ECDSAKey) AddKeys(keys ...comm_ecdsa.ECDSAKey) curve.Scalar {
+// AddKeys returns the sum of the private key with the private keys of the given keys.
+//
+// Each private key must already be reduced modulo the group order, otherwise
+// ErrScalarNotReduced is returned rather than silently reducing it.
+func (key ECDSAKey) AddKeys(keys ...comm_ecdsa.ECDSAKey) (curve.Scalar, error) {
 	group := key.group
-	sum := group.NewScalar().SetNat(curve.MakeInt(key.priv).Mod(group.Order())) // group.NewScalar().Set(key.priv)
+	if !reduced(group, key.priv) {
+		return nil, ErrScalarNotReduced
+	}
+	sum := group.NewScalar().Set(key.priv)
 	for _, k := range keys {
 		k, ok := k.(ECDSAKey)
-		if !ok {
-			return nil
+		if !ok || k.priv == nil {
+			return nil, ErrInvalidKey
+		}
+		if !reduced(group, k.priv) {
+			return nil, ErrScalarNotReduced
 		}
-		sum = sum.Add(group.NewScalar().SetNat(curve.MakeInt(k.priv).Mod(group.Order())))
+		sum = sum.Add(k.priv)
 	}
-	return group.NewScalar().SetNat(curve.MakeInt(sum).Mod(group.Order()))
+	return sum, nil
+}
+
+// reduced returns true if the integer encoded by s is in [0, q), where q is the group order.
+func reduced(group curve.Curve, s curve.Scalar) bool {
+	n := curve.MakeInt(s)
+	return n.Eq(curve.MakeInt(group.NewScalar().SetNat(n.Mod(group.Order())))) == 1
 }
@@ -4,8 +4,10 @@ import (
 	"crypto/rand"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
@@ -15,7 +17,7 @@ import (
 )
 
 func newEcdsakeyManager() *ECDSAKeyManager {
-	cfg := &Config{curve.Secp256k1{}}
+	cfg := &Config{Group: curve.Secp256k1{}}
 
 	ec_vault := vault.NewInMemoryVault()
 	ec_kr := keyopts.NewInMemoryKeyOpts()
@@ -106,6 +108,49 @@ func TestImportPublicKey(t *testing.T) {
 	assert.Equal(t, kb, newkb)
 }
 
+func TestKeyBytes_P256(t *testing.T) {
+	group := curve.P256{}
+	sk, pk := sample.ScalarPointPair(rand.Reader, group)
+
+	for _, key := range []ECDSAKey{NewECDSAKey(sk, pk, group), NewECDSAKey(nil, pk, group)} {
+		kb, err := key.Bytes()
+		assert.NoError(t, err)
+
+		decoded, err := fromBytes(kb)
+		assert.NoError(t, err)
+		assert.Equal(t, group.Name(), decoded.Group().Name())
+		assert.Equal(t, key.Private(), decoded.Private())
+		assert.True(t, pk.Equal(decoded.PublicKeyRaw()))
+		if key.Private() {
+			assert.True(t, sk.Equal(decoded.priv))
+		}
+
+		newkb, err := decoded.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, kb, newkb)
+	}
+
+	// keys persisted under the other name of the curve are also recognized
+	pub, err := pk.MarshalBinary()
+	assert.NoError(t, err)
+	kb, err := cbor.Marshal(&rawECDSAKey{Group: "p256", Pub: pub})
+	assert.NoError(t, err)
+	decoded, err := fromBytes(kb)
+	assert.NoError(t, err)
+	assert.True(t, pk.Equal(decoded.PublicKeyRaw()))
+}
+
+func TestKeyBytes_UnknownGroup(t *testing.T) {
+	_, pk := sample.ScalarPointPair(rand.Reader, curve.Secp256k1{})
+	pub, err := pk.MarshalBinary()
+	assert.NoError(t, err)
+
+	kb, err := cbor.Marshal(&rawECDSAKey{Group: "ed448", Pub: pub})
+	assert.NoError(t, err)
+	_, err = fromBytes(kb)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
 func TestSchnorr(t *testing.T) {
 	mgr1 := newEcdsakeyManager()
 	mgr2 := newEcdsakeyManager()
@@ -182,3 +227,119 @@ func TestImportVSS(t *testing.T) {
 	// assert.NoError(t, err)
 	// assert.False(t, key2.Private())
 }
+
+func TestValidateAll(t *testing.T) {
+	mgr := newEcdsakeyManager()
+
+	for _, partyID := range []string{"a", "b", "c"} {
+		opts := keyopts.Options{}
+		opts.Set("id", "123", "partyid", partyID)
+		_, err := mgr.GenerateKey(opts)
+		assert.NoError(t, err)
+	}
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123")
+
+	// Healthy store must not report any problems
+	invalid, err := mgr.ValidateAll(opts)
+	assert.NoError(t, err)
+	assert.Empty(t, invalid)
+
+	// Corrupt the key of party "d" by storing a mismatching public key
+	sk := sample.Scalar(rand.Reader, curve.Secp256k1{})
+	_, pk := sample.ScalarPointPair(rand.Reader, curve.Secp256k1{})
+	kb, err := NewECDSAKey(sk, pk, curve.Secp256k1{}).Bytes()
+	assert.NoError(t, err)
+	corruptOpts := keyopts.Options{}
+	corruptOpts.Set("id", "123", "partyid", "d")
+	assert.NoError(t, mgr.keystore.Import("corrupted", kb, corruptOpts))
+
+	// Store undecodable garbage for party "e"
+	garbageOpts := keyopts.Options{}
+	garbageOpts.Set("id", "123", "partyid", "e")
+	assert.NoError(t, mgr.keystore.Import("garbage", []byte("garbage"), garbageOpts))
+
+	invalid, err = mgr.ValidateAll(opts)
+	assert.NoError(t, err)
+	assert.Len(t, invalid, 2)
+	parties := []string{invalid[0].PartyID, invalid[1].PartyID}
+	assert.ElementsMatch(t, []string{"d", "e"}, parties)
+
+	// Unknown key ID must error
+	unknown := keyopts.Options{}
+	unknown.Set("id", "unknown")
+	_, err = mgr.ValidateAll(unknown)
+	assert.Error(t, err)
+}
+
+func TestPurgeSession(t *testing.T) {
+	mgr := newEcdsakeyManager()
+
+	parties := []string{"1", "2", "3"}
+	for _, p := range parties {
+		opts := keyopts.Options{}
+		opts.Set("id", "session", "partyid", p)
+		_, err := mgr.GenerateKey(opts)
+		assert.NoError(t, err)
+	}
+	otherOpts := keyopts.Options{}
+	otherOpts.Set("id", "other", "partyid", "1")
+	otherKey, err := mgr.GenerateKey(otherOpts)
+	assert.NoError(t, err)
+
+	// Must remove exactly the keys of the session
+	sessionOpts := keyopts.Options{}
+	sessionOpts.Set("id", "session")
+	n, err := mgr.PurgeSession(sessionOpts)
+	assert.NoError(t, err)
+	assert.Equal(t, len(parties), n)
+	for _, p := range parties {
+		opts := keyopts.Options{}
+		opts.Set("id", "session", "partyid", p)
+		_, err := mgr.GetKey(opts)
+		assert.Error(t, err)
+	}
+
+	// keys of other sessions must be kept
+	key, err := mgr.GetKey(otherOpts)
+	assert.NoError(t, err)
+	assert.Equal(t, otherKey.SKI(), key.SKI())
+
+	// purging again must not remove anything
+	n, err = mgr.PurgeSession(sessionOpts)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+// unreducedScalar encodes its value as s + q, where q is the group order.
+type unreducedScalar struct {
+	curve.Scalar
+}
+
+func (s unreducedScalar) MarshalBinary() ([]byte, error) {
+	n := curve.MakeInt(s.Scalar).Abs()
+	return n.Add(n, s.Curve().Order().Nat(), -1).Bytes(), nil
+}
+
+func TestAddKeys(t *testing.T) {
+	group := curve.Secp256k1{}
+	keys := make([]comm_ecdsa.ECDSAKey, 0, 3)
+	sum := group.NewScalar()
+	for i := 0; i < 3; i++ {
+		priv := sample.Scalar(rand.Reader, group)
+		sum.Add(priv)
+		keys = append(keys, NewECDSAKey(priv, priv.ActOnBase(), group))
+	}
+
+	total, err := keys[0].AddKeys(keys[1:]...)
+	assert.NoError(t, err)
+	assert.True(t, total.Equal(sum))
+
+	priv := sample.Scalar(rand.Reader, group)
+	unreduced := NewECDSAKey(unreducedScalar{priv}, priv.ActOnBase(), group)
+	_, err = keys[0].AddKeys(keys[1], unreduced)
+	assert.ErrorIs(t, err, ErrScalarNotReduced)
+	_, err = unreduced.AddKeys(keys...)
+	assert.ErrorIs(t, err, ErrScalarNotReduced)
+}
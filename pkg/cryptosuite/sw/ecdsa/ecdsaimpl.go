@@ -12,7 +12,8 @@ import (
 )
 
 var (
-	ErrInvalidKey = errors.New("invalid key")
+	ErrInvalidKey       = errors.New("invalid key")
+	ErrScalarNotReduced = errors.New("scalar is not reduced modulo the group order")
 )
 
 type ECDSAKey struct {
@@ -113,6 +114,10 @@ func fromBytes(data []byte) (ECDSAKey, error) {
 	switch raw.Group {
 	case "secp256k1":
 		group = curve.Secp256k1{}
+	case "p256", "secp256r1":
+		group = curve.P256{}
+	default:
+		return ECDSAKey{}, ErrInvalidKey
 	}
 	key.group = group
 
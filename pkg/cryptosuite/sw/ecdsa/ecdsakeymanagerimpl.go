@@ -3,6 +3,8 @@ package ecdsa
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"io"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
@@ -11,10 +13,13 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	zksch "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/zk-schnorr"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type Config struct {
 	Group curve.Curve
+	// Rand is the source of randomness for generated keys. Defaults to crypto/rand.Reader.
+	Rand io.Reader
 }
 
 type ECDSAKeyManager struct {
@@ -43,7 +48,11 @@ func (mgr *ECDSAKeyManager) NewKey(priv curve.Scalar, pub curve.Point, group cur
 
 func (mgr *ECDSAKeyManager) GenerateKey(opts keyopts.Options) (comm_ecdsa.ECDSAKey, error) {
 	// Generate a new ECDSA key pair
-	sk, pk := sample.ScalarPointPair(rand.Reader, mgr.cfg.Group)
+	source := mgr.cfg.Rand
+	if source == nil {
+		source = rand.Reader
+	}
+	sk, pk := sample.ScalarPointPair(source, mgr.cfg.Group)
 
 	// serialize key to store to the keystore
 	key := NewECDSAKey(sk, pk, mgr.cfg.Group)
@@ -67,6 +76,16 @@ func (mgr *ECDSAKeyManager) GenerateKey(opts keyopts.Options) (comm_ecdsa.ECDSAK
 		withVSSKeyMgr(mgr.vssmgr), nil
 }
 
+// ParseKey decodes an ECDSA key encoded by Bytes, without importing it, so that a key received
+// from another party can be checked before anything is stored.
+func ParseKey(data []byte) (comm_ecdsa.ECDSAKey, error) {
+	key, err := fromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
 func (mgr *ECDSAKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (comm_ecdsa.ECDSAKey, error) {
 	var err error
 	var key ECDSAKey
@@ -122,4 +141,39 @@ func (mgr *ECDSAKeyManager) GetKey(opts keyopts.Options) (comm_ecdsa.ECDSAKey, e
 	return k.
 		withZKSchnorr(zksch.NewZKSchnorr(mgr.schnorrstore.KeyAccessor(keyID, opts))).
 		withVSSKeyMgr(mgr.vssmgr), nil
-}
\ No newline at end of file
+}
+
+// ValidateAll decodes all keys stored under the MPC KeyID in opts and checks that each
+// private key matches its public key. Problems with individual keys are collected and
+// returned, while an error is only returned if the keys could not be retrieved at all.
+func (mgr *ECDSAKeyManager) ValidateAll(opts keyopts.Options) ([]keystore.ValidationError, error) {
+	keys, err := mgr.keystore.GetAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := opts.Get("id")
+	keyID, _ := id.(string)
+
+	var invalid []keystore.ValidationError
+	for partyID, kb := range keys {
+		key, err := fromBytes(kb)
+		if err != nil {
+			invalid = append(invalid, keystore.ValidationError{KeyID: keyID, PartyID: partyID, Err: err})
+			continue
+		}
+		if key.priv != nil && !key.priv.ActOnBase().Equal(key.pub) {
+			invalid = append(invalid, keystore.ValidationError{
+				KeyID:   keyID,
+				PartyID: partyID,
+				Err:     errors.New("public key does not match private key"),
+			})
+		}
+	}
+	return invalid, nil
+}
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *ECDSAKeyManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.keystore, mgr.schnorrstore)
+}
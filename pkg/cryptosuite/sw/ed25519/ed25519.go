@@ -48,6 +48,12 @@ type Ed25519KeyManager interface {
 	// GetKey returns a Ed25519 key by its SKI.
 	GetKey(opts keyopts.Options) (Ed25519, error)
 
+	// DeleteKey deletes the key of the party in opts, together with its Schnorr proof.
+	DeleteKey(opts keyopts.Options) error
+
+	// ListKeys returns the SKIs of the keys stored under the MPC KeyID in opts, indexed by PartyID.
+	ListKeys(opts keyopts.Options) (map[string]string, error)
+
 	SumKeys(optsList ...keyopts.Options) (Ed25519, error) 
 
 	NewSchnorrProof(h hash.Hash, opts keyopts.Options) (*Proof, error)
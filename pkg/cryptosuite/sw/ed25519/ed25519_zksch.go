@@ -105,6 +105,18 @@ func (p *Proof) fromBytes(data []byte) error {
 	}
 }
 
+// ParseSchnorrProof decodes a Schnorr proof, rejecting proofs with an identity commitment or a zero response.
+func ParseSchnorrProof(pb []byte) (*Proof, error) {
+	p := new(Proof)
+	if err := p.fromBytes(pb); err != nil {
+		return nil, errors.WithMessage(err, "ed25519: failed to import schnorr proof")
+	}
+	if p.cmt.C.Equal(ed.NewIdentityPoint()) == 1 || p.rsp.Z.Equal(ed.NewScalar()) == 1 {
+		return nil, errors.New("ed25519: invalid schnorr proof")
+	}
+	return p, nil
+}
+
 func (k *Ed25519Impl) NewScnorrProof(h hash.Hash) (*Proof, error) {
 	return newSchnorrProof(h, k.s, k.a)
 }
@@ -167,15 +179,34 @@ func newSchnorrProof(h hash.Hash, private *ed.Scalar, public *ed.Point) (*Proof,
 }
 
 func verifySchnorrProof(h hash.Hash, proof *Proof, public *ed.Point) (bool, error) {
-	challenge, err := newSchnorrChallenge(h.Clone(), proof.Commitment().C, public)
+	if proof == nil || proof.cmt == nil || proof.rsp == nil {
+		return false, errors.New("ed25519_zksch: invalid proof")
+	}
+	return VerifySchnorrProofStandalone(proof.Response(), proof.Commitment(), public, h)
+}
+
+// VerifySchnorrProofStandalone verifies the Schnorr proof with response proof and commitment that
+// the prover knows the secret key of public, for the transcript h, which is not modified.
+//
+// Unlike the VerifySchnorrProof methods, it does not need a key or a key manager, so a proof
+// received from another party can be verified before anything is imported.
+func VerifySchnorrProofStandalone(proof *Response, commitment *Commitment, public *ed.Point, h hash.Hash) (bool, error) {
+	if proof == nil || proof.Z == nil || commitment == nil || commitment.C == nil {
+		return false, errors.New("ed25519_zksch: invalid proof")
+	}
+	if public == nil {
+		return false, errors.New("ed25519_zksch: nil public key")
+	}
+
+	challenge, err := newSchnorrChallenge(h.Clone(), commitment.C, public)
 	if err != nil {
 		return false, errors.WithMessage(err, "ed25519_zksch: failed to create challenge")
 	}
 
-	lhs := (&ed.Point{}).ScalarBaseMult(proof.Response().Z)
+	lhs := (&ed.Point{}).ScalarBaseMult(proof.Z)
 
 	rhs := (&ed.Point{}).ScalarMult(challenge, public)
-	rhs = rhs.Add(rhs, proof.cmt.C)
+	rhs = rhs.Add(rhs, commitment.C)
 
 	return lhs.Equal(rhs) == 1, nil
 }
@@ -62,3 +62,36 @@ func TestSerializeDeserializeProof(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, v2)
 }
+
+func TestVerifySchnorrProofStandalone(t *testing.T) {
+	hash_mgr := hash.NewHashManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()))
+	opts := keyopts.Options{}
+	opts.Set("id", "1", "partyid", "a")
+	h := hash_mgr.NewHasher("test", opts)
+
+	k, err := GenerateKey()
+	assert.NoError(t, err)
+	proof, err := k.NewScnorrProof(h.Clone())
+	assert.NoError(t, err)
+
+	v, err := VerifySchnorrProofStandalone(proof.Response(), proof.Commitment(), k.PublickeyPoint(), h)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	// the proof of another key, or with another commitment, is rejected
+	other, err := GenerateKey()
+	assert.NoError(t, err)
+	v, err = VerifySchnorrProofStandalone(proof.Response(), proof.Commitment(), other.PublickeyPoint(), h)
+	assert.NoError(t, err)
+	assert.False(t, v)
+	v, err = VerifySchnorrProofStandalone(proof.Response(), &Commitment{C: other.PublickeyPoint()}, k.PublickeyPoint(), h)
+	assert.NoError(t, err)
+	assert.False(t, v)
+
+	_, err = VerifySchnorrProofStandalone(nil, proof.Commitment(), k.PublickeyPoint(), h)
+	assert.Error(t, err)
+	_, err = VerifySchnorrProofStandalone(proof.Response(), nil, k.PublickeyPoint(), h)
+	assert.Error(t, err)
+	_, err = VerifySchnorrProofStandalone(proof.Response(), proof.Commitment(), nil, h)
+	assert.Error(t, err)
+}
@@ -19,6 +19,37 @@ const (
 	SeedSize = 32
 )
 
+// ErrSmallOrderPoint is returned for points which are not in the prime-order subgroup.
+var ErrSmallOrderPoint = errors.New("ed25519: point is not in the prime-order subgroup")
+
+// lMinusOne is the scalar ℓ-1 in little-endian encoding, where ℓ = 2²⁵² + 27742317777372353535851937790883648493
+// is the order of the prime-order subgroup.
+var lMinusOne, _ = ed.NewScalar().SetCanonicalBytes([]byte{
+	0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+})
+
+// ValidatePoint returns an error if p is nil, the identity, or has a non-trivial torsion component,
+// i.e. if p is not a generator of the prime-order subgroup.
+//
+// A point P = Q + T, with Q in the prime-order subgroup and T a torsion point, satisfies
+// [ℓ-1]P + P = [ℓ]T, which is the identity only if T is.
+func ValidatePoint(p *ed.Point) error {
+	if p == nil {
+		return errors.New("ed25519: nil point")
+	}
+	identity := ed.NewIdentityPoint()
+	if p.Equal(identity) == 1 {
+		return ErrSmallOrderPoint
+	}
+	lp := new(ed.Point).ScalarMult(lMinusOne, p)
+	lp.Add(lp, p)
+	if lp.Equal(identity) != 1 {
+		return ErrSmallOrderPoint
+	}
+	return nil
+}
+
 // Ed25519Impl contains Ed25519 Private and Public Key and implements the Ed25519 interface.
 type Ed25519Impl struct {
 	s *ed.Scalar
@@ -30,6 +61,8 @@ func NewKey(priv *ed.Scalar, pub *ed.Point) (Ed25519, error) {
 		if pub.Equal((&ed.Point{}).ScalarBaseMult(priv)) != 1 {
 			return nil, errors.New("ed25519: public key does not match private key")
 		}
+	} else if err := ValidatePoint(pub); err != nil {
+		return nil, err
 	}
 	return &Ed25519Impl{
 		s: priv,
@@ -104,12 +137,18 @@ func FromPublicKey(pub any) (Ed25519, error) {
 		if _, err := A.SetBytes(st); err != nil {
 			return nil, errors.WithMessage(err, "ed25519: internal error: setting point failed")
 		}
+		if err := ValidatePoint(A); err != nil {
+			return nil, err
+		}
 
 		return &Ed25519Impl{
 			s: nil,
 			a: A,
 		}, nil
 	case *ed.Point:
+		if err := ValidatePoint(st); err != nil {
+			return nil, err
+		}
 		return &Ed25519Impl{
 			s: nil,
 			a: st,
@@ -243,6 +282,9 @@ func (k *Ed25519Impl) FromBytes(data []byte) error {
 		if _, err := A.SetBytes(data); err != nil {
 			return errors.WithMessage(err, "ed25519: internal error: setting point failed")
 		}
+		if err := ValidatePoint(A); err != nil {
+			return err
+		}
 		k.a = A
 
 		return nil
@@ -111,3 +111,51 @@ func TestSerDe(t *testing.T) {
 	assert.Equal(t, 1, new(ed.Point).ScalarBaseMult(x1Key.s).Equal(y))
 	assert.Equal(t, 1, x1Key.a.Equal(y))
 }
+
+func TestValidatePoint(t *testing.T) {
+	// lMinusOne must decode to ℓ-1, so that adding one wraps around to zero
+	ob := make([]byte, 32)
+	ob[0] = 1
+	one, err := ed.NewScalar().SetCanonicalBytes(ob)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ed.NewScalar().Add(lMinusOne, one).Equal(ed.NewScalar()))
+
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+	assert.NoError(t, ValidatePoint(key.PublickeyPoint()))
+
+	// (0, -1) is a point of order 2
+	order2 := make([]byte, 32)
+	order2[0] = 0xec
+	for i := 1; i < 31; i++ {
+		order2[i] = 0xff
+	}
+	order2[31] = 0x7f
+	T, err := new(ed.Point).SetBytes(order2)
+	assert.NoError(t, err)
+
+	smallOrder := [][]byte{
+		ed.NewIdentityPoint().Bytes(),
+		order2,
+	}
+	for _, b := range smallOrder {
+		P, err := new(ed.Point).SetBytes(b)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, ValidatePoint(P), ErrSmallOrderPoint)
+
+		_, err = FromPublicKey(b)
+		assert.Error(t, err)
+
+		_, err = NewKey(nil, P)
+		assert.Error(t, err)
+
+		k := new(Ed25519Impl)
+		assert.Error(t, k.FromBytes(b))
+	}
+
+	// a valid point with a torsion component added must be rejected
+	mixed := new(ed.Point).Add(key.PublickeyPoint(), T)
+	assert.ErrorIs(t, ValidatePoint(mixed), ErrSmallOrderPoint)
+	_, err = NewKey(nil, mixed)
+	assert.Error(t, err)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	vssed25519 "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss-ed25519"
+	sw_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/pkg/errors"
 )
 
@@ -93,6 +95,42 @@ func (mgr *Ed25519KeyManagerImpl) GetKey(opts keyopts.Options) (Ed25519, error)
 	return k, nil
 }
 
+// DeleteKey deletes the key of the party in opts and its Schnorr proof, if any. The keys of the
+// other parties of the same MPC KeyID are kept.
+func (mgr *Ed25519KeyManagerImpl) DeleteKey(opts keyopts.Options) error {
+	if err := mgr.keystore.Delete(opts); err != nil {
+		return errors.WithMessage(err, "ed25519: failed to delete key from keystore")
+	}
+	if _, err := mgr.schstore.Get(opts); err == nil {
+		if err := mgr.schstore.Delete(opts); err != nil {
+			return errors.WithMessage(err, "ed25519: failed to delete schnorr proof from keystore")
+		}
+	}
+	return nil
+}
+
+// ListKeys returns the hex encoded SKIs of the keys stored under the MPC KeyID in opts, indexed
+// by PartyID. A session without keys has an empty list.
+func (mgr *Ed25519KeyManagerImpl) ListKeys(opts keyopts.Options) (map[string]string, error) {
+	keys, err := mgr.keystore.GetAll(opts)
+	if errors.Is(err, sw_keyopts.ErrKeyNotFound) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "ed25519: failed to get keys from keystore")
+	}
+
+	skis := make(map[string]string, len(keys))
+	for partyID, kb := range keys {
+		k := new(Ed25519Impl)
+		if err := k.FromBytes(kb); err != nil {
+			return nil, errors.WithMessagef(err, "ed25519: failed to import key of party %s", partyID)
+		}
+		skis[partyID] = hex.EncodeToString(k.SKI())
+	}
+	return skis, nil
+}
+
 func (mgr *Ed25519KeyManagerImpl) SumKeys(optsList ...keyopts.Options) (Ed25519, error) {
 	s := ed.NewScalar()
 	a := new(ed.Point)
@@ -144,12 +182,8 @@ func (mgr *Ed25519KeyManagerImpl) NewSchnorrProof(h hash.Hash, opts keyopts.Opti
 }
 
 func (mgr *Ed25519KeyManagerImpl) ImportSchnorrProof(pb []byte, opts keyopts.Options) error {
-	p := new(Proof)
-	if err := p.fromBytes(pb); err != nil {
-		return errors.WithMessage(err, "ed25519: failed to import schnorr proof")
-	}
-	if p.cmt.C.Equal(ed.NewIdentityPoint()) == 1 || p.rsp.Z.Equal(ed.NewScalar()) == 1 {
-		return errors.New("ed25519: invalid schnorr proof")
+	if _, err := ParseSchnorrProof(pb); err != nil {
+		return err
 	}
 
 	k, err := mgr.GetKey(opts)
@@ -243,3 +277,8 @@ func (mgr *Ed25519KeyManagerImpl) GetVss(opts keyopts.Options) (vssed25519.VssKe
 
 	return vss, nil
 }
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *Ed25519KeyManagerImpl) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.keystore, mgr.schstore)
+}
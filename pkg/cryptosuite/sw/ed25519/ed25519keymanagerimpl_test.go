@@ -1,6 +1,7 @@
 package ed25519
 
 import (
+	"encoding/hex"
 	"testing"
 
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
@@ -152,3 +153,49 @@ func TestEd25519KeyManager_SchnorrProof(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, v)
 }
+
+func TestEd25519KeyManagerImpl_DeleteAndListKeys(t *testing.T) {
+	mgr := getKeyManager()
+
+	session := keyopts.Options{}
+	session.Set("id", "1")
+	skis, err := mgr.ListKeys(session)
+	assert.NoError(t, err)
+	assert.Empty(t, skis)
+
+	optsA := keyopts.Options{}
+	optsA.Set("id", "1", "partyid", "a")
+	kA, err := mgr.GenerateKey(optsA)
+	assert.NoError(t, err)
+	optsB := keyopts.Options{}
+	optsB.Set("id", "1", "partyid", "b")
+	kB, err := mgr.GenerateKey(optsB)
+	assert.NoError(t, err)
+
+	opts := keyopts.Options{}
+	opts.Set("id", "1", "partyid", "a")
+	h := hash.NewHashManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())).NewHasher("test", opts)
+	_, err = mgr.NewSchnorrProof(h, optsA)
+	assert.NoError(t, err)
+
+	skis, err = mgr.ListKeys(session)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"a": hex.EncodeToString(kA.SKI()),
+		"b": hex.EncodeToString(kB.SKI()),
+	}, skis)
+
+	assert.NoError(t, mgr.DeleteKey(optsA))
+	_, err = mgr.GetKey(optsA)
+	assert.Error(t, err)
+	_, err = mgr.schstore.Get(optsA)
+	assert.Error(t, err, "the schnorr proof should be deleted with the key")
+
+	// the key of the other party of the session is kept
+	k, err := mgr.GetKey(optsB)
+	assert.NoError(t, err)
+	assert.Equal(t, kB.SKI(), k.SKI())
+	skis, err = mgr.ListKeys(session)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": hex.EncodeToString(kB.SKI())}, skis)
+}
@@ -24,7 +24,7 @@ func TestElgamal(t *testing.T) {
 
 	// generate a new ElGamal key pair
 	opts := keyopts.Options{}
-	opts.Set("ID", 123, "partyID", 1)
+	opts.Set("id", "123", "partyid", "a")
 	key, err := mgr.GenerateKey(opts)
 	assert.NoError(t, err)
 	keyBytes, err := key.Bytes()
@@ -45,15 +45,61 @@ func TestElgamal(t *testing.T) {
 	assert.Equal(t, keyBytes, newKeyBytes)
 
 	// Encrypt a random message with the public key
-	msg := sample.Scalar(rand.Reader, curve.Secp256k1{})
-	ct, nonce, err := mgr.Encrypt(msg, opts)
+	msg := sample.Scalar(rand.Reader, curve.Secp256k1{}).ActOnBase()
+	ciphertext, err := mgr.Encrypt(opts, msg)
 	assert.NoError(t, err)
-	assert.NotNil(t, ct)
-	assert.NotNil(t, nonce)
+	assert.True(t, ciphertext.Valid())
 
-	// validate ciphertext
-	ciphertext := elgamal.NewCiphertext(curve.Secp256k1{})
-	ciphertext.UnmarshalBinary(ct)
-	v := ciphertext.Valid()
-	assert.True(t, v)
+	// decrypt it with the secret key
+	decrypted, err := mgr.Decrypt(opts, ciphertext)
+	assert.NoError(t, err)
+	assert.True(t, msg.Equal(decrypted))
+}
+
+func TestElgamalDecrypt(t *testing.T) {
+	group := curve.Secp256k1{}
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewElgamalKeyManager(ks, &Config{Group: group})
+
+	optsA := keyopts.Options{}
+	optsA.Set("id", "123", "partyid", "a")
+	optsB := keyopts.Options{}
+	optsB.Set("id", "123", "partyid", "b")
+	keyA, err := mgr.GenerateKey(optsA)
+	assert.NoError(t, err)
+	_, err = mgr.GenerateKey(optsB)
+	assert.NoError(t, err)
+
+	msg := sample.Scalar(rand.Reader, group).ActOnBase()
+	ct, err := mgr.Encrypt(optsA, msg)
+	assert.NoError(t, err)
+
+	// the ciphertext round trips through its binary encoding
+	data, err := ct.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := elgamal.NewCiphertext(group)
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	decrypted, err := mgr.Decrypt(optsA, decoded)
+	assert.NoError(t, err)
+	assert.True(t, msg.Equal(decrypted))
+
+	// the decryption with another key does not return the message
+	decrypted, err = mgr.Decrypt(optsB, ct)
+	assert.NoError(t, err)
+	assert.False(t, msg.Equal(decrypted))
+
+	// the public key imported by another party encrypts, but does not decrypt
+	other := NewElgamalKeyManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()), &Config{Group: group})
+	_, err = other.ImportKey(keyA.PublicKey(), optsA)
+	assert.NoError(t, err)
+	ct, err = other.Encrypt(optsA, msg)
+	assert.NoError(t, err)
+	_, err = other.Decrypt(optsA, ct)
+	assert.ErrorIs(t, err, ErrNotPrivate)
+	decrypted, err = mgr.Decrypt(optsA, ct)
+	assert.NoError(t, err)
+	assert.True(t, msg.Equal(decrypted))
+
+	_, err = mgr.Decrypt(optsA, elgamal.NewCiphertext(group))
+	assert.Error(t, err)
 }
@@ -13,6 +13,8 @@ import (
 
 var (
 	ErrInvalidKey = errors.New("invalid key")
+	// ErrNotPrivate is returned when decrypting with a key holding only the public point.
+	ErrNotPrivate = errors.New("elgamal: key is not private")
 )
 
 type ElgamalKey struct {
@@ -70,6 +72,10 @@ func (key ElgamalKey) PublicKeyRaw() curve.Point {
 	return key.publicKey
 }
 
+func (key ElgamalKey) PrivateKeyRaw() curve.Scalar {
+	return key.secretKey
+}
+
 func (key ElgamalKey) Encrypt(message curve.Scalar) ([]byte, curve.Scalar, error) {
 	ct, nonce := elgamal.Encrypt(key.publicKey, message)
 
@@ -93,6 +99,8 @@ func fromBytes(data []byte) (ElgamalKey, error) {
 	switch raw.Group {
 	case "secp256k1":
 		group = curve.Secp256k1{}
+	case "p256", "secp256r1":
+		group = curve.P256{}
 	}
 	key.group = group
 
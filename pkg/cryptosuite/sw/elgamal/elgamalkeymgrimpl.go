@@ -5,11 +5,13 @@ import (
 	"encoding/hex"
 	"errors"
 
+	"github.com/mr-shifu/mpc-lib/core/elgamal"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	cs_elgamal "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/elgamal"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type Config struct {
@@ -52,6 +54,16 @@ func (mgr *ElgamalKeyManager) GenerateKey(opts keyopts.Options) (cs_elgamal.Elga
 	return key, nil
 }
 
+// ParseKey decodes an ElGamal key encoded by Bytes, without importing it, so that a key received
+// from another party can be checked before anything is stored.
+func ParseKey(data []byte) (cs_elgamal.ElgamalKey, error) {
+	key, err := fromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
 func (mgr *ElgamalKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (cs_elgamal.ElgamalKey, error) {
 	var err error
 	var key ElgamalKey
@@ -100,13 +112,42 @@ func (mgr *ElgamalKeyManager) GetKey(opts keyopts.Options) (cs_elgamal.ElgamalKe
 	return k, err
 }
 
-func (mgr *ElgamalKeyManager) Encrypt(message curve.Scalar, opts keyopts.Options) ([]byte, curve.Scalar, error) {
+// Encrypt returns the encryption of the point message under the public key stored under opts.
+func (mgr *ElgamalKeyManager) Encrypt(opts keyopts.Options, message curve.Point) (*elgamal.Ciphertext, error) {
+	k, err := mgr.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	if k == (ElgamalKey{}) {
+		return nil, errors.New("key not found")
+	}
+	if message == nil || message.Curve().Name() != k.PublicKeyRaw().Curve().Name() {
+		return nil, errors.New("elgamal: message is not a point of the key's group")
+	}
+	ct, _ := elgamal.EncryptPoint(k.PublicKeyRaw(), message)
+	return ct, nil
+}
+
+// Decrypt returns the point encrypted by ct, using the secret key stored under opts. It returns
+// ErrNotPrivate if only the public key is stored.
+func (mgr *ElgamalKeyManager) Decrypt(opts keyopts.Options, ct *elgamal.Ciphertext) (curve.Point, error) {
 	k, err := mgr.GetKey(opts)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if k == (ElgamalKey{}) {
-		return nil, nil, errors.New("key not found")
+		return nil, errors.New("key not found")
+	}
+	if !k.Private() {
+		return nil, ErrNotPrivate
+	}
+	if !ct.Valid() {
+		return nil, errors.New("elgamal: invalid ciphertext")
 	}
-	return k.Encrypt(message)
+	return elgamal.Decrypt(k.PrivateKeyRaw(), ct), nil
+}
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *ElgamalKeyManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.keystore)
 }
@@ -0,0 +1,64 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"fmt"
+	gohash "hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Algorithm is the hash function computing the commitments of Commit and Decommit.
+type Algorithm int
+
+const (
+	// BLAKE3 uses the digest of the BLAKE3 transcript itself as commitment.
+	BLAKE3 Algorithm = iota
+	// SHA256 hashes the transcript with SHA-256.
+	SHA256
+	// SHA3_256 hashes the transcript with SHA3-256.
+	SHA3_256
+)
+
+func (alg Algorithm) String() string {
+	switch alg {
+	case BLAKE3:
+		return "BLAKE3"
+	case SHA256:
+		return "SHA-256"
+	case SHA3_256:
+		return "SHA3-256"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", int(alg))
+	}
+}
+
+func (alg Algorithm) new() (gohash.Hash, error) {
+	switch alg {
+	case SHA256:
+		return sha256.New(), nil
+	case SHA3_256:
+		return sha3.New256(), nil
+	default:
+		return nil, fmt.Errorf("unsupported commitment algorithm %s", alg)
+	}
+}
+
+// commitment returns the digest of the transcript with the commitment algorithm of the hash.
+//
+// Since the state of the hash records everything written to it, the transcript is replayed with
+// the same encoding into the digest of alg, and domain separated by the name of alg.
+func (hash *Hash) commitment() ([]byte, error) {
+	if hash.alg == BLAKE3 {
+		return hash.Sum(), nil
+	}
+	h, err := hash.alg.new()
+	if err != nil {
+		return nil, err
+	}
+	_, _ = h.Write([]byte("CMP-" + hash.alg.String()))
+	for _, d := range hash.state {
+		writeBytesWithDomain(h, d)
+	}
+	return h.Sum(nil), nil
+}
@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"reflect"
 
+	"filippo.io/edwards25519"
 	"github.com/fxamacker/cbor/v2"
 	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/lib/params"
@@ -24,10 +25,18 @@ type Hash struct {
 	h     *blake3.Hasher
 	state []core_hash.BytesWithDomain
 	store keystore.KeyAccessor
+	// alg computes the commitments of Commit and Decommit.
+	alg Algorithm
+	// recording enables Transcript.
+	recording bool
 }
 
 func New(store keystore.KeyAccessor, initialData ...core_hash.WriterToWithDomain) comm_hash.Hash {
-	hash := &Hash{h: blake3.New(), store: store}
+	return newHash(BLAKE3, store, initialData...)
+}
+
+func newHash(alg Algorithm, store keystore.KeyAccessor, initialData ...core_hash.WriterToWithDomain) *Hash {
+	hash := &Hash{h: blake3.New(), store: store, alg: alg}
 	_, _ = hash.h.WriteString("CMP-BLAKE")
 	for _, d := range initialData {
 		_ = hash.WriteAny(d)
@@ -36,7 +45,12 @@ func New(store keystore.KeyAccessor, initialData ...core_hash.WriterToWithDomain
 }
 
 func Restore(store keystore.KeyAccessor) (comm_hash.Hash, error) {
-	hash := &Hash{h: blake3.New(), store: store}
+	return restore(BLAKE3, store)
+}
+
+func restore(alg Algorithm, store keystore.KeyAccessor) (*Hash, error) {
+	hash := &Hash{h: blake3.New(), store: store, alg: alg}
+	_, _ = hash.h.WriteString("CMP-BLAKE")
 
 	ss, err := hash.store.Get()
 	if err != nil {
@@ -47,7 +61,7 @@ func Restore(store keystore.KeyAccessor) (comm_hash.Hash, error) {
 	}
 
 	for _, d := range hash.state {
-		hash.writeBytesWithDomain(d)
+		writeBytesWithDomain(hash.h, d)
 	}
 
 	return hash, nil
@@ -108,6 +122,15 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 				TheDomain: name.String(),
 				Bytes:     bytes,
 			}
+		case cs_encoding.PointMarshaler:
+			domain, err := pointDomain(t)
+			if err != nil {
+				return fmt.Errorf("hash.WriteAny: %w", err)
+			}
+			toBeWritten = core_hash.BytesWithDomain{
+				TheDomain: domain,
+				Bytes:     t.Bytes(),
+			}
 		default:
 			// This should panic or something
 			return fmt.Errorf("hash.WriteAny: invalid type provided as input")
@@ -115,30 +138,47 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 
 		hash.updateState(toBeWritten)
 
-		hash.writeBytesWithDomain(toBeWritten)
+		writeBytesWithDomain(hash.h, toBeWritten)
 	}
 	return nil
 }
 
-func (hash *Hash) writeBytesWithDomain(toBeWritten core_hash.BytesWithDomain) {
+// domainEdwards25519Point separates the encodings of edwards25519 points from those of the
+// points of other curves.
+const domainEdwards25519Point = "edwards25519.Point"
+
+// pointDomain returns the domain of the curve of p, which separates the encodings of points of
+// different curves.
+func pointDomain(p cs_encoding.PointMarshaler) (string, error) {
+	switch p := p.(type) {
+	case *edwards25519.Point:
+		return domainEdwards25519Point, nil
+	case cs_encoding.PointDomain:
+		return p.Domain(), nil
+	default:
+		return "", fmt.Errorf("%T: point of an unknown curve", p)
+	}
+}
+
+func writeBytesWithDomain(w io.Writer, toBeWritten core_hash.BytesWithDomain) {
 	var sizeBuf [8]byte
 
 	// Write out `(<domain_size><domain><data_size><data>)`, so that each domain separated piece of data
 	// is distinguished from others.
 
-	_, _ = hash.h.WriteString("(")
+	_, _ = io.WriteString(w, "(")
 	// <domain_size>
 	binary.BigEndian.PutUint64(sizeBuf[:], uint64(len(toBeWritten.TheDomain)))
-	_, _ = hash.h.Write(sizeBuf[:])
+	_, _ = w.Write(sizeBuf[:])
 	// <domain>
-	_, _ = hash.h.WriteString(toBeWritten.TheDomain)
+	_, _ = io.WriteString(w, toBeWritten.TheDomain)
 	// <data_size>
 	binary.BigEndian.PutUint64(sizeBuf[:], uint64(len(toBeWritten.Bytes)))
-	_, _ = hash.h.Write(sizeBuf[:])
+	_, _ = w.Write(sizeBuf[:])
 	// <data>
-	_, _ = hash.h.Write(toBeWritten.Bytes)
+	_, _ = w.Write(toBeWritten.Bytes)
 	// )
-	_, _ = hash.h.WriteString(")")
+	_, _ = io.WriteString(w, ")")
 }
 
 func (hash *Hash) updateState(toBeWritten core_hash.BytesWithDomain) error {
@@ -155,10 +195,28 @@ func (hash *Hash) updateState(toBeWritten core_hash.BytesWithDomain) error {
 
 func (hash *Hash) Clone() comm_hash.Hash {
 	return &Hash{
-		h:     hash.h.Clone(),
-		state: hash.state,
-		store: nil,
+		h:         hash.h.Clone(),
+		state:     append([]core_hash.BytesWithDomain(nil), hash.state...),
+		store:     nil,
+		alg:       hash.alg,
+		recording: hash.recording,
+	}
+}
+
+// Transcript returns a copy of the values written to the hash, including those of its initial
+// data, if it was created by a HashManager recording transcripts, and nil otherwise.
+func (hash *Hash) Transcript() []core_hash.BytesWithDomain {
+	if !hash.recording {
+		return nil
+	}
+	transcript := make([]core_hash.BytesWithDomain, len(hash.state))
+	for i, d := range hash.state {
+		transcript[i] = core_hash.BytesWithDomain{
+			TheDomain: d.TheDomain,
+			Bytes:     append([]byte(nil), d.Bytes...),
+		}
 	}
+	return transcript
 }
 
 // Commit creates a commitment to data, and returns a commitment hash, and a decommitment string such that
@@ -171,7 +229,7 @@ func (hash *Hash) Commit(data ...interface{}) (core_hash.Commitment, core_hash.D
 		return nil, nil, fmt.Errorf("hash.Commit: failed to generate decommitment: %w", err)
 	}
 
-	h := hash.Clone()
+	h := hash.Clone().(*Hash)
 
 	for _, item := range data {
 		if err = h.WriteAny(item); err != nil {
@@ -181,7 +239,10 @@ func (hash *Hash) Commit(data ...interface{}) (core_hash.Commitment, core_hash.D
 
 	_ = h.WriteAny(decommitment)
 
-	commitment := h.Sum()
+	commitment, err := h.commitment()
+	if err != nil {
+		return nil, nil, fmt.Errorf("hash.Commit: %w", err)
+	}
 
 	return commitment, decommitment, nil
 }
@@ -197,7 +258,7 @@ func (hash *Hash) Decommit(c core_hash.Commitment, d core_hash.Decommitment, dat
 		return false
 	}
 
-	h := hash.Clone()
+	h := hash.Clone().(*Hash)
 
 	for _, item := range data {
 		if err = h.WriteAny(item); err != nil {
@@ -207,7 +268,10 @@ func (hash *Hash) Decommit(c core_hash.Commitment, d core_hash.Decommitment, dat
 
 	_ = h.WriteAny(d)
 
-	computedCommitment := h.Sum()
+	computedCommitment, err := h.commitment()
+	if err != nil {
+		return false
+	}
 
 	return bytes.Equal(computedCommitment, c)
 }
@@ -7,13 +7,19 @@ import (
 	"math/big"
 	"testing"
 
+	"filippo.io/edwards25519"
 	"github.com/cronokirby/saferith"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHash_WriteAny(t *testing.T) {
@@ -123,3 +129,129 @@ func TestHash_Clone(t *testing.T) {
 	hashed = h.Sum()
 	fmt.Printf("hashed: %x\n", hashed)
 }
+
+// otherEdwardsPoint stands in for a point of a second edwards backend with a canonical encoding.
+type otherEdwardsPoint struct {
+	b []byte
+}
+
+func (p *otherEdwardsPoint) Bytes() []byte { return p.b }
+
+func (*otherEdwardsPoint) Domain() string { return "other.Point" }
+
+// unknownPoint is a point which does not name its curve.
+type unknownPoint struct {
+	b []byte
+}
+
+func (p *unknownPoint) Bytes() []byte { return p.b }
+
+func TestHash_CommitPoints(t *testing.T) {
+	v := vault.NewInMemoryVault()
+	kr := keyopts.NewInMemoryKeyOpts()
+	hs := keystore.NewInMemoryKeystore(v, kr)
+	mgr := NewHashManager(hs)
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "1")
+	h := mgr.NewHasher("test", opts)
+
+	seed := make([]byte, 64)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+	s, err := edwards25519.NewScalar().SetUniformBytes(seed)
+	require.NoError(t, err)
+	edPoint := new(edwards25519.Point).ScalarBaseMult(s)
+	other := &otherEdwardsPoint{b: edPoint.Bytes()}
+
+	for _, p := range []interface{}{edPoint, other} {
+		cmt, dcmt, err := h.Clone().Commit(p)
+		require.NoError(t, err)
+		assert.True(t, h.Clone().Decommit(cmt, dcmt, p))
+	}
+
+	// the same encoding of points of different curves must not decommit each other
+	cmt, dcmt, err := h.Clone().Commit(edPoint)
+	require.NoError(t, err)
+	assert.False(t, h.Clone().Decommit(cmt, dcmt, other))
+	assert.False(t, h.Clone().Decommit(cmt, dcmt, edwards25519.NewGeneratorPoint()))
+
+	// a point must name its curve
+	assert.Error(t, h.Clone().WriteAny(&unknownPoint{b: edPoint.Bytes()}))
+}
+
+func TestHash_CommitmentAlgorithm(t *testing.T) {
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "1")
+	hashers := make(map[Algorithm]comm_hash.Hash)
+	for _, alg := range []Algorithm{BLAKE3, SHA256, SHA3_256} {
+		hs := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+		mgr := NewHashManagerWithConfig(hs, &Config{CommitmentAlgorithm: alg})
+		h := mgr.NewHasher("test", opts)
+		require.NoError(t, h.WriteAny([]byte("transcript")))
+		hashers[alg] = h
+
+		cmt, dcmt, err := h.Clone().Commit([]byte("data"))
+		require.NoError(t, err, alg)
+		require.NoError(t, cmt.Validate(), alg)
+		assert.True(t, h.Clone().Decommit(cmt, dcmt, []byte("data")), alg)
+		assert.False(t, h.Clone().Decommit(cmt, dcmt, []byte("other")), alg)
+	}
+	assert.Equal(t, BLAKE3, NewHashManager(nil).cfg.CommitmentAlgorithm)
+
+	// a commitment made with one algorithm must not verify with another
+	for _, pair := range [][2]Algorithm{{SHA256, SHA3_256}, {SHA3_256, SHA256}, {SHA3_256, BLAKE3}, {BLAKE3, SHA256}} {
+		cmt, dcmt, err := hashers[pair[0]].Clone().Commit([]byte("data"))
+		require.NoError(t, err)
+		assert.False(t, hashers[pair[1]].Clone().Decommit(cmt, dcmt, []byte("data")), "%s verified as %s", pair[0], pair[1])
+	}
+}
+
+func TestHash_Transcript(t *testing.T) {
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "1")
+	hs := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewHashManagerWithConfig(hs, &Config{RecordTranscript: true})
+
+	rid, err := types.NewRID(rand.Reader)
+	require.NoError(t, err)
+	selfID := party.ID("a")
+
+	// the temporary hash of keygen round3
+	msg := types.SigningMessage("session")
+	h := mgr.NewHasher("test", opts, msg).Clone()
+	require.NoError(t, h.WriteAny(rid, selfID))
+	assert.Equal(t, []core_hash.BytesWithDomain{
+		{TheDomain: msg.Domain(), Bytes: []byte("session")},
+		{TheDomain: rid.Domain(), Bytes: rid},
+		{TheDomain: selfID.Domain(), Bytes: []byte("a")},
+	}, h.Transcript())
+
+	// the transcript is a copy
+	h.Transcript()[1].Bytes[0] ^= 1
+	assert.Equal(t, []byte(rid), h.Transcript()[1].Bytes)
+
+	// transcripts are only recorded if the manager is configured to
+	h = NewHashManager(hs).NewHasher("other", opts)
+	require.NoError(t, h.WriteAny(rid, selfID))
+	assert.Nil(t, h.Transcript())
+}
+
+func TestHash_Restore(t *testing.T) {
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "1")
+	hs := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewHashManager(hs)
+
+	h := mgr.NewHasher("test", opts)
+	require.NoError(t, h.WriteAny([]byte("123")))
+
+	// a restored hash continues from the same state as the hash it was stored by
+	restored, err := mgr.RestoreHasher("test", opts)
+	require.NoError(t, err)
+	assert.Equal(t, h.Sum(), restored.Sum())
+
+	require.NoError(t, h.WriteAny([]byte("456")))
+	require.NoError(t, restored.WriteAny([]byte("456")))
+	assert.Equal(t, h.Sum(), restored.Sum())
+}
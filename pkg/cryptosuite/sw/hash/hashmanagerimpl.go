@@ -5,20 +5,52 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
+type Config struct {
+	// CommitmentAlgorithm is the hash function computing commitments. Defaults to BLAKE3.
+	CommitmentAlgorithm Algorithm
+	// RecordTranscript makes the hashers return their transcript from Transcript, so that the
+	// transcripts of two parties can be diffed when they disagree. It is meant for debugging, since
+	// the transcript may contain secret values.
+	RecordTranscript bool
+}
+
 type HashManager struct {
 	store keystore.Keystore
+	cfg   *Config
 }
 
 func NewHashManager(store keystore.Keystore) *HashManager {
-	return &HashManager{store: store}
+	return NewHashManagerWithConfig(store, &Config{})
+}
+
+// NewHashManagerWithConfig returns a HashManager whose hashers commit with cfg.CommitmentAlgorithm.
+// The parties of a protocol must all use the same algorithm.
+func NewHashManagerWithConfig(store keystore.Keystore, cfg *Config) *HashManager {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	return &HashManager{store: store, cfg: cfg}
 }
 
 func (h *HashManager) NewHasher(keyID string, opts keyopts.Options, data ...core_hash.WriterToWithDomain) hash.Hash {
-	return New(h.store.KeyAccessor(keyID, opts), data...)
+	hash := newHash(h.cfg.CommitmentAlgorithm, h.store.KeyAccessor(keyID, opts), data...)
+	hash.recording = h.cfg.RecordTranscript
+	return hash
 }
 
 func (h *HashManager) RestoreHasher(keyID string, opts keyopts.Options) (hash.Hash, error) {
-	return Restore(h.store.KeyAccessor(keyID, opts))
+	hash, err := restore(h.cfg.CommitmentAlgorithm, h.store.KeyAccessor(keyID, opts))
+	if err != nil {
+		return nil, err
+	}
+	hash.recording = h.cfg.RecordTranscript
+	return hash, nil
+}
+
+// PurgeSession implements keystore.KeyManager.
+func (h *HashManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, h.store)
 }
@@ -6,6 +6,7 @@ import (
 	comm_mta "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/mta"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type MtAManager struct {
@@ -66,3 +67,8 @@ func (m *MtAManager) SetBeta(beta *saferith.Int, opts keyopts.Options) error {
 	}
 	return m.store.Update(mb, opts)
 }
+
+// PurgeSession implements keystore.KeyManager.
+func (m *MtAManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, m.store)
+}
@@ -1,13 +1,18 @@
 package paillier
 
 import (
+	"context"
 	"crypto/rand"
+	"encoding/hex"
 	"testing"
+	"time"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	pailliercore "github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/zk"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
@@ -25,7 +30,7 @@ func TestPaillier(t *testing.T) {
 
 	// generate a new Paillier key pair
 	opts := keyopts.Options{}
-	opts.Set("ID", 123, "partyID", 1)
+	opts.Set("id", "123", "partyid", "a")
 	key, err := mgr.GenerateKey(opts)
 	assert.NoError(t, err)
 
@@ -37,7 +42,8 @@ func TestPaillier(t *testing.T) {
 	// encode
 	sc := sample.Scalar(rand.Reader, curve.Secp256k1{})
 	msg := curve.MakeInt(sc)
-	ct, _ := mgr.Encode(msg, opts)
+	ct, _, err := mgr.Encode(msg, opts)
+	assert.NoError(t, err)
 
 	m, err := mgr.Decode(ct, opts)
 	assert.NoError(t, err)
@@ -47,7 +53,8 @@ func TestPaillier(t *testing.T) {
 
 	// encode with nonce
 	nonce := sample.UnitModN(rand.Reader, key.ParamN())
-	ctn := mgr.EncWithNonce(msg, nonce, opts)
+	ctn, err := mgr.EncWithNonce(msg, nonce, opts)
+	assert.NoError(t, err)
 
 	m, _, err = mgr.DecodeWithNonce(ctn, opts)
 	assert.NoError(t, err)
@@ -59,3 +66,236 @@ func TestPaillier(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, v)
 }
+
+func TestPaillierValidateAll(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ks_vault := vault.NewInMemoryVault()
+	ks_kr := keyopts.NewInMemoryKeyOpts()
+	ks := keystore.NewInMemoryKeystore(ks_vault, ks_kr)
+
+	mgr := NewPaillierKeyManager(ks, pl)
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+	key, err := mgr.GenerateKey(opts)
+	assert.NoError(t, err)
+
+	all := keyopts.Options{}
+	all.Set("id", "123")
+
+	// Healthy store must not report any problems
+	invalid, err := mgr.ValidateAll(all)
+	assert.NoError(t, err)
+	assert.Empty(t, invalid)
+
+	// Corrupt party "b" by pairing the secret primes of a fresh key with another modulus
+	_, sk := pailliercore.KeyGen(pl)
+	corrupted := NewPaillierKey(sk, key.PublicKeyRaw())
+	kb, err := corrupted.Bytes()
+	assert.NoError(t, err)
+	corruptOpts := keyopts.Options{}
+	corruptOpts.Set("id", "123", "partyid", "b")
+	assert.NoError(t, ks.Import("corrupted", kb, corruptOpts))
+
+	invalid, err = mgr.ValidateAll(all)
+	assert.NoError(t, err)
+	assert.Len(t, invalid, 1)
+	assert.Equal(t, "b", invalid[0].PartyID)
+	assert.Equal(t, "123", invalid[0].KeyID)
+}
+
+func TestPaillierGetKeyErrors(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ks_vault := vault.NewInMemoryVault()
+	ks_kr := keyopts.NewInMemoryKeyOpts()
+	ks := keystore.NewInMemoryKeystore(ks_vault, ks_kr)
+
+	mgr := NewPaillierKeyManager(ks, pl)
+
+	// missing SKI
+	missing := keyopts.Options{}
+	missing.Set("id", "123", "partyid", "a")
+	_, err := mgr.GetKey(missing)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	msg := new(saferith.Int).SetUint64(1)
+	_, _, err = mgr.Encode(msg, missing)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = mgr.EncWithNonce(msg, new(saferith.Nat).SetUint64(1), missing)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = mgr.Decode(nil, missing)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, _, err = mgr.DecodeWithNonce(nil, missing)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// corrupted blob
+	corrupted := keyopts.Options{}
+	corrupted.Set("id", "123", "partyid", "b")
+	assert.NoError(t, ks.Import("corrupted", []byte{0xff, 0x00, 0x01}, corrupted))
+	_, err = mgr.GetKey(corrupted)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrKeyNotFound)
+	_, _, err = mgr.Encode(msg, corrupted)
+	assert.Error(t, err)
+}
+
+func TestPaillierKeyManagerPool(t *testing.T) {
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	assert.Same(t, pl, NewPaillierKeyManager(ks, pl).pl)
+
+	// without a pool, keys are generated on the calling goroutine
+	assert.NotNil(t, NewPaillierKeyManager(ks, nil).pl)
+}
+
+func TestPaillierGenerateKeyWithContext(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewPaillierKeyManager(ks, pl)
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := mgr.GenerateKeyWithContext(ctx, opts)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = mgr.GenerateKeyWithContext(ctx, opts)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second, "generation should abort promptly")
+
+	// nothing is stored for an aborted generation
+	_, err = mgr.GetKey(opts)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestPaillierDeleteAndListKeys(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewPaillierKeyManager(ks, pl)
+
+	session := keyopts.Options{}
+	session.Set("id", "123")
+	skis, err := mgr.ListKeys(session)
+	assert.NoError(t, err)
+	assert.Empty(t, skis)
+
+	optsA := keyopts.Options{}
+	optsA.Set("id", "123", "partyid", "a")
+	keyA, err := mgr.GenerateKey(optsA)
+	assert.NoError(t, err)
+	optsB := keyopts.Options{}
+	optsB.Set("id", "123", "partyid", "b")
+	keyB, err := mgr.GenerateKey(optsB)
+	assert.NoError(t, err)
+
+	skis, err = mgr.ListKeys(session)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"a": hex.EncodeToString(keyA.SKI()),
+		"b": hex.EncodeToString(keyB.SKI()),
+	}, skis)
+
+	assert.NoError(t, mgr.DeleteKey(optsA))
+	_, err = mgr.GetKey(optsA)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.ErrorIs(t, mgr.DeleteKey(optsA), ErrKeyNotFound)
+
+	// the key of the other party of the session is kept
+	key, err := mgr.GetKey(optsB)
+	assert.NoError(t, err)
+	assert.Equal(t, keyB.SKI(), key.SKI())
+	skis, err = mgr.ListKeys(session)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": hex.EncodeToString(keyB.SKI())}, skis)
+}
+
+func TestPaillierImportKeyValidation(t *testing.T) {
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewPaillierKeyManager(ks, nil)
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+
+	// a modulus shorter than 2048 bits is rejected before anything is stored
+	short := new(saferith.Nat).Mul(zk.ProverPaillierSecret.P(), new(saferith.Nat).SetUint64(3), -1)
+	shortKey := NewPaillierKey(nil, pailliercore.NewPublicKey(saferith.ModulusFromNat(short)))
+	_, err := mgr.ImportKey(shortKey, opts)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	assert.ErrorIs(t, err, pailliercore.ErrPaillierLength)
+	kb, err := shortKey.Bytes()
+	assert.NoError(t, err)
+	_, err = mgr.ImportKey(kb, opts)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = mgr.GetKey(opts)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// the secret primes of a private key must match its modulus
+	mismatched := NewPaillierKey(zk.VerifierPaillierSecret, zk.ProverPaillierPublic)
+	_, err = mgr.ImportKey(mismatched, opts)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	// valid private and public-only keys
+	key, err := mgr.ImportKey(NewPaillierKey(zk.ProverPaillierSecret, zk.ProverPaillierPublic), opts)
+	assert.NoError(t, err)
+	assert.True(t, key.Private())
+	publicOpts := keyopts.Options{}
+	publicOpts.Set("id", "123", "partyid", "b")
+	kb, err = NewPaillierKey(nil, zk.VerifierPaillierPublic).Bytes()
+	assert.NoError(t, err)
+	key, err = mgr.ImportKey(kb, publicOpts)
+	assert.NoError(t, err)
+	assert.False(t, key.Private())
+}
+
+func TestPaillierImportPublicKey(t *testing.T) {
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewPaillierKeyManager(ks, nil)
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "b")
+	key, err := mgr.ImportPublicKey(zk.VerifierPaillierPublic, opts)
+	assert.NoError(t, err)
+	assert.False(t, key.Private())
+	assert.Nil(t, key.PrivateKeyRaw())
+
+	// the public key round trips through the keystore
+	stored, err := mgr.GetKey(opts)
+	assert.NoError(t, err)
+	assert.False(t, stored.Private())
+	assert.Equal(t, key.SKI(), stored.SKI())
+	assert.True(t, stored.PublicKeyRaw().Equal(zk.VerifierPaillierPublic))
+
+	// it encrypts, but does not decrypt
+	msg := new(saferith.Int).SetUint64(42)
+	ct, _, err := mgr.Encode(msg, opts)
+	assert.NoError(t, err)
+	_, err = mgr.Decode(ct, opts)
+	assert.ErrorIs(t, err, ErrNotPrivate)
+	_, _, err = mgr.DecodeWithNonce(ct, opts)
+	assert.ErrorIs(t, err, ErrNotPrivate)
+	_, err = stored.DerivePedersenKey()
+	assert.ErrorIs(t, err, ErrNotPrivate)
+
+	// the decryption succeeds with the private key
+	m, err := NewPaillierKey(zk.VerifierPaillierSecret, zk.VerifierPaillierPublic).Decode(ct)
+	assert.NoError(t, err)
+	assert.Equal(t, saferith.Choice(1), m.Eq(msg))
+
+	_, err = mgr.ImportPublicKey(nil, opts)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -20,10 +20,10 @@ func TestZKMod(t *testing.T) {
 	mgr := hash.NewHashManager(hs)
 
 	opts1 := keyopts.Options{}
-	opts1.Set("ID", 123, "partyID", 1)
+	opts1.Set("id", "123", "partyid", "1")
 
 	opts2 := keyopts.Options{}
-	opts2.Set("ID", 123, "partyID", 2)
+	opts2.Set("id", "123", "partyid", "2")
 
 	h1 := mgr.NewHasher("key1", opts1)
 	h2 := mgr.NewHasher("key2", opts2)
@@ -27,7 +27,7 @@ type rawPaillierKey struct {
 }
 
 func NewPaillierKey(sk *pailliercore.SecretKey, pk *pailliercore.PublicKey) PaillierKey {
-	return PaillierKey{sk, pk}
+	return PaillierKey{secretKey: sk, publicKey: pk}
 }
 
 // Bytes returns the binary encoded of N param of public key secret key params (P, Q) if exists.
@@ -72,13 +72,17 @@ func (k PaillierKey) Private() bool {
 
 // PublicKey returns the public key part of the key.
 func (k PaillierKey) PublicKey() cs_paillier.PaillierKey {
-	return PaillierKey{nil, k.publicKey}
+	return PaillierKey{publicKey: k.publicKey}
 }
 
 func (k PaillierKey) PublicKeyRaw() *pailliercore.PublicKey {
 	return k.publicKey
 }
 
+func (k PaillierKey) PrivateKeyRaw() *pailliercore.SecretKey {
+	return k.secretKey
+}
+
 // Modulus returns the modulus of the key.
 func (k PaillierKey) Modulus() *arith.Modulus {
 	return k.publicKey.Modulus()
@@ -99,13 +103,20 @@ func (k PaillierKey) EncWithNonce(m *saferith.Int, nonce *saferith.Nat) *paillie
 	return k.publicKey.EncWithNonce(m, nonce)
 }
 
-// Decrypt returns the decryption of `ct` as ciphertext.
+// Decrypt returns the decryption of `ct` as ciphertext, or ErrNotPrivate for a public key.
 func (k PaillierKey) Decode(ct *pailliercore.Ciphertext) (*saferith.Int, error) {
+	if !k.Private() {
+		return nil, ErrNotPrivate
+	}
 	return k.secretKey.Dec(ct)
 }
 
-// DecryptWithNonce returns the decryption of `ct` as ciphertext and nonce.
+// DecryptWithNonce returns the decryption of `ct` as ciphertext and nonce, or ErrNotPrivate for a
+// public key.
 func (k PaillierKey) DecodeWithNonce(ct *pailliercore.Ciphertext) (*saferith.Int, *saferith.Nat, error) {
+	if !k.Private() {
+		return nil, nil, ErrNotPrivate
+	}
 	return k.secretKey.DecWithRandomness(ct)
 }
 
@@ -116,6 +127,9 @@ func (k PaillierKey) ValidateCiphertexts(cts ...*pailliercore.Ciphertext) bool {
 
 // Derive Pedersen Key from Paillier Key prime factors
 func (k PaillierKey) DerivePedersenKey() (cs_pedersen.PedersenKey, error) {
+	if !k.Private() {
+		return nil, ErrNotPrivate
+	}
 	pk, sk := k.secretKey.GeneratePedersen()
 	return pedersen.NewPedersenKey(sk, pk), nil
 }
@@ -1,34 +1,64 @@
 package paillier
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"github.com/cronokirby/saferith"
 	comm_paillier "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
 
 	pailliercore "github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 )
 
+// ErrKeyNotFound is returned by GetKey when the keystore has no key for the given options.
+var ErrKeyNotFound = errors.New("paillier: key not found")
+
+// ErrInvalidKey is returned by ParseKey and ImportKey when the key fails validation. It wraps the
+// error of the failed check, e.g. pailliercore.ErrPaillierLength.
+var ErrInvalidKey = errors.New("paillier: invalid key")
+
+// ErrNotPrivate is returned when decrypting with a key holding only the public modulus N.
+var ErrNotPrivate = errors.New("paillier: key is not private")
+
 type PaillierKeyManager struct {
 	pl       *pool.Pool
 	keystore keystore.Keystore
 }
 
+// NewPaillierKeyManager returns a manager generating keys with the workers of pl, or on the calling
+// goroutine if pl is nil.
 func NewPaillierKeyManager(store keystore.Keystore, pl *pool.Pool) *PaillierKeyManager {
+	if pl == nil {
+		pl = pool.NewSerialPool()
+	}
 	return &PaillierKeyManager{
+		pl:       pl,
 		keystore: store,
 	}
 }
 
 // GenerateKey generates a new Paillier key pair.
 func (mgr *PaillierKeyManager) GenerateKey(opts keyopts.Options) (comm_paillier.PaillierKey, error) {
+	return mgr.GenerateKeyWithContext(context.Background(), opts)
+}
+
+// GenerateKeyWithContext generates a new Paillier key pair, and returns the error of ctx if it is
+// done before suitable primes are found.
+func (mgr *PaillierKeyManager) GenerateKeyWithContext(ctx context.Context, opts keyopts.Options) (comm_paillier.PaillierKey, error) {
 	// generate a new Paillier key pair
-	pk, sk := pailliercore.KeyGen(mgr.pl)
-	key := PaillierKey{sk, pk}
+	pk, sk, err := pailliercore.KeyGenWithContext(ctx, mgr.pl)
+	if err != nil {
+		return PaillierKey{}, err
+	}
+	key := NewPaillierKey(sk, pk)
 
 	// get binary encoded of secret key params (P, Q)
 	encoded, err := key.Bytes()
@@ -53,36 +83,103 @@ func (mgr *PaillierKeyManager) GetKey(opts keyopts.Options) (comm_paillier.Paill
 	// get the key from the keystore
 	// keyID := hex.EncodeToString(ski)
 	decoded, err := mgr.keystore.Get(opts)
+	if isNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+	}
 	if err != nil {
-		return PaillierKey{}, nil
+		return nil, err
+	}
+	if len(decoded) == 0 {
+		return nil, ErrKeyNotFound
 	}
 
 	// decode the key from the keystore
 	key, err := fromBytes(decoded)
 	if err != nil {
-		return PaillierKey{}, nil
+		return nil, fmt.Errorf("paillier: failed to decode key: %w", err)
+	}
+
+	return key, nil
+}
+
+// DeleteKey deletes the key of the party in opts, leaving the keys of the other parties of the
+// same MPC KeyID untouched.
+func (mgr *PaillierKeyManager) DeleteKey(opts keyopts.Options) error {
+	err := mgr.keystore.Delete(opts)
+	if isNotFound(err) {
+		return fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+	}
+	return err
+}
+
+// ListKeys returns the hex encoded SKIs of the keys stored under the MPC KeyID in opts, indexed by
+// PartyID.
+func (mgr *PaillierKeyManager) ListKeys(opts keyopts.Options) (map[string]string, error) {
+	keys, err := mgr.keystore.GetAll(opts)
+	if isNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	skis := make(map[string]string, len(keys))
+	for partyID, kb := range keys {
+		key, err := fromBytes(kb)
+		if err != nil {
+			return nil, fmt.Errorf("paillier: failed to decode key of party %s: %w", partyID, err)
+		}
+		skis[partyID] = hex.EncodeToString(key.SKI())
 	}
+	return skis, nil
+}
 
+// isNotFound returns true if err reports a key missing from the keystore.
+func isNotFound(err error) bool {
+	return errors.Is(err, sw_keystore.ErrKeyNotFound) || errors.Is(err, sw_keyopts.ErrKeyNotFound) || errors.Is(err, vault.ErrKeyNotFound)
+}
+
+// ParseKey decodes and validates a Paillier key encoded by Bytes, without importing it, so that
+// a key received from another party can be checked before anything is stored.
+func ParseKey(data []byte) (comm_paillier.PaillierKey, error) {
+	key, err := parseKey(data)
+	if err != nil {
+		return nil, err
+	}
 	return key, nil
 }
 
-// ImportKey imports a Paillier key from its byte representation.
+func parseKey(data []byte) (PaillierKey, error) {
+	key, err := fromBytes(data)
+	if err != nil {
+		return PaillierKey{}, err
+	}
+	if err := validate(key); err != nil {
+		return PaillierKey{}, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	return key, nil
+}
+
+// ImportKey validates a Paillier key, given as its byte representation or as a PaillierKey, and
+// stores it. An error wrapping ErrInvalidKey is returned if the modulus N or, for a private key,
+// the secret primes are invalid.
 func (mgr *PaillierKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (comm_paillier.PaillierKey, error) {
 	var err error
 	var key PaillierKey
 
 	switch raw := raw.(type) {
 	case []byte:
-		key, err = fromBytes(raw)
+		key, err = parseKey(raw)
 		if err != nil {
-			return PaillierKey{}, err
+			return nil, err
 		}
 	case PaillierKey:
 		key = raw
-	}
-
-	if err := pailliercore.ValidateN(key.ParamN()); err != nil {
-		return nil, errors.New("invalid Paillier key")
+		if err := validate(key); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+		}
+	default:
+		return nil, fmt.Errorf("paillier: cannot import key of type %T", raw)
 	}
 
 	// encode the key into binary
@@ -103,24 +200,34 @@ func (mgr *PaillierKeyManager) ImportKey(raw interface{}, opts keyopts.Options)
 	return key, nil
 }
 
+// ImportPublicKey validates and stores the public key pk of another party. The stored key is not
+// private, so it can be used to encrypt and to verify proofs, but not to decrypt.
+func (mgr *PaillierKeyManager) ImportPublicKey(pk *pailliercore.PublicKey, opts keyopts.Options) (comm_paillier.PaillierKey, error) {
+	if pk == nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, pailliercore.ErrPaillierNil)
+	}
+	return mgr.ImportKey(NewPaillierKey(nil, pk), opts)
+}
+
 // Encrypt returns the encryption of `message` as ciphertext and nonce generated by function.
-func (mgr *PaillierKeyManager) Encode(m *saferith.Int, opts keyopts.Options) (*pailliercore.Ciphertext, *saferith.Nat) {
+func (mgr *PaillierKeyManager) Encode(m *saferith.Int, opts keyopts.Options) (*pailliercore.Ciphertext, *saferith.Nat, error) {
 	key, err := mgr.GetKey(opts)
 	if err != nil {
-		return nil, nil
+		return nil, nil, err
 	}
 
-	return key.Encode(m)
+	ct, nonce := key.Encode(m)
+	return ct, nonce, nil
 }
 
 // EncryptWithNonce returns the encryption of `message` as ciphertext and nonce passed to function.
-func (mgr *PaillierKeyManager) EncWithNonce(m *saferith.Int, nonce *saferith.Nat, opts keyopts.Options) *pailliercore.Ciphertext {
+func (mgr *PaillierKeyManager) EncWithNonce(m *saferith.Int, nonce *saferith.Nat, opts keyopts.Options) (*pailliercore.Ciphertext, error) {
 	key, err := mgr.GetKey(opts)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	return key.EncWithNonce(m, nonce)
+	return key.EncWithNonce(m, nonce), nil
 }
 
 // Decrypt returns the decryption of `ct` as ciphertext.
@@ -152,3 +259,61 @@ func (mgr *PaillierKeyManager) ValidateCiphertexts(opts keyopts.Options, cts ...
 
 	return key.ValidateCiphertexts(cts...), nil
 }
+
+// ValidateAll decodes all keys stored under the MPC KeyID in opts and checks that each
+// modulus N is valid. For private keys it additionally checks that the secret primes are
+// Blum primes whose product is N.
+func (mgr *PaillierKeyManager) ValidateAll(opts keyopts.Options) ([]keystore.ValidationError, error) {
+	keys, err := mgr.keystore.GetAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := opts.Get("id")
+	keyID, _ := id.(string)
+
+	var invalid []keystore.ValidationError
+	for partyID, kb := range keys {
+		if err := validateKey(kb); err != nil {
+			invalid = append(invalid, keystore.ValidationError{KeyID: keyID, PartyID: partyID, Err: err})
+		}
+	}
+	return invalid, nil
+}
+
+func validateKey(kb []byte) error {
+	key, err := fromBytes(kb)
+	if err != nil {
+		return err
+	}
+	return validate(key)
+}
+
+// validate checks that the modulus N of key is valid and, for a private key, that the secret
+// primes are Blum primes whose product is N.
+func validate(key PaillierKey) error {
+	if key.publicKey == nil {
+		return pailliercore.ErrPaillierNil
+	}
+	if err := pailliercore.ValidateN(key.ParamN()); err != nil {
+		return err
+	}
+	if !key.Private() {
+		return nil
+	}
+	if err := pailliercore.ValidatePrime(key.secretKey.P()); err != nil {
+		return err
+	}
+	if err := pailliercore.ValidatePrime(key.secretKey.Q()); err != nil {
+		return err
+	}
+	if !key.secretKey.PublicKey.Equal(key.publicKey) {
+		return errors.New("public key does not match secret primes")
+	}
+	return nil
+}
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *PaillierKeyManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.keystore)
+}
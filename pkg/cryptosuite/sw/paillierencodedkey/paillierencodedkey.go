@@ -92,6 +92,8 @@ func fromBytes(data []byte) (PaillierEncodedKey, error) {
 	switch raw.Group {
 	case "secp256k1":
 		group = curve.Secp256k1{}
+	case "p256", "secp256r1":
+		group = curve.P256{}
 	}
 
 	k := &PaillierEncodedKey{
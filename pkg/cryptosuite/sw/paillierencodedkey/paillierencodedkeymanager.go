@@ -5,6 +5,7 @@ import (
 	pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type PaillierEncodedKeyManager struct {
@@ -56,4 +57,9 @@ func (k *PaillierEncodedKeyManager) Import(raw interface{}, opts keyopts.Options
 		return nil, err
 	}
 	return key, nil
-}
\ No newline at end of file
+}
+
+// PurgeSession implements keystore.KeyManager.
+func (k *PaillierEncodedKeyManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, k.store)
+}
@@ -3,12 +3,14 @@ package pedersen
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
 	comm_pedersen "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type PedersenKeyManager struct {
@@ -26,6 +28,30 @@ func (mgr *PedersenKeyManager) GenerateKey(opts keyopts.Options) (comm_pedersen.
 	return nil, errors.New("not implemented")
 }
 
+// ParseKey decodes and validates a Pedersen key encoded by Bytes, without importing it, so that
+// a key received from another party can be checked before anything is stored.
+func ParseKey(data []byte) (comm_pedersen.PedersenKey, error) {
+	key, err := fromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// validate checks that the parameters of key are set and valid.
+func validate(key PedersenKey) error {
+	if key.public == nil || key.public.N() == nil || key.public.S() == nil || key.public.T() == nil {
+		return errors.New("empty parameters in Pedersen key")
+	}
+	if err := pedersen.ValidateParameters(key.public.N(), key.public.S(), key.public.T()); err != nil {
+		return fmt.Errorf("invalid Pedersen key: %w", err)
+	}
+	return nil
+}
+
 // ImportKey imports a Pedersen key.
 func (mgr *PedersenKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (comm_pedersen.PedersenKey, error) {
 	var err error
@@ -41,11 +67,8 @@ func (mgr *PedersenKeyManager) ImportKey(raw interface{}, opts keyopts.Options)
 		key = raw
 	}
 
-	if key.public.N() == nil || key.public.S() == nil || key.public.T() == nil {
-		return nil, errors.New("empty parameters in Pedersen key")
-	}
-	if err := pedersen.ValidateParameters(key.public.N(), key.public.S(), key.public.T()); err != nil {
-		return nil, errors.New("invalid Pedersen key")
+	if err := validate(key); err != nil {
+		return nil, err
 	}
 
 	// encode key to binary
@@ -95,3 +118,8 @@ func (mgr *PedersenKeyManager) Verify(a, b, e *saferith.Int, S, T *saferith.Nat,
 	}
 	return key.Verify(a, b, e, S, T)
 }
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *PedersenKeyManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.ks)
+}
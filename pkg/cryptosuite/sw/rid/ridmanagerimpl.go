@@ -9,6 +9,7 @@ import (
 	cs_rid "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/rid"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type RIDManager struct {
@@ -37,6 +38,15 @@ func (mgr *RIDManager) GenerateKey(opts keyopts.Options) (cs_rid.RID, error) {
 	return rid, nil
 }
 
+// ParseKey validates a RID key from its byte representation, without importing it, so that a key
+// received from another party can be checked before anything is stored.
+func ParseKey(data []byte) (cs_rid.RID, error) {
+	if err := types.RID(data).Validate(); err != nil {
+		return nil, err
+	}
+	return &RID{data}, nil
+}
+
 // Import imports a RID key from its byte representation.
 func (mgr *RIDManager) ImportKey(data []byte, opts keyopts.Options) (cs_rid.RID, error) {
 	// validate data as rid
@@ -88,3 +98,8 @@ func (mgr *RIDManager) Validate(opts keyopts.Options) error {
 	}
 	return rid.Validate()
 }
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *RIDManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.ks)
+}
@@ -0,0 +1,86 @@
+package vssed25519
+
+import (
+	"strings"
+	"sync"
+
+	ed "filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// maxBasisEntries bounds the number of party sets for which a cache tracks requests, and
+// the number of bases it stores.
+const maxBasisEntries = 64
+
+// basisCache stores precomputed Lagrange bases keyed by the sorted party set.
+type basisCache struct {
+	threshold int
+
+	mtx    sync.Mutex
+	hits   map[string]int
+	tables map[string]map[party.ID]*ed.Scalar
+}
+
+func newBasisCache(threshold int) *basisCache {
+	return &basisCache{
+		threshold: threshold,
+		hits:      make(map[string]int),
+		tables:    make(map[string]map[party.ID]*ed.Scalar),
+	}
+}
+
+// get returns the Lagrange basis for partyIDs, computing and caching it once the
+// party set has been seen threshold times.
+func (c *basisCache) get(partyIDs []party.ID) (map[party.ID]*ed.Scalar, error) {
+	if c == nil || c.threshold <= 0 {
+		return polynomial.Lagrange(partyIDs)
+	}
+
+	ids := party.NewIDSlice(partyIDs)
+	key := basisKey(ids)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	table, ok := c.tables[key]
+	if !ok {
+		if _, seen := c.hits[key]; !seen && len(c.hits) >= maxBasisEntries {
+			c.hits = make(map[string]int)
+		}
+		c.hits[key]++
+		if c.hits[key] < c.threshold {
+			return polynomial.Lagrange(ids)
+		}
+		if len(c.tables) >= maxBasisEntries {
+			for k := range c.tables {
+				delete(c.tables, k)
+				break
+			}
+		}
+		var err error
+		if table, err = polynomial.Lagrange(ids); err != nil {
+			return nil, err
+		}
+		c.tables[key] = table
+		delete(c.hits, key)
+	}
+
+	return copyBasis(table), nil
+}
+
+func basisKey(ids party.IDSlice) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, string(id))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func copyBasis(table map[party.ID]*ed.Scalar) map[party.ID]*ed.Scalar {
+	out := make(map[party.ID]*ed.Scalar, len(table))
+	for id, l := range table {
+		out[id] = ed.NewScalar().Set(l)
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package vssed25519
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVssEd25519VssKeyManager_LagrangeBasis(t *testing.T) {
+	uncached := geVsstKeyManager()
+	cached := geVsstKeyManager().WithBasisCache(2)
+
+	partyIDs := test.PartyIDs(5)
+	expected, err := polynomial.Lagrange(partyIDs)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		a, err := uncached.LagrangeBasis(partyIDs)
+		require.NoError(t, err)
+		b, err := cached.LagrangeBasis(partyIDs)
+		require.NoError(t, err)
+		for _, id := range partyIDs {
+			assert.Equal(t, 1, expected[id].Equal(a[id]))
+			assert.Equal(t, 1, expected[id].Equal(b[id]))
+		}
+	}
+	assert.Len(t, cached.basis.tables, 1)
+
+	// mutating a returned basis must not affect the cached table
+	b, err := cached.LagrangeBasis(partyIDs)
+	require.NoError(t, err)
+	b[partyIDs[0]].Add(b[partyIDs[0]], b[partyIDs[1]])
+	b, err = cached.LagrangeBasis(partyIDs)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expected[partyIDs[0]].Equal(b[partyIDs[0]]))
+}
@@ -3,6 +3,7 @@ package vssed25519
 import (
 	ed "filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 )
 
@@ -49,4 +50,11 @@ type VssKeyManager interface {
 	EvaluateByExponents(index *ed.Scalar, opts keyopts.Options) (*ed.Point, error)
 
 	SumExponents(optsList ...keyopts.Options) (VssKey, error)
+
+	// GroupPublicKey returns the group public key Y = ∑ⱼ Fⱼ(0) of the parties' polynomials
+	// imported under the MPC KeyID in opts.
+	GroupPublicKey(opts keyopts.Options) (*ed.Point, error)
+
+	// LagrangeBasis returns the Lagrange coefficients at 0 for all parties in partyIDs.
+	LagrangeBasis(partyIDs []party.ID) (map[party.ID]*ed.Scalar, error)
 }
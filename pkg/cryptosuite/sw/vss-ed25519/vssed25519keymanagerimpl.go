@@ -5,21 +5,39 @@ import (
 
 	ed "filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/pkg/errors"
 )
 
 type VssKeyManagerImpl struct {
-	ks keystore.Keystore
+	ks    keystore.Keystore
+	basis *basisCache
 }
 
 func NewVssKeyManager(ks keystore.Keystore) *VssKeyManagerImpl {
 	return &VssKeyManagerImpl{
-		ks: ks,
+		ks:    ks,
+		basis: newBasisCache(0),
 	}
 }
 
+// WithBasisCache enables caching of the Lagrange evaluation basis. A basis for a given
+// party set is precomputed and cached once it has been requested threshold times;
+// a threshold <= 0 disables caching.
+func (mgr *VssKeyManagerImpl) WithBasisCache(threshold int) *VssKeyManagerImpl {
+	mgr.basis = newBasisCache(threshold)
+	return mgr
+}
+
+// LagrangeBasis returns the Lagrange coefficients at 0 for all parties in partyIDs.
+// The returned map is always a fresh copy which callers may modify.
+func (mgr *VssKeyManagerImpl) LagrangeBasis(partyIDs []party.ID) (map[party.ID]*ed.Scalar, error) {
+	return mgr.basis.get(partyIDs)
+}
+
 // GenerateSecrets generates a Polynomail of a specified degree with secret as constant value
 // and stores coefficients and expponents of coefficients.
 func (mgr *VssKeyManagerImpl) GenerateSecrets(secret *ed.Scalar, degree int, opts keyopts.Options) (VssKey, error) {
@@ -160,3 +178,36 @@ func (mgr *VssKeyManagerImpl) SumExponents(optsList ...keyopts.Options) (VssKey,
 
 	return NewVssKey(sum), nil
 }
+
+// GroupPublicKey returns the group public key Y = ∑ⱼ Fⱼ(0) of the parties' polynomials imported
+// under the MPC KeyID in opts. The ROOT polynomial, which is already their sum, is left out.
+func (mgr *VssKeyManagerImpl) GroupPublicKey(opts keyopts.Options) (*ed.Point, error) {
+	all, err := mgr.ks.GetAll(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "vss: failed to get keys")
+	}
+
+	pub := ed.NewIdentityPoint()
+	found := false
+	for partyID, vb := range all {
+		if partyID == "ROOT" {
+			continue
+		}
+		k := new(VssKeyImpl)
+		if err := k.FromBytes(vb); err != nil {
+			return nil, errors.WithMessage(err, "vss: failed to unmarshal key")
+		}
+		pub.Add(pub, k.poly.Constant())
+		found = true
+	}
+	if !found {
+		return nil, errors.New("vss: no polynomial found")
+	}
+
+	return pub, nil
+}
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *VssKeyManagerImpl) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.ks)
+}
@@ -3,12 +3,15 @@ package vssed25519
 import (
 	"testing"
 
+	ed "filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func geVsstKeyManager() *VssKeyManagerImpl {
@@ -136,3 +139,61 @@ func TestVssEd25519VssKeyManager_SumExponents(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, vss.Constant().Equal(sum_exp.Constant()))
 }
+
+func TestVssEd25519VssKeyManager_GroupPublicKey(t *testing.T) {
+	mgr := geVsstKeyManager()
+
+	degree := 1
+	partyIDs := []party.ID{"a", "b", "c"}
+
+	// generate the polynomial of every party in the same session
+	var polys []*polynomial.Polynomial
+	for _, id := range partyIDs {
+		s, err := sample.Ed25519Scalar(nil)
+		require.NoError(t, err)
+		opts := keyopts.Options{}
+		opts.Set("id", "1", "partyid", string(id))
+		vss, err := mgr.GenerateSecrets(s, degree, opts)
+		require.NoError(t, err)
+		polys = append(polys, vss.(*VssKeyImpl).poly)
+	}
+
+	// the ROOT polynomial is the sum and must not be counted twice
+	sum, err := new(polynomial.Polynomial).Sum(polys)
+	require.NoError(t, err)
+	rootOpts := keyopts.Options{}
+	rootOpts.Set("id", "1", "partyid", "ROOT")
+	_, err = mgr.ImportSecrets(NewVssKey(sum), rootOpts)
+	require.NoError(t, err)
+
+	sessionOpts := keyopts.Options{}
+	sessionOpts.Set("id", "1")
+	pub, err := mgr.GroupPublicKey(sessionOpts)
+	require.NoError(t, err)
+
+	// reconstruct the group secret from the shares xⱼ = ∑ᵢ fᵢ(j) of a threshold of parties
+	signers := partyIDs[:degree+1]
+	lagrange, err := polynomial.Lagrange(signers)
+	require.NoError(t, err)
+	secret := ed.NewScalar()
+	for _, j := range signers {
+		x, err := j.Ed25519Scalar()
+		require.NoError(t, err)
+		share := ed.NewScalar()
+		for _, i := range partyIDs {
+			opts := keyopts.Options{}
+			opts.Set("id", "1", "partyid", string(i))
+			fi, err := mgr.Evaluate(x, opts)
+			require.NoError(t, err)
+			share.Add(share, fi)
+		}
+		secret.MultiplyAdd(lagrange[j], share, secret)
+	}
+	assert.Equal(t, 1, pub.Equal(new(ed.Point).ScalarBaseMult(secret)))
+
+	// a session without polynomials has no group public key
+	otherOpts := keyopts.Options{}
+	otherOpts.Set("id", "2")
+	_, err = mgr.GroupPublicKey(otherOpts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package vss
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// maxBasisEntries bounds the number of party sets for which a cache tracks requests, and
+// the number of bases it stores.
+const maxBasisEntries = 64
+
+// basisCache stores precomputed Lagrange bases keyed by the sorted party set.
+type basisCache struct {
+	threshold int
+
+	mtx    sync.Mutex
+	hits   map[string]int
+	tables map[string]map[party.ID]curve.Scalar
+}
+
+func newBasisCache(threshold int) *basisCache {
+	return &basisCache{
+		threshold: threshold,
+		hits:      make(map[string]int),
+		tables:    make(map[string]map[party.ID]curve.Scalar),
+	}
+}
+
+// get returns the Lagrange basis for partyIDs, computing and caching it once the
+// party set has been seen threshold times.
+func (c *basisCache) get(group curve.Curve, partyIDs []party.ID) map[party.ID]curve.Scalar {
+	if c == nil || c.threshold <= 0 {
+		return polynomial.Lagrange(group, partyIDs)
+	}
+
+	ids := party.NewIDSlice(partyIDs)
+	key := basisKey(group, ids)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	table, ok := c.tables[key]
+	if !ok {
+		if _, seen := c.hits[key]; !seen && len(c.hits) >= maxBasisEntries {
+			c.hits = make(map[string]int)
+		}
+		c.hits[key]++
+		if c.hits[key] < c.threshold {
+			return polynomial.Lagrange(group, ids)
+		}
+		if len(c.tables) >= maxBasisEntries {
+			for k := range c.tables {
+				delete(c.tables, k)
+				break
+			}
+		}
+		table = polynomial.Lagrange(group, ids)
+		c.tables[key] = table
+		delete(c.hits, key)
+	}
+
+	return copyBasis(group, table)
+}
+
+func basisKey(group curve.Curve, ids party.IDSlice) string {
+	parts := make([]string, 0, len(ids)+1)
+	parts = append(parts, group.Name())
+	for _, id := range ids {
+		parts = append(parts, string(id))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func copyBasis(group curve.Curve, table map[party.ID]curve.Scalar) map[party.ID]curve.Scalar {
+	out := make(map[party.ID]curve.Scalar, len(table))
+	for id, l := range table {
+		out[id] = group.NewScalar().Set(l)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package vss
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVssKeyManager() *VssKeyManager {
+	vss_keyopts := keyopts.NewInMemoryKeyOpts()
+	vss_vault := vault.NewInMemoryVault()
+	vss_ks := keystore.NewInMemoryKeystore(vss_vault, vss_keyopts)
+	return NewVssKeyManager(vss_ks, curve.Secp256k1{})
+}
+
+func TestVssKeyManager_LagrangeBasis(t *testing.T) {
+	uncached := newVssKeyManager()
+	cached := newVssKeyManager().WithBasisCache(2)
+
+	partyIDs := test.PartyIDs(5)
+	expected := polynomial.Lagrange(curve.Secp256k1{}, partyIDs)
+
+	for i := 0; i < 4; i++ {
+		a := uncached.LagrangeBasis(partyIDs)
+		b := cached.LagrangeBasis(partyIDs)
+		for _, id := range partyIDs {
+			assert.True(t, expected[id].Equal(a[id]))
+			assert.True(t, expected[id].Equal(b[id]))
+		}
+	}
+	assert.Len(t, cached.basis.tables, 1)
+
+	// mutating a returned basis must not affect the cached table
+	b := cached.LagrangeBasis(partyIDs)
+	b[partyIDs[0]].Add(b[partyIDs[1]])
+	b = cached.LagrangeBasis(partyIDs)
+	assert.True(t, expected[partyIDs[0]].Equal(b[partyIDs[0]]))
+
+	// a different party set yields a different basis
+	subset := partyIDs[:3]
+	expected = polynomial.Lagrange(curve.Secp256k1{}, subset)
+	b = cached.LagrangeBasis(subset)
+	assert.Len(t, b, 3)
+	for _, id := range subset {
+		assert.True(t, expected[id].Equal(b[id]))
+	}
+}
+
+func benchmarkLagrangeBasis(b *testing.B, mgr *VssKeyManager) {
+	partyIDs := test.PartyIDs(20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mgr.LagrangeBasis(partyIDs)
+	}
+}
+
+func BenchmarkLagrangeBasis_Uncached(b *testing.B) {
+	benchmarkLagrangeBasis(b, newVssKeyManager())
+}
+
+func BenchmarkLagrangeBasis_Cached(b *testing.B) {
+	benchmarkLagrangeBasis(b, newVssKeyManager().WithBasisCache(1))
+}
+
+func TestBasisCache_Bounded(t *testing.T) {
+	cache := newBasisCache(2)
+	partyIDs := test.PartyIDs(maxBasisEntries + 8)
+
+	// every party set is requested once, so none of them is cached
+	for i := range partyIDs {
+		cache.get(curve.Secp256k1{}, partyIDs[:i+1])
+		assert.LessOrEqual(t, len(cache.hits), maxBasisEntries)
+	}
+	assert.Empty(t, cache.tables)
+
+	// every party set is requested twice, so each of them is cached
+	for i := range partyIDs {
+		cache.get(curve.Secp256k1{}, partyIDs[:i+1])
+		cache.get(curve.Secp256k1{}, partyIDs[:i+1])
+		assert.LessOrEqual(t, len(cache.hits), maxBasisEntries)
+		assert.LessOrEqual(t, len(cache.tables), maxBasisEntries)
+	}
+	assert.Len(t, cache.tables, maxBasisEntries)
+}
@@ -0,0 +1,210 @@
+package vss
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	sw_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+// shareBackupVersion is the version of the blobs produced by ExportShare.
+const shareBackupVersion = 1
+
+// shareBackupDomain separates the checksum of a share backup from the other hashes of the library.
+const shareBackupDomain = "mpc-lib/vss/share-backup"
+
+var (
+	ErrNoShareManager     = errors.New("vss: no share manager")
+	ErrShareNotPrivate    = errors.New("vss: share is not private")
+	ErrInvalidShare       = errors.New("vss: share does not match the VSS exponents")
+	ErrInvalidShareBackup = errors.New("vss: invalid share backup")
+)
+
+type rawShareBackup struct {
+	Version  uint8
+	Group    string
+	PartyID  string
+	Share    []byte
+	Public   []byte
+	Checksum []byte
+}
+
+// checksum returns the hash of the contents of the backup. It is not keyed, so it only detects
+// corrupted backups: anyone can recompute it over modified contents.
+func (raw *rawShareBackup) checksum() []byte {
+	h := sha256.New()
+	for _, field := range [][]byte{
+		[]byte(shareBackupDomain),
+		{raw.Version},
+		[]byte(raw.Group),
+		[]byte(raw.PartyID),
+		raw.Share,
+		raw.Public,
+	} {
+		var l [8]byte
+		binary.BigEndian.PutUint64(l[:], uint64(len(field)))
+		h.Write(l[:])
+		h.Write(field)
+	}
+	return h.Sum(nil)
+}
+
+// WithShareManager sets the key manager in which the VSS shares of the MPC keys are stored, which
+// is needed to export and import the shares.
+func (mgr *VssKeyManager) WithShareManager(shares comm_ecdsa.ECDSAKeyManager) *VssKeyManager {
+	mgr.shares = shares
+	return mgr
+}
+
+// ExportShare returns a backup of the VSS share of party "partyid" for the MPC key "id" of opts.
+// The backup is versioned and carries a checksum of its contents. The checksum is not keyed, so a
+// share is authenticated on import by checking it against the stored exponents instead. The backup
+// holds the secret share in the clear, so it must be encrypted by the caller before leaving the
+// party.
+func (mgr *VssKeyManager) ExportShare(opts keyopts.Options) ([]byte, error) {
+	if mgr.shares == nil {
+		return nil, ErrNoShareManager
+	}
+	partyID, shareOpts, public, err := mgr.shareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := mgr.shares.GetKey(shareOpts)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Private() {
+		return nil, ErrShareNotPrivate
+	}
+	one := mgr.group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	share := key.Mul(one)
+	if !share.ActOnBase().Equal(public) {
+		return nil, ErrInvalidShare
+	}
+
+	raw := &rawShareBackup{
+		Version: shareBackupVersion,
+		Group:   mgr.group.Name(),
+		PartyID: string(partyID),
+	}
+	if raw.Share, err = share.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if raw.Public, err = public.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	raw.Checksum = raw.checksum()
+	return cbor.Marshal(raw)
+}
+
+// ImportShare restores the VSS share of party "partyid" for the MPC key "id" of opts from a backup
+// returned by ExportShare. The exponents of the MPC key must already be stored, and the share is
+// only accepted if it is the evaluation of the polynomial they commit to at the party's index.
+//
+// Since the party's share is also the share of the MPC key, ImportShare stores it under the party
+// ID "ROOT" as well, replacing the share stored there. The backup must therefore be the one of the
+// party owning the store: importing the backup of another party makes its share the MPC key share.
+func (mgr *VssKeyManager) ImportShare(data []byte, opts keyopts.Options) error {
+	if mgr.shares == nil {
+		return ErrNoShareManager
+	}
+
+	raw := &rawShareBackup{}
+	if err := cbor.Unmarshal(data, raw); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidShareBackup, err)
+	}
+	if raw.Version != shareBackupVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidShareBackup, raw.Version)
+	}
+	if subtle.ConstantTimeCompare(raw.Checksum, raw.checksum()) != 1 {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidShareBackup)
+	}
+	if raw.Group != mgr.group.Name() {
+		return fmt.Errorf("%w: group %s", ErrInvalidShareBackup, raw.Group)
+	}
+
+	partyID, shareOpts, public, err := mgr.shareOptions(opts)
+	if err != nil {
+		return err
+	}
+	if raw.PartyID != string(partyID) {
+		return fmt.Errorf("%w: share of party %s", ErrInvalidShareBackup, raw.PartyID)
+	}
+
+	share := mgr.group.NewScalar()
+	if err := share.UnmarshalBinary(raw.Share); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidShareBackup, err)
+	}
+	backupPublic := mgr.group.NewPoint()
+	if err := backupPublic.UnmarshalBinary(raw.Public); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidShareBackup, err)
+	}
+	if !backupPublic.Equal(public) || !share.ActOnBase().Equal(public) {
+		return ErrInvalidShare
+	}
+
+	// the share of the party is also the share of the MPC key, which it replaces
+	rootOpts := sw_keyopts.Options{}
+	if _, err := rootOpts.Set("protocol", shareOpts["protocol"], "id", shareOpts["id"], "partyid", "ROOT"); err != nil {
+		return err
+	}
+	key := mgr.shares.NewKey(share, public, mgr.group)
+	for _, o := range []sw_keyopts.Options{shareOpts, rootOpts} {
+		if _, err := mgr.shares.ImportKey(key, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shareOptions returns the party of opts, the options of its share of the MPC key of opts, and
+// the public share expected by the exponents of the MPC key.
+func (mgr *VssKeyManager) shareOptions(opts keyopts.Options) (party.ID, sw_keyopts.Options, curve.Point, error) {
+	id, ok := opts.Get("id")
+	if !ok {
+		return "", nil, nil, sw_keyopts.ErrInvalidParamsKeyID
+	}
+	p, ok := opts.Get("partyid")
+	if !ok {
+		return "", nil, nil, sw_keyopts.ErrInvalidParamsPartyID
+	}
+	partyID, ok := p.(string)
+	if !ok || partyID == "" {
+		return "", nil, nil, sw_keyopts.ErrInvalidParamsPartyID
+	}
+	// the keys derived from the MPC key belong to the same protocol
+	protocol, ok := opts.Get("protocol")
+	if !ok {
+		protocol = sw_keyopts.DefaultProtocol
+	}
+
+	rootOpts := sw_keyopts.Options{}
+	if _, err := rootOpts.Set("protocol", protocol, "id", id, "partyid", "ROOT"); err != nil {
+		return "", nil, nil, err
+	}
+	root, err := mgr.GetSecrets(rootOpts)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	public, err := root.EvaluateByExponents(party.ID(partyID).Scalar(mgr.group))
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	shareOpts := sw_keyopts.Options{}
+	if _, err := shareOpts.Set("protocol", protocol, "id", hex.EncodeToString(root.SKI()), "partyid", partyID); err != nil {
+		return "", nil, nil, err
+	}
+	return party.ID(partyID), shareOpts, public, nil
+}
@@ -0,0 +1,114 @@
+package vss
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newShareBackup returns a VssKeyManager storing the exponents of a degree 1 polynomial f for
+// the MPC key keyID, whose share f(self) is stored in its share manager.
+func newShareBackup(t *testing.T, keyID string, self party.ID) (*VssKeyManager, *polynomial.Polynomial) {
+	group := curve.Secp256k1{}
+	mgr := newVssKeyManager()
+	sch_ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	ec_ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	shares := sw_ecdsa.NewECDSAKeyManager(ec_ks, sch_ks, mgr, &sw_ecdsa.Config{Group: group})
+	mgr.WithShareManager(shares)
+
+	f := polynomial.NewPolynomial(group, 1, sample.Scalar(rand.Reader, group))
+	root, err := mgr.ImportSecrets(NewVssKey(nil, polynomial.NewPolynomialExponent(f)), keyopts.Options{"id": keyID, "partyid": "ROOT"})
+	require.NoError(t, err)
+
+	share := f.Evaluate(self.Scalar(group))
+	shareOpts := keyopts.Options{"id": hex.EncodeToString(root.SKI()), "partyid": string(self)}
+	_, err = shares.ImportKey(shares.NewKey(share, share.ActOnBase(), group), shareOpts)
+	require.NoError(t, err)
+
+	return mgr, f
+}
+
+func TestVssKeyManager_ShareBackup(t *testing.T) {
+	opts := keyopts.Options{"id": "key", "partyid": "a"}
+	mgr, f := newShareBackup(t, "key", "a")
+
+	data, err := mgr.ExportShare(opts)
+	require.NoError(t, err)
+
+	// a manager holding the same exponents but having lost the share
+	restored := newVssKeyManager()
+	restoredShares := sw_ecdsa.NewECDSAKeyManager(
+		keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()),
+		keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()),
+		restored, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
+	restored.WithShareManager(restoredShares)
+	root, err := mgr.GetSecrets(keyopts.Options{"id": "key", "partyid": "ROOT"})
+	require.NoError(t, err)
+	_, err = restored.ImportSecrets(root, keyopts.Options{"id": "key", "partyid": "ROOT"})
+	require.NoError(t, err)
+
+	require.NoError(t, restored.ImportShare(data, opts))
+	for _, partyID := range []string{"a", "ROOT"} {
+		key, err := restoredShares.GetKey(keyopts.Options{"id": hex.EncodeToString(root.SKI()), "partyid": partyID})
+		require.NoError(t, err)
+		assert.True(t, key.Private())
+		assert.True(t, key.PublicKeyRaw().Equal(f.Evaluate(party.ID("a").Scalar(curve.Secp256k1{})).ActOnBase()))
+	}
+	again, err := restored.ExportShare(opts)
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+}
+
+func TestVssKeyManager_ShareBackup_Invalid(t *testing.T) {
+	opts := keyopts.Options{"id": "key", "partyid": "a"}
+	mgr, _ := newShareBackup(t, "key", "a")
+	data, err := mgr.ExportShare(opts)
+	require.NoError(t, err)
+
+	// a backup with a consistent checksum over the share of another polynomial
+	other, _ := newShareBackup(t, "key", "a")
+	wrong, err := other.ExportShare(opts)
+	require.NoError(t, err)
+	assert.ErrorIs(t, mgr.ImportShare(wrong, opts), ErrInvalidShare)
+
+	tamper := func(modify func(raw *rawShareBackup)) []byte {
+		raw := &rawShareBackup{}
+		require.NoError(t, cbor.Unmarshal(data, raw))
+		modify(raw)
+		b, err := cbor.Marshal(raw)
+		require.NoError(t, err)
+		return b
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"garbage", []byte("garbage")},
+		{"version", tamper(func(raw *rawShareBackup) { raw.Version++; raw.Checksum = raw.checksum() })},
+		{"checksum", tamper(func(raw *rawShareBackup) { raw.Share[len(raw.Share)-1] ^= 1 })},
+		{"party", tamper(func(raw *rawShareBackup) { raw.PartyID = "b"; raw.Checksum = raw.checksum() })},
+		{"group", tamper(func(raw *rawShareBackup) { raw.Group = "p256"; raw.Checksum = raw.checksum() })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, mgr.ImportShare(tt.data, opts), ErrInvalidShareBackup)
+		})
+	}
+
+	_, err = mgr.ExportShare(keyopts.Options{"id": "key", "partyid": "b"})
+	assert.Error(t, err, "no share of b is stored")
+	_, err = newVssKeyManager().ExportShare(opts)
+	assert.ErrorIs(t, err, ErrNoShareManager)
+}
@@ -112,6 +112,8 @@ func fromBytes(data []byte) (VssKey, error) {
 	switch raw.Group {
 	case "secp256k1":
 		group = curve.Secp256k1{}
+	case "p256", "secp256r1":
+		group = curve.P256{}
 	}
 
 	vss := VssKey{}
@@ -6,23 +6,46 @@ import (
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	comm_vss "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	sw_keystore "github.com/mr-shifu/mpc-lib/pkg/keystore"
 )
 
 type VssKeyManager struct {
 	group curve.Curve
 	ks    keystore.Keystore
+	basis *basisCache
+
+	// shares stores the VSS shares of the MPC keys, see WithShareManager.
+	shares comm_ecdsa.ECDSAKeyManager
 }
 
 func NewVssKeyManager(store keystore.Keystore, g curve.Curve) *VssKeyManager {
 	return &VssKeyManager{
 		group: g,
 		ks:    store,
+		basis: newBasisCache(0),
 	}
 }
 
+// WithBasisCache enables caching of the Lagrange evaluation basis. A basis for a given
+// party set is precomputed and cached once it has been requested threshold times;
+// a threshold <= 0 disables caching.
+func (mgr *VssKeyManager) WithBasisCache(threshold int) *VssKeyManager {
+	mgr.basis = newBasisCache(threshold)
+	return mgr
+}
+
+// LagrangeBasis returns the Lagrange coefficients at 0 for all parties in partyIDs.
+// Depending on the configured cache threshold the coefficients may be served from a
+// precomputed table; the returned map is always a fresh copy which callers may modify.
+func (mgr *VssKeyManager) LagrangeBasis(partyIDs []party.ID) map[party.ID]curve.Scalar {
+	return mgr.basis.get(mgr.group, partyIDs)
+}
+
 // GenerateSecrets generates a Polynomail of a specified degree with secret as constant value
 // and stores coefficients and expponents of coefficients.
 func (mgr *VssKeyManager) GenerateSecrets(secret curve.Scalar, degree int, opts keyopts.Options) (comm_vss.VssKey, error) {
@@ -178,3 +201,8 @@ func (mgr *VssKeyManager) SumExponents(optsList ...keyopts.Options) (comm_vss.Vs
 
 	return NewVssKey(nil, summed), nil
 }
+
+// PurgeSession implements keystore.KeyManager.
+func (mgr *VssKeyManager) PurgeSession(opts keyopts.Options) (int, error) {
+	return sw_keystore.PurgeSession(opts, mgr.ks)
+}
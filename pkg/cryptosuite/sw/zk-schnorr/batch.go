@@ -0,0 +1,94 @@
+package zkschnorrstore
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+)
+
+// ErrInvalidProof is wrapped by the BatchError returned by BatchVerify.
+var ErrInvalidProof = errors.New("zkschnorr: invalid proof")
+
+// Proof is a Schnorr proof, which is valid for a public point X and a challenge e if
+// Response⋅G = Commitment + e⋅X.
+type Proof struct {
+	Commitment curve.Point
+	Response   curve.Scalar
+}
+
+// BatchError is returned by BatchVerify when a proof of the batch is invalid.
+type BatchError struct {
+	// Index is the index of the first invalid proof of the batch.
+	Index int
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("zkschnorr: proof %d of the batch is invalid", e.Index)
+}
+
+func (BatchError) Unwrap() error {
+	return ErrInvalidProof
+}
+
+// BatchVerify checks that proofs[i] is valid for publics[i] and challenges[i], for every i.
+//
+// Rather than verifying each proof, it samples random ρᵢ and checks with a single multi-scalar
+// multiplication that
+//
+//	(∑ᵢ ρᵢ⋅zᵢ)⋅G - ∑ᵢ ρᵢ⋅Cᵢ - ∑ᵢ (ρᵢ⋅eᵢ)⋅Xᵢ = ∞,
+//
+// which holds with negligible probability if a proof is invalid. In that case, the proofs are
+// verified one by one, and a BatchError with the index of the first invalid one is returned.
+func BatchVerify(proofs []Proof, publics []curve.Point, challenges []curve.Scalar) error {
+	if len(proofs) != len(publics) || len(proofs) != len(challenges) {
+		return fmt.Errorf("zkschnorr: batch of %d proofs with %d public points and %d challenges",
+			len(proofs), len(publics), len(challenges))
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+	for i := range proofs {
+		if !isValidCommitment(proofs[i].Commitment) || !isValidProof(proofs[i].Response) ||
+			publics[i] == nil || publics[i].IsIdentity() || challenges[i] == nil {
+			return BatchError{Index: i}
+		}
+	}
+
+	group := publics[0].Curve()
+	scalars := make([]curve.Scalar, 0, 2*len(proofs)+1)
+	points := make([]curve.Point, 0, 2*len(proofs)+1)
+	z := group.NewScalar()
+	for i := range proofs {
+		rho := sample.Scalar(rand.Reader, group)
+		// ∑ᵢ ρᵢ⋅zᵢ
+		z.Add(group.NewScalar().Set(rho).Mul(proofs[i].Response))
+		// -ρᵢ⋅Cᵢ
+		scalars = append(scalars, group.NewScalar().Set(rho).Negate())
+		points = append(points, proofs[i].Commitment)
+		// -(ρᵢ⋅eᵢ)⋅Xᵢ
+		scalars = append(scalars, rho.Mul(challenges[i]).Negate())
+		points = append(points, publics[i])
+	}
+	scalars = append(scalars, z)
+	points = append(points, group.NewBasePoint())
+
+	if group.MultiScalarMult(scalars, points).IsIdentity() {
+		return nil
+	}
+	for i := range proofs {
+		if !verifyProof(proofs[i], publics[i], challenges[i]) {
+			return BatchError{Index: i}
+		}
+	}
+	// not reached, since the combination of valid proofs is ∞
+	return nil
+}
+
+// verifyProof checks that proof.Response⋅G = proof.Commitment + challenge⋅public.
+func verifyProof(proof Proof, public curve.Point, challenge curve.Scalar) bool {
+	rhs := challenge.Act(public).Add(proof.Commitment)
+	return proof.Response.ActOnBase().Equal(rhs)
+}
@@ -0,0 +1,86 @@
+package zkschnorrstore
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var group = curve.Secp256k1{}
+
+// newBatch returns n valid proofs with their public points and challenges.
+func newBatch(n int) ([]Proof, []curve.Point, []curve.Scalar) {
+	proofs := make([]Proof, n)
+	publics := make([]curve.Point, n)
+	challenges := make([]curve.Scalar, n)
+	for i := 0; i < n; i++ {
+		x := sample.Scalar(rand.Reader, group)
+		a := sample.Scalar(rand.Reader, group)
+		e := sample.Scalar(rand.Reader, group)
+		proofs[i] = Proof{
+			Commitment: a.ActOnBase(),
+			Response:   group.NewScalar().Set(e).Mul(x).Add(a),
+		}
+		publics[i] = x.ActOnBase()
+		challenges[i] = e
+	}
+	return proofs, publics, challenges
+}
+
+func TestBatchVerify(t *testing.T) {
+	proofs, publics, challenges := newBatch(10)
+	require.NoError(t, BatchVerify(proofs, publics, challenges))
+	require.NoError(t, BatchVerify(nil, nil, nil))
+
+	for _, i := range []int{0, 4, 9} {
+		tampered := append([]Proof(nil), proofs...)
+		tampered[i].Response = group.NewScalar().Set(proofs[i].Response).Add(sample.Scalar(rand.Reader, group))
+		err := BatchVerify(tampered, publics, challenges)
+		require.ErrorIs(t, err, ErrInvalidProof)
+		var batchErr BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, i, batchErr.Index)
+	}
+
+	// a proof for another public point
+	swapped := append([]curve.Point(nil), publics...)
+	swapped[2], swapped[3] = swapped[3], swapped[2]
+	var batchErr BatchError
+	require.ErrorAs(t, BatchVerify(proofs, swapped, challenges), &batchErr)
+	assert.Equal(t, 2, batchErr.Index)
+
+	// malformed proofs are rejected before the batch is combined
+	malformed := append([]Proof(nil), proofs...)
+	malformed[5].Commitment = group.NewPoint()
+	require.ErrorAs(t, BatchVerify(malformed, publics, challenges), &batchErr)
+	assert.Equal(t, 5, batchErr.Index)
+
+	assert.Error(t, BatchVerify(proofs, publics[:9], challenges))
+}
+
+func BenchmarkBatchVerify(b *testing.B) {
+	for _, n := range []int{8, 32, 128} {
+		proofs, publics, challenges := newBatch(n)
+		b.Run(fmt.Sprintf("batch/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := BatchVerify(proofs, publics, challenges); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("sequential/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range proofs {
+					if !verifyProof(proofs[j], publics[j], challenges[j]) {
+						b.Fatal("invalid proof")
+					}
+				}
+			}
+		})
+	}
+}
@@ -172,6 +172,12 @@ func isValidProof(z curve.Scalar) bool {
 	return true
 }
 
+// Challenge returns the challenge e of a proof for public with the given commitment, as derived
+// by Prove and Verify from hash, so that a proof z can be checked as z⋅G = commitment + e⋅public.
+func Challenge(hash hash.Hash, group curve.Curve, commitment, public curve.Point) (curve.Scalar, error) {
+	return challenge(hash, group, commitment, public, group.NewBasePoint())
+}
+
 func challenge(hash hash.Hash, group curve.Curve, commitment, public, gen curve.Point) (e curve.Scalar, err error) {
 	err = hash.WriteAny(commitment, public, gen)
 	e = sample.Scalar(hash.Digest(), group)
@@ -232,6 +238,9 @@ func fromBytes(data []byte, zksch *ZKSchnorr) (error) {
 	case "secp256k1":
 		group = curve.Secp256k1{}
 		zksch.group = group
+	case "p256", "secp256r1":
+		group = curve.P256{}
+		zksch.group = group
 	}
 
 	if raw.Alpha != nil {
@@ -18,7 +18,7 @@ type Keys map[string]*keyopts.KeyData
 type KeyOpts struct {
 	lock sync.RWMutex
 
-	// keys is a map of MPC KeyID to a map of PartyID to key metadata{SKI}.
+	// keys is a map of the namespaced MPC KeyID to a map of PartyID to key metadata{SKI}.
 	keys map[string]Keys
 }
 
@@ -33,13 +33,9 @@ func (kr *KeyOpts) Import(data interface{}, opts keyopts.Options) error {
 	defer kr.lock.Unlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("id")
-	if !ok {
-		return ErrInvalidParamsKeyID
-	}
-	kid, ok := ID.(string)
-	if !ok {
-		return ErrInvalidParamsKeyID
+	kid, err := keyID(opts)
+	if err != nil {
+		return err
 	}
 
 	// get PartyID from Options
@@ -76,13 +72,9 @@ func (kr *KeyOpts) Get(opts keyopts.Options) (*keyopts.KeyData, error) {
 	defer kr.lock.RUnlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("id")
-	if !ok {
-		return nil, ErrInvalidParamsKeyID
-	}
-	kid, ok := ID.(string)
-	if !ok {
-		return nil, ErrInvalidParamsKeyID
+	kid, err := keyID(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// get PartyID from Options
@@ -112,13 +104,9 @@ func (kr *KeyOpts) GetAll(opts keyopts.Options) (map[string]*keyopts.KeyData, er
 	kr.lock.RLock()
 	defer kr.lock.RUnlock()
 
-	ID, ok := opts.Get("id")
-	if !ok {
-		return nil, ErrInvalidParamsKeyID
-	}
-	kid, ok := ID.(string)
-	if !ok {
-		return nil, ErrInvalidParamsKeyID
+	kid, err := keyID(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	ks, ok := kr.keys[kid]
@@ -138,13 +126,9 @@ func (kr *KeyOpts) Delete(opts keyopts.Options) error {
 	defer kr.lock.Unlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("id")
-	if !ok {
-		return ErrInvalidParamsKeyID
-	}
-	kid, ok := ID.(string)
-	if !ok {
-		return ErrInvalidParamsKeyID
+	kid, err := keyID(opts)
+	if err != nil {
+		return err
 	}
 
 	// get PartyID from Options
@@ -172,16 +156,24 @@ func (kr *KeyOpts) DeleteAll(opts keyopts.Options) error {
 	defer kr.lock.Unlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("ID")
-	if !ok {
-		return ErrInvalidParamsKeyID
-	}
-	kid, ok := ID.(string)
-	if !ok {
-		return ErrInvalidParamsKeyID
+	kid, err := keyID(opts)
+	if err != nil {
+		return err
 	}
 
 	delete(kr.keys, kid)
 
 	return nil
 }
+
+// keyID returns the MPC KeyID of opts, namespaced by its protocol.
+func keyID(opts keyopts.Options) (string, error) {
+	ID, ok := opts.Get("id")
+	if !ok {
+		return "", ErrInvalidParamsKeyID
+	}
+	if _, ok := ID.(string); !ok {
+		return "", ErrInvalidParamsKeyID
+	}
+	return opts.Namespace(), nil
+}
@@ -35,3 +35,35 @@ func TestImportKeys(t *testing.T) {
 	assert.NoError(t, err, "GetAll should not return an error")
 	assert.Len(t, ks, len(keys), fmt.Sprintf("GetAll should return %d key", len(keys)))
 }
+
+func TestNamespace(t *testing.T) {
+	cmp, frost, deflt := Options{}, Options{}, Options{}
+	cmp.Set("id", "1", "partyid", "a", "protocol", "cmp")
+	frost.Set("id", "1", "partyid", "a", "protocol", "frost")
+	deflt.Set("id", "1", "partyid", "a")
+
+	assert.Equal(t, cmp.Namespace(), cmp.Namespace())
+	assert.NotEqual(t, cmp.Namespace(), frost.Namespace())
+	assert.NotEqual(t, cmp.Namespace(), deflt.Namespace())
+	explicit := Options{"id": "1", "protocol": DefaultProtocol}
+	assert.Equal(t, explicit.Namespace(), deflt.Namespace())
+
+	// the protocol and the KeyID cannot be shifted into each other
+	assert.NotEqual(t, Options{"id": "b:c", "protocol": "a"}.Namespace(), Options{"id": "c", "protocol": "a:b"}.Namespace())
+
+	kr := NewInMemoryKeyOpts()
+	assert.NoError(t, kr.Import("ski-cmp", cmp))
+	_, err := kr.Get(frost)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = kr.Get(deflt)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	assert.NoError(t, kr.Import("ski-frost", frost))
+	kd, err := kr.Get(cmp)
+	assert.NoError(t, err)
+	assert.Equal(t, "ski-cmp", kd.SKI)
+	assert.NoError(t, kr.DeleteAll(frost))
+	kd, err = kr.Get(cmp)
+	assert.NoError(t, err)
+	assert.Equal(t, "ski-cmp", kd.SKI)
+}
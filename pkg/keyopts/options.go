@@ -2,10 +2,20 @@ package keyopts
 
 import (
 	"errors"
+	"fmt"
 
 	com_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 )
 
+// DefaultProtocol is the protocol of the Options which do not set one with the "protocol" key.
+const DefaultProtocol = "mpc"
+
+// The protocols of the keys stored by the CMP and FROST sessions.
+const (
+	ProtocolCMP   = "cmp"
+	ProtocolFROST = "frost"
+)
+
 type Options map[string]interface{}
 
 var _ com_keyopts.Options = Options{}
@@ -32,3 +42,15 @@ func (opts Options) Get(key string) (interface{}, bool) {
 	val, ok := opts[key]
 	return val, ok
 }
+
+// Namespace returns a composite of the "protocol" and "id" values of opts, which differs for
+// different protocols with the same MPC KeyID. The length of the protocol is included, so that the
+// composite is unambiguous whatever the protocol and the KeyID contain.
+func (opts Options) Namespace() string {
+	protocol, ok := opts["protocol"].(string)
+	if !ok || protocol == "" {
+		protocol = DefaultProtocol
+	}
+	id, _ := opts["id"].(string)
+	return fmt.Sprintf("%d:%s:%s", len(protocol), protocol, id)
+}
@@ -0,0 +1,315 @@
+package keystore
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/dgraph-io/badger"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	mem_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+const (
+	// badgerKeyTag marks the entries holding the keys, indexed by SKI
+	badgerKeyTag = "key"
+	// badgerIndexTag marks the entries holding the SKI of the key stored under an MPC KeyID and a PartyID
+	badgerIndexTag = "index"
+)
+
+// BadgerKeystore is a Keystore persisting keys in a BadgerDB database.
+//
+// The entries of a BadgerKeystore are namespaced by its prefix, so that several keystores, e.g. one
+// per key manager, can share a database.
+type BadgerKeystore struct {
+	db     *badger.DB
+	prefix string
+	// owned is set if the keystore opened db, and closes it in Close
+	owned bool
+}
+
+var _ keystore.Keystore = (*BadgerKeystore)(nil)
+
+// NewBadgerKeystore opens the BadgerDB database in the directory path, creating it if needed, and
+// returns a keystore storing its keys in it. The database is closed by Close.
+func NewBadgerKeystore(path string) (*BadgerKeystore, error) {
+	db, err := badger.Open(badger.DefaultOptions(path).WithLogger(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &BadgerKeystore{db: db, owned: true}, nil
+}
+
+// NewBadgerKeystoreWithDB returns a keystore storing its keys in db under prefix. Keystores with
+// different prefixes do not see each other's keys. Close does not close db, which is left to the caller.
+func NewBadgerKeystoreWithDB(db *badger.DB, prefix string) *BadgerKeystore {
+	return &BadgerKeystore{db: db, prefix: prefix}
+}
+
+// Close closes the database if it was opened by NewBadgerKeystore.
+func (ks *BadgerKeystore) Close() error {
+	if !ks.owned {
+		return nil
+	}
+	return ks.db.Close()
+}
+
+func (ks *BadgerKeystore) Import(ski string, key []byte, opts keyopts.Options) error {
+	id, partyID, err := parseOptions(opts)
+	if err != nil {
+		return err
+	}
+	return ks.update(func(txn *badger.Txn) error {
+		return ks.importKey(txn, ski, key, id, partyID)
+	})
+}
+
+// importKey stores key under ski, and ski under the MPC KeyID id and partyID.
+func (ks *BadgerKeystore) importKey(txn *badger.Txn, ski string, key []byte, id, partyID string) error {
+	if err := txn.Set(ks.key(badgerKeyTag, ski), key); err != nil {
+		return err
+	}
+	return txn.Set(ks.key(badgerIndexTag, id, partyID), []byte(ski))
+}
+
+// ImportBatch imports the keys of entries indexed by SKI in a single transaction, so that either
+// all keys are imported or none is.
+func (ks *BadgerKeystore) ImportBatch(entries map[string]keystore.Entry) error {
+	return ks.update(func(txn *badger.Txn) error {
+		for ski, e := range entries {
+			id, partyID, err := parseOptions(e.Opts)
+			if err != nil {
+				return err
+			}
+			if err := ks.importKey(txn, ski, e.Key, id, partyID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (ks *BadgerKeystore) Update(key []byte, opts keyopts.Options) error {
+	id, partyID, err := parseOptions(opts)
+	if err != nil {
+		return err
+	}
+	return ks.update(func(txn *badger.Txn) error {
+		ski, err := ks.get(txn, ks.key(badgerIndexTag, id, partyID))
+		if err != nil {
+			return err
+		}
+		return txn.Set(ks.key(badgerKeyTag, string(ski)), key)
+	})
+}
+
+func (ks *BadgerKeystore) Get(opts keyopts.Options) ([]byte, error) {
+	id, partyID, err := parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	var key []byte
+	err = ks.db.View(func(txn *badger.Txn) error {
+		ski, err := ks.get(txn, ks.key(badgerIndexTag, id, partyID))
+		if err != nil {
+			return err
+		}
+		key, err = ks.get(txn, ks.key(badgerKeyTag, string(ski)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetAll returns all keys stored under the MPC KeyID in opts, indexed by PartyID.
+func (ks *BadgerKeystore) GetAll(opts keyopts.Options) (map[string][]byte, error) {
+	id, err := parseKeyID(opts)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string][]byte)
+	err = ks.db.View(func(txn *badger.Txn) error {
+		return ks.iterate(txn, id, func(partyID string, ski []byte) error {
+			key, err := ks.get(txn, ks.key(badgerKeyTag, string(ski)))
+			if err != nil {
+				return err
+			}
+			keys[partyID] = key
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, mem_keyopts.ErrKeyNotFound
+	}
+	return keys, nil
+}
+
+func (ks *BadgerKeystore) Delete(opts keyopts.Options) error {
+	id, partyID, err := parseOptions(opts)
+	if err != nil {
+		return err
+	}
+	return ks.update(func(txn *badger.Txn) error {
+		index := ks.key(badgerIndexTag, id, partyID)
+		ski, err := ks.get(txn, index)
+		if err != nil {
+			return err
+		}
+		if err := txn.Delete(ks.key(badgerKeyTag, string(ski))); err != nil {
+			return err
+		}
+		return txn.Delete(index)
+	})
+}
+
+// DeleteAll deletes all keys stored under the MPC KeyID in opts and returns the number of keys deleted.
+func (ks *BadgerKeystore) DeleteAll(opts keyopts.Options) (int, error) {
+	id, err := parseKeyID(opts)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	err = ks.update(func(txn *badger.Txn) error {
+		n = 0
+		return ks.iterate(txn, id, func(partyID string, ski []byte) error {
+			if err := txn.Delete(ks.key(badgerKeyTag, string(ski))); err != nil {
+				return err
+			}
+			if err := txn.Delete(ks.key(badgerIndexTag, id, partyID)); err != nil {
+				return err
+			}
+			n++
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (ks *BadgerKeystore) KeyAccessor(ski string, opts keyopts.Options) keystore.KeyAccessor {
+	return &keyAccessor{ski: ski, opts: opts, ks: ks}
+}
+
+// update runs fn in a read-write transaction, which is retried if it conflicts with another one.
+func (ks *BadgerKeystore) update(fn func(txn *badger.Txn) error) error {
+	for {
+		err := ks.db.Update(fn)
+		if !errors.Is(err, badger.ErrConflict) {
+			return err
+		}
+	}
+}
+
+// get returns the value stored under k, or ErrKeyNotFound.
+func (ks *BadgerKeystore) get(txn *badger.Txn, k []byte) ([]byte, error) {
+	item, err := txn.Get(k)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// iterate calls fn with the PartyID and the SKI of every key stored under the MPC KeyID id.
+func (ks *BadgerKeystore) iterate(txn *badger.Txn, id string, fn func(partyID string, ski []byte) error) error {
+	prefix := ks.key(badgerIndexTag, id)
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		item := it.Item()
+		partyID, ok := decodePart(item.Key()[len(prefix):])
+		if !ok {
+			return errors.New("keystore: malformed index entry")
+		}
+		ski, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(partyID, ski); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key returns the database key of an entry of the keystore. The prefix and every part are length
+// prefixed, so that the keys of an MPC KeyID are never a prefix of the keys of another one.
+func (ks *BadgerKeystore) key(tag string, parts ...string) []byte {
+	buf := appendPart(nil, ks.prefix)
+	buf = appendPart(buf, tag)
+	for _, p := range parts {
+		buf = appendPart(buf, p)
+	}
+	return buf
+}
+
+func appendPart(buf []byte, part string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(part)))
+	return append(buf, part...)
+}
+
+// decodePart returns the single length prefixed part encoded in data.
+func decodePart(data []byte) (string, bool) {
+	l, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) != l {
+		return "", false
+	}
+	return string(data[n:]), true
+}
+
+// parseKeyID returns the MPC KeyID set in opts, namespaced by its protocol.
+func parseKeyID(opts keyopts.Options) (string, error) {
+	ID, ok := opts.Get("id")
+	if !ok {
+		return "", mem_keyopts.ErrInvalidParamsKeyID
+	}
+	if _, ok := ID.(string); !ok {
+		return "", mem_keyopts.ErrInvalidParamsKeyID
+	}
+	return opts.Namespace(), nil
+}
+
+// parseOptions returns the namespaced MPC KeyID and the PartyID set in opts.
+func parseOptions(opts keyopts.Options) (string, string, error) {
+	id, err := parseKeyID(opts)
+	if err != nil {
+		return "", "", err
+	}
+	partyID, ok := opts.Get("partyid")
+	if !ok {
+		return "", "", mem_keyopts.ErrInvalidParamsPartyID
+	}
+	pid, ok := partyID.(string)
+	if !ok {
+		return "", "", mem_keyopts.ErrInvalidParamsPartyID
+	}
+	return id, pid, nil
+}
+
+// keyAccessor gives access to a single key of a Keystore.
+type keyAccessor struct {
+	opts keyopts.Options
+	ski  string
+	ks   keystore.Keystore
+}
+
+func (a *keyAccessor) Import(key []byte) error {
+	return a.ks.Import(a.ski, key, a.opts)
+}
+
+func (a *keyAccessor) Get() ([]byte, error) {
+	return a.ks.Get(a.opts)
+}
+
+func (a *keyAccessor) Delete() error {
+	return a.ks.Delete(a.opts)
+}
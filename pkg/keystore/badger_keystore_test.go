@@ -0,0 +1,136 @@
+package keystore
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadgerKeystore_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	optsA := keyopts.Options{}
+	optsA.Set("id", "123", "partyid", "a")
+	optsB := keyopts.Options{}
+	optsB.Set("id", "123", "partyid", "b")
+
+	ks, err := NewBadgerKeystore(dir)
+	require.NoError(t, err)
+	require.NoError(t, ks.Import("ski-a", []byte("key a"), optsA))
+	require.NoError(t, ks.ImportBatch(map[string]keystore.Entry{
+		"ski-b": {Key: []byte("key b"), Opts: optsB},
+	}))
+	require.NoError(t, ks.Update([]byte("new key a"), optsA))
+	require.NoError(t, ks.Close())
+
+	ks, err = NewBadgerKeystore(dir)
+	require.NoError(t, err)
+	defer ks.Close()
+
+	key, err := ks.Get(optsA)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new key a"), key)
+	all, err := ks.GetAll(optsA)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("new key a"), "b": []byte("key b")}, all)
+
+	require.NoError(t, ks.Delete(optsB))
+	_, err = ks.Get(optsB)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	n, err := ks.DeleteAll(optsA)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = ks.Get(optsA)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	n, err = ks.DeleteAll(optsA)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestBadgerKeystore_ConcurrentImports(t *testing.T) {
+	ks, err := NewBadgerKeystore(t.TempDir())
+	require.NoError(t, err)
+	defer ks.Close()
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			opts := keyopts.Options{}
+			opts.Set("id", "123", "partyid", fmt.Sprint(i))
+			assert.NoError(t, ks.Import(fmt.Sprint("ski-", i), []byte(fmt.Sprint("key ", i)), opts))
+		}(i)
+	}
+	wg.Wait()
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123")
+	all, err := ks.GetAll(opts)
+	require.NoError(t, err)
+	require.Len(t, all, n)
+	for i := 0; i < n; i++ {
+		assert.Equal(t, []byte(fmt.Sprint("key ", i)), all[fmt.Sprint(i)])
+	}
+}
+
+func TestBadgerKeystore_SharedDB(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions(t.TempDir()).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ksA := NewBadgerKeystoreWithDB(db, "a")
+	ksB := NewBadgerKeystoreWithDB(db, "b")
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+	require.NoError(t, ksA.Import("ski", []byte("key a"), opts))
+	_, err = ksB.Get(opts)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, ksB.Import("ski", []byte("key b"), opts))
+	key, err := ksA.Get(opts)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key a"), key)
+
+	// the keys of an MPC KeyID do not include those of a longer one with the same prefix
+	optsLonger := keyopts.Options{}
+	optsLonger.Set("id", "1234", "partyid", "b")
+	require.NoError(t, ksA.Import("ski-longer", []byte("key"), optsLonger))
+	all, err := ksA.GetAll(opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("key a")}, all)
+
+	// closing a keystore does not close a shared database
+	require.NoError(t, ksA.Close())
+	_, err = ksB.Get(opts)
+	assert.NoError(t, err)
+}
+
+func TestBadgerKeystore_Namespace(t *testing.T) {
+	ks, err := NewBadgerKeystore(t.TempDir())
+	require.NoError(t, err)
+	defer ks.Close()
+
+	cmp, frost := keyopts.Options{}, keyopts.Options{}
+	cmp.Set("id", "123", "partyid", "a", "protocol", "cmp")
+	frost.Set("id", "123", "partyid", "a", "protocol", "frost")
+
+	require.NoError(t, ks.Import("ski-cmp", []byte("cmp key"), cmp))
+	_, err = ks.Get(frost)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = ks.GetAll(frost)
+	assert.Error(t, err)
+
+	require.NoError(t, ks.Import("ski-frost", []byte("frost key"), frost))
+	key, err := ks.Get(cmp)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cmp key"), key)
+}
@@ -0,0 +1,187 @@
+package keystore
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	mem_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+var (
+	ErrDecryption = errors.New("keystore: failed to decrypt key")
+)
+
+// EncryptedKeystore wraps a Keystore so that keys are encrypted at rest.
+//
+// Each key is sealed with aead under a fresh random nonce, which is prepended to the
+// ciphertext. The MPC KeyID and PartyID of the entry are authenticated as associated data,
+// so an entry moved to another KeyID or PartyID in the wrapped keystore fails to decrypt.
+type EncryptedKeystore struct {
+	ks   keystore.Keystore
+	aead cipher.AEAD
+}
+
+var _ keystore.Keystore = (*EncryptedKeystore)(nil)
+
+func NewEncryptedKeystore(ks keystore.Keystore, aead cipher.AEAD) *EncryptedKeystore {
+	return &EncryptedKeystore{ks: ks, aead: aead}
+}
+
+func (ks *EncryptedKeystore) Import(ski string, key []byte, opts keyopts.Options) error {
+	ct, err := ks.seal(key, opts)
+	if err != nil {
+		return err
+	}
+	return ks.ks.Import(ski, ct, opts)
+}
+
+func (ks *EncryptedKeystore) ImportBatch(entries map[string]keystore.Entry) error {
+	sealed := make(map[string]keystore.Entry, len(entries))
+	for ski, e := range entries {
+		ct, err := ks.seal(e.Key, e.Opts)
+		if err != nil {
+			return err
+		}
+		sealed[ski] = keystore.Entry{Key: ct, Opts: e.Opts}
+	}
+	return ks.ks.ImportBatch(sealed)
+}
+
+func (ks *EncryptedKeystore) Update(key []byte, opts keyopts.Options) error {
+	ct, err := ks.seal(key, opts)
+	if err != nil {
+		return err
+	}
+	return ks.ks.Update(ct, opts)
+}
+
+func (ks *EncryptedKeystore) Get(opts keyopts.Options) ([]byte, error) {
+	ct, err := ks.ks.Get(opts)
+	if err != nil {
+		return nil, err
+	}
+	kid, pid, err := entryID(opts)
+	if err != nil {
+		return nil, err
+	}
+	return ks.open(ct, kid, pid)
+}
+
+// GetAll returns all keys stored under the MPC KeyID in opts, indexed by PartyID.
+func (ks *EncryptedKeystore) GetAll(opts keyopts.Options) (map[string][]byte, error) {
+	cts, err := ks.ks.GetAll(opts)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := stringOpt(opts, "id", mem_keyopts.ErrInvalidParamsKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte, len(cts))
+	for partyID, ct := range cts {
+		key, err := ks.open(ct, kid, partyID)
+		if err != nil {
+			return nil, err
+		}
+		keys[partyID] = key
+	}
+	return keys, nil
+}
+
+func (ks *EncryptedKeystore) Delete(opts keyopts.Options) error {
+	return ks.ks.Delete(opts)
+}
+
+// DeleteAll deletes all keys stored under the MPC KeyID in opts and returns the number of keys deleted.
+func (ks *EncryptedKeystore) DeleteAll(opts keyopts.Options) (int, error) {
+	return ks.ks.DeleteAll(opts)
+}
+
+func (ks *EncryptedKeystore) KeyAccessor(ski string, opts keyopts.Options) keystore.KeyAccessor {
+	return &encryptedKeyAccessor{ski: ski, opts: opts, ks: ks}
+}
+
+// seal returns nonce || aead.Seal(key) with the entry of opts as associated data.
+func (ks *EncryptedKeystore) seal(key []byte, opts keyopts.Options) ([]byte, error) {
+	kid, pid, err := entryID(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, ks.aead.NonceSize(), ks.aead.NonceSize()+len(key)+ks.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("keystore: failed to generate nonce: %w", err)
+	}
+	return ks.aead.Seal(nonce, nonce, key, associatedData(kid, pid)), nil
+}
+
+func (ks *EncryptedKeystore) open(ct []byte, kid, pid string) ([]byte, error) {
+	n := ks.aead.NonceSize()
+	if len(ct) < n+ks.aead.Overhead() {
+		return nil, ErrDecryption
+	}
+	key, err := ks.aead.Open(nil, ct[:n], ct[n:], associatedData(kid, pid))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return key, nil
+}
+
+// associatedData encodes the MPC KeyID and PartyID of an entry, each prefixed by its
+// big-endian uint32 length.
+func associatedData(kid, pid string) []byte {
+	ad := make([]byte, 0, 8+len(kid)+len(pid))
+	ad = binary.BigEndian.AppendUint32(ad, uint32(len(kid)))
+	ad = append(ad, kid...)
+	ad = binary.BigEndian.AppendUint32(ad, uint32(len(pid)))
+	ad = append(ad, pid...)
+	return ad
+}
+
+func entryID(opts keyopts.Options) (string, string, error) {
+	kid, err := stringOpt(opts, "id", mem_keyopts.ErrInvalidParamsKeyID)
+	if err != nil {
+		return "", "", err
+	}
+	pid, err := stringOpt(opts, "partyid", mem_keyopts.ErrInvalidParamsPartyID)
+	if err != nil {
+		return "", "", err
+	}
+	return kid, pid, nil
+}
+
+func stringOpt(opts keyopts.Options, key string, errInvalid error) (string, error) {
+	v, ok := opts.Get(key)
+	if !ok {
+		return "", errInvalid
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errInvalid
+	}
+	return s, nil
+}
+
+type encryptedKeyAccessor struct {
+	ski  string
+	opts keyopts.Options
+	ks   *EncryptedKeystore
+}
+
+func (kls *encryptedKeyAccessor) Import(key []byte) error {
+	return kls.ks.Import(kls.ski, key, kls.opts)
+}
+
+func (kls *encryptedKeyAccessor) Get() ([]byte, error) {
+	return kls.ks.Get(kls.opts)
+}
+
+func (kls *encryptedKeyAccessor) Delete() error {
+	return kls.ks.Delete(kls.opts)
+}
@@ -0,0 +1,86 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAEAD(t *testing.T) cipher.AEAD {
+	k := make([]byte, 32)
+	_, err := rand.Read(k)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(k)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestEncryptedKeystore(t *testing.T) {
+	inner := NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	ks := NewEncryptedKeystore(inner, newTestAEAD(t))
+
+	secret := []byte("secret a")
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+	require.NoError(t, ks.Import("ski-a", secret, opts))
+
+	// round-trip
+	key, err := ks.Get(opts)
+	require.NoError(t, err)
+	assert.Equal(t, secret, key)
+	key, err = ks.KeyAccessor("ski-a", opts).Get()
+	require.NoError(t, err)
+	assert.Equal(t, secret, key)
+	all, err := ks.GetAll(opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": secret}, all)
+
+	// the wrapped keystore only holds the ciphertext
+	ct, err := inner.Get(opts)
+	require.NoError(t, err)
+	assert.NotContains(t, string(ct), string(secret))
+
+	// encrypting the same key twice uses a fresh nonce
+	require.NoError(t, ks.Update(secret, opts))
+	ct2, err := inner.Get(opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, ct, ct2)
+	key, err = ks.Get(opts)
+	require.NoError(t, err)
+	assert.Equal(t, secret, key)
+
+	// an entry moved to another KeyID or PartyID fails to decrypt
+	optsKeyID := keyopts.Options{}
+	optsKeyID.Set("id", "456", "partyid", "a")
+	require.NoError(t, inner.Import("ski-b", ct2, optsKeyID))
+	_, err = ks.Get(optsKeyID)
+	assert.ErrorIs(t, err, ErrDecryption)
+	_, err = ks.GetAll(optsKeyID)
+	assert.ErrorIs(t, err, ErrDecryption)
+
+	optsPartyID := keyopts.Options{}
+	optsPartyID.Set("id", "123", "partyid", "b")
+	require.NoError(t, inner.Import("ski-c", ct2, optsPartyID))
+	_, err = ks.Get(optsPartyID)
+	assert.ErrorIs(t, err, ErrDecryption)
+
+	// tampered ciphertext fails to decrypt
+	tampered := append([]byte{}, ct2...)
+	tampered[len(tampered)-1] ^= 1
+	require.NoError(t, inner.Update(tampered, opts))
+	_, err = ks.Get(opts)
+	assert.ErrorIs(t, err, ErrDecryption)
+
+	// Delete passes through
+	require.NoError(t, ks.Delete(opts))
+	_, err = inner.Get(opts)
+	assert.Error(t, err)
+}
@@ -2,10 +2,12 @@ package keystore
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/vault"
+	mem_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
 )
 
 var (
@@ -13,6 +15,8 @@ var (
 )
 
 type InMemoryKeystore struct {
+	// mu serializes the writes to v and kr, so that a batch is never interleaved with another write
+	mu sync.RWMutex
 	v  vault.Vault
 	kr keyopts.KeyOpts
 }
@@ -25,6 +29,14 @@ func NewInMemoryKeystore(v vault.Vault, kr keyopts.KeyOpts) *InMemoryKeystore {
 }
 
 func (ks *InMemoryKeystore) Import(ski string, key []byte, opts keyopts.Options) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	return ks.importKey(ski, key, opts)
+}
+
+// importKey imports key under ski and opts, with ks.mu held.
+func (ks *InMemoryKeystore) importKey(ski string, key []byte, opts keyopts.Options) error {
 	// store key to vault
 	if err := ks.v.Import(ski, key); err != nil {
 		return err
@@ -38,7 +50,50 @@ func (ks *InMemoryKeystore) Import(ski string, key []byte, opts keyopts.Options)
 	return nil
 }
 
+// ImportBatch imports the keys of entries indexed by SKI. If an import fails, the entries
+// imported before it are restored to their previous state, so that either all keys are
+// imported or none is. The keystore is locked for the whole batch, so that no other write lands
+// between its imports and is lost when they are undone.
+func (ks *InMemoryKeystore) ImportBatch(entries map[string]keystore.Entry) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	var undo []func()
+	for ski, e := range entries {
+		undo = append(undo, ks.snapshot(ski, e.Opts))
+		if err := ks.importKey(ski, e.Key, e.Opts); err != nil {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i]()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot returns a function restoring the key stored under ski and the key metadata stored
+// under opts to their current state. It must be called with ks.mu held.
+func (ks *InMemoryKeystore) snapshot(ski string, opts keyopts.Options) func() {
+	prevKey, errKey := ks.v.Get(ski)
+	prevData, errData := ks.kr.Get(opts)
+	return func() {
+		if errKey == nil {
+			_ = ks.v.Import(ski, prevKey)
+		} else {
+			_ = ks.v.Delete(ski)
+		}
+		if errData == nil {
+			_ = ks.kr.Import(prevData.SKI, opts)
+		} else {
+			_ = ks.kr.Delete(opts)
+		}
+	}
+}
+
 func (ks *InMemoryKeystore) Update(key []byte, opts keyopts.Options) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
 	kd, err := ks.kr.Get(opts)
 	if err != nil {
 		return err
@@ -50,6 +105,9 @@ func (ks *InMemoryKeystore) Update(key []byte, opts keyopts.Options) error {
 }
 
 func (ks *InMemoryKeystore) Get(opts keyopts.Options) ([]byte, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
 	kd, err := ks.kr.Get(opts)
 	if err != nil {
 		return nil, err
@@ -58,7 +116,31 @@ func (ks *InMemoryKeystore) Get(opts keyopts.Options) ([]byte, error) {
 	return ks.v.Get(kd.SKI)
 }
 
+// GetAll returns all keys stored under the MPC KeyID in opts, indexed by PartyID.
+func (ks *InMemoryKeystore) GetAll(opts keyopts.Options) (map[string][]byte, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kds, err := ks.kr.GetAll(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte, len(kds))
+	for partyID, kd := range kds {
+		key, err := ks.v.Get(kd.SKI)
+		if err != nil {
+			return nil, err
+		}
+		keys[partyID] = key
+	}
+	return keys, nil
+}
+
 func (ks *InMemoryKeystore) Delete(opts keyopts.Options) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
 	kd, err := ks.kr.Get(opts)
 	if err != nil {
 		return err
@@ -75,6 +157,31 @@ func (ks *InMemoryKeystore) Delete(opts keyopts.Options) error {
 	return nil
 }
 
+// DeleteAll deletes all keys stored under the MPC KeyID in opts and returns the number of keys deleted.
+func (ks *InMemoryKeystore) DeleteAll(opts keyopts.Options) (int, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	kds, err := ks.kr.GetAll(opts)
+	if errors.Is(err, mem_keyopts.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, kd := range kds {
+		if err := ks.v.Delete(kd.SKI); err != nil {
+			return 0, err
+		}
+	}
+	if err := ks.kr.DeleteAll(opts); err != nil {
+		return 0, err
+	}
+
+	return len(kds), nil
+}
+
 func (ks *InMemoryKeystore) KeyAccessor(ski string, opts keyopts.Options) keystore.KeyAccessor {
 	return NewInMemoryKeyAccessor(ski, opts, ks)
 }
@@ -0,0 +1,155 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errVaultFailure = errors.New("vault failure")
+
+// failingVault fails the n-th Import, counting from 1.
+type failingVault struct {
+	*vault.InMemoryVault
+	n, imports int
+}
+
+func (v *failingVault) Import(keyID string, key []byte) error {
+	v.imports++
+	if v.imports == v.n {
+		return errVaultFailure
+	}
+	return v.InMemoryVault.Import(keyID, key)
+}
+
+func TestInMemoryKeystore_ImportBatch(t *testing.T) {
+	optsA := keyopts.Options{}
+	optsA.Set("id", "123", "partyid", "a")
+	optsB := keyopts.Options{}
+	optsB.Set("id", "123", "partyid", "b")
+
+	ks := NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	require.NoError(t, ks.ImportBatch(map[string]keystore.Entry{
+		"ski-a": {Key: []byte("key a"), Opts: optsA},
+		"ski-b": {Key: []byte("key b"), Opts: optsB},
+	}))
+	all, err := ks.GetAll(optsA)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("key a"), "b": []byte("key b")}, all)
+}
+
+func TestInMemoryKeystore_ImportBatchFailure(t *testing.T) {
+	optsA := keyopts.Options{}
+	optsA.Set("id", "123", "partyid", "a")
+	optsB := keyopts.Options{}
+	optsB.Set("id", "123", "partyid", "b")
+	optsC := keyopts.Options{}
+	optsC.Set("id", "123", "partyid", "c")
+
+	v := &failingVault{InMemoryVault: vault.NewInMemoryVault()}
+	ks := NewInMemoryKeystore(v, keyopts.NewInMemoryKeyOpts())
+	require.NoError(t, ks.Import("ski-a", []byte("old key a"), optsA))
+
+	// the second import of the batch fails, whichever entry it is
+	v.n = v.imports + 2
+	err := ks.ImportBatch(map[string]keystore.Entry{
+		"ski-a2": {Key: []byte("new key a"), Opts: optsA},
+		"ski-b":  {Key: []byte("key b"), Opts: optsB},
+		"ski-c":  {Key: []byte("key c"), Opts: optsC},
+	})
+	require.ErrorIs(t, err, errVaultFailure)
+
+	// the keystore is left as it was before the batch
+	all, err := ks.GetAll(optsA)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("old key a")}, all)
+	for _, ski := range []string{"ski-a2", "ski-b", "ski-c"} {
+		_, err := v.Get(ski)
+		assert.ErrorIs(t, err, vault.ErrKeyNotFound, ski)
+	}
+
+	// a batch with invalid options is rolled back too
+	err = ks.ImportBatch(map[string]keystore.Entry{
+		"ski-b": {Key: []byte("key b"), Opts: optsB},
+		"ski-x": {Key: []byte("key x"), Opts: keyopts.Options{"id": "123"}},
+	})
+	require.ErrorIs(t, err, keyopts.ErrInvalidParamsPartyID)
+	_, err = ks.Get(optsB)
+	assert.Error(t, err)
+	_, err = v.Get("ski-b")
+	assert.ErrorIs(t, err, vault.ErrKeyNotFound)
+}
+
+func TestInMemoryKeystore_ImportBatchConcurrent(t *testing.T) {
+	ks := NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	invalid := keyopts.Options{"id": "123"}
+
+	N := 50
+	var wg sync.WaitGroup
+	for i := 0; i < N; i++ {
+		i := i
+		opts := keyopts.Options{}
+		opts.Set("id", "123", "partyid", fmt.Sprint(i))
+
+		wg.Add(2)
+		// a batch writing the key of party i fails and is undone...
+		go func() {
+			defer wg.Done()
+			err := ks.ImportBatch(map[string]keystore.Entry{
+				fmt.Sprintf("batch-%d", i):   {Key: []byte("batch"), Opts: opts},
+				fmt.Sprintf("invalid-%d", i): {Key: []byte("invalid"), Opts: invalid},
+			})
+			assert.Error(t, err)
+		}()
+		// ...while the key of party i is imported on its own, which the undo must not lose
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, ks.Import(fmt.Sprintf("import-%d", i), []byte("import"), opts))
+		}()
+	}
+	wg.Wait()
+
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "0")
+	all, err := ks.GetAll(opts)
+	require.NoError(t, err)
+	assert.Len(t, all, N)
+	for partyID, key := range all {
+		assert.Equal(t, []byte("import"), key, partyID)
+	}
+}
+
+func TestConsume_Concurrent(t *testing.T) {
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+
+	ks := NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	require.NoError(t, ks.Import("ski", []byte("nonce"), opts))
+
+	const n = 16
+	var wg sync.WaitGroup
+	var consumed int32
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if key, err := keystore.Consume(ks, opts); err == nil {
+				assert.Equal(t, []byte("nonce"), key)
+				atomic.AddInt32(&consumed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, consumed, "the key is consumed once")
+
+	_, err := ks.Get(opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package keystore
+
+import (
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+)
+
+// PurgeSession deletes all keys stored under the MPC KeyID and the protocol of opts from each of
+// the stores, and returns the total number of keys deleted.
+func PurgeSession(opts keyopts.Options, stores ...keystore.Keystore) (int, error) {
+	total := 0
+	for _, s := range stores {
+		n, err := s.DeleteAll(opts)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,75 @@
+package keystore
+
+import (
+	"errors"
+
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+)
+
+var (
+	ErrReadOnly = errors.New("keystore: permission denied, keystore is read-only")
+)
+
+// ReadOnlyKeystore wraps a Keystore to give access to its keys without the ability to
+// modify them, e.g. for auditors. Import, ImportBatch, Update, Delete and DeleteAll return
+// ErrReadOnly.
+type ReadOnlyKeystore struct {
+	ks keystore.Keystore
+}
+
+var _ keystore.Keystore = (*ReadOnlyKeystore)(nil)
+
+func NewReadOnlyKeystore(ks keystore.Keystore) *ReadOnlyKeystore {
+	return &ReadOnlyKeystore{ks: ks}
+}
+
+func (ks *ReadOnlyKeystore) Import(string, []byte, keyopts.Options) error {
+	return ErrReadOnly
+}
+
+func (ks *ReadOnlyKeystore) ImportBatch(map[string]keystore.Entry) error {
+	return ErrReadOnly
+}
+
+func (ks *ReadOnlyKeystore) Update([]byte, keyopts.Options) error {
+	return ErrReadOnly
+}
+
+func (ks *ReadOnlyKeystore) Get(opts keyopts.Options) ([]byte, error) {
+	return ks.ks.Get(opts)
+}
+
+// GetAll returns all keys stored under the MPC KeyID in opts, indexed by PartyID.
+func (ks *ReadOnlyKeystore) GetAll(opts keyopts.Options) (map[string][]byte, error) {
+	return ks.ks.GetAll(opts)
+}
+
+func (ks *ReadOnlyKeystore) Delete(keyopts.Options) error {
+	return ErrReadOnly
+}
+
+func (ks *ReadOnlyKeystore) DeleteAll(keyopts.Options) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (ks *ReadOnlyKeystore) KeyAccessor(ski string, opts keyopts.Options) keystore.KeyAccessor {
+	return &readOnlyKeyAccessor{opts: opts, ks: ks}
+}
+
+type readOnlyKeyAccessor struct {
+	opts keyopts.Options
+	ks   *ReadOnlyKeystore
+}
+
+func (kls *readOnlyKeyAccessor) Import([]byte) error {
+	return ErrReadOnly
+}
+
+func (kls *readOnlyKeyAccessor) Get() ([]byte, error) {
+	return kls.ks.Get(kls.opts)
+}
+
+func (kls *readOnlyKeyAccessor) Delete() error {
+	return ErrReadOnly
+}
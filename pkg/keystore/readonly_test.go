@@ -0,0 +1,47 @@
+package keystore
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyKeystore(t *testing.T) {
+	ks := NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	opts := keyopts.Options{}
+	opts.Set("id", "123", "partyid", "a")
+	require.NoError(t, ks.Import("ski-a", []byte("public a"), opts))
+
+	ro := NewReadOnlyKeystore(ks)
+
+	key, err := ro.Get(opts)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("public a"), key)
+	key, err = ro.KeyAccessor("ski-a", opts).Get()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("public a"), key)
+	all, err := ro.GetAll(opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("public a")}, all)
+
+	optsB := keyopts.Options{}
+	optsB.Set("id", "123", "partyid", "b")
+	assert.ErrorIs(t, ro.Import("ski-b", []byte("public b"), optsB), ErrReadOnly)
+	assert.ErrorIs(t, ro.KeyAccessor("ski-b", optsB).Import([]byte("public b")), ErrReadOnly)
+	assert.ErrorIs(t, ro.Update([]byte("tampered"), opts), ErrReadOnly)
+	assert.ErrorIs(t, ro.Delete(opts), ErrReadOnly)
+	assert.ErrorIs(t, ro.KeyAccessor("ski-a", opts).Delete(), ErrReadOnly)
+	n, err := ro.DeleteAll(opts)
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.Zero(t, n)
+
+	// the underlying keystore is left untouched
+	key, err = ks.Get(opts)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("public a"), key)
+	_, err = ks.Get(optsB)
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"hash"
+
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 )
@@ -16,6 +18,10 @@ type KeyConfig interface {
 	Threshold() int
 	SelfID() party.ID
 	PartyIDs() party.IDSlice
+	// VSSDegree is the degree of the secret sharing polynomial, which is at least Threshold.
+	VSSDegree() int
+	// MaxParties is the maximum number of parties allowed in the session, or 0 for the default.
+	MaxParties() int
 }
 
 type KeyConfigManager interface {
@@ -31,6 +37,19 @@ type SignConfig interface {
 	SelfID() party.ID
 	PartyIDs() party.IDSlice
 	Message() []byte
+	// ChallengeHash returns the constructor of the hash used to derive Schnorr challenges,
+	// or nil if the protocol's default should be used.
+	ChallengeHash() func() hash.Hash
+	// MessageHash returns the constructor of the hash applied to Message to obtain the digest
+	// to sign, or nil if Message already is that digest.
+	MessageHash() func() hash.Hash
+	// MaxParties is the maximum number of parties allowed in the session, or 0 for the default.
+	MaxParties() int
+	// DeterministicNonces returns true if the nonces of the session are derived from the secret
+	// share and the message per RFC 6979, instead of being sampled.
+	DeterministicNonces() bool
+	// Progress returns the channel on which the session reports its progress, or nil.
+	Progress() chan<- ProgressEvent
 }
 
 type SignConfigManager interface {
@@ -0,0 +1,38 @@
+package config
+
+import "github.com/mr-shifu/mpc-lib/lib/round"
+
+// ProgressStatus is the state of a session at a round boundary.
+type ProgressStatus int
+
+const (
+	// ProgressRoundCompleted is reported when a round was finalized and the next one started.
+	ProgressRoundCompleted ProgressStatus = iota
+	// ProgressDone is reported when the final round produced the result of the session.
+	ProgressDone
+	// ProgressAborted is reported when a round aborted the session.
+	ProgressAborted
+)
+
+func (s ProgressStatus) String() string {
+	switch s {
+	case ProgressRoundCompleted:
+		return "round completed"
+	case ProgressDone:
+		return "done"
+	case ProgressAborted:
+		return "aborted"
+	default:
+		return "unknown"
+	}
+}
+
+// ProgressEvent is emitted each time a round of a session is finalized.
+type ProgressEvent struct {
+	// ID is the ID of the session.
+	ID string
+	// Round is the number of the round which was finalized.
+	Round round.Number
+	// Status is the state of the session after that round.
+	Status ProgressStatus
+}
@@ -19,4 +19,9 @@ type MessageManager interface {
 	Get(keyID string, round int, partyID string) (Message, error)
 	GetAll(keyID string, round int) (map[string]Message, error)
 	HasAll(keyID string, round int, partyIDs []string) (bool, error)
+	// Reserve reserves the message of partyID in round for keyID, and reports whether it was
+	// neither imported nor reserved before. Release frees a reservation whose message was not
+	// imported.
+	Reserve(keyID string, round int, partyID string) bool
+	Release(keyID string, round int, partyID string)
 }
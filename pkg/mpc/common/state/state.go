@@ -1,5 +1,16 @@
 package state
 
+import "errors"
+
+var (
+	// ErrStateExists is returned when starting a session whose ID is already in use.
+	ErrStateExists = errors.New("state: session already exists")
+	// ErrStateFinished is returned when resuming a session that was already aborted or completed.
+	ErrStateFinished = errors.New("state: session already finished")
+	// ErrSnapshotVersion is returned when restoring a snapshot of an unknown version.
+	ErrSnapshotVersion = errors.New("state: unsupported snapshot version")
+)
+
 type State interface {
 	ID() string
 	LastRound() int
@@ -22,4 +33,10 @@ type MPCStateManager interface {
 	SetAborted(ID string) error
 	SetCompleted(ID string) error
 	Get(ID string) (State, error)
+
+	// Snapshot serializes the state of session ID, so that it can be restored after a crash.
+	Snapshot(ID string) ([]byte, error)
+
+	// Restore imports a session state serialized by Snapshot.
+	Restore(data []byte) error
 }
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/merkle"
+	comm_cfg "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+)
+
+// Algorithm identifies the signature scheme a key in a Bundle is used for.
+type Algorithm string
+
+const (
+	// AlgorithmECDSA is a CMP threshold ECDSA key over secp256k1.
+	AlgorithmECDSA Algorithm = "ecdsa"
+	// AlgorithmEdDSA is a FROST threshold EdDSA key over Ed25519.
+	AlgorithmEdDSA Algorithm = "eddsa"
+)
+
+var (
+	ErrBundleConfigNotFound = errors.New("bundle: config not found")
+	ErrBundleSignerNotFound = errors.New("bundle: signer not found")
+)
+
+// Signer starts a signing protocol for a sign config, e.g. *cmp.MPC or *frost.FROST.
+type Signer interface {
+	Sign(cfg comm_cfg.SignConfig, pl *pool.Pool) protocol.StartFunc
+}
+
+// Bundle holds the key configs of a single party set for multiple signature algorithms,
+// and dispatches signing requests to the Signer registered for each algorithm.
+type Bundle struct {
+	lock    sync.RWMutex
+	configs map[Algorithm]comm_cfg.KeyConfig
+	signers map[Algorithm]Signer
+}
+
+type rawBundleConfig struct {
+	KeyID      string
+	Group      string
+	Threshold  int
+	SelfID     party.ID
+	PartyIDs   party.IDSlice
+	VSSDegree  int
+	MaxParties int
+}
+
+func NewBundle() *Bundle {
+	return &Bundle{
+		configs: make(map[Algorithm]comm_cfg.KeyConfig),
+		signers: make(map[Algorithm]Signer),
+	}
+}
+
+// AddConfig adds the key config for algo to the bundle. All configs of a bundle
+// must belong to the same party and party set.
+func (b *Bundle) AddConfig(algo Algorithm, cfg comm_cfg.KeyConfig) error {
+	if cfg == nil {
+		return errors.New("bundle: nil config")
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for other, c := range b.configs {
+		if c.SelfID() != cfg.SelfID() || !samePartySet(c.PartyIDs(), cfg.PartyIDs()) {
+			return fmt.Errorf("bundle: config for %s does not match party set of %s", algo, other)
+		}
+	}
+	b.configs[algo] = cfg
+
+	return nil
+}
+
+// Config returns the key config for algo.
+func (b *Bundle) Config(algo Algorithm) (comm_cfg.KeyConfig, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	cfg, ok := b.configs[algo]
+	if !ok {
+		return nil, ErrBundleConfigNotFound
+	}
+	return cfg, nil
+}
+
+// Algorithms returns the algorithms for which the bundle holds a config.
+func (b *Bundle) Algorithms() []Algorithm {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	algos := make([]Algorithm, 0, len(b.configs))
+	for algo := range b.configs {
+		algos = append(algos, algo)
+	}
+	return algos
+}
+
+// RegisterSigner sets the Signer used to sign with the key of algo.
+func (b *Bundle) RegisterSigner(algo Algorithm, signer Signer) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.signers[algo] = signer
+}
+
+// Sign starts signing message with the key of algo. signID identifies the signing session
+// and must be the same for all signers.
+func (b *Bundle) Sign(algo Algorithm, signID string, message []byte, pl *pool.Pool) (protocol.StartFunc, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	cfg, ok := b.configs[algo]
+	if !ok {
+		return nil, ErrBundleConfigNotFound
+	}
+	signer, ok := b.signers[algo]
+	if !ok {
+		return nil, ErrBundleSignerNotFound
+	}
+
+	signcfg := NewSignConfig(signID, cfg.ID(), cfg.Group(), cfg.Threshold(), cfg.SelfID(), cfg.PartyIDs(), message).
+		WithMaxParties(cfg.MaxParties())
+	return signer.Sign(signcfg, pl), nil
+}
+
+// SignBatch starts signing the merkle root of messages with the key of algo, so that a single
+// signature authorizes the whole batch. The returned tree provides the inclusion proof of each
+// message, to be verified against the signed root with merkle.Verify.
+func (b *Bundle) SignBatch(algo Algorithm, signID string, messages [][]byte, pl *pool.Pool) (protocol.StartFunc, *merkle.Tree, error) {
+	tree, err := merkle.New(messages)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start, err := b.Sign(algo, signID, tree.Root(), pl)
+	if err != nil {
+		return nil, nil, err
+	}
+	return start, tree, nil
+}
+
+// MarshalBinary encodes the key configs of the bundle. Signers are not encoded and must be
+// registered again after unmarshalling.
+func (b *Bundle) MarshalBinary() ([]byte, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	raw := make(map[Algorithm]rawBundleConfig, len(b.configs))
+	for algo, cfg := range b.configs {
+		raw[algo] = rawBundleConfig{
+			KeyID:      cfg.ID(),
+			Group:      cfg.Group().Name(),
+			Threshold:  cfg.Threshold(),
+			SelfID:     cfg.SelfID(),
+			PartyIDs:   cfg.PartyIDs(),
+			VSSDegree:  cfg.VSSDegree(),
+			MaxParties: cfg.MaxParties(),
+		}
+	}
+	return cbor.Marshal(raw)
+}
+
+// UnmarshalBinary replaces the key configs of the bundle with the decoded ones.
+func (b *Bundle) UnmarshalBinary(data []byte) error {
+	raw := make(map[Algorithm]rawBundleConfig)
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	configs := make(map[Algorithm]comm_cfg.KeyConfig, len(raw))
+	for algo, rc := range raw {
+		group, err := groupByName(rc.Group)
+		if err != nil {
+			return err
+		}
+		cfg, err := NewKeyConfig(rc.KeyID, group, rc.Threshold, rc.SelfID, party.NewIDSlice(rc.PartyIDs)).WithVSSDegree(rc.VSSDegree)
+		if err != nil {
+			return err
+		}
+		configs[algo] = cfg.WithMaxParties(rc.MaxParties)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.configs = configs
+
+	return nil
+}
+
+func groupByName(name string) (curve.Curve, error) {
+	switch name {
+	case curve.Secp256k1{}.Name():
+		return curve.Secp256k1{}, nil
+	}
+	return nil, fmt.Errorf("bundle: unsupported group %q", name)
+}
+
+func samePartySet(a, b party.IDSlice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return party.NewIDSlice(a).Contains(b...)
+}
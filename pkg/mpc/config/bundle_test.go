@@ -0,0 +1,150 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
+	result "github.com/mr-shifu/mpc-lib/pkg/mpc/result/eddsa"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+	"github.com/mr-shifu/mpc-lib/protocols/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stores struct {
+	ksf            *keystore.InmemoryKeystoreFactory
+	krf            *keyopts.InMemoryKeyOptsFactory
+	vf             *vault.InmemoryVaultFactory
+	keycfgstore    *config.InMemoryConfigStore
+	signcfgstore   *config.InMemoryConfigStore
+	keystatestore  *state.InMemoryStateStore
+	signstatestore *state.InMemoryStateStore
+	msgstore       *message.InMemoryMessageStore
+	bcststore      *message.InMemoryMessageStore
+}
+
+func newStores() stores {
+	return stores{
+		ksf:            &keystore.InmemoryKeystoreFactory{},
+		krf:            &keyopts.InMemoryKeyOptsFactory{},
+		vf:             &vault.InmemoryVaultFactory{},
+		keycfgstore:    config.NewInMemoryConfigStore(),
+		signcfgstore:   config.NewInMemoryConfigStore(),
+		keystatestore:  state.NewInMemoryStateStore(),
+		signstatestore: state.NewInMemoryStateStore(),
+		msgstore:       message.NewInMemoryMessageStore(),
+		bcststore:      message.NewInMemoryMessageStore(),
+	}
+}
+
+func run(t *testing.T, id party.ID, start protocol.StartFunc, n *test.Network) interface{} {
+	h, err := protocol.NewMultiHandler(start, nil)
+	require.NoError(t, err)
+	test.HandlerLoop(id, h, n)
+	r, err := h.Result()
+	require.NoError(t, err)
+	return r
+}
+
+func do(t *testing.T, id party.ID, ids party.IDSlice, ecdsaKeyID, eddsaKeyID, signID string, msg []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	threshold := len(ids) - 1
+
+	s := newStores()
+	mpc := cmp.NewMPC(s.ksf, s.krf, s.vf, s.keycfgstore, s.signcfgstore, s.keystatestore, s.signstatestore, s.msgstore, s.bcststore, pl)
+	s = newStores()
+	fr := frost.NewFROST(s.ksf, s.krf, s.vf, s.keycfgstore, s.signcfgstore, s.keystatestore, s.signstatestore, s.msgstore, s.bcststore, pl)
+
+	ecdsaCfg := config.NewKeyConfig(ecdsaKeyID, curve.Secp256k1{}, threshold, id, ids)
+	r := run(t, id, mpc.Keygen(ecdsaCfg, pl), n)
+	require.IsType(t, &cmp.KeygenResult{}, r)
+	cmpCfg := r.(*cmp.KeygenResult).Config
+
+	eddsaCfg := config.NewKeyConfig(eddsaKeyID, curve.Secp256k1{}, threshold, id, ids)
+	r = run(t, id, fr.Keygen(eddsaCfg, pl), n)
+	require.IsType(t, &frost.Config{}, r)
+	frostCfg := r.(*frost.Config)
+
+	bundle := config.NewBundle()
+	require.NoError(t, bundle.AddConfig(config.AlgorithmECDSA, ecdsaCfg))
+	require.NoError(t, bundle.AddConfig(config.AlgorithmEdDSA, eddsaCfg))
+
+	// save and load the bundle before signing
+	data, err := bundle.MarshalBinary()
+	require.NoError(t, err)
+	loaded := config.NewBundle()
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.ElementsMatch(t, bundle.Algorithms(), loaded.Algorithms())
+	loaded.RegisterSigner(config.AlgorithmECDSA, mpc)
+	loaded.RegisterSigner(config.AlgorithmEdDSA, fr)
+
+	start, err := loaded.Sign(config.AlgorithmECDSA, signID+"-ecdsa", msg, pl)
+	require.NoError(t, err)
+	r = run(t, id, start, n)
+	require.IsType(t, &ecdsa.Signature{}, r)
+	assert.True(t, r.(*ecdsa.Signature).Verify(cmpCfg.PublicPoint(), msg))
+
+	start, err = loaded.Sign(config.AlgorithmEdDSA, signID+"-eddsa", msg, pl)
+	require.NoError(t, err)
+	r = run(t, id, start, n)
+	require.IsType(t, &result.EddsaSignature{}, r)
+	sig := r.(*result.EddsaSignature)
+	assert.True(t, ed25519.Verify(frostCfg.PublicKey.Bytes(), msg, append(sig.R().Bytes(), sig.Z().Bytes()...)))
+	assert.False(t, ed25519.Verify(frostCfg.PublicKey.Bytes(), []byte("other"), append(sig.R().Bytes(), sig.Z().Bytes()...)))
+}
+
+func TestBundle_Sign(t *testing.T) {
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	msg := sha256.Sum256([]byte("hello"))
+
+	ecdsaKeyID := uuid.New().String()
+	eddsaKeyID := uuid.New().String()
+	signID := uuid.New().String()
+
+	n := test.NewNetwork(partyIDs)
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(0)
+		defer pl.TearDown()
+		go do(t, id, partyIDs, ecdsaKeyID, eddsaKeyID, signID, msg[:], pl, n, &wg)
+	}
+	wg.Wait()
+}
+
+func TestBundle_AddConfig(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+
+	bundle := config.NewBundle()
+	require.NoError(t, bundle.AddConfig(config.AlgorithmECDSA, config.NewKeyConfig("a", curve.Secp256k1{}, 2, partyIDs[0], partyIDs)))
+
+	// different party set
+	err := bundle.AddConfig(config.AlgorithmEdDSA, config.NewKeyConfig("b", curve.Secp256k1{}, 1, partyIDs[0], partyIDs[:2]))
+	assert.Error(t, err)
+
+	// unknown algorithm
+	_, err = bundle.Sign(config.AlgorithmEdDSA, "sign", []byte("hello"), nil)
+	assert.ErrorIs(t, err, config.ErrBundleConfigNotFound)
+
+	// no signer registered
+	_, err = bundle.Sign(config.AlgorithmECDSA, "sign", []byte("hello"), nil)
+	assert.ErrorIs(t, err, config.ErrBundleSignerNotFound)
+}
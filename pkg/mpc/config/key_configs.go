@@ -1,16 +1,20 @@
 package config
 
 import (
+	"fmt"
+
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 )
 
 type KeyConfig struct {
-	keyID     string
-	group     curve.Curve
-	threshold int
-	selfID    party.ID
-	partyIDs  party.IDSlice
+	keyID      string
+	group      curve.Curve
+	threshold  int
+	selfID     party.ID
+	partyIDs   party.IDSlice
+	vssDegree  int
+	maxParties int
 }
 
 func NewKeyConfig(
@@ -26,7 +30,22 @@ func NewKeyConfig(
 		threshold: threshold,
 		selfID:    selfID,
 		partyIDs:  partyIDs,
+		vssDegree: threshold,
+	}
+}
+
+// WithVSSDegree sets the degree of the secret sharing polynomial independently of the threshold.
+// The degree must be at least the threshold, and at most N-1 so that the secret can still be
+// reconstructed by all parties.
+func (c *KeyConfig) WithVSSDegree(degree int) (*KeyConfig, error) {
+	if degree < c.threshold {
+		return nil, fmt.Errorf("config: vss degree %d is lower than threshold %d", degree, c.threshold)
+	}
+	if degree >= len(c.partyIDs) {
+		return nil, fmt.Errorf("config: vss degree %d is invalid for number of parties %d", degree, len(c.partyIDs))
 	}
+	c.vssDegree = degree
+	return c, nil
 }
 
 func (c *KeyConfig) ID() string {
@@ -48,3 +67,19 @@ func (c *KeyConfig) SelfID() party.ID {
 func (c *KeyConfig) PartyIDs() party.IDSlice {
 	return c.partyIDs
 }
+
+func (c *KeyConfig) VSSDegree() int {
+	return c.vssDegree
+}
+
+// WithMaxParties sets the maximum number of parties allowed in a session using this config.
+func (c *KeyConfig) WithMaxParties(n int) *KeyConfig {
+	c.maxParties = n
+	return c
+}
+
+// MaxParties returns the maximum number of parties allowed in a session using this config,
+// or 0 if the default applies.
+func (c *KeyConfig) MaxParties() int {
+	return c.maxParties
+}
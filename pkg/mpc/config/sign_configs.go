@@ -1,8 +1,11 @@
 package config
 
 import (
+	"hash"
+
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	comm_cfg "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 )
 
 type SignConfig struct {
@@ -13,6 +16,14 @@ type SignConfig struct {
 	selfID    party.ID
 	partyIDs  party.IDSlice
 	message   []byte
+
+	challengeHash func() hash.Hash
+	messageHash   func() hash.Hash
+	maxParties    int
+
+	deterministicNonces bool
+
+	progress chan<- comm_cfg.ProgressEvent
 }
 
 func NewSignConfig(
@@ -35,6 +46,20 @@ func NewSignConfig(
 	}
 }
 
+// WithChallengeHash sets the hash used to derive Schnorr challenges, overriding the
+// default of the signing protocol.
+func (c *SignConfig) WithChallengeHash(h func() hash.Hash) *SignConfig {
+	c.challengeHash = h
+	return c
+}
+
+// WithMessageHash sets the hash applied to the message before it is signed, for instance
+// Keccak-256 for Ethereum transactions. Without it, the message must already be a digest.
+func (c *SignConfig) WithMessageHash(h func() hash.Hash) *SignConfig {
+	c.messageHash = h
+	return c
+}
+
 func (c *SignConfig) ID() string {
 	return c.id
 }
@@ -62,3 +87,52 @@ func (c *SignConfig) PartyIDs() party.IDSlice {
 func (c *SignConfig) Message() []byte {
 	return c.message
 }
+
+func (c *SignConfig) ChallengeHash() func() hash.Hash {
+	return c.challengeHash
+}
+
+func (c *SignConfig) MessageHash() func() hash.Hash {
+	return c.messageHash
+}
+
+// WithMaxParties sets the maximum number of parties allowed in a session using this config.
+func (c *SignConfig) WithMaxParties(n int) *SignConfig {
+	c.maxParties = n
+	return c
+}
+
+// MaxParties returns the maximum number of parties allowed in a session using this config,
+// or 0 if the default applies.
+func (c *SignConfig) MaxParties() int {
+	return c.maxParties
+}
+
+// WithDeterministicNonces derives the nonces of the session from the secret share and the
+// message per RFC 6979 instead of sampling them, for reproducible test vectors and audits.
+// A party then reuses its nonces whenever the same signers sign the same message, which other
+// signers could exploit by changing their own nonces between sessions, so this must not be
+// used in production.
+func (c *SignConfig) WithDeterministicNonces() *SignConfig {
+	c.deterministicNonces = true
+	return c
+}
+
+// DeterministicNonces returns true if the nonces of a session using this config are derived
+// per RFC 6979.
+func (c *SignConfig) DeterministicNonces() bool {
+	return c.deterministicNonces
+}
+
+// WithProgress makes a session using this config report its progress on ch, each time one of its
+// rounds is finalized. Events are sent without blocking, so they are dropped while ch is full; a
+// buffered channel should be used to receive all of them.
+func (c *SignConfig) WithProgress(ch chan<- comm_cfg.ProgressEvent) *SignConfig {
+	c.progress = ch
+	return c
+}
+
+// Progress returns the channel on which a session using this config reports its progress, or nil.
+func (c *SignConfig) Progress() chan<- comm_cfg.ProgressEvent {
+	return c.progress
+}
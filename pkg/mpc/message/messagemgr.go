@@ -1,14 +1,29 @@
 package message
 
-import com_msg "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+import (
+	"sync"
+
+	com_msg "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+)
 
 type MessageManager struct {
 	store com_msg.MessageStore
+
+	lock sync.Mutex
+	// reserved holds the messages being stored by a round, by ID, round number and party ID
+	reserved map[reservation]struct{}
+}
+
+type reservation struct {
+	id      string
+	round   int
+	partyID string
 }
 
 func NewMessageManager(store com_msg.MessageStore) *MessageManager {
 	return &MessageManager{
-		store: store,
+		store:    store,
+		reserved: make(map[reservation]struct{}),
 	}
 }
 
@@ -22,7 +37,14 @@ func (m *MessageManager) NewMessage(id string, round int, partyID string, verifi
 }
 
 func (m *MessageManager) Import(msg com_msg.Message) error {
-	return m.store.Import(msg)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.store.Import(msg); err != nil {
+		return err
+	}
+	delete(m.reserved, reservation{msg.ID(), msg.Round(), msg.PartyID()})
+	return nil
 }
 
 func (m *MessageManager) Get(keyID string, round int, partyID string) (com_msg.Message, error) {
@@ -47,3 +69,26 @@ func (m *MessageManager) HasAll(keyID string, round int, partyIDs []string) (boo
 
 	return true, nil
 }
+
+func (m *MessageManager) Reserve(keyID string, round int, partyID string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// the store only fails to get a message which was not imported
+	if _, err := m.store.Get(keyID, round, partyID); err == nil {
+		return false
+	}
+	r := reservation{keyID, round, partyID}
+	if _, ok := m.reserved[r]; ok {
+		return false
+	}
+	m.reserved[r] = struct{}{}
+	return true
+}
+
+func (m *MessageManager) Release(keyID string, round int, partyID string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.reserved, reservation{keyID, round, partyID})
+}
@@ -0,0 +1,62 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	com_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
+)
+
+// SnapshotVersion is the version of the encoding produced by Snapshot.
+const SnapshotVersion = 1
+
+// snapshot is the encoding of the state of a session.
+//
+// The keys of the session are not copied: every key manager stores them in its keystore under
+// the session ID, which is the reference a restored session uses to find them again.
+type snapshot struct {
+	Version   uint8
+	ID        string
+	LastRound int
+	Aborted   bool
+	Completed bool
+}
+
+// Snapshot serializes the state of session ID, so that it can be restored after a crash.
+func (mgr *MPCStateManager) Snapshot(ID string) ([]byte, error) {
+	state, err := mgr.store.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cbor.Marshal(&snapshot{
+		Version:   SnapshotVersion,
+		ID:        state.ID(),
+		LastRound: state.LastRound(),
+		Aborted:   state.Aborted(),
+		Completed: state.Completed(),
+	})
+}
+
+// Restore imports a session state serialized by Snapshot, replacing the state of the session
+// with the same ID if there is one.
+func (mgr *MPCStateManager) Restore(data []byte) error {
+	var snap snapshot
+	if err := cbor.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("state: failed to unmarshal snapshot: %w", err)
+	}
+	if snap.Version != SnapshotVersion {
+		return fmt.Errorf("%w: %d", com_state.ErrSnapshotVersion, snap.Version)
+	}
+	if snap.ID == "" {
+		return errors.New("state: snapshot has no session ID")
+	}
+
+	return mgr.Import(&State{
+		id:        snap.ID,
+		lastRound: snap.LastRound,
+		aborted:   snap.Aborted,
+		completed: snap.Completed,
+	})
+}
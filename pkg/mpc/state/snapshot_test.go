@@ -0,0 +1,34 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	com_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMPCStateManager_Snapshot(t *testing.T) {
+	mgr := NewMPCStateManager(NewInMemoryStateStore())
+	require.NoError(t, mgr.NewState("session"))
+	require.NoError(t, mgr.SetLastRound("session", 2))
+
+	data, err := mgr.Snapshot("session")
+	require.NoError(t, err)
+
+	restored := NewMPCStateManager(NewInMemoryStateStore())
+	require.NoError(t, restored.Restore(data))
+	s, err := restored.Get("session")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.LastRound())
+	assert.False(t, s.Aborted())
+	assert.False(t, s.Completed())
+
+	_, err = mgr.Snapshot("unknown")
+	assert.Error(t, err)
+
+	future, err := cbor.Marshal(&snapshot{Version: SnapshotVersion + 1, ID: "session"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, restored.Restore(future), com_state.ErrSnapshotVersion)
+}
@@ -28,6 +28,7 @@ import (
 	sw_pedersen "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
 	sw_rid "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	sw_vss "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
+	mem_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	comm_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	comm_message "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 	comm_result "github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
@@ -77,6 +78,16 @@ type MPC struct {
 	sigma     comm_result.SigmaStore
 	signature comm_result.Signature
 
+	// presigs holds the presignatures until they are consumed by SignOnline. It is created by the
+	// keystore factory, so that a persistent keystore keeps a consumed presignature consumed across
+	// restarts.
+	presigs keystore.Keystore
+
+	// worker generates the proofs of the first round of signing, see WithRemoteProofWorker.
+	worker sign.RemoteProofWorker
+	// redundancy is checked by keygen before a key is generated, see WithRedundancyPolicy.
+	redundancy keygen.RedundancyPolicy
+
 	pl *pool.Pool
 }
 
@@ -110,7 +121,7 @@ func NewMPC(
 	vss_kr := krf.NewKeyOpts(nil)
 	vss_vault := vf.NewVault(nil)
 	vss_ks := ksf.NewKeystore(vss_vault, vss_kr, nil)
-	vss_km := sw_vss.NewVssKeyManager(vss_ks, curve.Secp256k1{})
+	vss_km := sw_vss.NewVssKeyManager(vss_ks, curve.Secp256k1{}).WithBasisCache(2)
 
 	ec_kr := krf.NewKeyOpts(nil)
 	ec_vault := vf.NewVault(nil)
@@ -123,6 +134,7 @@ func NewMPC(
 	ec_vss_kr := krf.NewKeyOpts(nil)
 	ec_vss_ks := ksf.NewKeystore(ec_vault, ec_vss_kr, nil)
 	ec_vss_km := sw_ecdsa.NewECDSAKeyManager(ec_vss_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
+	vss_km.WithShareManager(ec_vss_km)
 
 	rid_kr := krf.NewKeyOpts(nil)
 	rid_vault := vf.NewVault(nil)
@@ -160,6 +172,10 @@ func NewMPC(
 
 	signature := mpc_result.NewSignStore()
 
+	presig_kr := krf.NewKeyOpts(nil)
+	presig_vault := vf.NewVault(nil)
+	presig_ks := ksf.NewKeystore(presig_vault, presig_kr, nil)
+
 	gamma_kr := krf.NewKeyOpts(nil)
 	gamma_ks := ksf.NewKeystore(ec_vault, gamma_kr, nil)
 	gamma_km := sw_ecdsa.NewECDSAKeyManager(gamma_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
@@ -228,12 +244,27 @@ func NewMPC(
 		chi_mta:     chi_mta_km,
 		sigma:       sigma,
 		signature:   signature,
+		presigs:     presig_ks,
 		pl:          pl,
 	}
 }
 
+// WithRemoteProofWorker delegates the generation of the proofs of the first round of signing to w,
+// see sign.RemoteProofWorker. It must be called before the MPC is used.
+func (mpc *MPC) WithRemoteProofWorker(w sign.RemoteProofWorker) *MPC {
+	mpc.worker = w
+	return mpc
+}
+
+// WithRedundancyPolicy makes keygen reject the keys which violate p, see keygen.RedundancyPolicy.
+// It must be called before the MPC is used.
+func (mpc *MPC) WithRedundancyPolicy(p keygen.RedundancyPolicy) *MPC {
+	mpc.redundancy = p
+	return mpc
+}
+
 func (mpc *MPC) NewMPCKeygenManager() *keygen.MPCKeygen {
-	return keygen.NewMPCKeygen(
+	mpckg := keygen.NewMPCKeygen(
 		mpc.keycfgmgr,
 		mpc.keystatmgr,
 		mpc.msgmgr,
@@ -250,10 +281,12 @@ func (mpc *MPC) NewMPCKeygenManager() *keygen.MPCKeygen {
 		mpc.commit_mgr,
 		mpc.pl,
 	)
+	mpckg.SetRedundancyPolicy(mpc.redundancy)
+	return mpckg
 }
 
 func (mpc *MPC) NewMPCSignManager() *sign.MPCSign {
-	return sign.NewMPCSign(
+	mpcsign := sign.NewMPCSign(
 		mpc.signcfgmgr,
 		mpc.signstatmgr,
 		mpc.msgmgr,
@@ -275,13 +308,23 @@ func (mpc *MPC) NewMPCSignManager() *sign.MPCSign {
 		mpc.chi_mta,
 		mpc.sigma,
 		mpc.signature,
+		mpc.presigs,
 	)
+	mpcsign.SetRemoteProofWorker(mpc.worker)
+	return mpcsign
 }
 
 // Config represents the stored state of a party who participated in a successful `Keygen` protocol.
 // It contains secret key material and should be safely stored.
 type Config = config.Config
 
+// PublicConfig is the public part of a Config, which is enough to verify the signatures of the key.
+type PublicConfig = config.PublicConfig
+
+// KeygenResult is the result of a successful `Keygen` protocol, holding the new Config together
+// with the final SSID and the Schnorr proofs of all parties.
+type KeygenResult = keygen.KeygenResult
+
 // EmptyConfig creates an empty Config with a fixed group, ready for unmarshalling.
 //
 // This needs to be used for unmarshalling, otherwise the points on the curve can't
@@ -302,9 +345,59 @@ func (mpc *MPC) Keygen(cfg comm_config.KeyConfig, pl *pool.Pool) protocol.StartF
 	return mpckg.Start(cfg, pl)
 }
 
+// ResumeKeygen continues the keygen session cfg.ID() from its last finalized round, see
+// keygen.MPCKeygen.Resume.
+func (mpc *MPC) ResumeKeygen(cfg comm_config.KeyConfig, pl *pool.Pool) protocol.StartFunc {
+	mpckg := mpc.NewMPCKeygenManager()
+	return mpckg.Resume(cfg, pl)
+}
+
+// PurgeSession deletes the keys stored under the session id by any of the key managers,
+// e.g. to clean up after an aborted keygen or sign session. It returns the number of keys deleted.
+func (mpc *MPC) PurgeSession(id string) (int, error) {
+	mgrs := []interface{}{
+		mpc.elgamal, mpc.paillier, mpc.pedersen, mpc.ec, mpc.ec_vss, mpc.rid, mpc.chainKey,
+		mpc.hash_mgr, mpc.commit_mgr, mpc.vss_mgr,
+		mpc.gamma, mpc.signK, mpc.delta, mpc.chi, mpc.bigDelta,
+		mpc.gamma_pek, mpc.signK_pek, mpc.delta_mta, mpc.chi_mta,
+	}
+	opts, err := mem_keyopts.NewOptions().Set("protocol", mem_keyopts.ProtocolCMP, "id", id)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, m := range mgrs {
+		km, ok := m.(keystore.KeyManager)
+		if !ok {
+			continue
+		}
+		n, err := km.PurgeSession(opts)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
 // Sign generates an ECDSA signature for `messageHash` among the given `signers`.
 // Returns *ecdsa.Signature if successful.
 func (mpc *MPC) Sign(cfg comm_config.SignConfig, pl *pool.Pool) protocol.StartFunc {
 	mpcsign := mpc.NewMPCSignManager()
 	return mpcsign.StartSign(cfg, pl)
 }
+
+// Presign runs the rounds of signing which do not depend on the message among the given `signers`.
+// Returns *sign.Presignature if successful, to be consumed by SignOnline.
+func (mpc *MPC) Presign(cfg comm_config.SignConfig, pl *pool.Pool) protocol.StartFunc {
+	mpcsign := mpc.NewMPCSignManager()
+	return mpcsign.StartPresign(cfg, pl)
+}
+
+// SignOnline generates an ECDSA signature for `messageHash` with a presignature, in a single round.
+// Returns *ecdsa.Signature if successful.
+func (mpc *MPC) SignOnline(presig *sign.Presignature, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
+	mpcsign := mpc.NewMPCSignManager()
+	return mpcsign.SignOnline(presig, messageHash, pl)
+}
@@ -2,28 +2,52 @@ package cmp
 
 import (
 	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"math"
 	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/cronokirby/saferith"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
+	zkenc "github.com/mr-shifu/mpc-lib/core/zk/enc"
+	"github.com/mr-shifu/mpc-lib/lib/container"
+	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	comm_paillier "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
+	comm_pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
+	comm_pedersen "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
+	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	comm_vault "github.com/mr-shifu/mpc-lib/pkg/common/vault"
+	sw_pedersen "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
+	sw_vss "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	mpc_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	cmp_config "github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+	"github.com/mr-shifu/mpc-lib/protocols/frost"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup) {
+// do runs keygen and then signs msg as party id. If afterKeygen is set, it is called between the
+// two protocols.
+func do(t *testing.T, id party.ID, ids []party.ID, threshold, degree int, msg []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup, afterKeygen func(t *testing.T, mpc *MPC, keyID string, id party.ID)) {
 	defer wg.Done()
 
 	keyID := uuid.New().String()
@@ -39,7 +63,8 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl
 
 	mpc := NewMPC(ksf, krf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
 
-	keycfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, id, ids)
+	keycfg, err := config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, id, ids).WithVSSDegree(degree)
+	require.NoError(t, err)
 	h, err := protocol.NewMultiHandler(
 		mpc.Keygen(keycfg, pl),
 		nil)
@@ -47,8 +72,12 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl
 	test.HandlerLoop(id, h, n)
 	r, err := h.Result()
 	require.NoError(t, err)
-	require.IsType(t, &Config{}, r)
-	c := r.(*Config)
+	require.IsType(t, &KeygenResult{}, r)
+	c := r.(*KeygenResult).Config
+
+	if afterKeygen != nil {
+		afterKeygen(t, mpc, keyID, id)
+	}
 
 	signID := uuid.New().String()
 	signcfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, threshold, id, ids, msg)
@@ -78,9 +107,112 @@ func TestCMP(t *testing.T) {
 	for _, id := range partyIDs {
 		pl := pool.NewPool(3)
 		defer pl.TearDown()
-		go do(t, id, partyIDs, T, message, pl, n, &wg)
+		go do(t, id, partyIDs, T, T, message, pl, n, &wg, nil)
+	}
+	wg.Wait()
+}
+
+func TestCMP_VSSDegree(t *testing.T) {
+	N := 3
+	T := 1
+	D := T + 1
+	message := []byte("hello")
+
+	partyIDs := test.PartyIDs(N)
+
+	n := test.NewNetwork(partyIDs)
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go do(t, id, partyIDs, T, D, message, pl, n, &wg, nil)
+	}
+	wg.Wait()
+}
+
+func TestCMP_ShareBackup(t *testing.T) {
+	N := 3
+	T := N - 1
+	message := []byte("hello")
+
+	partyIDs := test.PartyIDs(N)
+
+	n := test.NewNetwork(partyIDs)
+
+	// back up the VSS share of every party, wipe it from the store and restore it before signing
+	restore := func(t *testing.T, mpc *MPC, keyID string, id party.ID) {
+		opts := keyopts.Options{}
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", keyID, "partyid", string(id))
+		data, err := mpc.vss_mgr.ExportShare(opts)
+		require.NoError(t, err)
+
+		rootOpts := keyopts.Options{}
+		rootOpts.Set("protocol", keyopts.ProtocolCMP, "id", keyID, "partyid", "ROOT")
+		root, err := mpc.vss_mgr.GetSecrets(rootOpts)
+		require.NoError(t, err)
+		shareOpts := keyopts.Options{}
+		shareOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(root.SKI()), "partyid", string(id))
+		share, err := mpc.ec_vss.GetKey(shareOpts)
+		require.NoError(t, err)
+		_, err = mpc.ec_vss.ImportKey(share.PublicKey(), shareOpts)
+		require.NoError(t, err)
+		_, err = mpc.vss_mgr.ExportShare(opts)
+		require.ErrorIs(t, err, sw_vss.ErrShareNotPrivate)
+
+		require.NoError(t, mpc.vss_mgr.ImportShare(data, opts))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go do(t, id, partyIDs, T, T, message, pl, n, &wg, restore)
+	}
+	wg.Wait()
+}
+
+// countingProofWorker generates the proofs of round1 like the party would, counting them.
+type countingProofWorker struct {
+	calls atomic.Int32
+}
+
+func (w *countingProofWorker) NewZKEncProof(j party.ID, h comm_hash.Hash, k comm_ecdsa.ECDSAKey, kPEK comm_pek.PaillierEncodedKey, pk comm_paillier.PaillierKey, ped comm_pedersen.PedersenKey) (*zkenc.Proof, error) {
+	w.calls.Add(1)
+	return k.NewZKEncProof(h, kPEK, pk, ped)
+}
+
+func TestCMP_RemoteProofWorker(t *testing.T) {
+	N := 3
+	T := N - 1
+	message := []byte("hello")
+
+	partyIDs := test.PartyIDs(N)
+
+	n := test.NewNetwork(partyIDs)
+
+	workers := make(map[party.ID]*countingProofWorker, N)
+	for _, id := range partyIDs {
+		workers[id] = &countingProofWorker{}
+	}
+	withWorker := func(t *testing.T, mpc *MPC, keyID string, id party.ID) {
+		mpc.WithRemoteProofWorker(workers[id])
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go do(t, id, partyIDs, T, T, message, pl, n, &wg, withWorker)
 	}
 	wg.Wait()
+
+	for _, id := range partyIDs {
+		assert.EqualValues(t, N-1, workers[id].calls.Load(), "party %s", id)
+	}
 }
 
 func TestStart(t *testing.T) {
@@ -170,3 +302,405 @@ func TestStart(t *testing.T) {
 		})
 	}
 }
+
+func TestStart_ExistingSession(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ksf := &keystore.InmemoryKeystoreFactory{}
+	krf := &keyopts.InMemoryKeyOptsFactory{}
+	vf := &vault.InmemoryVaultFactory{}
+	keycfgstore := config.NewInMemoryConfigStore()
+	signcfgstore := config.NewInMemoryConfigStore()
+	keystatestore := state.NewInMemoryStateStore()
+	signstatestore := state.NewInMemoryStateStore()
+	msgstore := message.NewInMemoryMessageStore()
+	bcststore := message.NewInMemoryMessageStore()
+
+	mpc := NewMPC(ksf, krf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
+
+	partyIDs := test.PartyIDs(2)
+	keycfg := config.NewKeyConfig(uuid.New().String(), curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+
+	_, err := mpc.Keygen(keycfg, pl)(nil)
+	require.NoError(t, err)
+
+	_, err = mpc.Keygen(keycfg, pl)(nil)
+	assert.ErrorIs(t, err, mpc_state.ErrStateExists)
+}
+
+func TestKeygen_RedundancyPolicy(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ksf := &keystore.InmemoryKeystoreFactory{}
+	krf := &keyopts.InMemoryKeyOptsFactory{}
+	vf := &vault.InmemoryVaultFactory{}
+	keycfgstore := config.NewInMemoryConfigStore()
+	signcfgstore := config.NewInMemoryConfigStore()
+	keystatestore := state.NewInMemoryStateStore()
+	signstatestore := state.NewInMemoryStateStore()
+	msgstore := message.NewInMemoryMessageStore()
+	bcststore := message.NewInMemoryMessageStore()
+
+	mpc := NewMPC(ksf, krf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl).
+		WithRedundancyPolicy(keygen.RedundancyPolicy{MinSpare: 1})
+
+	partyIDs := test.PartyIDs(3)
+	keycfg := config.NewKeyConfig(uuid.New().String(), curve.Secp256k1{}, 2, partyIDs[0], partyIDs)
+	_, err := mpc.Keygen(keycfg, pl)(nil)
+	assert.ErrorIs(t, err, keygen.ErrInsufficientRedundancy)
+
+	keycfg = config.NewKeyConfig(uuid.New().String(), curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+	_, err = mpc.Keygen(keycfg, pl)(nil)
+	assert.NoError(t, err)
+}
+
+func TestSign_MissingSelfPaillierKey(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+
+	ksf := &keystore.InmemoryKeystoreFactory{}
+	krf := &keyopts.InMemoryKeyOptsFactory{}
+	vf := &vault.InmemoryVaultFactory{}
+	keycfgstore := config.NewInMemoryConfigStore()
+	signcfgstore := config.NewInMemoryConfigStore()
+	keystatestore := state.NewInMemoryStateStore()
+	signstatestore := state.NewInMemoryStateStore()
+	msgstore := message.NewInMemoryMessageStore()
+	bcststore := message.NewInMemoryMessageStore()
+
+	mpc := NewMPC(ksf, krf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
+
+	// import the aux info of all parties, except for the Paillier key of self
+	keyID := uuid.New().String()
+	selfID := partyIDs[0]
+	public := configs[selfID].Public
+	for _, j := range partyIDs {
+		opts := keyopts.Options{}
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", keyID, "partyid", string(j))
+		if j != selfID {
+			_, err := mpc.paillier.ImportPublicKey(public[j].Paillier, opts)
+			require.NoError(t, err)
+		}
+		_, err := mpc.pedersen.ImportKey(sw_pedersen.NewPedersenKey(nil, public[j].Pedersen), opts)
+		require.NoError(t, err)
+	}
+
+	signcfg := config.NewSignConfig(uuid.New().String(), keyID, group, 1, selfID, partyIDs, []byte("hello"))
+	var err error
+	require.NotPanics(t, func() {
+		_, err = mpc.Sign(signcfg, pl)(nil)
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing Paillier public key of party "+string(selfID))
+}
+
+func TestConfig_Export(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	for _, password := range [][]byte{nil, []byte("password")} {
+		exported, err := c.Export(password)
+		require.NoError(t, err)
+		data, err := exported.MarshalBinary()
+		require.NoError(t, err)
+		decoded := &container.Container{}
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, container.AlgorithmECDSASecp256k1, decoded.Algorithm)
+
+		publicKey := group.NewPoint()
+		require.NoError(t, publicKey.UnmarshalBinary(decoded.PublicKey))
+		assert.True(t, publicKey.Equal(c.PublicPoint()))
+
+		raw, err := decoded.SecretShare(password)
+		require.NoError(t, err)
+		share := group.NewScalar()
+		require.NoError(t, share.UnmarshalBinary(raw))
+		assert.True(t, share.ActOnBase().Equal(c.Public[c.ID].ECDSA))
+	}
+}
+
+// sharedKeyOptsFactory returns the same KeyOpts to the n-th call of NewKeyOpts after each reset, so
+// that the key managers of two protocols share their stores.
+type sharedKeyOptsFactory struct {
+	krs  []comm_keyopts.KeyOpts
+	next int
+}
+
+func (f *sharedKeyOptsFactory) NewKeyOpts(cfg interface{}) comm_keyopts.KeyOpts {
+	if f.next == len(f.krs) {
+		f.krs = append(f.krs, keyopts.NewInMemoryKeyOpts())
+	}
+	f.next++
+	return f.krs[f.next-1]
+}
+
+// sharedVaultFactory is the counterpart of sharedKeyOptsFactory for vaults.
+type sharedVaultFactory struct {
+	vaults []comm_vault.Vault
+	next   int
+}
+
+func (f *sharedVaultFactory) NewVault(cfg interface{}) comm_vault.Vault {
+	if f.next == len(f.vaults) {
+		f.vaults = append(f.vaults, (&vault.InmemoryVaultFactory{}).NewVault(cfg))
+	}
+	f.next++
+	return f.vaults[f.next-1]
+}
+
+func TestCMP_SharedKeyIDWithFROST(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ksf := &keystore.InmemoryKeystoreFactory{}
+	krf := &sharedKeyOptsFactory{}
+	vf := &sharedVaultFactory{}
+	mpc := NewMPC(ksf, krf, vf, config.NewInMemoryConfigStore(), config.NewInMemoryConfigStore(),
+		state.NewInMemoryStateStore(), state.NewInMemoryStateStore(),
+		message.NewInMemoryMessageStore(), message.NewInMemoryMessageStore(), pl)
+	krf.next, vf.next = 0, 0
+	fr := frost.NewFROST(ksf, krf, vf, config.NewInMemoryConfigStore(), config.NewInMemoryConfigStore(),
+		state.NewInMemoryStateStore(), state.NewInMemoryStateStore(),
+		message.NewInMemoryMessageStore(), message.NewInMemoryMessageStore(), pl)
+
+	// both sessions store their keys under the same KeyID in the same stores
+	keyID := uuid.New().String()
+	partyIDs := test.PartyIDs(2)
+	cmpRound, err := mpc.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs), pl)(nil)
+	require.NoError(t, err)
+	_, err = cmpRound.Finalize(make(chan *round.Message, 2*len(partyIDs)))
+	require.NoError(t, err)
+	frostRound, err := fr.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs), pl)(nil)
+	require.NoError(t, err)
+	_, err = frostRound.Finalize(make(chan *round.Message, 2*len(partyIDs)))
+	require.NoError(t, err)
+
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", keyID, "partyid", string(partyIDs[0]))
+	key, err := mpc.ec.GetKey(opts)
+	require.NoError(t, err)
+	_, err = mpc.elgamal.GetKey(opts)
+	require.NoError(t, err)
+
+	// purging the FROST session must keep the keys of the CMP session
+	n, err := fr.PurgeSession(keyID)
+	require.NoError(t, err)
+	assert.Positive(t, n)
+	stored, err := mpc.ec.GetKey(opts)
+	require.NoError(t, err)
+	assert.Equal(t, key.SKI(), stored.SKI())
+	_, err = mpc.elgamal.GetKey(opts)
+	require.NoError(t, err)
+
+	n, err = mpc.PurgeSession(keyID)
+	require.NoError(t, err)
+	assert.Positive(t, n)
+	_, err = mpc.ec.GetKey(opts)
+	assert.Error(t, err)
+}
+
+func TestConfig_PublicPolynomial(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	decoded := EmptyConfig(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+
+	F := decoded.PublicPolynomial()
+	require.NotNil(t, F)
+	assert.True(t, F.Constant().Equal(c.PublicPoint()))
+	for _, j := range partyIDs {
+		assert.True(t, F.Evaluate(j.Scalar(group)).Equal(c.Public[j].ECDSA))
+	}
+
+	// a polynomial which does not match the share of the party is rejected
+	c.Polynomial = configs[partyIDs[1]].Polynomial.AddConstant(group.NewBasePoint())
+	data, err = c.MarshalBinary()
+	require.NoError(t, err)
+	assert.Error(t, EmptyConfig(group).UnmarshalBinary(data))
+}
+
+func TestConfig_VerifyAgainstCommitment(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	commitment := c.PublicPolynomial()
+	require.NotNil(t, commitment)
+	assert.NoError(t, c.VerifyAgainstCommitment(commitment))
+
+	// a commitment to another public key is rejected
+	assert.Error(t, c.VerifyAgainstCommitment(commitment.AddConstant(group.NewBasePoint())))
+
+	// so is a config with a tampered public share
+	tampered := *c
+	tampered.Public = make(map[party.ID]*cmp_config.Public, len(c.Public))
+	for j, p := range c.Public {
+		tampered.Public[j] = p
+	}
+	p := *c.Public[partyIDs[1]]
+	p.ECDSA = p.ECDSA.Add(group.NewBasePoint())
+	tampered.Public[partyIDs[1]] = &p
+	assert.Error(t, tampered.VerifyAgainstCommitment(commitment))
+
+	assert.Error(t, c.VerifyAgainstCommitment(nil))
+}
+
+func TestConfig_MinSigners(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+
+	for _, c := range configs {
+		assert.Equal(t, c.Threshold+1, c.MinSigners())
+		assert.Equal(t, len(partyIDs), c.TotalParties())
+		assert.LessOrEqual(t, c.MinSigners(), c.TotalParties())
+
+		// exactly MinSigners parties including self are enough to sign
+		signers := party.IDSlice{c.ID}
+		for _, j := range partyIDs {
+			if len(signers) < c.MinSigners() && j != c.ID {
+				signers = append(signers, j)
+			}
+		}
+		signers = party.NewIDSlice(signers)
+		assert.True(t, c.CanSign(signers))
+		assert.False(t, c.CanSign(party.IDSlice{c.ID}))
+	}
+}
+
+func TestConfig_Rebuild(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	dropped, helpers := partyIDs[2], partyIDs[:2]
+
+	// each helper j sends λⱼ(i)⋅xⱼ, where λⱼ(i) is its Lagrange coefficient evaluated at the
+	// dropped party i, so that the sum is F(i) = xᵢ.
+	recovered := group.NewScalar()
+	for _, j := range helpers {
+		num := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		den := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		for _, m := range helpers {
+			if m == j {
+				continue
+			}
+			num.Mul(dropped.Scalar(group).Sub(m.Scalar(group)))
+			den.Mul(j.Scalar(group).Sub(m.Scalar(group)))
+		}
+		recovered.Add(num.Mul(den.Invert()).Mul(configs[j].ECDSA))
+	}
+
+	publicData := configs[helpers[0]].PublicData()
+	_, err := cmp_config.Rebuild(dropped, publicData, configs[helpers[0]].ECDSA)
+	assert.Error(t, err, "a share of another party must be rejected")
+	_, err = cmp_config.Rebuild("z", publicData, recovered)
+	assert.Error(t, err, "an unknown party must be rejected")
+
+	c, err := cmp_config.Rebuild(dropped, publicData, recovered)
+	require.NoError(t, err)
+	assert.Equal(t, dropped, c.ID)
+	assert.True(t, c.ECDSA.Equal(configs[dropped].ECDSA))
+	assert.True(t, c.PublicPoint().Equal(configs[dropped].PublicPoint()))
+
+	// the secrets which cannot be recovered are missing, so the config is rejected wherever they are needed
+	_, err = c.MarshalBinary()
+	assert.ErrorIs(t, err, cmp_config.ErrIncompleteConfig)
+	_, err = json.Marshal(c)
+	assert.ErrorIs(t, err, cmp_config.ErrIncompleteConfig)
+	assert.False(t, c.CanSign(party.NewIDSlice([]party.ID{helpers[0], dropped})))
+	exported, err := c.Export(nil)
+	require.NoError(t, err)
+	expected, err := configs[dropped].Export(nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Share, exported.Share)
+
+	// sign with the rebuilt share and the one of a helper, using presignatures from a dealer
+	signers := party.NewIDSlice([]party.ID{helpers[0], dropped})
+	shares := map[party.ID]curve.Scalar{helpers[0]: configs[helpers[0]].ECDSA, dropped: c.ECDSA}
+	lagrange := polynomial.Lagrange(group, signers)
+	k := make(map[party.ID]curve.Scalar, len(signers))
+	kSum := group.NewScalar()
+	for _, j := range signers {
+		k[j] = sample.Scalar(rand.Reader, group)
+		kSum.Add(k[j])
+	}
+	kInv := group.NewScalar().Set(kSum).Invert()
+	R := kInv.ActOnBase()
+	RBar := make(map[party.ID]curve.Point, len(signers))
+	S := make(map[party.ID]curve.Point, len(signers))
+	chi := make(map[party.ID]curve.Scalar, len(signers))
+	for _, j := range signers {
+		// χⱼ = k⋅λⱼ⋅xⱼ
+		chi[j] = group.NewScalar().Set(kSum).Mul(lagrange[j]).Mul(shares[j])
+		RBar[j] = group.NewScalar().Set(k[j]).Mul(kInv).ActOnBase()
+		S[j] = chi[j].Act(R)
+	}
+
+	hash := []byte("rebuilt config signs")
+	sigmas := make(map[party.ID]ecdsa.SignatureShare, len(signers))
+	var preSignature *ecdsa.PreSignature
+	for _, j := range signers {
+		preSignature = &ecdsa.PreSignature{
+			R:        R,
+			RBar:     party.NewPointMap(RBar),
+			S:        party.NewPointMap(S),
+			KShare:   k[j],
+			ChiShare: chi[j],
+		}
+		sigmas[j], err = preSignature.SignatureShare(hash)
+		require.NoError(t, err)
+	}
+	assert.Empty(t, preSignature.VerifySignatureShares(sigmas, hash))
+	assert.True(t, preSignature.Signature(sigmas).Verify(c.PublicPoint(), hash))
+}
+
+func TestConfig_PartyIndex(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 4, 2, rand.Reader, pl)
+
+	for _, c := range configs {
+		data, err := c.MarshalBinary()
+		require.NoError(t, err)
+		decoded := EmptyConfig(group)
+		require.NoError(t, decoded.UnmarshalBinary(data))
+
+		for i, j := range party.NewIDSlice(partyIDs) {
+			index, err := c.PartyIndex(j)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+			decodedIndex, err := decoded.PartyIndex(j)
+			require.NoError(t, err)
+			assert.Equal(t, index, decodedIndex)
+
+			id, err := decoded.PartyByIndex(index)
+			require.NoError(t, err)
+			assert.Equal(t, j, id)
+		}
+
+		_, err = c.PartyIndex("unknown")
+		assert.Error(t, err)
+		_, err = c.PartyByIndex(-1)
+		assert.Error(t, err)
+		_, err = c.PartyByIndex(len(partyIDs))
+		assert.Error(t, err)
+	}
+}
@@ -29,6 +29,7 @@ type Config struct {
 	ID party.ID
 	// Threshold is the integer t which defines the maximum number of corruptions tolerated for this config.
 	// Threshold + 1 is the minimum number of parties' shares required to reconstruct the secret/sign a message.
+	// It is the degree of the VSS polynomial of the key, which may exceed the threshold of the keygen session.
 	Threshold int
 	// ECDSA is this party's share xᵢ of the secret ECDSA x.
 	ECDSA curve.Scalar
@@ -42,6 +43,10 @@ type Config struct {
 	ChainKey types.RID
 	// Public maps party.ID to public. It contains all public information associated to a party.
 	Public map[party.ID]*Public
+	// Polynomial is the aggregate VSS polynomial in the exponent F(X) = ∑ⱼ Fⱼ(X), such that
+	// F(0) is the public key and F(j) the public share of party j. It is nil for configs
+	// which were not produced by keygen.
+	Polynomial *polynomial.Exponent
 }
 
 // Public holds public information for a party.
@@ -70,6 +75,41 @@ func (c *Config) PublicPoint() curve.Point {
 	return sum
 }
 
+// PublicPolynomial returns the aggregate VSS polynomial in the exponent computed during keygen,
+// or nil if it is not known.
+func (c *Config) PublicPolynomial() *polynomial.Exponent {
+	return c.Polynomial
+}
+
+// VerifyAgainstCommitment checks that the public share of every party equals the published
+// aggregate VSS commitment exp evaluated at the ID of that party. The commitment must have fewer
+// coefficients than there are parties, so that the shares determine it, and the public key.
+func (c *Config) VerifyAgainstCommitment(exp *polynomial.Exponent) error {
+	if exp == nil {
+		return errors.New("config: missing commitment")
+	}
+	if exp.Degree() >= len(c.Public) {
+		return fmt.Errorf("config: commitment of degree %d is not determined by %d public shares", exp.Degree(), len(c.Public))
+	}
+	for _, j := range c.PartyIDs() {
+		if !exp.Evaluate(j.Scalar(c.Group)).Equal(c.Public[j].ECDSA) {
+			return fmt.Errorf("config: public share of party %s does not match commitment", j)
+		}
+	}
+	return nil
+}
+
+// MinSigners returns the minimum number of parties required to sign, which is Threshold + 1.
+// For a valid config, MinSigners() ⩽ TotalParties().
+func (c *Config) MinSigners() int {
+	return c.Threshold + 1
+}
+
+// TotalParties returns the number of parties holding a share of the key.
+func (c *Config) TotalParties() int {
+	return len(c.Public)
+}
+
 // PartyIDs returns a sorted slice of party IDs.
 func (c *Config) PartyIDs() party.IDSlice {
 	ids := make([]party.ID, 0, len(c.Public))
@@ -79,6 +119,27 @@ func (c *Config) PartyIDs() party.IDSlice {
 	return party.NewIDSlice(ids)
 }
 
+// PartyIndex returns the index of party id in the sorted slice of party IDs returned by PartyIDs.
+// The mapping only depends on the set of parties, so it is the same for all their configs.
+func (c *Config) PartyIndex(id party.ID) (int, error) {
+	for i, j := range c.PartyIDs() {
+		if j == id {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("config: party %s not found", id)
+}
+
+// PartyByIndex returns the party at index i of the sorted slice of party IDs, which is the
+// inverse of PartyIndex.
+func (c *Config) PartyByIndex(i int) (party.ID, error) {
+	partyIDs := c.PartyIDs()
+	if i < 0 || i >= len(partyIDs) {
+		return "", fmt.Errorf("config: party index %d out of range [0, %d)", i, len(partyIDs))
+	}
+	return partyIDs[i], nil
+}
+
 // WriteTo implements io.WriterTo interface.
 func (c *Config) WriteTo(w io.Writer) (total int64, err error) {
 	if c == nil {
@@ -175,8 +236,12 @@ func (p *Public) WriteTo(w io.Writer) (total int64, err error) {
 
 // CanSign returns true if the given _sorted_ list of signers is
 // a valid subset of the original parties of size > t,
-// and includes self.
+// and includes self. It is false for a config missing secrets of this party.
 func (c *Config) CanSign(signers party.IDSlice) bool {
+	if c.complete() != nil {
+		return false
+	}
+
 	if !ValidThreshold(c.Threshold, len(signers)) {
 		return false
 	}
@@ -201,6 +266,14 @@ func (c *Config) CanSign(signers party.IDSlice) bool {
 	return true
 }
 
+// complete returns ErrIncompleteConfig if secrets of this party are missing from c.
+func (c *Config) complete() error {
+	if c.ECDSA == nil || c.ElGamal == nil || c.Paillier == nil {
+		return ErrIncompleteConfig
+	}
+	return nil
+}
+
 func ValidThreshold(t, n int) bool {
 	if t < 0 || t > math.MaxUint32 {
 		return false
@@ -239,16 +312,22 @@ func (c *Config) Derive(adjust curve.Scalar, newChainKey []byte) (*Config, error
 		}
 	}
 
+	var poly *polynomial.Exponent
+	if c.Polynomial != nil {
+		poly = c.Polynomial.AddConstant(adjustG)
+	}
+
 	return &Config{
-		Group:     c.Group,
-		ID:        c.ID,
-		Threshold: c.Threshold,
-		ECDSA:     c.Group.NewScalar().Set(c.ECDSA).Add(adjust),
-		ElGamal:   c.ElGamal,
-		Paillier:  c.Paillier,
-		RID:       c.RID,
-		ChainKey:  newChainKey,
-		Public:    public,
+		Group:      c.Group,
+		ID:         c.ID,
+		Threshold:  c.Threshold,
+		ECDSA:      c.Group.NewScalar().Set(c.ECDSA).Add(adjust),
+		ElGamal:    c.ElGamal,
+		Paillier:   c.Paillier,
+		RID:        c.RID,
+		ChainKey:   newChainKey,
+		Public:     public,
+		Polynomial: poly,
 	}, nil
 }
 
@@ -274,6 +353,56 @@ func (c *Config) DeriveBIP32(i uint32) (*Config, error) {
 	return c.Derive(scalar, newChainKey)
 }
 
+// DeriveChild derives the config of the non-hardened BIP32 child index of the key, using ChainKey
+// as the chain code. The child public key and chain key are those BIP32 derives from the extended
+// public key (PublicPoint(), ChainKey), and each party derives its share of the child key locally,
+// without interacting with the others.
+//
+// Unlike DeriveBIP32, an error is returned for a hardened index i ⩾ 2³¹, which would require the
+// secret key, as well as for the indices which BIP32 skips since they give an invalid key.
+func (c *Config) DeriveChild(index uint32) (*Config, error) {
+	if index>>31 != 0 {
+		return nil, fmt.Errorf("config: DeriveChild: index %d is hardened", index)
+	}
+	child, err := c.DeriveBIP32(index)
+	if err != nil {
+		return nil, fmt.Errorf("config: DeriveChild: %w", err)
+	}
+	if child.PublicPoint().IsIdentity() {
+		return nil, fmt.Errorf("config: DeriveChild: bad index: %d", index)
+	}
+	return child, nil
+}
+
+// ReconstructSecret recovers the ECDSA secret key from the secret shares of a quorum of at least
+// MinSigners() parties, such as restored backups. It is meant for disaster recovery only, since
+// the key exists in a single place afterwards.
+//
+// Every share is checked against the public share of its party, and the secret against the public
+// key, so that an error names the party whose backup is wrong instead of returning a wrong key.
+func (c *Config) ReconstructSecret(shares map[party.ID]curve.Scalar) (curve.Scalar, error) {
+	if len(shares) < c.MinSigners() {
+		return nil, fmt.Errorf("config: ReconstructSecret: %d shares, expected at least %d", len(shares), c.MinSigners())
+	}
+	for j, share := range shares {
+		public, ok := c.Public[j]
+		if !ok {
+			return nil, fmt.Errorf("config: ReconstructSecret: party %s not found", j)
+		}
+		if share == nil || !share.ActOnBase().Equal(public.ECDSA) {
+			return nil, fmt.Errorf("config: ReconstructSecret: share of party %s does not match its public share", j)
+		}
+	}
+	secret, err := polynomial.ReconstructSecret(shares, c.Group)
+	if err != nil {
+		return nil, fmt.Errorf("config: ReconstructSecret: %w", err)
+	}
+	if !secret.ActOnBase().Equal(c.PublicPoint()) {
+		return nil, errors.New("config: ReconstructSecret: secret does not match the public key")
+	}
+	return secret, nil
+}
+
 type configSerialized struct {
 	ID        party.ID
 	Threshold int
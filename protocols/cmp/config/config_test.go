@@ -0,0 +1,188 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var group = curve.Secp256k1{}
+
+// newConfigs returns the configs of a threshold sharing of the public key public, whose secret
+// shares are only known if secret is set.
+func newConfigs(public curve.Point, secret curve.Scalar, chainKey []byte) map[party.ID]*Config {
+	ids := party.IDSlice{"a", "b", "c"}
+	constant := group.NewScalar()
+	if secret != nil {
+		constant = secret
+	}
+	f := polynomial.NewPolynomial(group, 1, constant)
+	// F(X) - F(0)⋅G + public is a sharing of public, even when its discrete log is unknown
+	offset := public.Sub(constant.ActOnBase())
+
+	publics := make(map[party.ID]*Public, len(ids))
+	for _, id := range ids {
+		publics[id] = &Public{ECDSA: f.Evaluate(id.Scalar(group)).ActOnBase().Add(offset)}
+	}
+	configs := make(map[party.ID]*Config, len(ids))
+	for _, id := range ids {
+		configs[id] = &Config{
+			Group:     group,
+			ID:        id,
+			Threshold: 1,
+			ECDSA:     f.Evaluate(id.Scalar(group)),
+			ChainKey:  chainKey,
+			Public:    publics,
+		}
+	}
+	return configs
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestConfig_DeriveChild(t *testing.T) {
+	// test vectors 1 and 2 of BIP32
+	tests := []struct {
+		name                   string
+		parentPublic, parentCC string
+		index                  uint32
+		childPublic, childCC   string
+	}{
+		{
+			"m/0H/1",
+			"035a784662a4a20a65bf6aab9ae98a6c068a81c52e4b032c0fb5400c706cfccc56",
+			"47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+			1,
+			"03501e454bf00751f24b1b489aa925215d66af2234e3891c3b21a52bedb3cd711c",
+			"2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+		},
+		{
+			"m/0H/1/2H/2/1000000000",
+			"02e8445082a72f29b75ca48748a914df60622a609cacfce8ed0e35804560741d29",
+			"cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd",
+			1000000000,
+			"022a471424da5e657499d1ff51cb43c47481a03b1e77f951fe64cec9f5a48f7011",
+			"c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e",
+		},
+		{
+			"m/0",
+			"03cbcaa9c98c877a26977d00825c956a238e8dddfbd322cce4f74b0b5bd6ace4a7",
+			"60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689",
+			0,
+			"02fc9e5af0ac8d9b3cecfe2a888e2117ba3d089d8585886c9c826b6b22a98d12ea",
+			"f0909affaa7ee7abe5dd4e100598d4dc53cd709d5a5c2cac40e7412f232f7c9c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := group.NewPoint()
+			require.NoError(t, parent.UnmarshalBinary(decodeHex(t, tt.parentPublic)))
+
+			for _, c := range newConfigs(parent, nil, decodeHex(t, tt.parentCC)) {
+				child, err := c.DeriveChild(tt.index)
+				require.NoError(t, err)
+				public, err := child.PublicPoint().MarshalBinary()
+				require.NoError(t, err)
+				assert.Equal(t, tt.childPublic, hex.EncodeToString(public))
+				assert.Equal(t, tt.childCC, hex.EncodeToString(child.ChainKey))
+			}
+		})
+	}
+}
+
+func TestConfig_DeriveChild_Shares(t *testing.T) {
+	secret := sample.Scalar(rand.Reader, group)
+	chainKey := make([]byte, 32)
+	_, _ = rand.Read(chainKey)
+	configs := newConfigs(secret.ActOnBase(), secret, chainKey)
+
+	var public curve.Point
+	for id, c := range configs {
+		child, err := c.DeriveChild(7)
+		require.NoError(t, err)
+		assert.True(t, child.ECDSA.ActOnBase().Equal(child.Public[id].ECDSA), "share of %s", id)
+		if public == nil {
+			public = child.PublicPoint()
+		}
+		assert.True(t, public.Equal(child.PublicPoint()))
+		assert.False(t, public.Equal(c.PublicPoint()))
+	}
+}
+
+func TestConfig_DeriveChild_Hardened(t *testing.T) {
+	secret := sample.Scalar(rand.Reader, group)
+	c := newConfigs(secret.ActOnBase(), secret, make([]byte, 32))["a"]
+	_, err := c.DeriveChild(1 << 31)
+	assert.Error(t, err)
+}
+
+func TestConfig_ReconstructSecret(t *testing.T) {
+	secret := sample.Scalar(rand.Reader, group)
+	configs := newConfigs(secret.ActOnBase(), secret, make([]byte, 32))
+	c := configs["a"]
+
+	// any 2 of the 3 shares of a threshold 1 sharing recover the secret
+	for _, quorum := range []party.IDSlice{{"a", "b"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"}} {
+		shares := make(map[party.ID]curve.Scalar, len(quorum))
+		for _, j := range quorum {
+			shares[j] = configs[j].ECDSA
+		}
+		reconstructed, err := c.ReconstructSecret(shares)
+		require.NoError(t, err, quorum)
+		assert.True(t, reconstructed.ActOnBase().Equal(c.PublicPoint()), quorum)
+		assert.True(t, reconstructed.Equal(secret), quorum)
+	}
+
+	_, err := c.ReconstructSecret(map[party.ID]curve.Scalar{"a": configs["a"].ECDSA})
+	assert.Error(t, err, "insufficient quorum")
+
+	_, err = c.ReconstructSecret(map[party.ID]curve.Scalar{"a": configs["a"].ECDSA, "d": configs["b"].ECDSA})
+	assert.Error(t, err, "unknown party")
+
+	_, err = c.ReconstructSecret(map[party.ID]curve.Scalar{"a": configs["a"].ECDSA, "b": configs["c"].ECDSA})
+	assert.Error(t, err, "wrong share")
+}
+
+func TestConfig_PublicConfig(t *testing.T) {
+	secret := sample.Scalar(rand.Reader, group)
+	c := newConfigs(secret.ActOnBase(), secret, make([]byte, 32))["a"]
+
+	pc := c.PublicConfig()
+	assert.Equal(t, c.Group.Name(), pc.Group.Name())
+	assert.Equal(t, c.Threshold, pc.Threshold)
+	assert.Equal(t, c.MinSigners(), pc.MinSigners())
+	assert.Equal(t, c.PartyIDs(), pc.PartyIDs())
+	assert.True(t, pc.PublicKey.Equal(secret.ActOnBase()))
+	for j, p := range c.Public {
+		assert.True(t, p.ECDSA.Equal(pc.Public[j].ECDSA), j)
+	}
+
+	// s = k⁻¹(m + r⋅x)
+	msg := []byte("hello")
+	m := curve.FromHash(group, ecdsa.Digest(msg, sha256.New))
+	k := sample.Scalar(rand.Reader, group)
+	R := k.ActOnBase()
+	s := R.XScalar().Mul(secret).Add(m)
+	s = group.NewScalar().Set(k).Invert().Mul(s)
+	sig := &ecdsa.Signature{R: R, S: s}
+	assert.True(t, pc.VerifyMessage(sig, msg, sha256.New))
+	assert.False(t, pc.VerifyMessage(sig, []byte("other"), sha256.New))
+	assert.False(t, pc.Verify(nil, msg))
+
+	// the public config of another key rejects the signature
+	other := sample.Scalar(rand.Reader, group)
+	assert.False(t, newConfigs(other.ActOnBase(), other, make([]byte, 32))["a"].PublicConfig().VerifyMessage(sig, msg, sha256.New))
+}
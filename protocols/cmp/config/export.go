@@ -0,0 +1,29 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/lib/container"
+)
+
+// Export returns the public key and this party's ECDSA share in a container.
+// If password is not empty, the share is encrypted with a key derived from it.
+func (c *Config) Export(password []byte) (*container.Container, error) {
+	if c.Group == nil || c.Group.Name() != (curve.Secp256k1{}).Name() {
+		return nil, errors.New("config: export is only supported for secp256k1")
+	}
+	if c.ECDSA == nil {
+		return nil, errors.New("config: missing ECDSA share")
+	}
+
+	publicKey, err := c.PublicPoint().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	share, err := c.ECDSA.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return container.New(container.AlgorithmECDSASecp256k1, c.ID, c.Threshold, publicKey, share, password)
+}
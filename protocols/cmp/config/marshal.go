@@ -7,6 +7,7 @@ import (
 	"github.com/cronokirby/saferith"
 	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
@@ -30,6 +31,7 @@ type configMarshal struct {
 	P, Q           *saferith.Nat
 	RID, ChainKey  types.RID
 	Public         []cbor.RawMessage
+	Polynomial     []byte `cbor:",omitempty"`
 }
 
 type publicMarshal struct {
@@ -40,6 +42,9 @@ type publicMarshal struct {
 }
 
 func (c *Config) MarshalBinary() ([]byte, error) {
+	if err := c.complete(); err != nil {
+		return nil, err
+	}
 	ps := make([]cbor.RawMessage, 0, len(c.Public))
 	for _, id := range c.PartyIDs() {
 		p := c.Public[id]
@@ -57,16 +62,24 @@ func (c *Config) MarshalBinary() ([]byte, error) {
 		}
 		ps = append(ps, data)
 	}
+	var poly []byte
+	if c.Polynomial != nil {
+		var err error
+		if poly, err = c.Polynomial.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
 	return cbor.Marshal(&configMarshal{
-		ID:        c.ID,
-		Threshold: c.Threshold,
-		ECDSA:     c.ECDSA,
-		ElGamal:   c.ElGamal,
-		P:         c.Paillier.P(),
-		Q:         c.Paillier.Q(),
-		RID:       c.RID,
-		ChainKey:  c.ChainKey,
-		Public:    ps,
+		ID:         c.ID,
+		Threshold:  c.Threshold,
+		ECDSA:      c.ECDSA,
+		ElGamal:    c.ElGamal,
+		P:          c.Paillier.P(),
+		Q:          c.Paillier.Q(),
+		RID:        c.RID,
+		ChainKey:   c.ChainKey,
+		Public:     ps,
+		Polynomial: poly,
 	})
 }
 
@@ -82,6 +95,23 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 		return fmt.Errorf("config: %w", err)
 	}
 
+	ps := make([]*publicMarshal, 0, len(cm.Public))
+	for _, pm := range cm.Public {
+		p := &publicMarshal{
+			ECDSA:   c.Group.NewPoint(),
+			ElGamal: c.Group.NewPoint(),
+		}
+		if err := cbor.Unmarshal(pm, p); err != nil {
+			return fmt.Errorf("config: party %s: %w", p.ID, err)
+		}
+		ps = append(ps, p)
+	}
+	return c.fromMarshal(cm, ps)
+}
+
+// fromMarshal validates the decoded config cm with the public data of the parties, and sets c
+// to it.
+func (c *Config) fromMarshal(cm *configMarshal, publics []*publicMarshal) error {
 	// check ECDSA, ElGamal
 	if cm.ECDSA.IsZero() || cm.ElGamal.IsZero() {
 		return errors.New("config: ECDSA or ElGamal secret key is zero")
@@ -97,15 +127,8 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 	paillierSecret := paillier.NewSecretKeyFromPrimes(cm.P, cm.Q)
 
 	// handle public parameters
-	ps := make(map[party.ID]*Public, len(cm.Public))
-	for _, pm := range cm.Public {
-		p := &publicMarshal{
-			ECDSA:   c.Group.NewPoint(),
-			ElGamal: c.Group.NewPoint(),
-		}
-		if err := cbor.Unmarshal(pm, p); err != nil {
-			return fmt.Errorf("config: party %s: %w", p.ID, err)
-		}
+	ps := make(map[party.ID]*Public, len(publics))
+	for _, p := range publics {
 		if _, ok := ps[p.ID]; ok {
 			return fmt.Errorf("config: party %s: duplicate entry", p.ID)
 		}
@@ -151,16 +174,28 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 		return errors.New("config: no public data for this party")
 	}
 
+	var poly *polynomial.Exponent
+	if len(cm.Polynomial) > 0 {
+		poly = polynomial.NewEmptyExponent(c.Group)
+		if err := poly.UnmarshalBinary(cm.Polynomial); err != nil {
+			return fmt.Errorf("config: polynomial: %w", err)
+		}
+		if !poly.Evaluate(cm.ID.Scalar(c.Group)).Equal(ps[cm.ID].ECDSA) {
+			return errors.New("config: polynomial does not match public share")
+		}
+	}
+
 	*c = Config{
-		Group:     c.Group,
-		ID:        cm.ID,
-		Threshold: cm.Threshold,
-		ECDSA:     cm.ECDSA,
-		ElGamal:   cm.ElGamal,
-		Paillier:  paillierSecret,
-		RID:       cm.RID,
-		ChainKey:  cm.ChainKey,
-		Public:    ps,
+		Group:      c.Group,
+		ID:         cm.ID,
+		Threshold:  cm.Threshold,
+		ECDSA:      cm.ECDSA,
+		ElGamal:    cm.ElGamal,
+		Paillier:   paillierSecret,
+		RID:        cm.RID,
+		ChainKey:   cm.ChainKey,
+		Public:     ps,
+		Polynomial: poly,
 	}
 	return nil
 }
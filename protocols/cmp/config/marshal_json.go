@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// configJSON is the JSON encoding of a Config. Curve points and scalars, as well as the other
+// binary values, are encoded as hex strings.
+type configJSON struct {
+	Group     string   `json:"group"`
+	ID        party.ID `json:"id"`
+	Threshold int      `json:"threshold"`
+	// Secret holds the secrets of the party, which are grouped so that they stand out when a
+	// config is inspected.
+	Secret     secretJSON               `json:"secret"`
+	RID        string                   `json:"rid"`
+	ChainKey   string                   `json:"chain_key"`
+	Public     map[party.ID]*publicJSON `json:"public"`
+	Polynomial string                   `json:"polynomial,omitempty"`
+}
+
+type secretJSON struct {
+	ECDSA     string `json:"ecdsa_share"`
+	ElGamal   string `json:"elgamal"`
+	PaillierP string `json:"paillier_p"`
+	PaillierQ string `json:"paillier_q"`
+}
+
+type publicJSON struct {
+	ECDSA     string `json:"ecdsa"`
+	ElGamal   string `json:"elgamal"`
+	PaillierN string `json:"paillier_n"`
+	PedersenS string `json:"pedersen_s"`
+	PedersenT string `json:"pedersen_t"`
+}
+
+// groups are the curves which can be decoded by name from JSON.
+var groups = []curve.Curve{curve.Secp256k1{}, curve.P256{}}
+
+// MarshalJSON implements json.Marshaler. The secrets of the party are encoded in the clear, under
+// the "secret" field, so the output must be protected like the config itself.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	if err := c.complete(); err != nil {
+		return nil, err
+	}
+	ecdsa, err := c.ECDSA.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	elgamal, err := c.ElGamal.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	public := make(map[party.ID]*publicJSON, len(c.Public))
+	for id, p := range c.Public {
+		pj := &publicJSON{
+			PaillierN: hex.EncodeToString(p.Pedersen.N().Bytes()),
+			PedersenS: hex.EncodeToString(p.Pedersen.S().Bytes()),
+			PedersenT: hex.EncodeToString(p.Pedersen.T().Bytes()),
+		}
+		if pj.ECDSA, err = marshalHex(p.ECDSA); err != nil {
+			return nil, fmt.Errorf("config: party %s: %w", id, err)
+		}
+		if pj.ElGamal, err = marshalHex(p.ElGamal); err != nil {
+			return nil, fmt.Errorf("config: party %s: %w", id, err)
+		}
+		public[id] = pj
+	}
+
+	var poly string
+	if c.Polynomial != nil {
+		if poly, err = marshalHex(c.Polynomial); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(&configJSON{
+		Group:     c.Group.Name(),
+		ID:        c.ID,
+		Threshold: c.Threshold,
+		Secret: secretJSON{
+			ECDSA:     hex.EncodeToString(ecdsa),
+			ElGamal:   hex.EncodeToString(elgamal),
+			PaillierP: hex.EncodeToString(c.Paillier.P().Bytes()),
+			PaillierQ: hex.EncodeToString(c.Paillier.Q().Bytes()),
+		},
+		RID:        hex.EncodeToString(c.RID),
+		ChainKey:   hex.EncodeToString(c.ChainKey),
+		Public:     public,
+		Polynomial: poly,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and validates the config like UnmarshalBinary.
+//
+// If c was initialized with EmptyConfig, the group of the encoding must be the same. Otherwise, the
+// group is found from its name.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	cj := &configJSON{}
+	if err := json.Unmarshal(data, cj); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	group := c.Group
+	if group == nil {
+		for _, g := range groups {
+			if g.Name() == cj.Group {
+				group = g
+			}
+		}
+		if group == nil {
+			return fmt.Errorf("config: unknown group %q", cj.Group)
+		}
+	} else if group.Name() != cj.Group {
+		return fmt.Errorf("config: group %q, expected %q", cj.Group, group.Name())
+	}
+
+	cm := &configMarshal{
+		ID:        cj.ID,
+		Threshold: cj.Threshold,
+		ECDSA:     group.NewScalar(),
+		ElGamal:   group.NewScalar(),
+	}
+	if err := unmarshalHex(cj.Secret.ECDSA, cm.ECDSA); err != nil {
+		return fmt.Errorf("config: ECDSA: %w", err)
+	}
+	if err := unmarshalHex(cj.Secret.ElGamal, cm.ElGamal); err != nil {
+		return fmt.Errorf("config: ElGamal: %w", err)
+	}
+	var err error
+	if cm.P, err = natFromHex(cj.Secret.PaillierP); err != nil {
+		return fmt.Errorf("config: prime P: %w", err)
+	}
+	if cm.Q, err = natFromHex(cj.Secret.PaillierQ); err != nil {
+		return fmt.Errorf("config: prime Q: %w", err)
+	}
+	if cm.RID, err = hex.DecodeString(cj.RID); err != nil {
+		return fmt.Errorf("config: RID: %w", err)
+	}
+	if cm.ChainKey, err = hex.DecodeString(cj.ChainKey); err != nil {
+		return fmt.Errorf("config: chain key: %w", err)
+	}
+	if cm.Polynomial, err = hex.DecodeString(cj.Polynomial); err != nil {
+		return fmt.Errorf("config: polynomial: %w", err)
+	}
+
+	ps := make([]*publicMarshal, 0, len(cj.Public))
+	for id, pj := range cj.Public {
+		if pj == nil {
+			return fmt.Errorf("config: party %s: missing public data", id)
+		}
+		p := &publicMarshal{
+			ID:      id,
+			ECDSA:   group.NewPoint(),
+			ElGamal: group.NewPoint(),
+		}
+		if err := unmarshalHex(pj.ECDSA, p.ECDSA); err != nil {
+			return fmt.Errorf("config: party %s: ECDSA: %w", id, err)
+		}
+		if err := unmarshalHex(pj.ElGamal, p.ElGamal); err != nil {
+			return fmt.Errorf("config: party %s: ElGamal: %w", id, err)
+		}
+		n, err := natFromHex(pj.PaillierN)
+		if err != nil {
+			return fmt.Errorf("config: party %s: N: %w", id, err)
+		}
+		p.N = saferith.ModulusFromNat(n)
+		if p.S, err = natFromHex(pj.PedersenS); err != nil {
+			return fmt.Errorf("config: party %s: S: %w", id, err)
+		}
+		if p.T, err = natFromHex(pj.PedersenT); err != nil {
+			return fmt.Errorf("config: party %s: T: %w", id, err)
+		}
+		ps = append(ps, p)
+	}
+
+	c.Group = group
+	return c.fromMarshal(cm, ps)
+}
+
+type binaryValue interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+func marshalHex(v binaryValue) (string, error) {
+	data, err := v.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+func unmarshalHex(s string, v binaryValue) error {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalBinary(data)
+}
+
+func natFromHex(s string) (*saferith.Nat, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	n := new(saferith.Nat).SetBytes(data)
+	if n.EqZero() == 1 {
+		return nil, errors.New("zero value")
+	}
+	return n, nil
+}
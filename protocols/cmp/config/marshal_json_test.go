@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_JSON(t *testing.T) {
+	pl := pool.NewPool(2)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+
+	decoded := make(map[party.ID]*config.Config, len(configs))
+	for _, id := range partyIDs {
+		c := configs[id]
+		data, err := json.Marshal(c)
+		require.NoError(t, err)
+
+		// the group is found from its name
+		d := &config.Config{}
+		require.NoError(t, json.Unmarshal(data, d))
+		decoded[id] = d
+
+		assert.Equal(t, group.Name(), d.Group.Name())
+		assert.Equal(t, c.ID, d.ID)
+		assert.Equal(t, c.Threshold, d.Threshold)
+		assert.True(t, c.ECDSA.Equal(d.ECDSA))
+		assert.True(t, c.ElGamal.Equal(d.ElGamal))
+		assert.Equal(t, c.Paillier.P().Bytes(), d.Paillier.P().Bytes())
+		assert.Equal(t, c.Paillier.Q().Bytes(), d.Paillier.Q().Bytes())
+		assert.Equal(t, c.RID, d.RID)
+		assert.Equal(t, c.ChainKey, d.ChainKey)
+		assert.True(t, c.Polynomial.Equal(*d.Polynomial))
+
+		require.Len(t, d.Public, len(c.Public))
+		for j, p := range c.Public {
+			q := d.Public[j]
+			require.NotNil(t, q, j)
+			assert.True(t, p.ECDSA.Equal(q.ECDSA), j)
+			assert.True(t, p.ElGamal.Equal(q.ElGamal), j)
+			assert.True(t, p.Paillier.Equal(q.Paillier), j)
+			assert.Equal(t, p.Pedersen.S().Bytes(), q.Pedersen.S().Bytes(), j)
+			assert.Equal(t, p.Pedersen.T().Bytes(), q.Pedersen.T().Bytes(), j)
+		}
+		assert.True(t, c.PublicPoint().Equal(d.PublicPoint()))
+
+		// the encoding is stable
+		again, err := json.Marshal(d)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(data), string(again))
+	}
+
+	shares := make(map[party.ID]curve.Scalar, len(decoded))
+	for id, d := range decoded {
+		shares[id] = d.ECDSA
+	}
+	c := configs[partyIDs[0]]
+	secret, err := c.ReconstructSecret(shares)
+	require.NoError(t, err)
+	decodedSecret, err := decoded[partyIDs[0]].ReconstructSecret(shares)
+	require.NoError(t, err)
+	assert.True(t, secret.Equal(decodedSecret))
+	assert.True(t, decodedSecret.ActOnBase().Equal(c.PublicPoint()))
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Error(t, json.Unmarshal(data, config.EmptyConfig(curve.P256{})), "group mismatch")
+}
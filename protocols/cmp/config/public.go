@@ -0,0 +1,66 @@
+package config
+
+import (
+	"hash"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// PublicConfig is the public part of a Config, holding no secret of any party. It is enough to
+// verify the signatures of the key, so it can be given to services which only verify signatures
+// instead of the Config.
+type PublicConfig struct {
+	// Group returns the Elliptic Curve Group associated with this config.
+	Group curve.Curve
+	// Threshold is the integer t which defines the maximum number of corruptions tolerated for this config.
+	Threshold int
+	// PublicKey is the public ECDSA key shared by the parties.
+	PublicKey curve.Point
+	// Public maps party.ID to public. It contains all public information associated to a party.
+	Public map[party.ID]*Public
+}
+
+// PublicConfig returns the public part of c.
+//
+// It is not named Public since this is the name of the field holding the public data of the parties.
+func (c *Config) PublicConfig() *PublicConfig {
+	public := make(map[party.ID]*Public, len(c.Public))
+	for j, p := range c.Public {
+		public[j] = p
+	}
+	return &PublicConfig{
+		Group:     c.Group,
+		Threshold: c.Threshold,
+		PublicKey: c.PublicPoint(),
+		Public:    public,
+	}
+}
+
+// MinSigners returns the minimum number of parties required to sign, which is Threshold + 1.
+func (pc *PublicConfig) MinSigners() int {
+	return pc.Threshold + 1
+}
+
+// PartyIDs returns a sorted slice of party IDs.
+func (pc *PublicConfig) PartyIDs() party.IDSlice {
+	ids := make([]party.ID, 0, len(pc.Public))
+	for j := range pc.Public {
+		ids = append(ids, j)
+	}
+	return party.NewIDSlice(ids)
+}
+
+// Verify returns true if sig is a valid signature of hash under the public key.
+func (pc *PublicConfig) Verify(sig *ecdsa.Signature, hash []byte) bool {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return false
+	}
+	return sig.Verify(pc.PublicKey, hash)
+}
+
+// VerifyMessage is like Verify, but first hashes msg with newHash, as done by ecdsa.Digest.
+func (pc *PublicConfig) VerifyMessage(sig *ecdsa.Signature, msg []byte, newHash func() hash.Hash) bool {
+	return pc.Verify(sig, ecdsa.Digest(msg, newHash))
+}
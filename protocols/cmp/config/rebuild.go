@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+)
+
+// ErrIncompleteConfig is returned when encoding a config which misses the ElGamal or Paillier
+// secrets of its party, such as one returned by Rebuild.
+var ErrIncompleteConfig = errors.New("config: missing ElGamal or Paillier secret")
+
+// PublicData is the part of a Config which is shared by all parties, and can therefore be
+// obtained from any of them.
+type PublicData struct {
+	Group      curve.Curve
+	Threshold  int
+	RID        types.RID
+	ChainKey   types.RID
+	Public     map[party.ID]*Public
+	Polynomial *polynomial.Exponent
+}
+
+// PublicData returns the public part of the config.
+func (c *Config) PublicData() *PublicData {
+	public := make(map[party.ID]*Public, len(c.Public))
+	for j, p := range c.Public {
+		public[j] = p
+	}
+	return &PublicData{
+		Group:      c.Group,
+		Threshold:  c.Threshold,
+		RID:        c.RID.Copy(),
+		ChainKey:   c.ChainKey.Copy(),
+		Public:     public,
+		Polynomial: c.Polynomial,
+	}
+}
+
+// Rebuild assembles the config of party selfID, which lost its own, from the public data held by
+// the other parties and its ECDSA share xᵢ recovered from them.
+//
+// The recovered share is checked against the public share Xᵢ, and against the public polynomial
+// when it is known. The ElGamal and Paillier secrets of the party cannot be recovered and are nil
+// in the returned config: it cannot be encoded, MarshalBinary and MarshalJSON fail with
+// ErrIncompleteConfig, and CanSign is false for it. Only its ECDSA share can be used, with Export,
+// until the parties refresh the key.
+func Rebuild(selfID party.ID, publicData *PublicData, recoveredShare curve.Scalar) (*Config, error) {
+	if publicData == nil || recoveredShare == nil {
+		return nil, errors.New("config: rebuild: missing public data or share")
+	}
+	group := publicData.Group
+
+	if !ValidThreshold(publicData.Threshold, len(publicData.Public)) {
+		return nil, fmt.Errorf("config: rebuild: threshold %d is invalid", publicData.Threshold)
+	}
+	self, ok := publicData.Public[selfID]
+	if !ok {
+		return nil, fmt.Errorf("config: rebuild: no public data for party %s", selfID)
+	}
+	if recoveredShare.IsZero() || !recoveredShare.ActOnBase().Equal(self.ECDSA) {
+		return nil, fmt.Errorf("config: rebuild: recovered share does not match public share of party %s", selfID)
+	}
+	if publicData.Polynomial != nil && !publicData.Polynomial.Evaluate(selfID.Scalar(group)).Equal(self.ECDSA) {
+		return nil, errors.New("config: rebuild: polynomial does not match public share")
+	}
+
+	public := make(map[party.ID]*Public, len(publicData.Public))
+	for j, p := range publicData.Public {
+		public[j] = p
+	}
+
+	return &Config{
+		Group:      group,
+		ID:         selfID,
+		Threshold:  publicData.Threshold,
+		ECDSA:      group.NewScalar().Set(recoveredShare),
+		RID:        publicData.RID.Copy(),
+		ChainKey:   publicData.ChainKey.Copy(),
+		Public:     public,
+		Polynomial: publicData.Polynomial,
+	}, nil
+}
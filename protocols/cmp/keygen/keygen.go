@@ -1,6 +1,7 @@
 package keygen
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/mr-shifu/mpc-lib/core/pool"
@@ -20,7 +21,27 @@ import (
 	mpc_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 )
 
-const Rounds round.Number = 5
+const (
+	protocolKeygenID              = "cmp/keygen"
+	Rounds           round.Number = 5
+)
+
+func init() {
+	protocol.RegisterMessageContent(protocolKeygenID,
+		&broadcast2{}, &broadcast3{}, &message4{}, &broadcast4{}, &broadcast5{})
+}
+
+var (
+	ErrModulusMismatch = errors.New("keygen: Paillier and Pedersen moduli differ")
+	// ErrVSSDegree is returned when the VSS polynomial of a party does not have the degree of the key.
+	ErrVSSDegree = errors.New("keygen: vss polynomial has incorrect degree")
+	// ErrVSSConstant is returned when the constant of the VSS polynomial of a party is zero in a
+	// keygen, or is not the previous share of the party in a refresh.
+	ErrVSSConstant = errors.New("keygen: vss polynomial has incorrect constant")
+	// ErrInvalidShareCiphertext is returned when the encrypted share sent by a party is not a valid
+	// ciphertext for the Paillier key of the receiver.
+	ErrInvalidShareCiphertext = errors.New("keygen: invalid share ciphertext")
+)
 
 type MPCKeygen struct {
 	configmgr   mpc_config.KeyConfigManager
@@ -37,6 +58,7 @@ type MPCKeygen struct {
 	chainKey_km rid.RIDManager
 	hash_mgr    hash.HashManager
 	commit_mgr  commitment.CommitmentManager
+	redundancy  RedundancyPolicy
 }
 
 func NewMPCKeygen(
@@ -75,58 +97,90 @@ func NewMPCKeygen(
 }
 
 func (m *MPCKeygen) Start(cfg mpc_config.KeyConfig, pl *pool.Pool) protocol.StartFunc {
-	return func(sessionID []byte) (_ round.Session, err error) {
-		info := round.Info{
-			ProtocolID:       "cmp/keygen",
-			SelfID:           cfg.SelfID(),
-			PartyIDs:         cfg.PartyIDs(),
-			Threshold:        cfg.Threshold(),
-			Group:            cfg.Group(),
-			FinalRoundNumber: Rounds,
-		}
-
-		// m.keys[keyID] = info
-		opts := keyopts.Options{}
-		opts.Set("id", cfg.ID(), "partyid", string(info.SelfID))
-		h := m.hash_mgr.NewHasher(cfg.ID(), opts)
-
-		helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h)
-		if err != nil {
-			return nil, fmt.Errorf("keygen: %w", err)
-		}
-
-		// sample fᵢ(X) deg(fᵢ) = t, fᵢ(0) = secretᵢ
-		key, err := m.ecdsa_km.GenerateKey(opts)
-		if err != nil {
-			return nil, fmt.Errorf("keygen: %w", err)
-		}
-		if err := key.GenerateVSSSecrets(helper.Threshold(), opts); err != nil {
-			return nil, fmt.Errorf("keygen: %w", err)
-		}
-
-		if err := m.configmgr.ImportConfig(cfg); err != nil {
-			return nil, err
-		}
-
-		if err := m.statemgr.NewState(cfg.ID()); err != nil {
-			return nil, err
-		}
-
-		return &round1{
-			Helper:      helper,
-			statemanger: m.statemgr,
-			msgmgr:      m.msgmgr,
-			bcstmgr:     m.bcstmgr,
-			elgamal_km:  m.elgamal_km,
-			paillier_km: m.paillier_km,
-			pedersen_km: m.pedersen_km,
-			ecdsa_km:    m.ecdsa_km,
-			ec_vss_km:   m.ec_vss_km,
-			vss_mgr:     m.vss_mgr,
-			rid_km:      m.rid_km,
-			chainKey_km: m.chainKey_km,
-			commit_mgr:  m.commit_mgr,
-		}, nil
+	return func(sessionID []byte) (round.Session, error) {
+		return m.start(cfg, pl, sessionID, nil)
+	}
+}
+
+// start creates the first round of a keygen session, or of a refresh of prev if it is not nil.
+func (m *MPCKeygen) start(cfg mpc_config.KeyConfig, pl *pool.Pool, sessionID []byte, prev *previousKey) (round.Session, error) {
+	// rounds of a CMP session only live in memory, so an existing session cannot be resumed
+	if _, err := m.statemgr.Get(cfg.ID()); err == nil {
+		return nil, fmt.Errorf("keygen: %w", mpc_state.ErrStateExists)
+	}
+
+	info := round.Info{
+		ProtocolID:       protocolKeygenID,
+		SelfID:           cfg.SelfID(),
+		PartyIDs:         cfg.PartyIDs(),
+		Threshold:        cfg.Threshold(),
+		Group:            cfg.Group(),
+		MaxParties:       cfg.MaxParties(),
+		FinalRoundNumber: Rounds,
+	}
+
+	// m.keys[keyID] = info
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.ID(), "partyid", string(info.SelfID))
+	h := m.hash_mgr.NewHasher(cfg.ID(), opts)
+
+	helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+	helper.SetMessageManagers(m.msgmgr, m.bcstmgr)
+
+	degree := cfg.VSSDegree()
+	if degree < helper.Threshold() || degree >= helper.N() {
+		return nil, fmt.Errorf("keygen: vss degree %d is invalid for threshold %d", degree, helper.Threshold())
+	}
+	if err := m.redundancy.check(helper.N(), degree); err != nil {
+		return nil, err
+	}
 
+	// sample fᵢ(X) deg(fᵢ) = d ≥ t, fᵢ(0) = secretᵢ
+	// Refresh: secretᵢ = λᵢ⋅x'ᵢ, so that the secrets still sum to the previous secret
+	var key ecdsa.ECDSAKey
+	if prev == nil {
+		key, err = m.ecdsa_km.GenerateKey(opts)
+	} else {
+		key, err = m.ecdsa_km.ImportKey(prev.key, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+	if err := key.GenerateVSSSecrets(degree, opts); err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+
+	if err := m.configmgr.ImportConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := m.statemgr.NewState(cfg.ID()); err != nil {
+		return nil, err
+	}
+
+	r := &round1{
+		Helper:      helper,
+		VSSDegree:   degree,
+		SessionID:   sessionID,
+		statemanger: m.statemgr,
+		msgmgr:      m.msgmgr,
+		bcstmgr:     m.bcstmgr,
+		elgamal_km:  m.elgamal_km,
+		paillier_km: m.paillier_km,
+		pedersen_km: m.pedersen_km,
+		ecdsa_km:    m.ecdsa_km,
+		ec_vss_km:   m.ec_vss_km,
+		vss_mgr:     m.vss_mgr,
+		rid_km:      m.rid_km,
+		chainKey_km: m.chainKey_km,
+		commit_mgr:  m.commit_mgr,
+	}
+	if prev != nil {
+		r.PreviousPublicSharesECDSA = prev.publicShares
+		r.PreviousChainKey = prev.chainKey
 	}
+	return r, nil
 }
@@ -1,15 +1,31 @@
 package keygen
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"fmt"
 	"testing"
 
+	"github.com/cronokirby/saferith"
+	"github.com/dgraph-io/badger"
 	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	paillier_core "github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	pedersen_core "github.com/mr-shifu/mpc-lib/core/pedersen"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/core/zk"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/lib/types"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/commitment"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/elgamal"
@@ -37,11 +53,11 @@ func checkOutput(t *testing.T, rounds []round.Session) {
 	for _, r := range rounds {
 		require.IsType(t, &round.Output{}, r)
 		resultRound := r.(*round.Output)
-		require.IsType(t, &config.Config{}, resultRound.Result)
-		c := resultRound.Result.(*config.Config)
+		require.IsType(t, &KeygenResult{}, resultRound.Result)
+		c := resultRound.Result.(*KeygenResult).Config
 		marshalledConfig, err := cbor.Marshal(c)
 		require.NoError(t, err)
-		unmarshalledConfig := config.EmptyConfig(group)
+		unmarshalledConfig := config.EmptyConfig(c.Group)
 		err = cbor.Unmarshal(marshalledConfig, unmarshalledConfig)
 		require.NoError(t, err)
 		newConfigs = append(newConfigs, unmarshalledConfig)
@@ -63,13 +79,18 @@ func checkOutput(t *testing.T, rounds []round.Session) {
 		}
 		data, err := c.MarshalBinary()
 		assert.NoError(t, err, "failed to marshal new config", c.ID)
-		c2 := config.EmptyConfig(group)
+		c2 := config.EmptyConfig(c.Group)
 		err = c2.UnmarshalBinary(data)
 		assert.NoError(t, err, "failed to unmarshal new config", c.ID)
 	}
 }
 
 func newMPCKeygen() *MPCKeygen {
+	return newMPCKeygenWithGroup(curve.Secp256k1{})
+}
+
+// newMPCKeygenWithGroup returns an MPCKeygen whose key managers work in group.
+func newMPCKeygenWithGroup(group curve.Curve) *MPCKeygen {
 	pl := pool.NewPool(0)
 
 	keycfgstore := mpc_config.NewInMemoryConfigStore()
@@ -86,7 +107,7 @@ func newMPCKeygen() *MPCKeygen {
 	elgamal_keyopts := keyopts.NewInMemoryKeyOpts()
 	elgamal_vault := vault.NewInMemoryVault()
 	elgamal_ks := keystore.NewInMemoryKeystore(elgamal_vault, elgamal_keyopts)
-	elgamal_km := elgamal.NewElgamalKeyManager(elgamal_ks, &elgamal.Config{Group: curve.Secp256k1{}})
+	elgamal_km := elgamal.NewElgamalKeyManager(elgamal_ks, &elgamal.Config{Group: group})
 
 	paillier_keyopts := keyopts.NewInMemoryKeyOpts()
 	paillier_vault := vault.NewInMemoryVault()
@@ -101,7 +122,7 @@ func newMPCKeygen() *MPCKeygen {
 	vss_keyopts := keyopts.NewInMemoryKeyOpts()
 	vss_vault := vault.NewInMemoryVault()
 	vss_ks := keystore.NewInMemoryKeystore(vss_vault, vss_keyopts)
-	vss_km := vss.NewVssKeyManager(vss_ks, curve.Secp256k1{})
+	vss_km := vss.NewVssKeyManager(vss_ks, group)
 
 	ec_keyopts := keyopts.NewInMemoryKeyOpts()
 	ec_vault := vault.NewInMemoryVault()
@@ -109,11 +130,11 @@ func newMPCKeygen() *MPCKeygen {
 	sch_keyopts := keyopts.NewInMemoryKeyOpts()
 	sch_vault := vault.NewInMemoryVault()
 	sch_ks := keystore.NewInMemoryKeystore(sch_vault, sch_keyopts)
-	ecdsa_km := ecdsa.NewECDSAKeyManager(ec_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	ecdsa_km := ecdsa.NewECDSAKeyManager(ec_ks, sch_ks, vss_km, &ecdsa.Config{Group: group})
 
 	ec_vss_keyopts := keyopts.NewInMemoryKeyOpts()
 	ec_vss_ks := keystore.NewInMemoryKeystore(ec_vault, ec_vss_keyopts)
-	ec_vss_km := ecdsa.NewECDSAKeyManager(ec_vss_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	ec_vss_km := ecdsa.NewECDSAKeyManager(ec_vss_ks, sch_ks, vss_km, &ecdsa.Config{Group: group})
 
 	rid_keyopts := keyopts.NewInMemoryKeyOpts()
 	rid_vault := vault.NewInMemoryVault()
@@ -182,3 +203,965 @@ func TestKeygen(t *testing.T) {
 	}
 	// checkOutput(t, rounds)
 }
+
+func TestKeygen_P256(t *testing.T) {
+	keyID := uuid.NewString()
+	group := curve.P256{}
+
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	// the commitments of round1 are decommitted in round3 with points of the second curve
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygenWithGroup(group).Start(cfg, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*KeygenResult).Config
+		assert.Equal(t, group.Name(), c.Group.Name())
+	}
+}
+
+func TestKeygen_PublicPolynomial(t *testing.T) {
+	keyID := uuid.NewString()
+
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygen().Start(cfg, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r)
+		res, err := Result(r)
+		require.NoError(t, err)
+		c := res.Config
+
+		F := c.PublicPolynomial()
+		require.NotNil(t, F)
+		assert.Equal(t, 1, F.Degree())
+		assert.True(t, F.Evaluate(group.NewScalar()).Equal(c.PublicPoint()), "F(0) is not the public key")
+		for _, j := range partyIDs {
+			assert.True(t, F.Evaluate(j.Scalar(group)).Equal(c.Public[j].ECDSA), "F(j) is not the public share of", j)
+		}
+
+		derived, err := c.DeriveBIP32(0)
+		require.NoError(t, err)
+		F = derived.PublicPolynomial()
+		assert.True(t, F.Constant().Equal(derived.PublicPoint()))
+		for _, j := range partyIDs {
+			assert.True(t, F.Evaluate(j.Scalar(group)).Equal(derived.Public[j].ECDSA), "F(j) is not the derived public share of", j)
+		}
+	}
+}
+
+func TestKeygen_ConfigSecrets(t *testing.T) {
+	keyID := uuid.NewString()
+
+	pl := pool.NewSerialPool()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygen().Start(cfg, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	for _, r := range rounds {
+		res, err := Result(r)
+		require.NoError(t, err)
+		c := res.Config
+		public := c.Public[c.ID]
+
+		require.NotNil(t, c.Paillier)
+		m := curve.MakeInt(sample.Scalar(rand.Reader, group))
+		ct, _ := public.Paillier.Enc(m)
+		decrypted, err := c.Paillier.Dec(ct)
+		require.NoError(t, err)
+		assert.Equal(t, saferith.Choice(1), decrypted.Eq(m), "decryption under own Paillier key differs")
+
+		require.NotNil(t, c.ElGamal)
+		assert.True(t, c.ElGamal.ActOnBase().Equal(public.ElGamal), "ElGamal secret does not match public key")
+	}
+}
+
+func TestKeygen_Result(t *testing.T) {
+	keyID := uuid.NewString()
+
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygen().Start(cfg, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+
+	_, err := Result(rounds[0])
+	assert.Error(t, err, "the result of an unfinished session should not be available")
+
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	var ssid []byte
+	for _, r := range rounds {
+		res, err := Result(r)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, res.SSID)
+		if ssid == nil {
+			ssid = res.SSID
+		}
+		assert.Equal(t, ssid, res.SSID, "parties should agree on the SSID")
+
+		assert.Len(t, res.Proofs, N)
+		require.NoError(t, res.VerifyProofs())
+		for _, j := range partyIDs {
+			h, err := res.Transcript.SchnorrHashForID(res.Config, j)
+			require.NoError(t, err)
+			assert.True(t, res.Proofs[j].Verify(h))
+		}
+
+		// the challenges are bound to the transcript
+		otherRID := *res.Transcript
+		otherRID.RID = types.RID(bytes.Repeat([]byte{1}, len(res.Transcript.RID)))
+		otherRes := *res
+		otherRes.Transcript = &otherRID
+		assert.Error(t, otherRes.VerifyProofs())
+
+		tampered := *res.Proofs[partyIDs[0]]
+		tampered.Response = group.NewScalar().Set(tampered.Response).Add(group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1)))
+		res.Proofs[partyIDs[0]] = &tampered
+		assert.EqualError(t, res.VerifyProofs(), fmt.Sprintf("keygen: invalid Schnorr proof of party %s", partyIDs[0]))
+	}
+}
+
+func TestKeygen_AuxProofsTranscript(t *testing.T) {
+	keyID := uuid.NewString()
+	sessionID := []byte("audited session")
+
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygen().Start(cfg, pl)(sessionID)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	res, err := Result(rounds[0])
+	require.NoError(t, err)
+	require.NotNil(t, res.Transcript)
+	assert.Len(t, res.ModProofs, N)
+	assert.Len(t, res.PrmProofs, N)
+	require.NoError(t, res.VerifyAuxProofs(pl))
+
+	// the auditor recomputes the hash of each party from the public session parameters alone
+	for _, j := range partyIDs {
+		h := hash.New(nil)
+		require.NoError(t, h.WriteAny(&core_hash.BytesWithDomain{TheDomain: "Session ID", Bytes: sessionID}))
+		require.NoError(t, h.WriteAny(&core_hash.BytesWithDomain{TheDomain: "Protocol ID", Bytes: []byte("cmp/keygen")}))
+		require.NoError(t, h.WriteAny(&core_hash.BytesWithDomain{TheDomain: "Group Name", Bytes: []byte(group.Name())}))
+		require.NoError(t, h.WriteAny(partyIDs, types.ThresholdWrapper(1), res.Transcript.RID, j))
+
+		recomputed, err := res.Transcript.HashForID(j)
+		require.NoError(t, err)
+		assert.Equal(t, h.Sum(), recomputed.Sum(), "transcript hash of party %s differs", j)
+
+		public := res.Config.Public[j]
+		assert.True(t, paillier.NewPaillierKey(nil, public.Paillier).VerifyZKMod(res.ModProofs[j], h.Clone(), pl), "invalid zkmod proof of party %s", j)
+		assert.True(t, pedersen.NewPedersenKey(nil, public.Pedersen).VerifyProof(h.Clone(), pl, res.PrmProofs[j]), "invalid zkprm proof of party %s", j)
+	}
+
+	// the proofs are bound to the transcript
+	tampered := *res.Transcript
+	tampered.RID = types.EmptyRID()
+	res.Transcript = &tampered
+	assert.Error(t, res.VerifyAuxProofs(pl))
+}
+
+// captureBroadcast4 records the broadcast4 sent by each party.
+type captureBroadcast4 map[party.ID]*broadcast4
+
+func (captureBroadcast4) ModifyBefore(round.Session) {}
+func (captureBroadcast4) ModifyAfter(round.Session)  {}
+func (c captureBroadcast4) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if b, ok := content.(*broadcast4); ok {
+		c[rNext.SelfID()] = b
+	}
+}
+
+// keygenUntilRound runs a keygen with N parties until they all reached round n, and returns
+// their rounds.
+func keygenUntilRound(tb testing.TB, N int, pl *pool.Pool, n round.Number, rule test.Rule) []round.Session {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygen().Start(cfg, pl)(nil)
+		require.NoError(tb, err)
+		rounds = append(rounds, r)
+	}
+	for rounds[0].Number() != n {
+		err, done := test.SerialRounds(rounds, rule)
+		require.NoError(tb, err)
+		require.False(tb, done)
+	}
+	return rounds
+}
+
+// keygenUntilRound4 runs a keygen with N parties until they all reached round4, and returns
+// their rounds with the proofs they broadcast.
+func keygenUntilRound4(tb testing.TB, N int, pl *pool.Pool) ([]*round4, map[party.ID]*broadcast4) {
+	proofs := captureBroadcast4{}
+	rounds := keygenUntilRound(tb, N, pl, 4, proofs)
+
+	r4 := make([]*round4, 0, N)
+	for _, r := range rounds {
+		r4 = append(r4, r.(*round4))
+	}
+	return r4, proofs
+}
+
+func TestKeygen_ContentRoundTrip(t *testing.T) {
+	pl := pool.NewSerialPool()
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		r, err := newMPCKeygen().Start(cfg, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	checker := &test.ContentChecker{}
+	for {
+		err, done := test.SerialRounds(rounds, checker)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	for _, content := range []round.Content{&broadcast2{}, &broadcast3{}, &broadcast4{}, &message4{}, &broadcast5{}} {
+		t.Run(fmt.Sprintf("%T", content), func(t *testing.T) {
+			assert.NoError(t, checker.Check(content))
+		})
+	}
+}
+
+func TestKeygen_Resume(t *testing.T) {
+	keyID := uuid.NewString()
+	sessionID := []byte("resume")
+
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	cfgs := make([]*mpc_config.KeyConfig, 0, N)
+	mpckgs := make([]*MPCKeygen, 0, N)
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		mpckg := newMPCKeygen()
+		r, err := mpckg.Start(cfg, pl)(sessionID)
+		require.NoError(t, err)
+		cfgs = append(cfgs, cfg)
+		mpckgs = append(mpckgs, mpckg)
+		rounds = append(rounds, r)
+	}
+
+	// run until round2 is finalized and the round3 messages are stored
+	for rounds[0].Number() != 3 {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	// crash: only the snapshot of the state and the keystores survive
+	for i, mpckg := range mpckgs {
+		snapshot, err := mpckg.statemgr.Snapshot(keyID)
+		require.NoError(t, err)
+
+		mpckg.statemgr = state.NewMPCStateManager(state.NewInMemoryStateStore())
+		_, err = mpckg.Resume(cfgs[i], pl)(sessionID)
+		require.Error(t, err, "a session without state should not be resumed")
+
+		require.NoError(t, mpckg.statemgr.Restore(snapshot))
+		r, err := mpckg.Resume(cfgs[i], pl)(sessionID)
+		require.NoError(t, err)
+		require.IsType(t, &round3{}, r)
+		assert.Equal(t, rounds[i].SSID(), r.SSID())
+		assert.True(t, r.CanFinalize())
+		rounds[i] = r
+	}
+
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+}
+
+// newBadgerMPCKeygen returns an MPCKeygen whose keys are stored in db, and whose configs and
+// messages are kept in the given stores, as a process restarting on persisted stores would.
+func newBadgerMPCKeygen(db *badger.DB, keycfgstore *mpc_config.InMemoryConfigStore, msgstore, bcststore *message.InMemoryMessageStore, pl *pool.Pool) *MPCKeygen {
+	ks := func(prefix string) *keystore.BadgerKeystore {
+		return keystore.NewBadgerKeystoreWithDB(db, prefix)
+	}
+	vss_km := vss.NewVssKeyManager(ks("vss"), curve.Secp256k1{})
+	sch_ks := ks("schnorr")
+	return NewMPCKeygen(
+		mpc_config.NewKeyConfigManager(keycfgstore),
+		state.NewMPCStateManager(state.NewInMemoryStateStore()),
+		message.NewMessageManager(msgstore),
+		message.NewMessageManager(bcststore),
+		elgamal.NewElgamalKeyManager(ks("elgamal"), &elgamal.Config{Group: curve.Secp256k1{}}),
+		paillier.NewPaillierKeyManager(ks("paillier"), pl),
+		pedersen.NewPedersenKeymanager(ks("pedersen")),
+		ecdsa.NewECDSAKeyManager(ks("ecdsa"), sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}}),
+		ecdsa.NewECDSAKeyManager(ks("ecdsa-vss"), sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}}),
+		vss_km,
+		rid.NewRIDManager(ks("rid")),
+		rid.NewRIDManager(ks("chainkey")),
+		hash.NewHashManager(ks("hash")),
+		commitment.NewCommitmentManager(ks("commitment")),
+		pl,
+	)
+}
+
+func TestKeygen_ResumeAfterRestart(t *testing.T) {
+	keyID := uuid.NewString()
+	sessionID := []byte("resume")
+
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	// the first party stores its keys in a database, the other ones keep running
+	dir := t.TempDir()
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	require.NoError(t, err)
+	keycfgstore := mpc_config.NewInMemoryConfigStore()
+	msgstore, bcststore := message.NewInMemoryMessageStore(), message.NewInMemoryMessageStore()
+	restarted := newBadgerMPCKeygen(db, keycfgstore, msgstore, bcststore, pl)
+
+	cfgs := make([]*mpc_config.KeyConfig, 0, N)
+	rounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := mpc_config.NewKeyConfig(keyID, group, 1, partyID, partyIDs)
+		mpckg := restarted
+		if i > 0 {
+			mpckg = newMPCKeygen()
+		}
+		r, err := mpckg.Start(cfg, pl)(sessionID)
+		require.NoError(t, err)
+		cfgs = append(cfgs, cfg)
+		rounds = append(rounds, r)
+	}
+
+	// run until round2 is finalized and the round3 messages are stored
+	for rounds[0].Number() != 3 {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	// restart: a new MPCKeygen reopens the database, and restores the snapshot of the state
+	snapshot, err := restarted.statemgr.Snapshot(keyID)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	db, err = badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	restarted = newBadgerMPCKeygen(db, keycfgstore, msgstore, bcststore, pl)
+	require.NoError(t, restarted.statemgr.Restore(snapshot))
+
+	r, err := restarted.Resume(cfgs[0], pl)(sessionID)
+	require.NoError(t, err)
+	require.IsType(t, &round3{}, r)
+	assert.Equal(t, rounds[0].SSID(), r.SSID())
+	rounds[0] = r
+
+	for {
+		err, done := test.SerialRounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+}
+
+func TestKeygen_ReceivedFrom(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N := 3
+	rounds := keygenUntilRound(t, N, pl, 4, nil)
+	for _, r := range rounds {
+		others := []party.ID(r.OtherPartyIDs())
+
+		// round3 only receives broadcast messages
+		broadcast, p2p, err := r.ReceivedFrom(3)
+		require.NoError(t, err)
+		assert.Equal(t, others, broadcast)
+		assert.Empty(t, p2p)
+
+		// round4 receives both
+		broadcast, p2p, err = r.ReceivedFrom(4)
+		require.NoError(t, err)
+		assert.Equal(t, others, broadcast)
+		assert.Equal(t, others, p2p)
+
+		broadcast, p2p, err = r.ReceivedFrom(5)
+		require.NoError(t, err)
+		assert.Empty(t, broadcast)
+		assert.Empty(t, p2p)
+	}
+}
+
+// cancelAfter is a context which is canceled once its Err method was called checks times, so
+// that a round is canceled at a deterministic point of its Finalize.
+type cancelAfter struct {
+	context.Context
+	checks int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestRound3_FinalizeContext(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	rounds := keygenUntilRound(t, 3, pl, 3, nil)
+
+	// a context canceled beforehand leaves the round untouched
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := make(chan *round.Message, len(rounds)+1)
+	next, err := rounds[0].(*round3).FinalizeContext(ctx, out)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, next)
+	assert.Empty(t, out)
+
+	next, err = rounds[0].(*round3).Finalize(out)
+	require.NoError(t, err)
+	assert.IsType(t, &round4{}, next)
+
+	// canceled once the zkmod proof is generated, the round returns before proving zkprm and
+	// sending anything
+	out = make(chan *round.Message, len(rounds)+1)
+	next, err = rounds[1].(*round3).FinalizeContext(&cancelAfter{Context: context.Background(), checks: 2}, out)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, next)
+	assert.Empty(t, out)
+}
+
+// shareMessages returns the messages of round3 r for partyIDs, created with the workers of pl, and
+// with a fixed nonce for the encryptions.
+func shareMessages(tb testing.TB, r *round3, partyIDs []party.ID, pl *pool.Pool) []*message4 {
+	opts := keyopts.Options{"protocol": keyopts.ProtocolCMP, "id": r.ID, "partyid": string(r.SelfID())}
+	pk, err := r.paillier_km.GetKey(opts)
+	require.NoError(tb, err)
+	vssKey, err := r.vss_mgr.GetSecrets(opts)
+	require.NoError(tb, err)
+	nonce := func(*saferith.Modulus) *saferith.Nat { return new(saferith.Nat).SetUint64(7) }
+
+	msgs, err := r.shareMessages(context.Background(), r.Hash().Clone(), pk, vssKey, partyIDs, pl, nonce)
+	require.NoError(tb, err)
+	return msgs
+}
+
+func TestRound3_ShareMessages(t *testing.T) {
+	pl := pool.NewPool(2)
+	defer pl.TearDown()
+
+	rounds := keygenUntilRound(t, 4, pl, 3, nil)
+	r := rounds[0].(*round3)
+	otherIDs := r.OtherPartyIDs()
+
+	sequential := shareMessages(t, r, otherIDs, pool.NewSerialPool())
+	parallel := shareMessages(t, r, otherIDs, pl)
+	require.Len(t, sequential, len(otherIDs))
+	require.Len(t, parallel, len(otherIDs))
+	for i, j := range otherIDs {
+		assert.True(t, sequential[i].Share.Equal(parallel[i].Share), j)
+		assert.NotNil(t, parallel[i].Fac, j)
+	}
+
+	// the messages are in the order of the parties
+	reversed := []party.ID{otherIDs[2], otherIDs[1], otherIDs[0]}
+	msgs := shareMessages(t, r, reversed, pl)
+	for i := range reversed {
+		assert.True(t, msgs[i].Share.Equal(sequential[len(otherIDs)-1-i].Share))
+	}
+}
+
+func BenchmarkRound3_ShareMessages(b *testing.B) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	rounds := keygenUntilRound(b, 16, pl, 3, nil)
+	r := rounds[0].(*round3)
+	otherIDs := r.OtherPartyIDs()
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			shareMessages(b, r, otherIDs, pool.NewSerialPool())
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			shareMessages(b, r, otherIDs, pl)
+		}
+	})
+}
+
+// keygenUntilRound3 runs a keygen with N parties until they all reached round3, without delivering
+// the round3 broadcasts, and returns the rounds with those broadcasts.
+func keygenUntilRound3(tb testing.TB, N int, pl *pool.Pool) ([]*round3, map[party.ID]*broadcast3) {
+	rounds := keygenUntilRound(tb, N, pl, 2, nil)
+
+	out := make(chan *round.Message, N*N)
+	r3 := make([]*round3, 0, N)
+	for _, r := range rounds {
+		next, err := r.Finalize(out)
+		require.NoError(tb, err)
+		r3 = append(r3, next.(*round3))
+	}
+	close(out)
+
+	broadcasts := make(map[party.ID]*broadcast3, N)
+	for msg := range out {
+		broadcasts[msg.From] = msg.Content.(*broadcast3)
+	}
+	return r3, broadcasts
+}
+
+// requireNothingStored checks that r stored none of the keys broadcast in round3 by from.
+func requireNothingStored(t *testing.T, r *round3, from party.ID) {
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(from))
+
+	_, err := r.rid_km.GetKey(opts)
+	assert.Error(t, err, "rid")
+	_, err = r.chainKey_km.GetKey(opts)
+	assert.Error(t, err, "chain key")
+	_, err = r.paillier_km.GetKey(opts)
+	assert.Error(t, err, "paillier")
+	_, err = r.pedersen_km.GetKey(opts)
+	assert.Error(t, err, "pedersen")
+	_, err = r.ecdsa_km.GetKey(opts)
+	assert.Error(t, err, "ecdsa")
+	_, err = r.elgamal_km.GetKey(opts)
+	assert.Error(t, err, "elgamal")
+	_, err = r.vss_mgr.GetSecrets(opts)
+	assert.Error(t, err, "vss")
+}
+
+func TestRound3_ModulusMismatch(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, broadcasts := keygenUntilRound3(t, 3, pl)
+
+	// the Pedersen parameters of another party are valid, but not derived from the Paillier key
+	from := rounds[1].SelfID()
+	body := *broadcasts[from]
+	body.PedersenKey = broadcasts[rounds[2].SelfID()].PedersenKey
+
+	err := rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: &body})
+	require.ErrorIs(t, err, ErrModulusMismatch)
+	requireNothingStored(t, rounds[0], from)
+
+	// the genuine message is still accepted afterwards
+	require.NoError(t, rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: broadcasts[from]}))
+}
+
+func TestRound3_InvalidAuxParameters(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, broadcasts := keygenUntilRound3(t, 3, pl)
+
+	from := rounds[1].SelfID()
+	ped, err := pedersen.ParseKey(broadcasts[from].PedersenKey)
+	require.NoError(t, err)
+	N, S, T := ped.PublicKeyRaw().NArith(), ped.PublicKeyRaw().S(), ped.PublicKeyRaw().T()
+
+	pedersenBytes := func(s, tt *saferith.Nat) []byte {
+		b, err := pedersen.NewPedersenKey(nil, pedersen_core.New(N, s, tt)).Bytes()
+		require.NoError(t, err)
+		return b
+	}
+	smallN := saferith.ModulusFromNat(new(saferith.Nat).SetUint64(3 * 7))
+	smallPaillier, err := paillier.NewPaillierKey(nil, paillier_core.NewPublicKey(smallN)).Bytes()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		modify   func(body *broadcast3)
+		expected error
+	}{
+		{"N too small", func(body *broadcast3) { body.PaillierKey = smallPaillier }, paillier_core.ErrPaillierLength},
+		{"S not in ZN*", func(body *broadcast3) { body.PedersenKey = pedersenBytes(N.Nat(), T) }, pedersen_core.ErrNotValidModN},
+		{"T not in ZN*", func(body *broadcast3) { body.PedersenKey = pedersenBytes(S, new(saferith.Nat)) }, pedersen_core.ErrNotValidModN},
+		{"S equal to T", func(body *broadcast3) { body.PedersenKey = pedersenBytes(S, S) }, pedersen_core.ErrSEqualT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := *broadcasts[from]
+			tt.modify(&body)
+
+			err := rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: &body})
+			require.ErrorIs(t, err, tt.expected)
+			assert.ErrorContains(t, err, string(from))
+			requireNothingStored(t, rounds[0], from)
+		})
+	}
+
+	require.NoError(t, rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: broadcasts[from]}))
+}
+
+func TestRound3_InvalidVSSPolynomial(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, broadcasts := keygenUntilRound3(t, 3, pl)
+	r := rounds[0]
+	from := rounds[1].SelfID()
+
+	polynomialBytes := func(degree int, constant curve.Scalar) []byte {
+		b, err := polynomial.NewPolynomialExponent(polynomial.NewPolynomial(group, degree, constant)).MarshalBinary()
+		require.NoError(t, err)
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		poly     []byte
+		previous map[party.ID]curve.Point
+		expected error
+	}{
+		{"degree too high", polynomialBytes(r.VSSDegree+1, sample.Scalar(rand.Reader, group)), nil, ErrVSSDegree},
+		{"degree too high is invalid content", polynomialBytes(r.VSSDegree+1, sample.Scalar(rand.Reader, group)), nil, round.ErrInvalidContent},
+		{"oversized encoding", append([]byte{0xff, 0xff, 0xff, 0xff}, make([]byte, 1<<16)...), nil, round.ErrInvalidContent},
+		{"zero constant", polynomialBytes(r.VSSDegree, group.NewScalar()), nil, ErrVSSConstant},
+		{
+			"refresh with another constant",
+			broadcasts[from].VSSPolynomial,
+			map[party.ID]curve.Point{from: sample.Scalar(rand.Reader, group).ActOnBase()},
+			ErrVSSConstant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.PreviousPublicSharesECDSA = tt.previous
+			defer func() { r.PreviousPublicSharesECDSA = nil }()
+
+			body := *broadcasts[from]
+			body.VSSPolynomial = tt.poly
+			err := r.StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: &body})
+			require.ErrorIs(t, err, tt.expected)
+			requireNothingStored(t, r, from)
+		})
+	}
+
+	require.NoError(t, r.StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: broadcasts[from]}))
+}
+
+func TestRound3_InvalidDecommitment(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, broadcasts := keygenUntilRound3(t, 3, pl)
+
+	// every key of the message is valid, but the chain key is not the committed one
+	from := rounds[1].SelfID()
+	body := *broadcasts[from]
+	body.C = broadcasts[rounds[2].SelfID()].C
+
+	err := rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: &body})
+	require.EqualError(t, err, "failed to decommit")
+	requireNothingStored(t, rounds[0], from)
+
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", rounds[0].ID, "partyid", string(from))
+	cmt, err := rounds[0].commit_mgr.Get(opts)
+	require.NoError(t, err)
+	assert.Empty(t, cmt.Decommitment())
+	assert.False(t, rounds[0].CanFinalize())
+
+	require.NoError(t, rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: broadcasts[from]}))
+}
+
+func TestRound3_DuplicateMessage(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, broadcasts := keygenUntilRound3(t, 3, pl)
+
+	from := rounds[1].SelfID()
+	require.NoError(t, rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: broadcasts[from]}))
+
+	// a replay carrying the keys of another party must not replace the stored ones
+	replay := *broadcasts[rounds[2].SelfID()]
+	err := rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: &replay})
+	require.ErrorIs(t, err, round.ErrDuplicateMessage)
+	err = rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: broadcasts[from]})
+	require.ErrorIs(t, err, round.ErrDuplicateMessage)
+
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", rounds[0].ID, "partyid", string(from))
+	rid, err := rounds[0].rid_km.GetKey(opts)
+	require.NoError(t, err)
+	assert.EqualValues(t, broadcasts[from].RID, rid.Raw())
+}
+
+func TestRound4_DuplicateMessage(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, proofs := keygenUntilRound4(t, 3, pl)
+
+	// the broadcasts of round4 were all delivered by keygenUntilRound4
+	from := rounds[1].SelfID()
+	err := rounds[0].StoreBroadcastMessage(round.Message{From: from, Broadcast: true, Content: proofs[from]})
+	require.ErrorIs(t, err, round.ErrDuplicateMessage)
+}
+
+// oversizedCiphertext returns a ciphertext larger than N² for any valid Paillier modulus N.
+func oversizedCiphertext(t *testing.T) *paillier_core.Ciphertext {
+	data := make([]byte, 2*params.BytesCiphertext)
+	data[0] = 1
+	b, err := new(saferith.Nat).SetBytes(data).MarshalBinary()
+	require.NoError(t, err)
+	ct := &paillier_core.Ciphertext{}
+	require.NoError(t, ct.UnmarshalBinary(b))
+	return ct
+}
+
+func TestRound4_OversizedShare(t *testing.T) {
+	// the share is rejected before the round accesses any of its keys
+	r := &round4{}
+	err := r.VerifyMessage(round.Message{From: "a", To: "b", Content: &message4{Share: oversizedCiphertext(t)}})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+	err = r.VerifyMessage(round.Message{From: "a", To: "b", Content: &message4{}})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+}
+
+func TestRound4_ShareCiphertextErrors(t *testing.T) {
+	pl := pool.NewSerialPool()
+	rounds, _ := keygenUntilRound4(t, 3, pl)
+	r := rounds[0]
+	from := rounds[1].SelfID()
+
+	// N is in the range of ciphertexts, but is not a unit modulo N²
+	opts := keyopts.Options{"protocol": keyopts.ProtocolCMP, "id": r.ID, "partyid": string(r.SelfID())}
+	pk, err := r.paillier_km.GetKey(opts)
+	require.NoError(t, err)
+	b, err := pk.PublicKey().ParamN().Nat().MarshalBinary()
+	require.NoError(t, err)
+	share := &paillier_core.Ciphertext{}
+	require.NoError(t, share.UnmarshalBinary(b))
+
+	msg := round.Message{From: from, To: r.SelfID(), Content: &message4{Share: share}}
+	err = r.VerifyMessage(msg)
+	require.ErrorIs(t, err, ErrInvalidShareCiphertext)
+
+	// a round missing its own Paillier key fails without blaming the sender
+	missing := *r.round1
+	missing.paillier_km = paillier.NewPaillierKeyManager(
+		keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()), pl)
+	rMissing := &round4{round3: &round3{round2: &round2{round1: &missing}}}
+	err = rMissing.VerifyMessage(msg)
+	require.ErrorIs(t, err, paillier.ErrKeyNotFound)
+	assert.NotErrorIs(t, err, ErrInvalidShareCiphertext)
+}
+
+func TestRound4_VerifyAllBroadcasts(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	rounds, proofs := keygenUntilRound4(t, 3, pl)
+	partyIDs := rounds[0].PartyIDs()
+	require.Len(t, proofs, len(partyIDs))
+	require.NoError(t, rounds[0].VerifyAllBroadcasts(proofs))
+
+	// the proof of another party is attributed to the party which sent it
+	tampered := make(map[party.ID]*broadcast4, len(proofs))
+	for j, b := range proofs {
+		tampered[j] = b
+	}
+	tampered[partyIDs[1]] = &broadcast4{Mod: proofs[partyIDs[2]].Mod, Prm: proofs[partyIDs[1]].Prm}
+
+	err := rounds[0].VerifyAllBroadcasts(tampered)
+	var protocolErr protocol.Error
+	require.ErrorAs(t, err, &protocolErr)
+	assert.Equal(t, []party.ID{partyIDs[1]}, protocolErr.Culprits)
+}
+
+// replaceMod replaces the zkmod proof broadcast by party from in round4 with mod.
+type replaceMod struct {
+	from party.ID
+	mod  *zkmod.Proof
+}
+
+func (replaceMod) ModifyBefore(round.Session) {}
+func (replaceMod) ModifyAfter(round.Session)  {}
+func (rule replaceMod) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if b, ok := content.(*broadcast4); ok && rNext.SelfID() == rule.from {
+		b.Mod = rule.mod
+	}
+}
+
+func TestRound4_FinalizeVerifiesBroadcasts(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	// a proof for the Paillier modulus of another session is stored, but fails in Finalize
+	_, foreign := keygenUntilRound4(t, 3, pl)
+	partyIDs := test.PartyIDs(3)
+	rounds := keygenUntilRound(t, 3, pl, 4, replaceMod{from: partyIDs[1], mod: foreign[partyIDs[1]].Mod})
+
+	next, err := rounds[0].Finalize(make(chan *round.Message, 2*len(partyIDs)))
+	require.NoError(t, err)
+	abort, ok := next.(*round.Abort)
+	require.True(t, ok, "round4 returned %T", next)
+	assert.Equal(t, []party.ID{partyIDs[1]}, abort.Culprits)
+}
+
+func BenchmarkRound4_VerifyBroadcasts(b *testing.B) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	rounds, proofs := keygenUntilRound4(b, 16, pl)
+	r := rounds[0]
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j, proof := range proofs {
+				if err := r.verifyBroadcast(j, proof, r.Pool); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := r.VerifyAllBroadcasts(proofs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestKeygen_RedundancyPolicy(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	start := func(policy *RedundancyPolicy, threshold int) error {
+		mpckg := newMPCKeygen()
+		if policy != nil {
+			mpckg.SetRedundancyPolicy(*policy)
+		}
+		cfg := mpc_config.NewKeyConfig(uuid.NewString(), group, threshold, partyIDs[0], partyIDs)
+		_, err := mpckg.Start(cfg, nil)(nil)
+		return err
+	}
+
+	// by default, a threshold requiring all parties is accepted
+	assert.NoError(t, start(nil, N-1))
+
+	strict := &RedundancyPolicy{MinSpare: 1}
+	assert.ErrorIs(t, start(strict, N-1), ErrInsufficientRedundancy)
+	assert.NoError(t, start(strict, N-2))
+
+	// the parties needed to sign depend on the VSS degree, not on the threshold of the session
+	mpckg := newMPCKeygen()
+	mpckg.SetRedundancyPolicy(*strict)
+	cfg, err := mpc_config.NewKeyConfig(uuid.NewString(), group, N-2, partyIDs[0], partyIDs).WithVSSDegree(N - 1)
+	require.NoError(t, err)
+	_, err = mpckg.Start(cfg, nil)(nil)
+	assert.ErrorIs(t, err, ErrInsufficientRedundancy)
+
+	var warnings []error
+	lenient := &RedundancyPolicy{MinSpare: 1, Warn: func(err error) { warnings = append(warnings, err) }}
+	assert.NoError(t, start(lenient, N-1))
+	require.Len(t, warnings, 1)
+	assert.ErrorIs(t, warnings[0], ErrInsufficientRedundancy)
+}
+
+func TestKeygen_NoParties(t *testing.T) {
+	cfg := mpc_config.NewKeyConfig(uuid.NewString(), group, 0, "a", nil)
+	_, err := newMPCKeygen().Start(cfg, nil)(nil)
+	assert.ErrorContains(t, err, "no parties")
+}
+
+func TestCheckAuxModuli(t *testing.T) {
+	// zk.Pedersen is derived from the modulus of the verifier's Paillier key
+	ped := pedersen.NewPedersenKey(nil, zk.Pedersen)
+	assert.NoError(t, checkAuxModuli(paillier.NewPaillierKey(nil, zk.VerifierPaillierPublic), ped))
+	assert.ErrorIs(t, checkAuxModuli(paillier.NewPaillierKey(nil, zk.ProverPaillierPublic), ped), ErrModulusMismatch)
+}
@@ -0,0 +1,44 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInsufficientRedundancy is returned by Start when the VSS degree of a keygen leaves fewer spare
+// parties than required by the RedundancyPolicy.
+var ErrInsufficientRedundancy = errors.New("keygen: VSS degree leaves too few spare parties")
+
+// RedundancyPolicy guards against keys which leave no fault tolerance. With a VSS polynomial of
+// degree d, which is at least the threshold of the session, d+1 of the N parties are needed to
+// sign, so that N-d-1 of them may become unavailable.
+//
+// The zero value accepts every key.
+type RedundancyPolicy struct {
+	// MinSpare is the minimum number N-d-1 of parties which may be unavailable without losing
+	// the ability to sign. A degree of N-1 leaves no spare party.
+	MinSpare int
+	// Warn, if not nil, is called with the error instead of rejecting the keygen.
+	Warn func(error)
+}
+
+// check returns ErrInsufficientRedundancy if a keygen of n parties with the given VSS degree
+// violates the policy, unless the policy only warns.
+func (p RedundancyPolicy) check(n, degree int) error {
+	spare := n - degree - 1
+	if spare >= p.MinSpare {
+		return nil
+	}
+	err := fmt.Errorf("%w: degree %d of %d parties leaves %d, need %d", ErrInsufficientRedundancy, degree, n, spare, p.MinSpare)
+	if p.Warn != nil {
+		p.Warn(err)
+		return nil
+	}
+	return err
+}
+
+// SetRedundancyPolicy applies p to the keygen sessions started afterwards. By default, no minimum
+// redundancy is required.
+func (m *MPCKeygen) SetRedundancyPolicy(p RedundancyPolicy) {
+	m.redundancy = p
+}
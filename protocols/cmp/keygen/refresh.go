@@ -0,0 +1,120 @@
+package keygen
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	mpc_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+)
+
+var (
+	// ErrRefreshMissingParties is returned, wrapped in a protocol.Error naming them, when parties
+	// of the refreshed key are not part of the refresh.
+	ErrRefreshMissingParties = errors.New("keygen: parties of the key are missing from the refresh")
+	// ErrRefreshUnknownParties is returned, wrapped in a protocol.Error naming them, when parties
+	// of the refresh do not hold a share of the refreshed key.
+	ErrRefreshUnknownParties = errors.New("keygen: parties of the refresh do not hold the key")
+)
+
+// previousKey holds what a refresh carries over from the key being refreshed.
+type previousKey struct {
+	// key = λᵢ⋅x'ᵢ is the previous share of this party, scaled by its Lagrange coefficient
+	key ecdsa.ECDSAKey
+	// publicShares[j] = λⱼ⋅X'ⱼ
+	publicShares map[party.ID]curve.Point
+	chainKey     types.RID
+}
+
+// Refresh returns a function which starts a refresh of the key previousKeyID, stored by an earlier
+// keygen or refresh with this MPCKeygen.
+//
+// The refresh runs the keygen rounds under cfg.ID(), with the previous shares of the parties,
+// scaled by their Lagrange coefficients, as the constant of their VSS polynomials. The new key
+// therefore has the same public key and chain key as the previous one, with fresh shares and fresh
+// Paillier, Pedersen and ElGamal keys. The previous key is left untouched.
+//
+// All parties of the previous key must take part in the refresh, since the share of a party which
+// misses it would not be valid with the new key: Refresh fails with a protocol.Error naming the
+// missing parties otherwise.
+func (m *MPCKeygen) Refresh(cfg mpc_config.KeyConfig, previousKeyID string, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		prev, err := m.previousKey(cfg, previousKeyID)
+		if err != nil {
+			return nil, err
+		}
+		return m.start(cfg, pl, sessionID, prev)
+	}
+}
+
+// previousKey loads the share of the key previousKeyID which is refreshed with cfg.
+func (m *MPCKeygen) previousKey(cfg mpc_config.KeyConfig, previousKeyID string) (*previousKey, error) {
+	prevCfg, err := m.configmgr.GetConfig(previousKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: refresh: %w", err)
+	}
+	if prevCfg.Group().Name() != cfg.Group().Name() {
+		return nil, fmt.Errorf("keygen: refresh: key is on %s, not %s", prevCfg.Group().Name(), cfg.Group().Name())
+	}
+	if missing := partiesNotIn(prevCfg.PartyIDs(), cfg.PartyIDs()); len(missing) > 0 {
+		return nil, protocol.Error{Culprits: missing, Err: ErrRefreshMissingParties}
+	}
+	if unknown := partiesNotIn(cfg.PartyIDs(), prevCfg.PartyIDs()); len(unknown) > 0 {
+		return nil, protocol.Error{Culprits: unknown, Err: ErrRefreshUnknownParties}
+	}
+
+	rootOpts := keyopts.Options{}
+	rootOpts.Set("protocol", keyopts.ProtocolCMP, "id", previousKeyID, "partyid", "ROOT")
+	vss, err := m.vss_mgr.GetSecrets(rootOpts)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: refresh: %w", err)
+	}
+	chainKey, err := m.chainKey_km.GetKey(rootOpts)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: refresh: %w", err)
+	}
+
+	lagrange := m.vss_mgr.LagrangeBasis(cfg.PartyIDs())
+	prev := &previousKey{
+		publicShares: make(map[party.ID]curve.Point, len(cfg.PartyIDs())),
+		chainKey:     types.RID(chainKey.Raw()).Copy(),
+	}
+	for _, j := range cfg.PartyIDs() {
+		shareOpts := keyopts.Options{}
+		shareOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
+		share, err := m.ec_vss_km.GetKey(shareOpts)
+		if err != nil {
+			return nil, fmt.Errorf("keygen: refresh: share of party %s: %w", j, err)
+		}
+
+		scaled := share.CloneByMultiplier(lagrange[j])
+		prev.publicShares[j] = scaled.PublicKeyRaw()
+		if j == cfg.SelfID() {
+			if !share.Private() {
+				return nil, errors.New("keygen: refresh: missing own secret share")
+			}
+			prev.key = scaled
+		}
+	}
+	return prev, nil
+}
+
+// partiesNotIn returns the parties of ids which are not in others.
+func partiesNotIn(ids, others party.IDSlice) []party.ID {
+	sorted := party.NewIDSlice(others)
+	var missing []party.ID
+	for _, id := range ids {
+		if !sorted.Contains(id) {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
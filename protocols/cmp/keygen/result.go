@@ -0,0 +1,201 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
+	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	sw_hash "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillier"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
+	zkschnorr "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/zk-schnorr"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+)
+
+// KeygenResult is the result of a successful keygen, with what is needed to audit it.
+type KeygenResult struct {
+	// Config is the new config of this party.
+	Config *config.Config
+	// SSID is the digest of the session transcript once the new config was written to it.
+	// It is the same for all parties.
+	SSID []byte
+	// Proofs holds the Schnorr proofs of knowledge of the ECDSA key of every party, including
+	// this one, as broadcast in the last round.
+	Proofs map[party.ID]*SchnorrProof
+	// Transcript holds the public inputs of the session hash, from which the hash every party
+	// used for its zkmod and zkprm proofs can be recomputed.
+	Transcript *Transcript
+	// ModProofs and PrmProofs hold the zkmod and zkprm proofs of the Paillier and Pedersen
+	// parameters of every party, including this one, as broadcast in round 4.
+	ModProofs map[party.ID]*zkmod.Proof
+	PrmProofs map[party.ID]*zkprm.Proof
+}
+
+// Transcript holds what was written to the session hash before the zkmod and zkprm proofs
+// were generated: the session parameters, and the RID agreed on in round 3.
+type Transcript struct {
+	// SessionID is the optional session identifier the session was started with.
+	SessionID []byte
+	Group     curve.Curve
+	PartyIDs  party.IDSlice
+	Threshold int
+	RID       types.RID
+}
+
+// HashForID recomputes the hash of the session for id, as used by party id for its zkmod and
+// zkprm proofs.
+func (t *Transcript) HashForID(id party.ID) (hash.Hash, error) {
+	helper, err := t.helper(id)
+	if err != nil {
+		return nil, err
+	}
+	return helper.HashForID(id), nil
+}
+
+// SchnorrHashForID recomputes the hash of the session for id once cfg was written to it, as used
+// by party id for its Schnorr proof in the last round.
+func (t *Transcript) SchnorrHashForID(cfg *config.Config, id party.ID) (hash.Hash, error) {
+	helper, err := t.helper(id)
+	if err != nil {
+		return nil, err
+	}
+	helper.UpdateHashState(cfg)
+	return helper.HashForID(id), nil
+}
+
+// helper returns a session of party id whose hash holds the public inputs of the transcript.
+func (t *Transcript) helper(id party.ID) (*round.Helper, error) {
+	info := round.Info{
+		ProtocolID:       protocolKeygenID,
+		SelfID:           id,
+		PartyIDs:         t.PartyIDs,
+		Threshold:        t.Threshold,
+		Group:            t.Group,
+		MaxParties:       len(t.PartyIDs),
+		FinalRoundNumber: Rounds,
+	}
+	helper, err := round.NewSession("", info, t.SessionID, nil, sw_hash.New(nil))
+	if err != nil {
+		return nil, fmt.Errorf("keygen: invalid transcript: %w", err)
+	}
+	helper.UpdateHashState(t.RID)
+	return helper, nil
+}
+
+// SchnorrProof is a proof of knowledge of the discrete logarithm of Public, which is valid for a
+// hash h if Response⋅G = Commitment + e⋅Public, where the challenge e is derived from h,
+// Commitment and Public.
+type SchnorrProof struct {
+	Public     curve.Point
+	Commitment curve.Point
+	Response   curve.Scalar
+}
+
+// Verify returns true if the proof is valid for the hash h, which must be recomputed by the
+// verifier, for instance with Transcript.SchnorrHashForID.
+func (p *SchnorrProof) Verify(h hash.Hash) bool {
+	if !p.valid() {
+		return false
+	}
+	e, err := zkschnorr.Challenge(h, p.Public.Curve(), p.Commitment, p.Public)
+	if err != nil {
+		return false
+	}
+	rhs := e.Act(p.Public).Add(p.Commitment)
+	return p.Response.ActOnBase().Equal(rhs)
+}
+
+func (p *SchnorrProof) valid() bool {
+	if p == nil || p.Public == nil || p.Commitment == nil || p.Response == nil {
+		return false
+	}
+	return !p.Public.IsIdentity() && !p.Response.IsZero()
+}
+
+// VerifyProofs checks that there is a valid proof for each party of the config, with challenges
+// recomputed from the transcript, and that the proven public keys sum to the public key of the
+// config. The proofs are verified as a batch.
+func (res *KeygenResult) VerifyProofs() error {
+	if res.Transcript == nil {
+		return errors.New("keygen: missing transcript")
+	}
+	partyIDs := res.Config.PartyIDs()
+	proofs := make([]zkschnorr.Proof, 0, len(partyIDs))
+	publics := make([]curve.Point, 0, len(partyIDs))
+	challenges := make([]curve.Scalar, 0, len(partyIDs))
+	sum := res.Config.Group.NewPoint()
+	for _, j := range partyIDs {
+		p := res.Proofs[j]
+		if !p.valid() {
+			return fmt.Errorf("keygen: invalid Schnorr proof of party %s", j)
+		}
+		h, err := res.Transcript.SchnorrHashForID(res.Config, j)
+		if err != nil {
+			return err
+		}
+		e, err := zkschnorr.Challenge(h, res.Config.Group, p.Commitment, p.Public)
+		if err != nil {
+			return fmt.Errorf("keygen: %w", err)
+		}
+		proofs = append(proofs, zkschnorr.Proof{Commitment: p.Commitment, Response: p.Response})
+		publics = append(publics, p.Public)
+		challenges = append(challenges, e)
+		sum = sum.Add(p.Public)
+	}
+	if !sum.Equal(res.Config.PublicPoint()) {
+		return errors.New("keygen: Schnorr proofs do not sum to the public key")
+	}
+
+	var batchErr zkschnorr.BatchError
+	if err := zkschnorr.BatchVerify(proofs, publics, challenges); errors.As(err, &batchErr) {
+		return fmt.Errorf("keygen: invalid Schnorr proof of party %s", partyIDs[batchErr.Index])
+	} else if err != nil {
+		return fmt.Errorf("keygen: %w", err)
+	}
+	return nil
+}
+
+// VerifyAuxProofs checks that there is a valid zkmod and zkprm proof for the Paillier and
+// Pedersen parameters of each party of the config, against hashes recomputed from the transcript.
+func (res *KeygenResult) VerifyAuxProofs(pl *pool.Pool) error {
+	if res.Transcript == nil {
+		return errors.New("keygen: missing transcript")
+	}
+	for _, j := range res.Config.PartyIDs() {
+		public, ok := res.Config.Public[j]
+		if !ok {
+			return fmt.Errorf("keygen: missing public data of party %s", j)
+		}
+		h, err := res.Transcript.HashForID(j)
+		if err != nil {
+			return err
+		}
+		if !paillier.NewPaillierKey(nil, public.Paillier).VerifyZKMod(res.ModProofs[j], h.Clone(), pl) {
+			return fmt.Errorf("keygen: invalid zkmod proof of party %s", j)
+		}
+		if !pedersen.NewPedersenKey(nil, public.Pedersen).VerifyProof(h, pl, res.PrmProofs[j]) {
+			return fmt.Errorf("keygen: invalid zkprm proof of party %s", j)
+		}
+	}
+	return nil
+}
+
+// Result returns the result of a keygen session which finished successfully.
+func Result(s round.Session) (*KeygenResult, error) {
+	output, ok := s.(*round.Output)
+	if !ok {
+		return nil, errors.New("keygen: session has not finished")
+	}
+	res, ok := output.Result.(*KeygenResult)
+	if !ok {
+		return nil, fmt.Errorf("keygen: unexpected result type %T", output.Result)
+	}
+	return res, nil
+}
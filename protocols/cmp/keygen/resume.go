@@ -0,0 +1,133 @@
+package keygen
+
+import (
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	mpc_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+	mpc_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
+)
+
+// Resume returns a StartFunc which continues the keygen session cfg.ID() from the state found in
+// the state manager of m, for instance after it was restored from a snapshot following a crash.
+// The keys of the session must still be in the keystores of m, and the StartFunc must be called
+// with the session ID the session was started with.
+//
+// A session can be resumed until round3 is finalized, since the later rounds keep the proofs
+// they receive in memory only. A refresh cannot be resumed.
+func (m *MPCKeygen) Resume(cfg mpc_config.KeyConfig, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		state, err := m.statemgr.Get(cfg.ID())
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+		if state.Aborted() || state.Completed() {
+			return nil, fmt.Errorf("keygen: %w", mpc_state.ErrStateFinished)
+		}
+		if state.LastRound() > 2 {
+			return nil, fmt.Errorf("keygen: cannot resume a session after round %d", state.LastRound())
+		}
+
+		stored, err := m.configmgr.GetConfig(cfg.ID())
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+		if stored.SelfID() != cfg.SelfID() ||
+			stored.Threshold() != cfg.Threshold() ||
+			stored.VSSDegree() != cfg.VSSDegree() ||
+			!party.NewIDSlice(stored.PartyIDs()).Equal(party.NewIDSlice(cfg.PartyIDs())) {
+			return nil, fmt.Errorf("keygen: %w: session was started with a different config", mpc_state.ErrStateExists)
+		}
+
+		info := round.Info{
+			ProtocolID:       protocolKeygenID,
+			SelfID:           cfg.SelfID(),
+			PartyIDs:         cfg.PartyIDs(),
+			Threshold:        cfg.Threshold(),
+			Group:            cfg.Group(),
+			MaxParties:       cfg.MaxParties(),
+			FinalRoundNumber: Rounds,
+		}
+
+		opts := keyopts.Options{}
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.ID(), "partyid", string(info.SelfID))
+		h, err := m.hash_mgr.RestoreHasher(cfg.ID(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+
+		// a new hasher is only stored once written to, so a clone of it is an empty hash
+		helper, err := round.ResumeSession(cfg.ID(), info, sessionID, pl, m.hash_mgr.NewHasher(cfg.ID(), opts).Clone(), h)
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+		helper.SetMessageManagers(m.msgmgr, m.bcstmgr)
+
+		r1 := &round1{
+			Helper:      helper,
+			VSSDegree:   cfg.VSSDegree(),
+			SessionID:   sessionID,
+			statemanger: m.statemgr,
+			msgmgr:      m.msgmgr,
+			bcstmgr:     m.bcstmgr,
+			elgamal_km:  m.elgamal_km,
+			paillier_km: m.paillier_km,
+			pedersen_km: m.pedersen_km,
+			ecdsa_km:    m.ecdsa_km,
+			ec_vss_km:   m.ec_vss_km,
+			vss_mgr:     m.vss_mgr,
+			rid_km:      m.rid_km,
+			chainKey_km: m.chainKey_km,
+			commit_mgr:  m.commit_mgr,
+		}
+
+		switch state.LastRound() {
+		case 0:
+			return r1, nil
+		case 1:
+			return &round2{round1: r1}, nil
+		default:
+			aggregate, err := m.receivedPolynomials(cfg.ID())
+			if err != nil {
+				return nil, err
+			}
+			return &round3{round2: &round2{round1: r1}, aggregate: aggregate}, nil
+		}
+	}
+}
+
+// receivedPolynomials sums the VSS polynomials of the parties whose round3 message was stored.
+func (m *MPCKeygen) receivedPolynomials(keyID string) (*polynomial.ExponentAggregate, error) {
+	aggregate := polynomial.NewExponentAggregate()
+
+	// no message was stored yet
+	received, err := m.bcstmgr.GetAll(keyID, 3)
+	if err != nil {
+		return aggregate, nil
+	}
+
+	for from, msg := range received {
+		if !msg.Verified() {
+			continue
+		}
+		fromOpts := keyopts.Options{}
+		fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", keyID, "partyid", from)
+		vssKey, err := m.vss_mgr.GetSecrets(fromOpts)
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+		exponents, err := vssKey.ExponentsRaw()
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+		if err := aggregate.Add(party.ID(from), exponents); err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+	}
+	return aggregate, nil
+}
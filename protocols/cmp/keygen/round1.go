@@ -1,6 +1,7 @@
 package keygen
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 
@@ -39,15 +40,17 @@ type round1 struct {
 	chainKey_km rid.RIDManager
 	commit_mgr  commitment.CommitmentManager
 
-	// PreviousSecretECDSA = sk'ᵢ
-	// Contains the previous secret ECDSA key share which is being refreshed
-	// Keygen:  sk'ᵢ = nil
-	// Refresh: sk'ᵢ = sk'ᵢ
-	PreviousSecretECDSA curve.Scalar
+	// VSSDegree is the degree of the VSS polynomials, which is at least the threshold.
+	VSSDegree int
 
-	// PreviousPublicSharesECDSA[j] = pk'ⱼ
+	// SessionID is the optional session identifier the session was started with.
+	SessionID []byte
+
+	// PreviousPublicSharesECDSA[j] = λⱼ⋅pk'ⱼ
+	// Contains the previous public ECDSA key shares which are being refreshed, scaled by the
+	// Lagrange coefficients of the parties, which must be the constants of their VSS polynomials.
 	// Keygen:  pk'ⱼ = nil
-	// Refresh: pk'ⱼ = pk'ⱼ
+	// Refresh: pk'ⱼ = λⱼ⋅pk'ⱼ
 	PreviousPublicSharesECDSA map[party.ID]curve.Point
 
 	// PreviousChainKey contains the chain key, if we're refreshing
@@ -65,7 +68,12 @@ func (r *round1) StoreBroadcastMessage(round.Message) error { return nil }
 // StoreMessage implements round.Round.
 func (r *round1) StoreMessage(round.Message) error { return nil }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - sample Paillier (pᵢ, qᵢ)
 // - sample Pedersen Nᵢ, sᵢ, tᵢ
@@ -75,10 +83,15 @@ func (r *round1) StoreMessage(round.Message) error { return nil }
 // - sample ridᵢ <- {0,1}ᵏ
 // - sample cᵢ <- {0,1}ᵏ
 // - commit to message.
-func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round1) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	// generate Paillier and Pedersen
 	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(r.SelfID()))
 	paillierKey, err := r.paillier_km.GenerateKey(opts)
 	if err != nil {
 		return nil, err
@@ -117,7 +130,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	sharePublic := share.ActOnBase()
 	shareKey := r.ecdsa_km.NewKey(share, sharePublic, r.Group())
 	vssOpts := keyopts.Options{}
-	vssOpts.Set("id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
+	vssOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
 	if _, err := r.ec_vss_km.ImportKey(shareKey, vssOpts); err != nil {
 		return nil, err
 	}
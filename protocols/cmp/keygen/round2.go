@@ -1,7 +1,9 @@
 package keygen
 
 import (
+	"context"
 	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 )
@@ -30,7 +32,7 @@ func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 	}
 
 	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(msg.From))
+	fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(msg.From))
 
 	cmt := r.commit_mgr.NewCommitment(body.Commitment, nil)
 	if err := r.commit_mgr.Import(cmt, fromOpts); err != nil {
@@ -53,17 +55,27 @@ func (round2) VerifyMessage(round.Message) error { return nil }
 // StoreMessage implements round.Round.
 func (round2) StoreMessage(round.Message) error { return nil }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - send all committed data.
-func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round2) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(r.SelfID()))
 
 	// TODO need keyID to get the key
 	elgamalKey, err := r.elgamal_km.GetKey(opts)
@@ -157,7 +169,8 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	return &round3{
-		round2: r,
+		round2:    r,
+		aggregate: polynomial.NewExponentAggregate(),
 	}, nil
 }
 
@@ -1,14 +1,32 @@
 package keygen
 
 import (
+	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
 
+	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
 	zkfac "github.com/mr-shifu/mpc-lib/core/zk/fac"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
+	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/types"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
+	comm_vss "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
+	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+	sw_elgamal "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/elgamal"
+	sw_paillier "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillier"
+	sw_pedersen "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
+	sw_rid "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 )
@@ -17,6 +35,10 @@ var _ round.Round = (*round3)(nil)
 
 type round3 struct {
 	*round2
+
+	// aggregate is the running sum of the VSS polynomials Fⱼ(X) of all parties, added as their
+	// decommitments are verified so that round4 does not have to sum them all at once.
+	aggregate *polynomial.ExponentAggregate
 }
 
 type broadcast3 struct {
@@ -48,112 +70,92 @@ type broadcast3 struct {
 // - verify length of Schnorr commitments
 // - verify degree of VSS polynomial Fⱼ "in-the-exponent"
 //   - if keygen, verify Fⱼ(0) != ∞
-//   - if refresh, verify Fⱼ(0) == ∞
+//   - if refresh, verify Fⱼ(0) == λⱼ⋅X'ⱼ
 //
 // - validate Paillier
 // - validate Pedersen
 // - validate commitments.
 // - store ridⱼ, Cⱼ, Nⱼ, Sⱼ, Tⱼ, Fⱼ(X), Aⱼ.
-func (r *round3) StoreBroadcastMessage(msg round.Message) error {
+func (r *round3) StoreBroadcastMessage(msg round.Message) (err error) {
 	from := msg.From
 	body, ok := msg.Content.(*broadcast3)
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
 
-	// TODO verify vss polynomial
-	// Save all X, VSSCommitments
-	// VSSPolynomial := body.VSSPolynomial
-	// check that the constant coefficient is 0
-	// if refresh then the polynomial is constant
-	// ecKey, err := r.ecdsa_km.GetKey(r.ID, string(r.SelfID()))
-	// if err != nil {
-	// 	return err
-	// }
-	// vssKey, err := ecKey.VSS()
-	// // vssKey, err := r.vss_km.GetKey(r.ID, string(r.SelfID()))
-	// if err != nil {
-	// 	return err
-	// }
-	// exp, err := vssKey.ExponentsRaw()
-	// if err != nil {
-	// 	return err
-	// }
-	// if exp.IsConstant != VSSPolynomial.IsConstant {
-	// 	// if !(r.VSSSecret.Constant().IsZero() == VSSPolynomial.IsConstant) {
-	// 	return errors.New("vss polynomial has incorrect constant")
-	// }
-	// // check deg(Fⱼ) = t
-	// if VSSPolynomial.Degree() != r.Threshold() {
-	// 	return errors.New("vss polynomial has incorrect degree")
-	// }
-
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
-
-	ridFrom, err := r.rid_km.ImportKey(body.RID, fromOpts)
-	if err != nil {
+	if err := r.BeginStore(r.Number(), from, true); err != nil {
 		return err
 	}
+	defer r.EndStore(r.Number(), from, true, &err)
 
-	chainKeyFrom, err := r.chainKey_km.ImportKey(body.C, fromOpts)
-	if err != nil {
+	// validate what can be checked before importing any key of the sender, so that an
+	// invalid message does not leave some of them stored
+	if err := body.Decommitment.Validate(); err != nil {
 		return err
 	}
-
-	if _, err := r.paillier_km.ImportKey(body.PaillierKey, fromOpts); err != nil {
-		return err
+	// bound deg(Fⱼ) before decoding it, so that an oversized polynomial is not processed
+	exponents := polynomial.NewEmptyExponent(r.Group())
+	if err := exponents.UnmarshalBinaryWithMaxDegree(body.VSSPolynomial, r.VSSDegree); err != nil {
+		if errors.Is(err, polynomial.ErrExponentDegree) {
+			err = fmt.Errorf("%w: %w", ErrVSSDegree, err)
+		}
+		return fmt.Errorf("%w: VSS polynomial of party %s: %w", round.ErrInvalidContent, from, err)
 	}
-
-	pedersenFrom, err := r.pedersen_km.ImportKey(body.PedersenKey, fromOpts)
+	// check deg(Fⱼ) = d
+	if exponents.Degree() != r.VSSDegree {
+		return fmt.Errorf("%w: party %s sent degree %d, need %d", ErrVSSDegree, from, exponents.Degree(), r.VSSDegree)
+	}
+	if r.PreviousPublicSharesECDSA != nil {
+		// a refresh must keep the secret, so Fⱼ(0) is the scaled previous share of Pⱼ
+		previous, ok := r.PreviousPublicSharesECDSA[from]
+		if !ok || !exponents.Constant().Equal(previous) {
+			return fmt.Errorf("%w: party %s does not refresh its previous share", ErrVSSConstant, from)
+		}
+	} else if exponents.Constant().IsIdentity() {
+		return fmt.Errorf("%w: party %s sent a zero constant", ErrVSSConstant, from)
+	}
+	ridFrom, err := sw_rid.ParseKey(body.RID)
 	if err != nil {
 		return err
 	}
-
-	fromKey, err := r.ecdsa_km.ImportKey(body.EcdsaKey, fromOpts)
+	chainKeyFrom, err := sw_rid.ParseKey(body.C)
 	if err != nil {
 		return err
 	}
-
-	exponents := polynomial.NewEmptyExponent(r.Group())
-	if err := exponents.UnmarshalBinary(body.VSSPolynomial); err != nil {
-		return err
+	// ParseKey validates N, and N, S, T ∈ ℤₙˣ with S ≠ T
+	paillierFrom, err := sw_paillier.ParseKey(body.PaillierKey)
+	if err != nil {
+		return fmt.Errorf("keygen: Paillier key of party %s: %w", from, err)
 	}
-	vssKey := vss.NewVssKey(nil, exponents)
-	if _, err := r.vss_mgr.ImportSecrets(vssKey, fromOpts); err != nil {
-		return err
+	pedersenFrom, err := sw_pedersen.ParseKey(body.PedersenKey)
+	if err != nil {
+		return fmt.Errorf("keygen: Pedersen parameters of party %s: %w", from, err)
 	}
-
-	if err := fromKey.ImportSchnorrCommitment(body.SchnorrCommitments); err != nil {
+	if err := checkAuxModuli(paillierFrom, pedersenFrom); err != nil {
 		return err
 	}
-
-	vssKeyFrom, err := fromKey.VSS(fromOpts)
+	ecdsaFrom, err := sw_ecdsa.ParseKey(body.EcdsaKey)
 	if err != nil {
 		return err
 	}
-	exponentsFrom, err := vssKeyFrom.Exponents()
+	elgamalFrom, err := sw_elgamal.ParseKey(body.ElgamalKey)
 	if err != nil {
 		return err
 	}
-
-	elgamalFrom, err := r.elgamal_km.ImportKey(body.ElgamalKey, fromOpts)
+	vssKey := vss.NewVssKey(nil, exponents)
+	exponentsFrom, err := vssKey.Exponents()
 	if err != nil {
 		return err
 	}
 
+	fromOpts := keyopts.Options{}
+	fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(from))
+
 	// Verify decommit
-	if err := body.Decommitment.Validate(); err != nil {
-		return err
-	}
 	cmt, err := r.commit_mgr.Get(fromOpts)
 	if err != nil {
 		return err
 	}
-	if err := r.commit_mgr.ImportDecommitment(body.Decommitment, fromOpts); err != nil {
-		return err
-	}
-
 	if !r.Hash().Clone().Decommit(
 		cmt.Commitment(),
 		body.Decommitment,
@@ -169,6 +171,43 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 		return errors.New("failed to decommit")
 	}
 
+	// the message is valid, store the keys of the sender. They go to the keystores of different
+	// key managers, which Keystore.ImportBatch cannot span, so the imports are not atomic: all
+	// that can reject the message is checked above, and if an import fails anyway the message
+	// may be received again, overwriting the keys imported for it.
+	if _, err := r.rid_km.ImportKey(body.RID, fromOpts); err != nil {
+		return err
+	}
+	if _, err := r.chainKey_km.ImportKey(body.C, fromOpts); err != nil {
+		return err
+	}
+	if _, err := r.paillier_km.ImportKey(paillierFrom, fromOpts); err != nil {
+		return err
+	}
+	if _, err := r.pedersen_km.ImportKey(pedersenFrom, fromOpts); err != nil {
+		return err
+	}
+	fromKey, err := r.ecdsa_km.ImportKey(ecdsaFrom, fromOpts)
+	if err != nil {
+		return err
+	}
+	if _, err := r.vss_mgr.ImportSecrets(vssKey, fromOpts); err != nil {
+		return err
+	}
+	if err := fromKey.ImportSchnorrCommitment(body.SchnorrCommitments); err != nil {
+		return err
+	}
+	if _, err := r.elgamal_km.ImportKey(elgamalFrom, fromOpts); err != nil {
+		return err
+	}
+	if err := r.commit_mgr.ImportDecommitment(body.Decommitment, fromOpts); err != nil {
+		return err
+	}
+
+	if err := r.aggregate.Add(from, exponents); err != nil {
+		return err
+	}
+
 	// Mark the message as received
 	if err := r.bcstmgr.Import(
 		r.bcstmgr.NewMessage(r.ID, int(r.Number()), string(msg.From), true),
@@ -185,7 +224,12 @@ func (round3) VerifyMessage(round.Message) error { return nil }
 // StoreMessage implements round.Round.
 func (round3) StoreMessage(round.Message) error { return nil }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - set rid = ⊕ⱼ ridⱼ and update hash state
 // - prove Nᵢ is Blum
@@ -194,41 +238,49 @@ func (round3) StoreMessage(round.Message) error { return nil }
 //   - if refresh skip constant coefficient
 //
 // - send proofs and encryption of share for Pⱼ.
-func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round3) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties messages are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
 
 	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(r.SelfID()))
 
 	rootOpts := keyopts.Options{}
-	rootOpts.Set("id", r.ID, "partyid", "ROOT")
+	rootOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", "ROOT")
 
 	// c = ⊕ⱼ cⱼ
 	chainKey := r.PreviousChainKey
 	if chainKey == nil {
 		chainKey = types.EmptyRID()
-		for _, j := range r.PartyIDs() {
+		for _, j := range party.SortedIDs(r.PartyIDs()) {
 			partyOpts := keyopts.Options{}
-			partyOpts.Set("id", r.ID, "partyid", string(j))
+			partyOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(j))
 			ck, err := r.chainKey_km.GetKey(partyOpts)
 			if err != nil {
 				return nil, err
 			}
 			chainKey.XOR(ck.Raw())
 		}
-		if _, err := r.chainKey_km.ImportKey(chainKey, rootOpts); err != nil {
-			return nil, err
-		}
+	}
+	if _, err := r.chainKey_km.ImportKey(chainKey, rootOpts); err != nil {
+		return nil, err
 	}
 
 	// RID = ⊕ⱼ RIDⱼ
 	rid := types.EmptyRID()
-	for _, j := range r.PartyIDs() {
+	for _, j := range party.SortedIDs(r.PartyIDs()) {
 		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(j))
 		rj, err := r.rid_km.GetKey(partyOpts)
 		if err != nil {
 			return nil, err
@@ -244,6 +296,9 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	_ = h.WriteAny(rid, r.SelfID())
 
 	// Prove N is a blum prime with zkmod
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	pk, err := r.paillier_km.GetKey(opts)
 	if err != nil {
 		return nil, err
@@ -251,6 +306,9 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	mod := pk.NewZKModProof(h.Clone(), r.Pool)
 
 	// prove s, t are correct as aux parameters with zkprm
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	ped, err := r.pedersen_km.GetKey(opts)
 	if err != nil {
 		return nil, err
@@ -268,19 +326,62 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return nil, err
 	}
+	selfExponents, err := vssKey.ExponentsRaw()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.aggregate.Add(r.SelfID(), selfExponents); err != nil {
+		return nil, err
+	}
+
+	// create P2P messages with encrypted shares and zkfac proof, and send them in the order of the
+	// parties once they are all created, so that the outbound messages are deterministic
+	otherIDs := r.OtherPartyIDs()
+	msgs, err := r.shareMessages(ctx, h, pk, vssKey, otherIDs, r.Pool, randomNonce)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range otherIDs {
+		if err := r.SendMessage(out, msgs[i], j); err != nil {
+			return r, err
+		}
+	}
+
+	// update last round processed in StateManager
+	if err := r.statemanger.SetLastRound(r.ID, int(r.Number())); err != nil {
+		return r, err
+	}
+
+	// Write rid to the hash state
+	r.UpdateHashState(rid)
+	return &round4{
+		round3:    r,
+		ModProofs: map[party.ID]*zkmod.Proof{r.SelfID(): mod},
+		PrmProofs: map[party.ID]*zkprm.Proof{r.SelfID(): prm},
+	}, nil
+}
+
+// shareMessages returns the message4 for each party j of partyIDs, holding the share fᵢ(j) encrypted
+// under the Paillier key of j with the nonce returned by nonce, and the zkfac proof of Nᵢ for the
+// Pedersen parameters of j. The messages are created in parallel by the workers of pl, and returned
+// in the order of partyIDs.
+func (r *round3) shareMessages(ctx context.Context, h comm_hash.Hash, pk paillier.PaillierKey, vssKey comm_vss.VssKey, partyIDs []party.ID, pl *pool.Pool, nonce func(*saferith.Modulus) *saferith.Nat) ([]*message4, error) {
+	results := pl.Parallelize(len(partyIDs), func(i int) interface{} {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		j := partyIDs[i]
 
-	// create P2P messages with encrypted shares and zkfac proof
-	for _, j := range r.OtherPartyIDs() {
 		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(j))
 
 		pedj, err := r.pedersen_km.GetKey(partyOpts)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		paillierj, err := r.paillier_km.GetKey(partyOpts)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		fac := pk.NewZKFACProof(h.Clone(), zkfac.Public{
@@ -291,30 +392,30 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		// compute fᵢ(j)
 		share, err := vssKey.Evaluate(j.Scalar(r.Group()))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// Encrypt share
-		C, _ := paillierj.Encode(curve.MakeInt(share))
+		C := paillierj.EncWithNonce(curve.MakeInt(share), nonce(paillierj.ParamN()))
 
-		err = r.SendMessage(out, &message4{
+		return &message4{
 			Share: C,
 			Fac:   fac,
-		}, j)
-		if err != nil {
-			return r, err
 		}
-	}
+	})
 
-	// update last round processed in StateManager
-	if err := r.statemanger.SetLastRound(r.ID, int(r.Number())); err != nil {
-		return r, err
+	msgs := make([]*message4, len(results))
+	for i, res := range results {
+		if err, ok := res.(error); ok {
+			return nil, err
+		}
+		msgs[i] = res.(*message4)
 	}
+	return msgs, nil
+}
 
-	// Write rid to the hash state
-	r.UpdateHashState(rid)
-	return &round4{
-		round3: r,
-	}, nil
+// randomNonce returns a random nonce for a Paillier encryption under the modulus n.
+func randomNonce(n *saferith.Modulus) *saferith.Nat {
+	return sample.UnitModN(rand.Reader, n)
 }
 
 func (r *round3) CanFinalize() bool {
@@ -339,11 +440,18 @@ func (broadcast3) RoundNumber() round.Number { return 3 }
 // BroadcastContent implements round.BroadcastRound.
 func (r *round3) BroadcastContent() round.BroadcastContent {
 	return &broadcast3{
-		// VSSPolynomial:      polynomial.EmptyExponent(r.Group()),
-		SchnorrCommitments: r.Group().NewPoint(), //zksch.EmptyCommitment(r.Group()),
-		// ElGamalPublic:      r.Group().NewPoint(),
+		SchnorrCommitments: r.Group().NewPoint(),
 	}
 }
 
 // Number implements round.Round.
 func (round3) Number() round.Number { return 3 }
+
+// checkAuxModuli verifies that a party's Pedersen parameters were derived from its Paillier key,
+// i.e. that both use the same modulus N, which the zkfac and zkprm proofs rely on.
+func checkAuxModuli(paillierKey paillier.PaillierKey, pedersenKey pedersen.PedersenKey) error {
+	if paillierKey.ParamN().Nat().Eq(pedersenKey.PublicKeyRaw().N().Nat()) != 1 {
+		return ErrModulusMismatch
+	}
+	return nil
+}
@@ -1,19 +1,24 @@
 package keygen
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
 	zkfac "github.com/mr-shifu/mpc-lib/core/zk/fac"
 	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
 	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
-	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+	sw_vss "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
 )
@@ -22,6 +27,12 @@ var _ round.Round = (*round4)(nil)
 
 type round4 struct {
 	*round3
+
+	mtx sync.Mutex
+	// ModProofs and PrmProofs hold the zkmod and zkprm proofs broadcast by every party,
+	// including this one. The proofs of the other parties are verified by Finalize.
+	ModProofs map[party.ID]*zkmod.Proof
+	PrmProofs map[party.ID]*zkprm.Proof
 }
 
 type message4 struct {
@@ -38,16 +49,77 @@ type broadcast4 struct {
 
 // StoreBroadcastMessage implements round.BroadcastRound.
 //
-// - verify Mod, Prm proof for N
-func (r *round4) StoreBroadcastMessage(msg round.Message) error {
+// - save Mod, Prm proof for N, which Finalize verifies with those of all other parties.
+func (r *round4) StoreBroadcastMessage(msg round.Message) (err error) {
 	from := msg.From
 	body, ok := msg.Content.(*broadcast4)
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
+	if body.Mod == nil || body.Prm == nil {
+		return round.ErrNilFields
+	}
+
+	if err := r.BeginStore(r.Number(), from, true); err != nil {
+		return err
+	}
+	defer r.EndStore(r.Number(), from, true, &err)
+
+	r.mtx.Lock()
+	r.ModProofs[from] = body.Mod
+	r.PrmProofs[from] = body.Prm
+	r.mtx.Unlock()
+
+	// Mark the message as received
+	if err := r.bcstmgr.Import(
+		r.bcstmgr.NewMessage(r.ID, int(r.Number()), string(msg.From), true),
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// VerifyAllBroadcasts verifies the Mod and Prm proofs broadcast by all parties at once, with the
+// proofs of each party verified by a different worker of the pool. If some proofs are invalid,
+// it returns a protocol.Error naming the parties which sent them.
+func (r *round4) VerifyAllBroadcasts(proofs map[party.ID]*broadcast4) error {
+	partyIDs := make([]party.ID, 0, len(proofs))
+	for j := range proofs {
+		partyIDs = append(partyIDs, j)
+	}
+	partyIDs = party.NewIDSlice(partyIDs)
+
+	results := r.Pool.Parallelize(len(partyIDs), func(i int) interface{} {
+		// a worker cannot wait on the pool, so each party's proofs are verified serially
+		return r.verifyBroadcast(partyIDs[i], proofs[partyIDs[i]], nil)
+	})
+
+	var culprits []party.ID
+	var first error
+	for i, res := range results {
+		if err, ok := res.(error); ok {
+			culprits = append(culprits, partyIDs[i])
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if culprits != nil {
+		return protocol.Error{Culprits: culprits, Err: first}
+	}
+	return nil
+}
+
+// verifyBroadcast verifies the Mod and Prm proofs of party from, using pl to parallelize the
+// verification of each proof.
+func (r *round4) verifyBroadcast(from party.ID, body *broadcast4, pl *pool.Pool) error {
+	if body == nil {
+		return round.ErrNilFields
+	}
 
 	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(from))
 
 	// verify zkmod
 	ped, err := r.pedersen_km.GetKey(fromOpts)
@@ -58,22 +130,14 @@ func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 	if err != nil {
 		return err
 	}
-	if !paillier.VerifyZKMod(body.Mod, r.HashForID(from), r.Pool) {
+	if !paillier.VerifyZKMod(body.Mod, r.HashForID(from), pl) {
 		return errors.New("failed to validate mod proof")
 	}
 
 	// verify zkprm
-	if !ped.VerifyProof(r.HashForID(from), r.Pool, body.Prm) {
+	if !ped.VerifyProof(r.HashForID(from), pl, body.Prm) {
 		return errors.New("failed to validate prm proof")
 	}
-
-	// Mark the message as received
-	if err := r.bcstmgr.Import(
-		r.bcstmgr.NewMessage(r.ID, int(r.Number()), string(msg.From), true),
-	); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -86,20 +150,29 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
+	if !paillier.ValidateCiphertextSizes(body.Share) {
+		return fmt.Errorf("%w: share ciphertext", round.ErrInvalidContent)
+	}
 
 	selfOpts := keyopts.Options{}
-	selfOpts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	selfOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(r.SelfID()))
 
 	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(from))
+
+	// an error here is a failure of this party, and not an invalid share sent by from
+	valid, err := r.paillier_km.ValidateCiphertexts(selfOpts, body.Share)
+	if err != nil {
+		return fmt.Errorf("keygen: validating share ciphertext: %w", err)
+	}
+	if !valid {
+		return ErrInvalidShareCiphertext
+	}
 
 	paillierKey, err := r.paillier_km.GetKey(selfOpts)
 	if err != nil {
 		return err
 	}
-	if !paillierKey.ValidateCiphertexts(body.Share) {
-		return errors.New("invalid ciphertext")
-	}
 
 	ped, err := r.pedersen_km.GetKey(selfOpts)
 	if err != nil {
@@ -127,14 +200,19 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 // - check that the decrypted share did not overflow.
 // - check VSS condition.
 // - save share.
-func (r *round4) StoreMessage(msg round.Message) error {
+func (r *round4) StoreMessage(msg round.Message) (err error) {
 	from, body := msg.From, msg.Content.(*message4)
 
+	if err := r.BeginStore(r.Number(), from, false); err != nil {
+		return err
+	}
+	defer r.EndStore(r.Number(), from, false, &err)
+
 	selfOpts := keyopts.Options{}
-	selfOpts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	selfOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(r.SelfID()))
 
 	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(from))
 
 	// decrypt share
 	paillierKey, err := r.paillier_km.GetKey(selfOpts)
@@ -170,7 +248,7 @@ func (r *round4) StoreMessage(msg round.Message) error {
 	}
 
 	vssShareOpts := keyopts.Options{}
-	vssShareOpts.Set("id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
+	vssShareOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
 	vssShareKey := sw_ecdsa.NewECDSAKey(Share, PublicShare, r.Group())
 	if _, err := r.ec_vss_km.ImportKey(vssShareKey, vssShareOpts); err != nil {
 		return err
@@ -186,7 +264,12 @@ func (r *round4) StoreMessage(msg round.Message) error {
 	return nil
 }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - sum of all received shares
 // - compute group public key and individual public keys
@@ -194,55 +277,55 @@ func (r *round4) StoreMessage(msg round.Message) error {
 // - validate Config
 // - write new ssid hash to old hash state
 // - create proof of knowledge of secret.
-func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round4) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// check if we received all messages
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
-
-	// Calculate MPC public Key
-	mpcPublicKey := r.Group().NewPoint()
-	for _, partyID := range r.PartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(partyID))
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
 
-		vssKey, err := r.vss_mgr.GetSecrets(partyOpts)
-		if err != nil {
-			return nil, err
-		}
-		exp, err := vssKey.ExponentsRaw()
-		if err != nil {
-			return nil, err
+	// verify the Mod and Prm proofs of all other parties together
+	proofs := make(map[party.ID]*broadcast4, len(r.OtherPartyIDs()))
+	r.mtx.Lock()
+	for _, j := range r.OtherPartyIDs() {
+		proofs[j] = &broadcast4{Mod: r.ModProofs[j], Prm: r.PrmProofs[j]}
+	}
+	r.mtx.Unlock()
+	if err := r.VerifyAllBroadcasts(proofs); err != nil {
+		var perr protocol.Error
+		if errors.As(err, &perr) {
+			return r.AbortRound(perr.Err, perr.Culprits...), nil
 		}
-		pub := exp.Constant()
-		mpcPublicKey = mpcPublicKey.Add(pub)
+		return nil, err
+	}
+
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(r.SelfID()))
+
+	// the VSS polynomials of all parties were summed in round3 as they were received
+	publicPolynomial, err := r.aggregate.Sum(r.PartyIDs())
+	if err != nil {
+		return nil, err
 	}
 
 	// Import MPC public Key
 	rootOpts := keyopts.Options{}
-	rootOpts.Set("id", r.ID, "partyid", "ROOT")
-	k := r.ecdsa_km.NewKey(nil, mpcPublicKey, r.Group())
+	rootOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", "ROOT")
+	k := r.ecdsa_km.NewKey(nil, publicPolynomial.Constant(), r.Group())
 	if _, err := r.ecdsa_km.ImportKey(k, rootOpts); err != nil {
 		return nil, err
 	}
 
-	// Sum all VSS Exponents Shares to generate MPC VSS Exponent
-	// var allExponents []*polynomial.Exponent
-	vssOptsList := make([]comm_keyopts.Options, 0)
-	for _, partyID := range r.PartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(partyID))
-		vssOptsList = append(vssOptsList, partyOpts)
-	}
-	rootVss, err := r.vss_mgr.SumExponents(vssOptsList...)
-	if err != nil {
-		return nil, err
-	}
-	_, err = r.vss_mgr.ImportSecrets(rootVss, rootOpts)
-	if err != nil {
+	// Import the summed VSS Exponents as MPC VSS Exponent
+	rootVss := sw_vss.NewVssKey(nil, publicPolynomial)
+	if _, err := r.vss_mgr.ImportSecrets(rootVss, rootOpts); err != nil {
 		return nil, err
 	}
 
@@ -251,9 +334,13 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 	for _, j := range r.PartyIDs() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		vssPartyOpts := keyopts.Options{}
 
-		vssPartyOpts.Set("id", hex.EncodeToString(vssPoly.SKI()), "partyid", string(j))
+		vssPartyOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vssPoly.SKI()), "partyid", string(j))
 
 		vssPub, err := vssPoly.EvaluateByExponents(j.Scalar(r.Group()))
 		if err != nil {
@@ -269,7 +356,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	var vss_shares []comm_ecdsa.ECDSAKey
 	for _, j := range r.OtherPartyIDs() {
 		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(j))
 
 		vss, err := r.vss_mgr.GetSecrets(partyOpts)
 		if err != nil {
@@ -277,7 +364,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		}
 
 		vssOpts := keyopts.Options{}
-		vssOpts.Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
+		vssOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
 		vss_share, err := r.ec_vss_km.GetKey(vssOpts)
 		if err != nil {
 			return nil, err
@@ -289,16 +376,19 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 	vssOpts := keyopts.Options{}
-	vssOpts.Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
+	vssOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
 	selfVSSShare, err := r.ec_vss_km.GetKey(vssOpts)
 	if err != nil {
 		return nil, err
 	}
-	vssSharePrivateKey := selfVSSShare.AddKeys(vss_shares...)
+	vssSharePrivateKey, err := selfVSSShare.AddKeys(vss_shares...)
+	if err != nil {
+		return nil, err
+	}
 	vssSharePublicKey := vssSharePrivateKey.ActOnBase()
 	vssShareKey := sw_ecdsa.NewECDSAKey(vssSharePrivateKey, vssSharePublicKey, r.Group())
 	rootVssOpts := keyopts.Options{}
-	rootVssOpts.Set("id", hex.EncodeToString(rootVss.SKI()), "partyid", "ROOT")
+	rootVssOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(rootVss.SKI()), "partyid", "ROOT")
 	if _, err := r.ec_vss_km.ImportKey(vssShareKey, rootVssOpts); err != nil {
 		return nil, err
 	}
@@ -314,8 +404,12 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 	PublicData := make(map[party.ID]*config.Public, len(r.PartyIDs()))
 	for _, j := range r.PartyIDs() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(j))
 
 		elgamalj, err := r.elgamal_km.GetKey(partyOpts)
 		if err != nil {
@@ -358,17 +452,30 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 
-	// TODO elgamal and paillier secret key is missed here
+	elgamalKey, err := r.elgamal_km.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	paillierKey, err := r.paillier_km.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !elgamalKey.Private() || !paillierKey.Private() {
+		return nil, errors.New("cmp.keygen: missing own elgamal or paillier secret key")
+	}
+
+	// the shares are points of a polynomial of degree d ⩾ t, so d+1 of them are needed to sign
 	UpdatedConfig := &config.Config{
-		Group:     r.Group(),
-		ID:        r.SelfID(),
-		Threshold: r.Threshold(),
-		ECDSA:     vssSharePrivateKey,
-		// ElGamal:   r.ElGamalSecret,
-		// Paillier:  r.PaillierSecret,
-		RID:      rid.Raw(),
-		ChainKey: chainKey.Raw(),
-		Public:   PublicData,
+		Group:      r.Group(),
+		ID:         r.SelfID(),
+		Threshold:  r.VSSDegree,
+		ECDSA:      vssSharePrivateKey,
+		ElGamal:    elgamalKey.PrivateKeyRaw(),
+		Paillier:   paillierKey.PrivateKeyRaw(),
+		RID:        rid.Raw(),
+		ChainKey:   chainKey.Raw(),
+		Public:     PublicData,
+		Polynomial: publicPolynomial,
 	}
 
 	// write new ssid to hash, to bind the Schnorr proof to this new config
@@ -377,6 +484,9 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	_ = h.WriteAny(UpdatedConfig, r.SelfID())
 
 	// proof := r.SchnorrRand.Prove(h, PublicData[r.SelfID()].ECDSA, UpdatedSecretECDSA, nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	ecKey, err := r.ecdsa_km.GetKey(opts)
 	if err != nil {
 		return nil, err
@@ -426,13 +536,13 @@ func (r *round4) CanFinalize() bool {
 func (message4) RoundNumber() round.Number { return 4 }
 
 // MessageContent implements round.Round.
-func (round4) MessageContent() round.Content { return &message4{} }
+func (*round4) MessageContent() round.Content { return &message4{} }
 
 // RoundNumber implements round.Content.
 func (broadcast4) RoundNumber() round.Number { return 4 }
 
 // BroadcastContent implements round.BroadcastRound.
-func (round4) BroadcastContent() round.BroadcastContent { return &broadcast4{} }
+func (*round4) BroadcastContent() round.BroadcastContent { return &broadcast4{} }
 
 // Number implements round.Round.
-func (round4) Number() round.Number { return 4 }
+func (*round4) Number() round.Number { return 4 }
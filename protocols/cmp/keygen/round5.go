@@ -1,9 +1,11 @@
 package keygen
 
 import (
+	"context"
 	"errors"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
@@ -34,7 +36,7 @@ func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 	}
 
 	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(from))
 
 	// TODO implement SchnorrResponse validation
 	// if !body.SchnorrResponse.IsValid() {
@@ -85,12 +87,69 @@ func (round5) VerifyMessage(round.Message) error { return nil }
 func (r *round5) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round.
-func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
+func (r *round5) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round.
+func (r *round5) FinalizeContext(ctx context.Context, _ chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
-	return r.ResultRound(r.UpdatedConfig), nil
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	proofs := make(map[party.ID]*SchnorrProof, len(r.PartyIDs()))
+	for _, j := range r.PartyIDs() {
+		opts := keyopts.Options{}
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", string(j))
+
+		ecKey, err := r.ecdsa_km.GetKey(opts)
+		if err != nil {
+			return r, err
+		}
+		commitment, err := ecKey.SchnorrCommitment()
+		if err != nil {
+			return r, err
+		}
+		response, err := ecKey.SchnorrProof()
+		if err != nil {
+			return r, err
+		}
+		proofs[j] = &SchnorrProof{
+			Public:     ecKey.PublicKeyRaw(),
+			Commitment: commitment,
+			Response:   response,
+		}
+	}
+
+	rootOpts := keyopts.Options{}
+	rootOpts.Set("protocol", keyopts.ProtocolCMP, "id", r.ID, "partyid", "ROOT")
+	rid, err := r.rid_km.GetKey(rootOpts)
+	if err != nil {
+		return r, err
+	}
+
+	return r.ResultRound(&KeygenResult{
+		Config: r.UpdatedConfig,
+		SSID:   r.Hash().Sum(),
+		Proofs: proofs,
+		Transcript: &Transcript{
+			SessionID: r.SessionID,
+			Group:     r.Group(),
+			PartyIDs:  r.PartyIDs(),
+			// the threshold of the session, which is written to the hash, and not the degree of
+			// the key held by Config
+			Threshold: r.Threshold(),
+			RID:       rid.Raw(),
+		},
+		ModProofs: r.ModProofs,
+		PrmProofs: r.PrmProofs,
+	}), nil
 }
 
 func (r *round5) CanFinalize() bool {
@@ -115,7 +174,7 @@ func (broadcast5) RoundNumber() round.Number { return 5 }
 // BroadcastContent implements round.BroadcastRound.
 func (r *round5) BroadcastContent() round.BroadcastContent {
 	return &broadcast5{
-		SchnorrResponse: r.Group().NewScalar(), // sch.EmptyResponse(r.Group()),
+		SchnorrResponse: r.Group().NewScalar(),
 	}
 }
 
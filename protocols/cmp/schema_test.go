@@ -0,0 +1,45 @@
+package cmp
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageSchema_KeygenRound3(t *testing.T) {
+	s, err := protocol.MessageSchema("cmp/keygen", 3)
+	require.NoError(t, err)
+
+	assert.Nil(t, s.Message, "round 3 has no P2P message")
+	require.NotNil(t, s.Broadcast)
+	assert.False(t, s.Broadcast.Reliable)
+
+	fields := make(map[string]protocol.FieldSchema)
+	for _, f := range s.Broadcast.Fields {
+		fields[f.Name] = f
+	}
+
+	require.Contains(t, fields, "RID")
+	assert.Equal(t, "types.RID", fields["RID"].Type)
+	assert.Equal(t, "cbor byte string", fields["RID"].Encoding)
+
+	require.Contains(t, fields, "VSSPolynomial")
+	assert.Equal(t, "[]uint8", fields["VSSPolynomial"].Type)
+	assert.Equal(t, "cbor byte string", fields["VSSPolynomial"].Encoding)
+
+	require.Contains(t, fields, "SchnorrCommitments")
+	assert.Equal(t, "curve.Point", fields["SchnorrCommitments"].Type)
+	assert.Equal(t, "cbor byte string (MarshalBinary)", fields["SchnorrCommitments"].Encoding)
+
+	assert.NotContains(t, fields, "NormalBroadcastContent")
+}
+
+func TestMessageSchema_Unknown(t *testing.T) {
+	_, err := protocol.MessageSchema("cmp/keygen", 1)
+	assert.Error(t, err)
+
+	_, err = protocol.MessageSchema("unknown", 2)
+	assert.Error(t, err)
+}
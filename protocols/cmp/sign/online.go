@@ -0,0 +1,310 @@
+package sign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
+)
+
+// protocolSignOnlineID signs a message with a Presignature.
+const (
+	protocolSignOnlineID                  = "cmp/sign-online"
+	protocolSignOnlineRounds round.Number = 2
+)
+
+// ErrPresignatureUsed is returned by SignOnline for a presignature which already signed a message.
+var ErrPresignatureUsed = errors.New("sign: presignature was already used")
+
+// SignOnline signs msg with presig in a single round, and outputs an *ecdsa.Signature. As in
+// StartSign without a message hash, msg is the digest to sign.
+//
+// The shares of presig are read from the presignature keystore of m, from which they are deleted
+// before the session starts, so that a presignature signs a single message even when SignOnline is
+// called concurrently or after a restart with a persistent keystore. The session ID is derived from
+// the ID of presig.
+func (m *MPCSign) SignOnline(presig *Presignature, msg []byte, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if len(msg) == 0 {
+			return nil, errors.New("sign.SignOnline: message is nil")
+		}
+		if err := presig.validate(); err != nil {
+			return nil, fmt.Errorf("sign.SignOnline: %w", err)
+		}
+
+		// the presignature is consumed before any state of the session is created, so that
+		// concurrent calls with the same presignature do not share a session
+		stored, err := m.consumePresignature(presig)
+		if err != nil {
+			return nil, fmt.Errorf("sign.SignOnline: %w", err)
+		}
+		presig = stored
+
+		ID := presig.ID + "/online"
+
+		koptsRoot := keyopts.Options{}
+		koptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", presig.KeyID, "partyid", "ROOT")
+		ecKey, err := m.ec.GetKey(koptsRoot)
+		if err != nil {
+			return nil, fmt.Errorf("sign.SignOnline: %w", err)
+		}
+
+		info := round.Info{
+			ProtocolID:       protocolSignOnlineID,
+			FinalRoundNumber: protocolSignOnlineRounds,
+			SelfID:           presig.SelfID,
+			PartyIDs:         presig.PartyIDs,
+			Threshold:        len(presig.PartyIDs) - 1,
+			Group:            presig.Group,
+		}
+		opts := keyopts.Options{}
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", ID, "partyid", info.SelfID)
+
+		h := m.hash_mgr.NewHasher(ID, opts)
+		helper, err := round.NewSession(ID, info, sessionID, pl, h, types.SigningMessage(msg))
+		if err != nil {
+			return nil, fmt.Errorf("sign.SignOnline: %w", err)
+		}
+		helper.SetMessageManagers(m.msgmgr, m.bcstmgr)
+
+		if err := m.statmgr.NewState(ID); err != nil {
+			return nil, err
+		}
+
+		return &online1{
+			Helper:    helper,
+			presig:    presig,
+			digest:    msg,
+			publicKey: ecKey.PublicKeyRaw(),
+			statemgr:  m.statmgr,
+			bcstmgr:   m.bcstmgr,
+		}, nil
+	}
+}
+
+// consumePresignature deletes the presignature stored by the presigning session of presig, and
+// returns it. Only one call succeeds for a presignature, the others return ErrPresignatureUsed.
+func (m *MPCSign) consumePresignature(presig *Presignature) (*Presignature, error) {
+	if m.presigs == nil {
+		return nil, errors.New("no keystore to read presignatures from")
+	}
+	data, err := keystore.Consume(m.presigs, presignatureOpts(presig))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPresignatureUsed, err)
+	}
+	stored := EmptyPresignature(presig.Group)
+	if err := stored.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if stored.KeyID != presig.KeyID || !stored.R.Equal(presig.R) {
+		return nil, errors.New("presignature differs from the stored one")
+	}
+	return stored, nil
+}
+
+var _ round.Round = (*online1)(nil)
+
+type online1 struct {
+	*round.Helper
+
+	presig    *Presignature
+	digest    []byte
+	publicKey curve.Point
+
+	statemgr state.MPCStateManager
+	bcstmgr  message.MessageManager
+}
+
+// StoreBroadcastMessage implements round.Round.
+func (*online1) StoreBroadcastMessage(round.Message) error { return nil }
+
+// VerifyMessage implements round.Round.
+func (*online1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (*online1) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *online1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
+//
+// - compute σᵢ = kᵢm + rχᵢ.
+func (r *online1) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	m := curve.FromHash(r.Group(), r.digest)
+	R := r.presig.R.XScalar()
+
+	// σᵢ = kᵢm + rχᵢ
+	SigmaShare := r.Group().NewScalar().Set(r.presig.KShare).Mul(m)
+	SigmaShare.Add(r.Group().NewScalar().Set(r.presig.ChiShare).Mul(R))
+
+	if err := r.BroadcastMessage(out, &broadcastOnline2{SigmaShare: SigmaShare}); err != nil {
+		return r, err
+	}
+
+	if err := r.statemgr.SetLastRound(r.ID, int(r.Number())); err != nil {
+		return r, err
+	}
+
+	return &online2{
+		online1:     r,
+		m:           m,
+		SigmaShares: map[party.ID]curve.Scalar{r.SelfID(): SigmaShare},
+	}, nil
+}
+
+func (*online1) CanFinalize() bool { return true }
+
+// MessageContent implements round.Round.
+func (*online1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (*online1) Number() round.Number { return 1 }
+
+var _ round.Round = (*online2)(nil)
+
+type online2 struct {
+	*online1
+
+	// m is the digest as a scalar
+	m curve.Scalar
+
+	mtx sync.Mutex
+	// SigmaShares[j] = σⱼ
+	SigmaShares map[party.ID]curve.Scalar
+}
+
+type broadcastOnline2 struct {
+	round.NormalBroadcastContent
+	SigmaShare curve.Scalar
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+//
+// - verify [σⱼ]R = [m]R̄ⱼ + [r]Sⱼ
+// - save σⱼ.
+func (r *online2) StoreBroadcastMessage(msg round.Message) (err error) {
+	from := msg.From
+	body, ok := msg.Content.(*broadcastOnline2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.SigmaShare == nil || body.SigmaShare.IsZero() {
+		return round.ErrNilFields
+	}
+	if err := r.BeginStore(r.Number(), from, true); err != nil {
+		return err
+	}
+	defer r.EndStore(r.Number(), from, true, &err)
+
+	BigR := r.presig.R
+	expected := r.m.Act(r.presig.BigKShares[from]).Add(BigR.XScalar().Act(r.presig.BigChiShares[from]))
+	if !body.SigmaShare.Act(BigR).Equal(expected) {
+		return fmt.Errorf("sign: invalid σ share of party %s", from)
+	}
+
+	r.mtx.Lock()
+	r.SigmaShares[from] = body.SigmaShare
+	r.mtx.Unlock()
+
+	// Mark the message as received
+	return r.bcstmgr.Import(
+		r.bcstmgr.NewMessage(r.ID, int(r.Number()), string(from), true),
+	)
+}
+
+// Finalize implements round.Round.
+func (r *online2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
+//
+// - compute σ = ∑ⱼ σⱼ
+// - verify signature.
+func (r *online2) FinalizeContext(ctx context.Context, _ chan<- *round.Message) (_ round.Session, err error) {
+	if !r.CanFinalize() {
+		return nil, round.ErrNotEnoughMessages
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	Sigma := r.Group().NewScalar()
+	r.mtx.Lock()
+	for _, sigmaShare := range r.SigmaShares {
+		Sigma.Add(sigmaShare)
+	}
+	r.mtx.Unlock()
+
+	signature := &ecdsa.Signature{
+		R: r.presig.R,
+		S: Sigma,
+	}
+	if !signature.Verify(r.publicKey, r.digest) {
+		if err := r.statemgr.SetAborted(r.ID); err != nil {
+			return r, err
+		}
+		return r.AbortRound(errors.New("failed to validate signature")), nil
+	}
+
+	v, err := signature.ComputeRecoveryID()
+	if err != nil {
+		return r, err
+	}
+	signature.RecoveryID = v
+
+	if err := r.statemgr.SetLastRound(r.ID, int(r.Number())); err != nil {
+		return r, err
+	}
+	if err := r.statemgr.SetCompleted(r.ID); err != nil {
+		return r, err
+	}
+	return r.ResultRound(signature), nil
+}
+
+func (r *online2) CanFinalize() bool {
+	var parties []string
+	for _, p := range r.OtherPartyIDs() {
+		parties = append(parties, string(p))
+	}
+	rcvd, err := r.bcstmgr.HasAll(r.ID, int(r.Number()), parties)
+	if err != nil {
+		return false
+	}
+	return rcvd
+}
+
+// RoundNumber implements round.Content.
+func (broadcastOnline2) RoundNumber() round.Number { return 2 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *online2) BroadcastContent() round.BroadcastContent {
+	return &broadcastOnline2{
+		SigmaShare: r.Group().NewScalar(),
+	}
+}
+
+// Number implements round.Round.
+func (*online2) Number() round.Number { return 2 }
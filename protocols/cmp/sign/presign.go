@@ -0,0 +1,408 @@
+package sign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+)
+
+// protocolPresignID runs the rounds of CMP signing which do not depend on the message.
+const protocolPresignID = "cmp/presign"
+
+// Presignature is the output of a presigning session: the nonce R = [k⁻¹]G of a future signature,
+// with the shares of this party. SignOnline consumes it to sign a message in a single round.
+//
+// A presignature holds secret shares, and must be stored as carefully as the key. It must be used to
+// sign a single message: two signatures with the same R reveal the key. The presigning session
+// stores it in the presignature keystore of MPCSign, from which SignOnline deletes it before
+// signing, so the returned copy and its encoding are only used to name the presignature.
+type Presignature struct {
+	// ID is the ID of the presigning session.
+	ID string
+	// KeyID is the ID of the key the presignature signs with.
+	KeyID    string
+	Group    curve.Curve
+	SelfID   party.ID
+	PartyIDs party.IDSlice
+	// R = [δ⁻¹]Γ = [k⁻¹]G
+	R curve.Point
+	// KShare = kᵢ
+	KShare curve.Scalar
+	// ChiShare = χᵢ, a share of χ = k⋅x
+	ChiShare curve.Scalar
+	// BigKShares[j] = R̄ⱼ = [kⱼ]R
+	BigKShares map[party.ID]curve.Point
+	// BigChiShares[j] = Sⱼ = [χⱼ]R
+	BigChiShares map[party.ID]curve.Point
+}
+
+// EmptyPresignature returns a Presignature over group, ready to be unmarshalled.
+func EmptyPresignature(group curve.Curve) *Presignature {
+	return &Presignature{Group: group}
+}
+
+// presignatureData is the encoding of a Presignature.
+type presignatureData struct {
+	ID           string
+	KeyID        string
+	SelfID       party.ID
+	PartyIDs     party.IDSlice
+	R            []byte
+	KShare       []byte
+	ChiShare     []byte
+	BigKShares   map[party.ID][]byte
+	BigChiShares map[party.ID][]byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *Presignature) MarshalBinary() ([]byte, error) {
+	data := presignatureData{
+		ID:           p.ID,
+		KeyID:        p.KeyID,
+		SelfID:       p.SelfID,
+		PartyIDs:     p.PartyIDs,
+		BigKShares:   make(map[party.ID][]byte, len(p.BigKShares)),
+		BigChiShares: make(map[party.ID][]byte, len(p.BigChiShares)),
+	}
+	var err error
+	if data.R, err = p.R.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if data.KShare, err = p.KShare.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if data.ChiShare, err = p.ChiShare.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	for j, Kj := range p.BigKShares {
+		if data.BigKShares[j], err = Kj.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	for j, Sj := range p.BigChiShares {
+		if data.BigChiShares[j], err = Sj.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	return cbor.Marshal(data)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. p must have been created with EmptyPresignature.
+func (p *Presignature) UnmarshalBinary(b []byte) error {
+	if p.Group == nil {
+		return errors.New("presignature: group is not set")
+	}
+	var data presignatureData
+	if err := cbor.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	group := p.Group
+	p.ID, p.KeyID, p.SelfID, p.PartyIDs = data.ID, data.KeyID, data.SelfID, data.PartyIDs
+	p.R = group.NewPoint()
+	if err := p.R.UnmarshalBinary(data.R); err != nil {
+		return err
+	}
+	p.KShare = group.NewScalar()
+	if err := p.KShare.UnmarshalBinary(data.KShare); err != nil {
+		return err
+	}
+	p.ChiShare = group.NewScalar()
+	if err := p.ChiShare.UnmarshalBinary(data.ChiShare); err != nil {
+		return err
+	}
+	p.BigKShares = make(map[party.ID]curve.Point, len(data.BigKShares))
+	for j, b := range data.BigKShares {
+		Kj := group.NewPoint()
+		if err := Kj.UnmarshalBinary(b); err != nil {
+			return err
+		}
+		p.BigKShares[j] = Kj
+	}
+	p.BigChiShares = make(map[party.ID]curve.Point, len(data.BigChiShares))
+	for j, b := range data.BigChiShares {
+		Sj := group.NewPoint()
+		if err := Sj.UnmarshalBinary(b); err != nil {
+			return err
+		}
+		p.BigChiShares[j] = Sj
+	}
+	return p.validate()
+}
+
+// validate checks that p holds the values of every party.
+func (p *Presignature) validate() error {
+	if !p.PartyIDs.Valid() || !p.PartyIDs.Contains(p.SelfID) {
+		return errors.New("presignature: invalid parties")
+	}
+	if p.R.IsIdentity() || p.KShare.IsZero() || p.ChiShare.IsZero() {
+		return errors.New("presignature: zero nonce or share")
+	}
+	for _, j := range p.PartyIDs {
+		if _, ok := p.BigKShares[j]; !ok {
+			return fmt.Errorf("presignature: missing R̄ of party %s", j)
+		}
+		if _, ok := p.BigChiShares[j]; !ok {
+			return fmt.Errorf("presignature: missing S of party %s", j)
+		}
+	}
+	return nil
+}
+
+// StartPresign runs the rounds of signing which do not depend on the message, and outputs a
+// *Presignature. cfg.Message() is ignored, and cfg must not ask for deterministic nonces.
+func (m *MPCSign) StartPresign(cfg config.SignConfig, pl *pool.Pool) protocol.StartFunc {
+	return m.start(cfg, pl, true)
+}
+
+var _ round.Round = (*presign5)(nil)
+
+// presign5 replaces round5 in a presigning session, and collects Sⱼ = [χⱼ]R.
+type presign5 struct {
+	*round4
+
+	// deltaInv = δ⁻¹, with which R̄ⱼ = [kⱼ]R is derived from Δⱼ
+	deltaInv curve.Scalar
+	BigR     curve.Point
+
+	mtx sync.Mutex
+	// BigChiShares[j] = Sⱼ = [χⱼ]R
+	BigChiShares map[party.ID]curve.Point
+}
+
+type broadcastPresign5 struct {
+	round.NormalBroadcastContent
+	// BigChiShare = Sⱼ = [χⱼ]R
+	BigChiShare curve.Point
+}
+
+// finalizePresign ends round4 of a presigning session by broadcasting Sᵢ = [χᵢ]R.
+func (r *round4) finalizePresign(out chan<- *round.Message, deltaInv curve.Scalar, BigR, BigChiShare curve.Point) (round.Session, error) {
+	if err := r.BroadcastMessage(out, &broadcastPresign5{BigChiShare: BigChiShare}); err != nil {
+		return r, err
+	}
+
+	if err := r.statemgr.SetLastRound(r.ID, int(r.Number())); err != nil {
+		return r, err
+	}
+
+	r.reportProgress(r.Number(), config.ProgressRoundCompleted)
+	return &presign5{
+		round4:       r,
+		deltaInv:     deltaInv,
+		BigR:         BigR,
+		BigChiShares: map[party.ID]curve.Point{r.SelfID(): BigChiShare},
+	}, nil
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+//
+// - save Sⱼ.
+func (r *presign5) StoreBroadcastMessage(msg round.Message) (err error) {
+	body, ok := msg.Content.(*broadcastPresign5)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.BigChiShare == nil || body.BigChiShare.IsIdentity() {
+		return round.ErrNilFields
+	}
+	if err := r.BeginStore(r.Number(), msg.From, true); err != nil {
+		return err
+	}
+	defer r.EndStore(r.Number(), msg.From, true, &err)
+
+	r.mtx.Lock()
+	r.BigChiShares[msg.From] = body.BigChiShare
+	r.mtx.Unlock()
+
+	// Mark the message as received
+	return r.bcstmgr.Import(
+		r.bcstmgr.NewMessage(r.cfg.ID(), int(r.Number()), string(msg.From), true),
+	)
+}
+
+// VerifyMessage implements round.Round.
+func (*presign5) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (*presign5) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round.
+func (r *presign5) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
+//
+// - verify ∑ⱼ Sⱼ = X
+// - compute R̄ⱼ = [δ⁻¹]Δⱼ
+// - output the presignature.
+func (r *presign5) FinalizeContext(ctx context.Context, _ chan<- *round.Message) (_ round.Session, err error) {
+	if !r.CanFinalize() {
+		return nil, round.ErrNotEnoughMessages
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	sopts := keyopts.Options{}
+	sopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(r.SelfID()))
+
+	soptsRoot := keyopts.Options{}
+	soptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", "ROOT")
+
+	r.mtx.Lock()
+	BigChiShares := make(map[party.ID]curve.Point, len(r.BigChiShares))
+	for j, Sj := range r.BigChiShares {
+		BigChiShares[j] = Sj
+	}
+	r.mtx.Unlock()
+
+	// ∑ⱼ Sⱼ = [χ]R = [k⋅x][k⁻¹]G = X
+	ecKey, err := r.ec.GetKey(soptsRoot)
+	if err != nil {
+		return nil, err
+	}
+	S := r.Group().NewPoint()
+	for _, Sj := range BigChiShares {
+		S = S.Add(Sj)
+	}
+	if !S.Equal(ecKey.PublicKeyRaw()) {
+		if err := r.statemgr.SetAborted(r.ID); err != nil {
+			return r, err
+		}
+		r.reportProgress(r.Number(), config.ProgressAborted)
+		return r.AbortRound(errors.New("presign: χ shares are inconsistent with the public key")), nil
+	}
+
+	// R̄ⱼ = [δ⁻¹]Δⱼ = [kⱼ]R
+	BigKShares := make(map[party.ID]curve.Point, len(r.PartyIDs()))
+	for _, j := range r.PartyIDs() {
+		soptsj := keyopts.Options{}
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
+		bigDeltaShare, err := r.bigDelta.GetKey(soptsj)
+		if err != nil {
+			return nil, err
+		}
+		BigKShares[j] = r.deltaInv.Act(bigDeltaShare.PublicKeyRaw())
+	}
+
+	kShare, err := r.signK.GetKey(sopts)
+	if err != nil {
+		return nil, err
+	}
+	k, err := kShare.AddKeys()
+	if err != nil {
+		return nil, err
+	}
+	chiShare, err := r.chi.GetKey(sopts)
+	if err != nil {
+		return nil, err
+	}
+	chi, err := chiShare.AddKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	presig := &Presignature{
+		ID:           r.cfg.ID(),
+		KeyID:        r.cfg.KeyID(),
+		Group:        r.Group(),
+		SelfID:       r.SelfID(),
+		PartyIDs:     r.PartyIDs(),
+		R:            r.BigR,
+		KShare:       k,
+		ChiShare:     chi,
+		BigKShares:   BigKShares,
+		BigChiShares: BigChiShares,
+	}
+	if err := r.storePresignature(presig); err != nil {
+		return r, err
+	}
+
+	if err := r.statemgr.SetLastRound(r.ID, int(r.Number())); err != nil {
+		return r, err
+	}
+	if err := r.statemgr.SetCompleted(r.ID); err != nil {
+		return r, err
+	}
+
+	r.reportProgress(r.Number(), config.ProgressDone)
+	return r.ResultRound(presig), nil
+}
+
+// storePresignature stores presig until SignOnline consumes it, and deletes the shares kᵢ and χᵢ
+// of the session from their key managers, so that the stored presignature is their only copy.
+func (r *presign5) storePresignature(presig *Presignature) error {
+	data, err := presig.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := r.presigs.Import(presignatureSKI(presig), data, presignatureOpts(presig)); err != nil {
+		return err
+	}
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID())
+	if err != nil {
+		return err
+	}
+	for _, mgr := range []interface{}{r.signK, r.chi} {
+		if km, ok := mgr.(keystore.KeyManager); ok {
+			if _, err := km.PurgeSession(opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// presignatureOpts returns the options presig is stored under.
+func presignatureOpts(presig *Presignature) keyopts.Options {
+	opts := keyopts.Options{}
+	opts.Set("protocol", keyopts.ProtocolCMP, "id", presig.ID, "partyid", string(presig.SelfID))
+	return opts
+}
+
+func presignatureSKI(presig *Presignature) string {
+	return presig.ID + "/" + string(presig.SelfID)
+}
+
+func (r *presign5) CanFinalize() bool {
+	var parties []string
+	for _, p := range r.OtherPartyIDs() {
+		parties = append(parties, string(p))
+	}
+	rcvd, err := r.bcstmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
+	if err != nil {
+		return false
+	}
+	return rcvd
+}
+
+// MessageContent implements round.Round.
+func (*presign5) MessageContent() round.Content { return nil }
+
+// RoundNumber implements round.Content.
+func (broadcastPresign5) RoundNumber() round.Number { return 5 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *presign5) BroadcastContent() round.BroadcastContent {
+	return &broadcastPresign5{
+		BigChiShare: r.Group().NewPoint(),
+	}
+}
+
+// Number implements round.Round.
+func (*presign5) Number() round.Number { return 5 }
@@ -0,0 +1,19 @@
+package sign
+
+import (
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+)
+
+// reportProgress sends the status of round n to the progress channel of the session, if there is
+// one and it has room for the event.
+func (r *round1) reportProgress(n round.Number, status config.ProgressStatus) {
+	ch := r.cfg.Progress()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- config.ProgressEvent{ID: r.ID, Round: n, Status: status}:
+	default:
+	}
+}
@@ -0,0 +1,87 @@
+package sign
+
+import (
+	"crypto/hmac"
+	"hash"
+
+	"github.com/cronokirby/saferith"
+)
+
+// hmacDRBG is the HMAC-DRBG of RFC 6979, section 3.2.
+type hmacDRBG struct {
+	newHash func() hash.Hash
+	k, v    []byte
+}
+
+// newRFC6979 instantiates the generator of RFC 6979 (steps a to g) for the secret x and the
+// message digest h1, modulo q. extra is the additional data of section 3.6, which separates
+// the nonces derived from the same secret and digest.
+func newRFC6979(newHash func() hash.Hash, q *saferith.Modulus, x *saferith.Nat, h1, extra []byte) *hmacDRBG {
+	rlen := (q.BitLen() + 7) / 8
+	seed := make([]byte, 0, 2*rlen+len(extra))
+	seed = append(seed, int2octets(x, rlen)...)
+	seed = append(seed, int2octets(new(saferith.Nat).Mod(bits2int(h1, q.BitLen()), q), rlen)...)
+	seed = append(seed, extra...)
+
+	size := newHash().Size()
+	d := &hmacDRBG{
+		newHash: newHash,
+		k:       make([]byte, size),
+		v:       make([]byte, size),
+	}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+	d.k = d.mac(d.k, d.v, []byte{0x00}, seed)
+	d.v = d.mac(d.k, d.v)
+	d.k = d.mac(d.k, d.v, []byte{0x01}, seed)
+	d.v = d.mac(d.k, d.v)
+	return d
+}
+
+// Read fills p with the output of the generator, then updates its state as RFC 6979 does
+// before generating another candidate. It never fails.
+func (d *hmacDRBG) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		d.v = d.mac(d.k, d.v)
+		n += copy(p[n:], d.v)
+	}
+	d.k = d.mac(d.k, d.v, []byte{0x00})
+	d.v = d.mac(d.k, d.v)
+	return len(p), nil
+}
+
+// nonce returns the next nonce in [1, q), following step h of RFC 6979.
+func (d *hmacDRBG) nonce(q *saferith.Modulus) *saferith.Nat {
+	qlen := q.BitLen()
+	t := make([]byte, (qlen+7)/8)
+	for {
+		_, _ = d.Read(t)
+		k := bits2int(t, qlen)
+		if _, _, lt := k.CmpMod(q); lt == 1 && k.EqZero() == 0 {
+			return k
+		}
+	}
+}
+
+func (d *hmacDRBG) mac(key []byte, data ...[]byte) []byte {
+	m := hmac.New(d.newHash, key)
+	for _, b := range data {
+		_, _ = m.Write(b)
+	}
+	return m.Sum(nil)
+}
+
+// bits2int interprets the leftmost qlen bits of b as a big-endian integer.
+func bits2int(b []byte, qlen int) *saferith.Nat {
+	x := new(saferith.Nat).SetBytes(b)
+	if blen := 8 * len(b); blen > qlen {
+		x.Rsh(x, uint(blen-qlen), qlen)
+	}
+	return x
+}
+
+// int2octets encodes x as rlen big-endian bytes.
+func int2octets(x *saferith.Nat, rlen int) []byte {
+	return x.FillBytes(make([]byte, rlen))
+}
@@ -1,6 +1,12 @@
 package sign
 
 import (
+	"context"
+	"crypto/sha256"
+
+	ecdsa_core "github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
@@ -9,6 +15,9 @@ import (
 	pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
@@ -48,6 +57,13 @@ type round1 struct {
 	chi_mta   mta.MtAManager
 
 	sigma result.SigmaStore
+
+	worker RemoteProofWorker
+
+	// presign is set when the session outputs a Presignature instead of signing cfg.Message()
+	presign bool
+	// presigs stores the Presignature output by a presigning session
+	presigs keystore.Keystore
 }
 
 // StoreBroadcastMessage implements round.Round.
@@ -59,7 +75,12 @@ func (round1) VerifyMessage(round.Message) error { return nil }
 // StoreMessage implements round.Round.
 func (round1) StoreMessage(round.Message) error { return nil }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - sample kᵢ, γᵢ <- 𝔽,
 // - Γᵢ = [γᵢ]⋅G
@@ -73,27 +94,29 @@ func (round1) StoreMessage(round.Message) error { return nil }
 //
 // In the next round, we send a hash of all the {Kⱼ,Gⱼ}ⱼ.
 // In two rounds, we compare the hashes received and if they are different then we abort.
-func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round1) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	// Retreive Paillier Key to encode K and Gamma
 	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
 
 	paillierKey, err := r.paillier_km.GetKey(kopts)
 	if err != nil {
 		return r, err
 	}
 
-	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	// K and Gamma are both encoded under the Paillier Key of this party
+	pk := paillierKey.PublicKey()
 
-	// Generate Gamma ECDSA key to mask K and store its SKI to Gamma keyrpository
-	gamma, err := r.gamma.GenerateKey(sopts)
-	if err != nil {
-		return r, err
-	}
+	sopts := keyopts.Options{}
+	sopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(r.SelfID()))
 
-	// Encode Gamma using Paillier Key
-	gammaPEK, err := gamma.EncodeByPaillier(paillierKey.PublicKey())
+	// Generate Gamma ECDSA key to mask K, encode it using Paillier Key and store both
+	gamma, gammaPEK, err := r.newNonce(r.gamma, "gamma", pk, sopts)
 	if err != nil {
 		return r, err
 	}
@@ -101,17 +124,16 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
-	// Generate K Scalar using ecdsa keymanager and store its SKI to K keyrepository
-	KShare, err := r.signK.GenerateKey(sopts)
+	// Generate K Scalar, encode it using Paillier Key and store both
+	KShare, KSharePEK, err := r.newNonce(r.signK, "k", pk, sopts)
 	if err != nil {
 		return r, err
 	}
 
-	// Encode K using Paillier Key
-	KSharePEK, err := KShare.EncodeByPaillier(paillierKey.PublicKey())
-	if err != nil {
-		return nil, err
+	if err := checkNonces(gamma, KShare); err != nil {
+		return r, err
 	}
+
 	if _, err := r.signK_pek.Import(KSharePEK, sopts); err != nil {
 		return r, err
 	}
@@ -125,13 +147,13 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 		j := otherIDs[i]
 
 		partyKopts := keyopts.Options{}
-		partyKopts.Set("id", r.cfg.KeyID(), "partyid", string(j))
+		partyKopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(j))
 
 		pedj, err := r.pedersen_km.GetKey(partyKopts)
 		if err != nil {
 			return err
 		}
-		proof, err := KShare.NewZKEncProof(r.HashForID(r.SelfID()), KSharePEK, paillierKey.PublicKey(), pedj.PublicKey())
+		proof, err := r.newZKEncProof(j, KShare, KSharePEK, paillierKey.PublicKey(), pedj.PublicKey())
 		if err != nil {
 			return err
 		}
@@ -152,6 +174,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
+	r.reportProgress(r.Number(), config.ProgressRoundCompleted)
 	return &round2{
 		round1: r,
 	}, nil
@@ -171,3 +194,56 @@ func (round1) Number() round.Number { return 1 }
 func (r *round1) Equal(other round.Round) bool {
 	return true
 }
+
+// checkNonces guards against a malfunctioning source of randomness, by verifying that the sampled
+// γᵢ and kᵢ are non-zero and distinct.
+func checkNonces(gamma, k ecdsa.ECDSAKey) error {
+	if gamma.PublicKeyRaw().IsIdentity() || k.PublicKeyRaw().IsIdentity() {
+		return ErrZeroNonce
+	}
+	if gamma.PublicKeyRaw().Equal(k.PublicKeyRaw()) {
+		return ErrRepeatedNonce
+	}
+	return nil
+}
+
+// newNonce generates a nonce key stored in km and its encryption under pk.
+//
+// If the config asks for deterministic nonces, the nonce and the randomness of its encryption
+// are derived per RFC 6979 with SHA-256 from the secret share of this party and the digest of
+// the message, with label as additional data to separate the nonces of a session.
+func (r *round1) newNonce(km ecdsa.ECDSAKeyManager, label string, pk paillier.PaillierKey, opts keyopts.Options) (ecdsa.ECDSAKey, pek.PaillierEncodedKey, error) {
+	if !r.cfg.DeterministicNonces() {
+		k, err := km.GenerateKey(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		kPEK, err := k.EncodeByPaillier(pk)
+		if err != nil {
+			return nil, nil, err
+		}
+		return k, kPEK, nil
+	}
+
+	ecKey, err := r.ec.GetKey(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	x, err := ecKey.AddKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group := r.Group()
+	digest := ecdsa_core.Digest(r.cfg.Message(), r.cfg.MessageHash())
+	drbg := newRFC6979(sha256.New, group.Order(), curve.MakeInt(x).Abs(), digest, []byte(label))
+	secret := group.NewScalar().SetNat(drbg.nonce(group.Order()))
+
+	k, err := km.ImportKey(sw_ecdsa.NewECDSAKey(secret, secret.ActOnBase(), group), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := sample.UnitModN(drbg, pk.ParamN())
+	encoded := pk.EncWithNonce(curve.MakeInt(secret), nonce)
+	return k, paillierencodedkey.NewPaillierEncodedkey(nil, encoded, nonce, group), nil
+}
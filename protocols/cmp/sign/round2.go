@@ -1,7 +1,9 @@
 package sign
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
@@ -10,6 +12,7 @@ import (
 	sw_mta "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/mta"
 	pek "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 )
 
 var _ round.Round = (*round2)(nil)
@@ -39,12 +42,15 @@ func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
+	if !paillier.ValidateCiphertextSizes(body.K, body.G) {
+		return fmt.Errorf("%w: K, G ciphertexts", round.ErrInvalidContent)
+	}
 
 	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(from))
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(from))
 
 	paillierj, err := r.paillier_km.GetKey(koptsFrom)
 	if err != nil {
@@ -90,13 +96,13 @@ func (r *round2) VerifyMessage(msg round.Message) error {
 	}
 
 	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(from))
 
 	koptsTo := keyopts.Options{}
-	koptsTo.Set("id", r.cfg.KeyID(), "partyid", string(to))
+	koptsTo.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(to))
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(from))
 
 	paillierFrom, err := r.paillier_km.GetKey(koptsFrom)
 	if err != nil {
@@ -123,23 +129,37 @@ func (r *round2) VerifyMessage(msg round.Message) error {
 
 // StoreMessage implements round.Round.
 //
-// - store Kⱼ, Gⱼ.
-func (round2) StoreMessage(round.Message) error { return nil }
+// - mark the verified zkenc(Kⱼ) as received.
+func (r *round2) StoreMessage(msg round.Message) error {
+	return r.msgmgr.Import(
+		r.msgmgr.NewMessage(r.cfg.ID(), int(r.Number()), string(msg.From), true),
+	)
+}
+
+// Finalize implements round.Round.
+func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
 
-// Finalize implements round.Round
+// FinalizeContext implements round.Round
 //
 // - compute Hash(ssid, K₁, G₁, …, Kₙ, Gₙ).
-func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round2) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(r.SelfID()))
 
 	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
 
 	// Retreive Gamma key from keystore
 	gamma, err := r.gamma.GetKey(sopts)
@@ -168,10 +188,10 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		j := otherIDs[i]
 
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 
 		koptsj := keyopts.Options{}
-		koptsj.Set("id", r.cfg.KeyID(), "partyid", string(j))
+		koptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(j))
 
 		// TODO must be changed to signID
 		gamma, err := r.gamma.GetKey(sopts)
@@ -256,7 +276,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		}
 
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 
 		delta_mta := sw_mta.NewMtA(nil, m.DeltaBeta)
 		if err := r.delta_mta.Import(delta_mta, soptsj); err != nil {
@@ -273,6 +293,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
+	r.reportProgress(r.Number(), config.ProgressRoundCompleted)
 	return &round3{
 		round2: r,
 	}, nil
@@ -284,11 +305,15 @@ func (r *round2) CanFinalize() bool {
 	for _, p := range r.OtherPartyIDs() {
 		parties = append(parties, string(p))
 	}
-	rcvd, err := r.bcstmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
+	bcstsRcvd, err := r.bcstmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
+	if err != nil {
+		return false
+	}
+	msgsRcvd, err := r.msgmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
 	if err != nil {
 		return false
 	}
-	return rcvd
+	return bcstsRcvd && msgsRcvd
 }
 
 // RoundNumber implements round.Content.
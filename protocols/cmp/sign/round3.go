@@ -1,16 +1,22 @@
 package sign
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pedersen"
 	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
 	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
 	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 )
 
 var _ round.Round = (*round3)(nil)
@@ -47,7 +53,7 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	// }
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(msg.From))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(msg.From))
 
 	// gamma := sw_ecdsa.NewECDSAKey(nil, body.BigGammaShare, body.BigGammaShare.Curve())
 	if _, err := r.gamma.ImportKey(body.BigGammaShare, soptsFrom); err != nil {
@@ -73,18 +79,21 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
+	if !paillier.ValidateCiphertextSizes(body.DeltaD, body.DeltaF, body.ChiD, body.ChiF) {
+		return fmt.Errorf("%w: D, F ciphertexts", round.ErrInvalidContent)
+	}
 
 	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(from))
 
 	koptsTo := keyopts.Options{}
-	koptsTo.Set("id", r.cfg.KeyID(), "partyid", string(to))
+	koptsTo.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(to))
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(from))
 
 	soptsTo := keyopts.Options{}
-	soptsTo.Set("id", r.cfg.ID(), "partyid", string(to))
+	soptsTo.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(to))
 
 	paillierFrom, err := r.paillier_km.GetKey(koptsFrom)
 	if err != nil {
@@ -142,18 +151,30 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		return errors.New("failed to validate affg proof for Chi MtA")
 	}
 
-	if !body.ProofLog.Verify(r.HashForID(from), zklogstar.Public{
-		C:      gammaFrom_pek.Encoded(),
-		X:      gammaFrom.PublicKeyRaw(),
-		Prover: paillierFrom.PublicKeyRaw(),
-		Aux:    pedTo.PublicKeyRaw(),
-	}) {
-		return errors.New("failed to validate log proof")
+	if err := verifyGammaShare(r.HashForID(from), from, gammaFrom_pek.Encoded(), gammaFrom.PublicKeyRaw(), body.ProofLog, paillierFrom.PublicKeyRaw(), pedTo.PublicKeyRaw()); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// verifyGammaShare checks with the log* proof of party j that the ciphertext Gⱼ it sent in round1
+// encrypts the discrete logarithm γⱼ of the share Γⱼ it broadcast in round2.
+func verifyGammaShare(h hash.Hash, j party.ID, G *paillier.Ciphertext, BigGammaShare curve.Point, proof *zklogstar.Proof, prover *paillier.PublicKey, aux *pedersen.Parameters) error {
+	if G == nil || BigGammaShare == nil || BigGammaShare.IsIdentity() {
+		return fmt.Errorf("party %s: %w", j, ErrInconsistentGamma)
+	}
+	if !proof.Verify(h, zklogstar.Public{
+		C:      G,
+		X:      BigGammaShare,
+		Prover: prover,
+		Aux:    aux,
+	}) {
+		return fmt.Errorf("party %s: %w", j, ErrInconsistentGamma)
+	}
+	return nil
+}
+
 // StoreMessage implements round.Round.
 //
 // - Decrypt MtA shares,
@@ -162,10 +183,10 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	from, body := msg.From, msg.Content.(*message3)
 
 	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(from))
 
 	// αᵢⱼ
 	paillierKey, err := r.paillier_km.GetKey(kopts)
@@ -198,29 +219,39 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	return nil
 }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - Γ = ∑ⱼ Γⱼ
 // - Δᵢ = [kᵢ]Γ
 // - δᵢ = γᵢ kᵢ + ∑ⱼ δᵢⱼ
 // - χᵢ = xᵢ kᵢ + ∑ⱼ χᵢⱼ.
-func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round3) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(r.SelfID()))
 
 	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
 
 	// Γ = ∑ⱼ Γⱼ
 	Gamma := r.Group().NewPoint()
 	for _, j := range r.PartyIDs() {
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 		gammaj, err := r.gamma.GetKey(soptsj)
 		if err != nil {
 			return nil, err
@@ -228,7 +259,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		Gamma = Gamma.Add(gammaj.PublicKeyRaw())
 	}
 	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", "ROOT")
+	soptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", "ROOT")
 	gammaRoot := sw_ecdsa.NewECDSAKey(nil, Gamma, Gamma.Curve())
 	if _, err := r.gamma.ImportKey(gammaRoot, soptsRoot); err != nil {
 		return nil, err
@@ -249,7 +280,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	deltaSum := new(saferith.Int)
 	for _, j := range r.OtherPartyIDs() {
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 		//δᵢ += αᵢⱼ + βᵢⱼ
 		deltaj, err := r.delta_mta.Get(soptsj)
 		if err != nil {
@@ -273,7 +304,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	chiSum := new(saferith.Int)
 	for _, j := range r.OtherPartyIDs() {
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 		chij, err := r.chi_mta.Get(soptsj)
 		if err != nil {
 			return nil, err
@@ -315,7 +346,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		j := otherIDs[i]
 
 		koptsj := keyopts.Options{}
-		koptsj.Set("id", r.cfg.KeyID(), "partyid", string(j))
+		koptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(j))
 
 		pedj, err := r.pedersen_km.GetKey(koptsj)
 		if err != nil {
@@ -351,6 +382,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
+	r.reportProgress(r.Number(), config.ProgressRoundCompleted)
 	return &round4{
 		round3: r,
 	}, nil
@@ -390,9 +422,7 @@ func (broadcast3) RoundNumber() round.Number { return 3 }
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round3) BroadcastContent() round.BroadcastContent {
-	return &broadcast3{
-		// BigGammaShare: r.Group().NewPoint(),
-	}
+	return &broadcast3{}
 }
 
 // Number implements round.Round.
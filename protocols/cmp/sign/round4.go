@@ -1,14 +1,18 @@
 package sign
 
 import (
+	"context"
 	"errors"
 
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 )
 
 var _ round.Round = (*round4)(nil)
@@ -42,7 +46,7 @@ func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 	}
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(msg.From))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(msg.From))
 
 	bigDeltaShareFrom := body.BigDeltaShare
 	bigDeltaFrom := sw_ecdsa.NewECDSAKey(nil, bigDeltaShareFrom, bigDeltaShareFrom.Curve())
@@ -77,16 +81,16 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 	}
 
 	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(from))
 
 	koptsTo := keyopts.Options{}
-	koptsTo.Set("id", r.cfg.KeyID(), "partyid", string(to))
+	koptsTo.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string(to))
 
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(from))
 
 	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", string("ROOT"))
+	soptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string("ROOT"))
 
 	kFromPek, err := r.signK_pek.Get(soptsFrom)
 	if err != nil {
@@ -127,33 +131,47 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 }
 
 // StoreMessage implements round.Round.
-func (round4) StoreMessage(round.Message) error {
-	return nil
+//
+// - mark the verified zklog*(Kⱼ) as received.
+func (r *round4) StoreMessage(msg round.Message) error {
+	return r.msgmgr.Import(
+		r.msgmgr.NewMessage(r.cfg.ID(), int(r.Number()), string(msg.From), true),
+	)
+}
+
+// Finalize implements round.Round.
+func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
 }
 
-// Finalize implements round.Round
+// FinalizeContext implements round.Round
 //
 // - set δ = ∑ⱼ δⱼ
 // - set Δ = ∑ⱼ Δⱼ
 // - verify Δ = [δ]G
 // - compute σᵢ = rχᵢ + kᵢm.
-func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+func (r *round4) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(r.SelfID()))
 
 	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", "ROOT")
+	soptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", "ROOT")
 
 	// δ = ∑ⱼ δⱼ
 	var deltaShares []comm_ecdsa.ECDSAKey
 	for _, j := range r.OtherPartyIDs() {
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 		delta, err := r.delta.GetKey(soptsj)
 		if err != nil {
 			return nil, err
@@ -164,13 +182,16 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return nil, err
 	}
-	Delta := selfdeltaShare.AddKeys(deltaShares...)
+	Delta, err := selfdeltaShare.AddKeys(deltaShares...)
+	if err != nil {
+		return nil, err
+	}
 
 	// Δ = ∑ⱼ Δⱼ
 	BigDelta := r.Group().NewPoint()
 	for _, j := range r.PartyIDs() {
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 		bigDeltaj, err := r.bigDelta.GetKey(soptsj)
 		if err != nil {
 			return nil, err
@@ -181,6 +202,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Δ == [δ]G
 	deltaComputed := Delta.ActOnBase()
 	if !deltaComputed.Equal(BigDelta) {
+		r.reportProgress(r.Number(), config.ProgressAborted)
 		return r.AbortRound(errors.New("computed Δ is inconsistent with [δ]G")), nil
 	}
 
@@ -200,9 +222,16 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 	RChi := chiShare.Mul(R)
 
+	// Sᵢ = [χᵢ]R, with which the other parties can check σᵢ
+	BigChiShare := chiShare.Act(BigR, false)
+
+	if r.presign {
+		return r.finalizePresign(out, deltaInv, BigR, BigChiShare)
+	}
+
 	// km = Hash(m)⋅kᵢ
 	// σᵢ = rχᵢ + kᵢm
-	m := curve.FromHash(r.Group(), r.cfg.Message())
+	m := curve.FromHash(r.Group(), ecdsa.Digest(r.cfg.Message(), r.cfg.MessageHash()))
 	selfKShare, err := r.signK.GetKey(sopts)
 	if err != nil {
 		return nil, err
@@ -214,7 +243,10 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	r.signature.ImportSignR(r.cfg.ID(), BigR)
 
 	// Send to all
-	err = r.BroadcastMessage(out, &broadcast5{SigmaShare: SigmaShare})
+	err = r.BroadcastMessage(out, &broadcast5{
+		SigmaShare:  SigmaShare,
+		BigChiShare: BigChiShare,
+	})
 	if err != nil {
 		return r, err
 	}
@@ -224,8 +256,11 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
+	r.reportProgress(r.Number(), config.ProgressRoundCompleted)
 	return &round5{
-		round4: r,
+		round4:       r,
+		deltaInv:     deltaInv,
+		BigChiShares: map[party.ID]curve.Point{r.SelfID(): BigChiShare},
 	}, nil
 }
 
@@ -235,11 +270,15 @@ func (r *round4) CanFinalize() bool {
 	for _, p := range r.OtherPartyIDs() {
 		parties = append(parties, string(p))
 	}
-	rcvd, err := r.bcstmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
+	bcstsRcvd, err := r.bcstmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
+	if err != nil {
+		return false
+	}
+	msgsRcvd, err := r.msgmgr.HasAll(r.cfg.ID(), int(r.Number()), parties)
 	if err != nil {
 		return false
 	}
-	return rcvd
+	return bcstsRcvd && msgsRcvd
 }
 
 // RoundNumber implements round.Content.
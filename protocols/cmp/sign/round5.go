@@ -1,40 +1,58 @@
 package sign
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 )
 
 var _ round.Round = (*round5)(nil)
 
 type round5 struct {
 	*round4
+
+	// deltaInv = δ⁻¹, with which R̄ⱼ = [kⱼ]R is derived from Δⱼ
+	deltaInv curve.Scalar
+
+	mtx sync.Mutex
+	// BigChiShares[j] = Sⱼ = [χⱼ]R
+	BigChiShares map[party.ID]curve.Point
 }
 
 type broadcast5 struct {
 	round.NormalBroadcastContent
 	SigmaShare curve.Scalar
+	// BigChiShare = Sⱼ = [χⱼ]R
+	BigChiShare curve.Point
 }
 
 // StoreBroadcastMessage implements round.BroadcastRound.
 //
-// - save σⱼ
+// - save σⱼ, Sⱼ
 func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 	body, ok := msg.Content.(*broadcast5)
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
 
-	if body.SigmaShare.IsZero() {
+	if body.SigmaShare.IsZero() || body.BigChiShare.IsIdentity() {
 		return round.ErrNilFields
 	}
 
+	r.mtx.Lock()
+	r.BigChiShares[msg.From] = body.BigChiShare
+	r.mtx.Unlock()
+
 	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(msg.From))
+	soptsFrom.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(msg.From))
 
 	// r.SigmaShares[msg.From] = body.SigmaShare
 	if err := r.sigma.ImportSigma(body.SigmaShare, soptsFrom); err != nil {
@@ -52,32 +70,42 @@ func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 }
 
 // VerifyMessage implements round.Round.
-func (round5) VerifyMessage(round.Message) error { return nil }
+func (*round5) VerifyMessage(round.Message) error { return nil }
 
 // StoreMessage implements round.Round.
-func (round5) StoreMessage(round.Message) error { return nil }
+func (*round5) StoreMessage(round.Message) error { return nil }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
+func (r *round5) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
 //
 // - compute σ = ∑ⱼ σⱼ
 // - verify signature.
-func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
+func (r *round5) FinalizeContext(ctx context.Context, _ chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", string("ROOT"))
+	soptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string("ROOT"))
 
 	koptsRoot := keyopts.Options{}
-	koptsRoot.Set("id", r.cfg.KeyID(), "partyid", string("ROOT"))
+	koptsRoot.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.KeyID(), "partyid", string("ROOT"))
 
 	// compute σ = ∑ⱼ σⱼ
 	Sigma := r.Group().NewScalar()
 	for _, j := range r.PartyIDs() {
 		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
 		sigmaShare, err := r.sigma.GetSigma(soptsj)
 		if err != nil {
 			return nil, err
@@ -97,25 +125,24 @@ func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return nil, err
 	}
-	if !signature.Verify(ecKey.PublicKeyRaw(), r.cfg.Message()) {
-		// update state to Aborted in StateManager
-		if err := r.statemgr.SetAborted(r.ID); err != nil {
-			return r, err
-		}
-		return r.AbortRound(errors.New("failed to validate signature")), nil
+	if !signature.VerifyMessage(ecKey.PublicKeyRaw(), r.cfg.Message(), r.cfg.MessageHash()) {
+		return r.abortSignature(signR, ecKey.PublicKeyRaw())
 	}
 
 	ecKey, err = r.ec.GetKey(koptsRoot)
 	if err != nil {
 		return nil, err
 	}
-	if !signature.Verify(ecKey.PublicKeyRaw(), r.cfg.Message()) {
-		// update state to Aborted in StateManager
-		if err := r.statemgr.SetAborted(r.ID); err != nil {
-			return r, err
-		}
-		return r.AbortRound(errors.New("failed to validate signature")), nil
+	if !signature.VerifyMessage(ecKey.PublicKeyRaw(), r.cfg.Message(), r.cfg.MessageHash()) {
+		return r.abortSignature(signR, ecKey.PublicKeyRaw())
+	}
+
+	// the public key can be recovered from (r, s) and the recovery id, as done by Ethereum
+	v, err := signature.ComputeRecoveryID()
+	if err != nil {
+		return r, err
 	}
+	signature.RecoveryID = v
 
 	// update last round processed in StateManager
 	if err := r.statemgr.SetLastRound(r.ID, int(r.Number())); err != nil {
@@ -126,9 +153,80 @@ func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
+	r.reportProgress(r.Number(), config.ProgressDone)
 	return r.ResultRound(signature), nil
 }
 
+// abortSignature aborts the session after the signature (R, σ) failed to verify under the public
+// key X. The abort does not name culprits: Sⱼ is not proven, so a party sending an invalid σⱼ can
+// broadcast an Sⱼ matching it, and is only detected by ∑ⱼ Sⱼ ≠ X. The parties whose σⱼ does not
+// match their own Sⱼ are only reported in the error.
+func (r *round5) abortSignature(BigR, X curve.Point) (round.Session, error) {
+	inconsistent, err := r.inconsistentSigmas(BigR)
+	if err != nil {
+		return r, err
+	}
+
+	// update state to Aborted in StateManager
+	if err := r.statemgr.SetAborted(r.ID); err != nil {
+		return r, err
+	}
+	r.reportProgress(r.Number(), config.ProgressAborted)
+
+	if len(inconsistent) > 0 {
+		return r.AbortRound(fmt.Errorf("failed to validate signature: %w: parties %v", ErrInconsistentSigma, inconsistent)), nil
+	}
+	// every σⱼ matches its Sⱼ, so some Sⱼ is wrong, which shows in ∑ⱼ Sⱼ = [χ]R ≠ X but does not
+	// tell which
+	S := r.Group().NewPoint()
+	r.mtx.Lock()
+	for _, Sj := range r.BigChiShares {
+		S = S.Add(Sj)
+	}
+	r.mtx.Unlock()
+	if !S.Equal(X) {
+		return r.AbortRound(errors.New("failed to validate signature: χ shares are inconsistent with the public key")), nil
+	}
+	return r.AbortRound(errors.New("failed to validate signature")), nil
+}
+
+// inconsistentSigmas returns the parties j whose σⱼ does not satisfy [σⱼ]R = [m]R̄ⱼ + [r]Sⱼ, where
+// R̄ⱼ = [δ⁻¹]Δⱼ = [kⱼ]R is derived from the Δⱼ proven in round4, and Sⱼ = [χⱼ]R was broadcast with
+// σⱼ without a proof.
+func (r *round5) inconsistentSigmas(BigR curve.Point) ([]party.ID, error) {
+	m := curve.FromHash(r.Group(), ecdsa.Digest(r.cfg.Message(), r.cfg.MessageHash()))
+	R := BigR.XScalar()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	var inconsistent []party.ID
+	for _, j := range r.PartyIDs() {
+		soptsj := keyopts.Options{}
+		soptsj.Set("protocol", keyopts.ProtocolCMP, "id", r.cfg.ID(), "partyid", string(j))
+
+		sigmaShare, err := r.sigma.GetSigma(soptsj)
+		if err != nil {
+			return nil, err
+		}
+		bigDeltaShare, err := r.bigDelta.GetKey(soptsj)
+		if err != nil {
+			return nil, err
+		}
+		BigChiShare, ok := r.BigChiShares[j]
+		if !ok {
+			return nil, fmt.Errorf("sign: missing χ share of party %s", j)
+		}
+
+		BigKShare := r.deltaInv.Act(bigDeltaShare.PublicKeyRaw())
+		expected := m.Act(BigKShare).Add(R.Act(BigChiShare))
+		if !sigmaShare.Act(BigR).Equal(expected) {
+			inconsistent = append(inconsistent, j)
+		}
+	}
+	return inconsistent, nil
+}
+
 func (r *round5) CanFinalize() bool {
 	// Verify if all parties commitments are received
 	var parties []string
@@ -151,12 +249,13 @@ func (broadcast5) RoundNumber() round.Number { return 5 }
 // BroadcastContent implements round.BroadcastRound.
 func (r *round5) BroadcastContent() round.BroadcastContent {
 	return &broadcast5{
-		SigmaShare: r.Group().NewScalar(),
+		SigmaShare:  r.Group().NewScalar(),
+		BigChiShare: r.Group().NewPoint(),
 	}
 }
 
 // Number implements round.Round.
-func (round5) Number() round.Number { return 5 }
+func (*round5) Number() round.Number { return 5 }
 
 func (r *round5) Equal(other round.Round) bool {
 	return true
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 	"github.com/mr-shifu/mpc-lib/lib/round"
@@ -18,12 +18,13 @@ import (
 	pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
-	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 )
 
 // protocolSignID for the "3 round" variant using echo broadcast.
@@ -32,6 +33,29 @@ const (
 	protocolSignRounds round.Number = 5
 )
 
+func init() {
+	protocol.RegisterMessageContent(protocolSignID,
+		&broadcast2{}, &message2{}, &broadcast3{}, &message3{}, &message4{}, &broadcast4{}, &broadcast5{})
+	protocol.RegisterMessageContent(protocolPresignID,
+		&broadcast2{}, &message2{}, &broadcast3{}, &message3{}, &message4{}, &broadcast4{}, &broadcastPresign5{})
+	protocol.RegisterMessageContent(protocolSignOnlineID, &broadcastOnline2{})
+}
+
+var (
+	ErrZeroNonce     = errors.New("sign: sampled nonce is zero")
+	ErrRepeatedNonce = errors.New("sign: sampled nonce is repeated")
+	// ErrInconsistentGamma is returned when the ciphertext Gⱼ of a party does not encrypt the
+	// discrete logarithm of its share Γⱼ.
+	ErrInconsistentGamma = errors.New("sign: G does not encrypt the discrete log of Γ")
+	// ErrInconsistentSigma is the error of a session aborted because the σⱼ of some parties does
+	// not match the Sⱼ = [χⱼ]R they broadcast with it. Sⱼ is not proven, so this does not identify
+	// every party sending an invalid σⱼ, and the parties are not named as culprits of the abort.
+	ErrInconsistentSigma = errors.New("sign: σ share does not match its χ share")
+	// ErrTooFewSigners is returned when a signing session is started with no more signers than the
+	// degree of the key.
+	ErrTooFewSigners = errors.New("sign: too few signers")
+)
+
 type MPCSign struct {
 	signcfgmgr config.SignConfigManager
 	statmgr    state.MPCStateManager
@@ -62,6 +86,11 @@ type MPCSign struct {
 
 	sigma     result.SigmaStore
 	signature result.Signature
+
+	// presigs holds the presignatures of this party until SignOnline consumes them.
+	presigs keystore.Keystore
+
+	worker RemoteProofWorker
 }
 
 func NewMPCSign(
@@ -86,6 +115,7 @@ func NewMPCSign(
 	chi_mta mta.MtAManager,
 	sigma result.SigmaStore,
 	signature result.Signature,
+	presigs keystore.Keystore,
 ) *MPCSign {
 	return &MPCSign{
 		signcfgmgr:  signcfgmgr,
@@ -109,54 +139,89 @@ func NewMPCSign(
 		chi_mta:     chi_mta,
 		sigma:       sigma,
 		signature:   signature,
+		presigs:     presigs,
 	}
 }
 
 func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.StartFunc {
+	return m.start(cfg, pl, false)
+}
+
+// start returns the first round of a signing session, or of a presigning session if presign is
+// set, in which case cfg.Message() is ignored.
+func (m *MPCSign) start(cfg config.SignConfig, pl *pool.Pool, presign bool) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
+		// rounds of a CMP session only live in memory, so an existing session cannot be resumed
+		if _, err := m.statmgr.Get(cfg.ID()); err == nil {
+			return nil, fmt.Errorf("sign.Create: %w", state.ErrStateExists)
+		}
+
+		protocolID := protocolSignID
+		var auxInfo []core_hash.WriterToWithDomain
+		if presign {
+			// the nonces of a presignature cannot depend on the message it will sign
+			if cfg.DeterministicNonces() {
+				return nil, errors.New("sign.Create: deterministic nonces cannot be used to presign")
+			}
+			if m.presigs == nil {
+				return nil, errors.New("sign.Create: no keystore to store presignatures")
+			}
+			protocolID = protocolPresignID
+		} else {
+			// this could be used to indicate a pre-signature later on
+			if len(cfg.Message()) == 0 {
+				return nil, errors.New("sign.Create: message is nil")
+			}
+			auxInfo = append(auxInfo, types.SigningMessage(cfg.Message()))
+		}
+
 		info := round.Info{
-			ProtocolID:       "cmp/sign",
+			ProtocolID:       protocolID,
 			FinalRoundNumber: 5,
 			SelfID:           cfg.SelfID(),
 			PartyIDs:         cfg.PartyIDs(),
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
+			MaxParties:       cfg.MaxParties(),
 		}
-		group := info.Group
-
 		opts := keyopts.Options{}
-		opts.Set("id", cfg.ID(), "partyid", info.SelfID)
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.ID(), "partyid", info.SelfID)
 
 		h := m.hash_mgr.NewHasher(cfg.ID(), opts)
 
-		// this could be used to indicate a pre-signature later on
-		if len(cfg.Message()) == 0 {
-			return nil, errors.New("sign.Create: message is nil")
+		helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h, auxInfo...)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
 		}
+		helper.SetMessageManagers(m.msgmgr, m.bcstmgr)
 
-		helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h, types.SigningMessage(cfg.Message()))
-		if err != nil {
+		if err := m.checkAuxInfo(cfg); err != nil {
 			return nil, fmt.Errorf("sign.Create: %w", err)
 		}
 
-		// if !config.CanSign(helper.PartyIDs()) {
-		// 	return nil, errors.New("sign.Create: signers is not a valid signing subset")
-		// }
+		// the signers may be any subset of the key holders, as long as they are more than the
+		// threshold of the key, which is the degree of its VSS polynomial
+		vssOpts := keyopts.Options{}
+		vssOpts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.KeyID(), "partyid", "ROOT")
+		vss, err := m.vss_mgr.GetSecrets(vssOpts)
+		if err != nil {
+			return nil, err
+		}
+		exponents, err := vss.ExponentsRaw()
+		if err != nil {
+			return nil, err
+		}
+		if n := len(helper.PartyIDs()); n <= exponents.Degree() {
+			return nil, fmt.Errorf("sign.Create: %w: %d signers, the key requires at least %d", ErrTooFewSigners, n, exponents.Degree()+1)
+		}
 
 		// Scale public data
 
-		lagrange := polynomial.Lagrange(group, cfg.PartyIDs())
+		lagrange := m.vss_mgr.LagrangeBasis(cfg.PartyIDs())
 		clonedPubKey := info.Group.NewPoint()
 		for _, j := range helper.PartyIDs() {
-			vssOpts := keyopts.Options{}
-			vssOpts.Set("id", cfg.KeyID(), "partyid", "ROOT")
-			vss, err := m.vss_mgr.GetSecrets(vssOpts)
-			if err != nil {
-				return nil, err
-			}
-
 			partyVSSOpts := keyopts.Options{}
-			partyVSSOpts.Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
+			partyVSSOpts.Set("protocol", keyopts.ProtocolCMP, "id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
 
 			vssShareKey, err := m.ec_vss.GetKey(partyVSSOpts)
 			if err != nil {
@@ -164,7 +229,7 @@ func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.Start
 			}
 
 			partyOpts := keyopts.Options{}
-			partyOpts.Set("id", cfg.ID(), "partyid", string(j))
+			partyOpts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.ID(), "partyid", string(j))
 			clonedj := vssShareKey.CloneByMultiplier(lagrange[j])
 			if _, err := m.ec.ImportKey(clonedj, partyOpts); err != nil {
 				return nil, err
@@ -172,7 +237,7 @@ func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.Start
 			clonedPubKey = clonedPubKey.Add(clonedj.PublicKeyRaw())
 		}
 		rootECOpts := keyopts.Options{}
-		rootECOpts.Set("id", cfg.ID(), "partyid", "ROOT")
+		rootECOpts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.ID(), "partyid", "ROOT")
 		cloned := sw_ecdsa.NewECDSAKey(nil, clonedPubKey, info.Group)
 		if _, err := m.ec.ImportKey(cloned, rootECOpts); err != nil {
 			return nil, err
@@ -208,6 +273,28 @@ func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.Start
 			chi_mta:     m.chi_mta,
 			sigma:       m.sigma,
 			signature:   m.signature,
+			worker:      m.worker,
+			presign:     presign,
+			presigs:     m.presigs,
 		}, nil
 	}
 }
+
+// checkAuxInfo verifies that the Paillier and Pedersen public parameters of every signer
+// are available, since the rounds use them without further checks.
+func (m *MPCSign) checkAuxInfo(cfg config.SignConfig) error {
+	for _, j := range cfg.PartyIDs() {
+		opts := keyopts.Options{}
+		opts.Set("protocol", keyopts.ProtocolCMP, "id", cfg.KeyID(), "partyid", string(j))
+
+		paillierj, err := m.paillier_km.GetKey(opts)
+		if err != nil || paillierj == nil || paillierj.PublicKeyRaw() == nil {
+			return fmt.Errorf("missing Paillier public key of party %s", j)
+		}
+		pedersenj, err := m.pedersen_km.GetKey(opts)
+		if err != nil || pedersenj == nil || pedersenj.PublicKeyRaw() == nil {
+			return fmt.Errorf("missing Pedersen parameters of party %s", j)
+		}
+	}
+	return nil
+}
@@ -1,19 +1,41 @@
 package sign
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"testing"
 
+	"github.com/cronokirby/saferith"
 	"github.com/google/uuid"
+	ecdsa_core "github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	paillier_core "github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/core/zk"
+	zkenc "github.com/mr-shifu/mpc-lib/core/zk/enc"
+	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	comm_paillier "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
+	comm_pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
+	comm_pedersen "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
 	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
 
@@ -27,6 +49,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
+	comm_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
 	mpc_result "github.com/mr-shifu/mpc-lib/pkg/mpc/result"
@@ -34,7 +57,17 @@ import (
 )
 
 func newMPC() (*keygen.MPCKeygen, *MPCSign) {
-	pl := pool.NewPool(0)
+	return newMPCWithPool(pool.NewPool(0))
+}
+
+func newMPCWithPool(pl *pool.Pool) (*keygen.MPCKeygen, *MPCSign) {
+	return newMPCWithSource(pl, nil)
+}
+
+// newMPCWithSource returns managers whose ECDSA keys, including the shares of the key and the
+// nonces, are sampled from source, or from crypto/rand if source is nil.
+func newMPCWithSource(pl *pool.Pool, source io.Reader) (*keygen.MPCKeygen, *MPCSign) {
+	ecCfg := &ecdsa.Config{Group: curve.Secp256k1{}, Rand: source}
 
 	ksf := keystore.InmemoryKeystoreFactory{}
 	krf := keyopts.InMemoryKeyOptsFactory{}
@@ -81,11 +114,11 @@ func newMPC() (*keygen.MPCKeygen, *MPCSign) {
 	sch_keyopts := krf.NewKeyOpts(nil)
 	sch_vault := vf.NewVault(nil)
 	sch_ks := ksf.NewKeystore(sch_vault, sch_keyopts, nil)
-	ecdsa_km := ecdsa.NewECDSAKeyManager(ec_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	ecdsa_km := ecdsa.NewECDSAKeyManager(ec_ks, sch_ks, vss_km, ecCfg)
 
 	ec_vss_keyopts := krf.NewKeyOpts(nil)
 	ec_vss_ks := ksf.NewKeystore(ec_vault, ec_vss_keyopts, nil)
-	ec_vss_km := ecdsa.NewECDSAKeyManager(ec_vss_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	ec_vss_km := ecdsa.NewECDSAKeyManager(ec_vss_ks, sch_ks, vss_km, ecCfg)
 
 	rid_keyopts := krf.NewKeyOpts(nil)
 	rid_vault := vf.NewVault(nil)
@@ -134,23 +167,23 @@ func newMPC() (*keygen.MPCKeygen, *MPCSign) {
 
 	gamma_kr := krf.NewKeyOpts(nil)
 	gamma_ks := ksf.NewKeystore(ec_vault, gamma_kr, nil)
-	gamma_km := ecdsa.NewECDSAKeyManager(gamma_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	gamma_km := ecdsa.NewECDSAKeyManager(gamma_ks, sch_ks, vss_km, ecCfg)
 
 	signK_kr := krf.NewKeyOpts(nil)
 	signK_ks := ksf.NewKeystore(ec_vault, signK_kr, nil)
-	signK_km := ecdsa.NewECDSAKeyManager(signK_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	signK_km := ecdsa.NewECDSAKeyManager(signK_ks, sch_ks, vss_km, ecCfg)
 
 	delta_kr := krf.NewKeyOpts(nil)
 	delta_ks := ksf.NewKeystore(ec_vault, delta_kr, nil)
-	delta_km := ecdsa.NewECDSAKeyManager(delta_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	delta_km := ecdsa.NewECDSAKeyManager(delta_ks, sch_ks, vss_km, ecCfg)
 
 	chi_kr := krf.NewKeyOpts(nil)
 	chi_ks := ksf.NewKeystore(ec_vault, chi_kr, nil)
-	chi_km := ecdsa.NewECDSAKeyManager(chi_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	chi_km := ecdsa.NewECDSAKeyManager(chi_ks, sch_ks, vss_km, ecCfg)
 
 	bigDelta_kr := krf.NewKeyOpts(nil)
 	bigDelta_ks := ksf.NewKeystore(ec_vault, bigDelta_kr, nil)
-	bigDelta_km := ecdsa.NewECDSAKeyManager(bigDelta_ks, sch_ks, vss_km, &ecdsa.Config{Group: curve.Secp256k1{}})
+	bigDelta_km := ecdsa.NewECDSAKeyManager(bigDelta_ks, sch_ks, vss_km, ecCfg)
 
 	gamma_pek_vault := vf.NewVault(nil)
 	gamma_pek_kr := krf.NewKeyOpts(nil)
@@ -194,6 +227,7 @@ func newMPC() (*keygen.MPCKeygen, *MPCSign) {
 		chi_mta_km,
 		sigma,
 		signature,
+		ksf.NewKeystore(vf.NewVault(nil), krf.NewKeyOpts(nil), nil),
 	)
 
 	return mpc_keygen, mpc_sign
@@ -266,3 +300,920 @@ func TestSign(t *testing.T) {
 	}
 	// checkOutput(t, rounds)
 }
+
+// goldenSignature is the signature produced by TestSign_Golden, encoded as R || S || V.
+// It only depends on the ECDSA key and nonce shares, and must only change along with their sampling.
+const goldenSignature = "1cb7e836ec3169f0a9954f57b8ea3aa7b8c4e9f30646b3dd718c59eb1a3ea62377a6f1df45f2e61fe8f481116f8e39ebe95af116f7cfdd94fcd2fb4f9d4e4ff000"
+
+func TestSign_Golden(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	keyID := "golden-key"
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		// the signature only depends on the shares of the key and of the nonce, which are
+		// sampled from a seed of each party
+		seed := sha3.NewShake256()
+		_, _ = seed.Write([]byte("mpc-lib cmp sign golden " + string(partyID)))
+		mpckg, mpcsign := newMPCWithSource(pl, seed)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)([]byte("golden-keygen"))
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+
+	signRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig("golden-sign", keyID, group, N-1, partyID, partyIDs, messageHash)
+		r, err := signs[i].StartSign(cfg, pl)([]byte("golden-sign"))
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(signRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	for _, r := range signRounds {
+		require.IsType(t, &round.Output{}, r)
+		signature, ok := r.(*round.Output).Result.(*ecdsa_core.Signature)
+		require.True(t, ok)
+		sig, err := signature.MarshalCompact(true)
+		require.NoError(t, err)
+		assert.Equal(t, goldenSignature, hex.EncodeToString(sig))
+	}
+}
+
+// repeatReader returns the same bytes on every read.
+type repeatReader struct {
+	seed []byte
+}
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	return copy(p, bytes.Repeat(r.seed, len(p)/len(r.seed)+1)), nil
+}
+
+func TestCheckNonces(t *testing.T) {
+	seed := make([]byte, 32)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		source io.Reader
+		err    error
+	}{
+		{"random", rand.Reader, nil},
+		{"zero", bytes.NewReader(make([]byte, 1024)), ErrZeroNonce},
+		{"repeat", repeatReader{seed}, ErrRepeatedNonce},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newKeyManager := func() *ecdsa.ECDSAKeyManager {
+				ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+				sch_ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+				return ecdsa.NewECDSAKeyManager(ks, sch_ks, nil, &ecdsa.Config{Group: curve.Secp256k1{}, Rand: tt.source})
+			}
+
+			opts := keyopts.Options{}
+			opts.Set("id", uuid.NewString(), "partyid", "a")
+			gamma, err := newKeyManager().GenerateKey(opts)
+			require.NoError(t, err)
+			k, err := newKeyManager().GenerateKey(opts)
+			require.NoError(t, err)
+
+			err = checkNonces(gamma, k)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+// mockProofWorker stands for a remote worker, which proves with the inputs it would receive
+// serialized, and checks each of its proofs like the receiving party does. If invalid is set, its
+// proofs are bound to another transcript.
+type mockProofWorker struct {
+	mtx      sync.Mutex
+	fail     bool
+	invalid  bool
+	peers    []party.ID
+	verified int
+}
+
+func (w *mockProofWorker) NewZKEncProof(j party.ID, h comm_hash.Hash, k comm_ecdsa.ECDSAKey, kPEK comm_pek.PaillierEncodedKey, pk comm_paillier.PaillierKey, ped comm_pedersen.PedersenKey) (*zkenc.Proof, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.peers = append(w.peers, j)
+	if w.fail {
+		return nil, errors.New("worker unreachable")
+	}
+
+	verifierHash := h.Clone()
+	if w.invalid {
+		if err := h.WriteAny([]byte("another transcript")); err != nil {
+			return nil, err
+		}
+	}
+	proof, err := k.NewZKEncProof(h, kPEK, pk, ped)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Verify(k.Group(), verifierHash, zkenc.Public{
+		K:      kPEK.Encoded(),
+		Prover: pk.PublicKeyRaw(),
+		Aux:    ped.PublicKeyRaw(),
+	}) {
+		w.verified++
+	}
+	return proof, nil
+}
+
+func TestSign_RemoteProofWorker(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	// all parties offload their proofs, but the worker of b is unreachable and the one of c is faulty
+	workers := map[party.ID]*mockProofWorker{
+		partyIDs[0]: {},
+		partyIDs[1]: {fail: true},
+		partyIDs[2]: {invalid: true},
+	}
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		if w, ok := workers[partyID]; ok {
+			mpcsign.SetRemoteProofWorker(w)
+		}
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+
+	signID := uuid.NewString()
+	signRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash)
+		r, err := signs[i].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(signRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	for _, r := range signRounds {
+		require.IsType(t, &round.Output{}, r)
+	}
+	assert.ElementsMatch(t, []party.ID{partyIDs[1], partyIDs[2]}, workers[partyIDs[0]].peers)
+	assert.Equal(t, N-1, workers[partyIDs[0]].verified)
+	assert.ElementsMatch(t, []party.ID{partyIDs[0], partyIDs[2]}, workers[partyIDs[1]].peers)
+	assert.Zero(t, workers[partyIDs[1]].verified)
+	// the invalid proofs of c were replaced before they were sent
+	assert.ElementsMatch(t, []party.ID{partyIDs[0], partyIDs[1]}, workers[partyIDs[2]].peers)
+	assert.Zero(t, workers[partyIDs[2]].verified)
+}
+
+func TestSign_Progress(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	// a consumes its progress, while nobody reads the unbuffered channel of b
+	progress := make(chan comm_config.ProgressEvent, protocolSignRounds)
+	blocked := make(chan comm_config.ProgressEvent)
+	channels := map[party.ID]chan comm_config.ProgressEvent{partyIDs[0]: progress, partyIDs[1]: blocked}
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+
+	signID := uuid.NewString()
+	signRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash).
+			WithProgress(channels[partyID])
+		r, err := signs[i].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(signRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	for _, r := range signRounds {
+		require.IsType(t, &round.Output{}, r)
+	}
+	close(progress)
+
+	var events []comm_config.ProgressEvent
+	for e := range progress {
+		events = append(events, e)
+	}
+	assert.Equal(t, []comm_config.ProgressEvent{
+		{ID: signID, Round: 1, Status: comm_config.ProgressRoundCompleted},
+		{ID: signID, Round: 2, Status: comm_config.ProgressRoundCompleted},
+		{ID: signID, Round: 3, Status: comm_config.ProgressRoundCompleted},
+		{ID: signID, Round: 4, Status: comm_config.ProgressRoundCompleted},
+		{ID: signID, Round: 5, Status: comm_config.ProgressDone},
+	}, events)
+}
+
+func TestRFC6979(t *testing.T) {
+	// RFC 6979, A.2.5: ECDSA with P-256 and SHA-256, message "sample"
+	q, err := saferith.ModulusFromHex("FFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551")
+	require.NoError(t, err)
+	x, err := hex.DecodeString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	require.NoError(t, err)
+	h1 := sha256.Sum256([]byte("sample"))
+
+	k := newRFC6979(sha256.New, q, new(saferith.Nat).SetBytes(x), h1[:], nil).nonce(q)
+	assert.Equal(t, "a6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60", hex.EncodeToString(int2octets(k, 32)))
+}
+
+// recordNonces records the ciphertexts Kᵢ and Gᵢ broadcast by each party in round1.
+type recordNonces struct {
+	K, G map[party.ID]*paillier_core.Ciphertext
+}
+
+func (recordNonces) ModifyBefore(round.Session) {}
+func (recordNonces) ModifyAfter(round.Session)  {}
+func (r recordNonces) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if b, ok := content.(*broadcast2); ok {
+		r.K[rNext.SelfID()] = b.K
+		r.G[rNext.SelfID()] = b.G
+	}
+}
+
+func TestSign_DeterministicNonces(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+
+	sign := func(deterministic bool) recordNonces {
+		rule := recordNonces{
+			K: make(map[party.ID]*paillier_core.Ciphertext),
+			G: make(map[party.ID]*paillier_core.Ciphertext),
+		}
+		signID := uuid.NewString()
+		signRounds := make([]round.Session, 0, N)
+		for i, partyID := range partyIDs {
+			cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash)
+			if deterministic {
+				cfg.WithDeterministicNonces()
+			}
+			r, err := signs[i].StartSign(cfg, pl)(nil)
+			require.NoError(t, err)
+			signRounds = append(signRounds, r)
+		}
+		for {
+			err, done := test.SerialRounds(signRounds, rule)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+		for _, r := range signRounds {
+			require.IsType(t, &round.Output{}, r)
+		}
+		return rule
+	}
+
+	first := sign(true)
+	second := sign(true)
+	random := sign(false)
+	for _, j := range partyIDs {
+		require.Contains(t, first.K, j)
+		assert.True(t, first.K[j].Equal(second.K[j]), "K of %s differs", j)
+		assert.True(t, first.G[j].Equal(second.G[j]), "G of %s differs", j)
+		assert.False(t, first.K[j].Equal(first.G[j]), "K and G of %s are equal", j)
+		assert.False(t, first.K[j].Equal(random.K[j]), "K of %s is not sampled by default", j)
+	}
+}
+
+func TestSign_MessageHash(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	require.IsType(t, &round.Output{}, keygenRounds[0])
+	public := keygenRounds[0].(*round.Output).Result.(*keygen.KeygenResult).Config.PublicPoint()
+
+	// an Ethereum transaction is signed as its Keccak-256 digest
+	tx := []byte("ethereum transaction")
+	signID := uuid.NewString()
+	signRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, tx).WithMessageHash(sha3.NewLegacyKeccak256)
+		r, err := signs[i].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(signRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	digest := sha3.NewLegacyKeccak256()
+	_, _ = digest.Write(tx)
+	for _, r := range signRounds {
+		require.IsType(t, &round.Output{}, r)
+		signature := r.(*round.Output).Result.(*ecdsa_core.Signature)
+
+		assert.True(t, signature.Verify(public, digest.Sum(nil)))
+		assert.True(t, signature.VerifyMessage(public, tx, sha3.NewLegacyKeccak256))
+		assert.False(t, signature.VerifyMessage(public, tx, sha256.New))
+		assert.False(t, signature.Verify(public, tx))
+
+		// the group public key is recovered from (r, s, v) and the message
+		recovered, err := signature.RecoverPublicKey(digest.Sum(nil), signature.RecoveryID)
+		require.NoError(t, err)
+		assert.True(t, recovered.Equal(public))
+
+		sig, err := signature.MarshalCompact(true)
+		require.NoError(t, err)
+		assert.Len(t, sig, 65)
+	}
+}
+
+func TestSign_VSSDegree(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	// the key is shared with a polynomial of degree D > T, so that D+1 parties are needed to sign
+	N, T, D := 4, 1, 2
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg, err := config.NewKeyConfig(keyID, group, T, partyID, partyIDs).WithVSSDegree(D)
+		require.NoError(t, err)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	require.IsType(t, &round.Output{}, keygenRounds[0])
+	keyConfig := keygenRounds[0].(*round.Output).Result.(*keygen.KeygenResult).Config
+	assert.Equal(t, D, keyConfig.Threshold)
+	assert.Equal(t, D+1, keyConfig.MinSigners())
+	public := keyConfig.PublicPoint()
+
+	digest := sha256.Sum256([]byte("hello"))
+
+	// D signers do not determine the key, even with a sign config claiming a lower threshold
+	signers := partyIDs[:D]
+	for i, partyID := range signers {
+		cfg := config.NewSignConfig(uuid.NewString(), keyID, group, T, partyID, signers, digest[:])
+		_, err := signs[i].StartSign(cfg, pl)(nil)
+		assert.ErrorIs(t, err, ErrTooFewSigners)
+	}
+
+	// any D+1 of the N parties can sign
+	signers = partyIDs[1 : D+2]
+	signID := uuid.NewString()
+	signRounds := make([]round.Session, 0, len(signers))
+	for i, partyID := range signers {
+		cfg := config.NewSignConfig(signID, keyID, group, T, partyID, signers, digest[:])
+		r, err := signs[i+1].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(signRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	for _, r := range signRounds {
+		require.IsType(t, &round.Output{}, r)
+		signature := r.(*round.Output).Result.(*ecdsa_core.Signature)
+		assert.True(t, signature.Verify(public, digest[:]))
+	}
+}
+
+func TestSign_ContentRoundTrip(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	signID := uuid.NewString()
+	signRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, []byte("hello"))
+		r, err := signs[i].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	checker := &test.ContentChecker{}
+	for {
+		err, done := test.SerialRounds(signRounds, checker)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	for _, content := range []round.Content{
+		&broadcast2{}, &message2{}, &broadcast3{}, &message3{}, &broadcast4{}, &message4{}, &broadcast5{},
+	} {
+		t.Run(fmt.Sprintf("%T", content), func(t *testing.T) {
+			assert.NoError(t, checker.Check(content))
+		})
+	}
+}
+
+func TestPresign(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	require.IsType(t, &round.Output{}, keygenRounds[0])
+	public := keygenRounds[0].(*round.Output).Result.(*keygen.KeygenResult).Config.PublicPoint()
+
+	// presignatures[i][j] is the i-th presignature of party j
+	const count = 5
+	presignatures := make([][]*Presignature, count)
+	for i := range presignatures {
+		presignID := uuid.NewString()
+		rounds := make([]round.Session, 0, N)
+		for j, partyID := range partyIDs {
+			cfg := config.NewSignConfig(presignID, keyID, group, N-1, partyID, partyIDs, nil)
+			r, err := signs[j].StartPresign(cfg, pl)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		for {
+			err, done := test.SerialRounds(rounds, nil)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+
+		for _, r := range rounds {
+			require.IsType(t, &round.Output{}, r)
+			presig := r.(*round.Output).Result.(*Presignature)
+
+			// presignatures are stored until a message is signed
+			data, err := presig.MarshalBinary()
+			require.NoError(t, err)
+			decoded := EmptyPresignature(group)
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			assert.True(t, presig.R.Equal(decoded.R))
+			assert.True(t, presig.KShare.Equal(decoded.KShare))
+			assert.True(t, presig.ChiShare.Equal(decoded.ChiShare))
+
+			presignatures[i] = append(presignatures[i], decoded)
+		}
+		for j := 1; j < N; j++ {
+			assert.True(t, presignatures[i][0].R.Equal(presignatures[i][j].R), "parties hold different nonces")
+		}
+		for k := 0; k < i; k++ {
+			assert.False(t, presignatures[i][0].R.Equal(presignatures[k][0].R), "nonce is reused")
+		}
+	}
+
+	for i := range presignatures {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		rounds := make([]round.Session, 0, N)
+		for j := range partyIDs {
+			r := signOnlineConcurrently(t, signs[j], presignatures[i][j], digest[:], pl)
+			rounds = append(rounds, r)
+		}
+		for {
+			err, done := test.SerialRounds(rounds, nil)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+
+		for _, r := range rounds {
+			require.IsType(t, &round.Output{}, r)
+			signature := r.(*round.Output).Result.(*ecdsa_core.Signature)
+			assert.True(t, signature.Verify(public, digest[:]))
+			assert.True(t, signature.R.Equal(presignatures[i][0].R))
+		}
+	}
+
+	// a presignature signs a single message
+	_, err := signs[0].SignOnline(presignatures[0][0], []byte("another message"), pl)(nil)
+	assert.ErrorIs(t, err, ErrPresignatureUsed)
+}
+
+// signOnlineConcurrently calls SignOnline with presig from several goroutines, checks that a
+// single call succeeds, and returns its session.
+func signOnlineConcurrently(t *testing.T, m *MPCSign, presig *Presignature, digest []byte, pl *pool.Pool) round.Session {
+	const callers = 4
+	var wg sync.WaitGroup
+	sessions := make([]round.Session, callers)
+	errs := make([]error, callers)
+	for c := 0; c < callers; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			sessions[c], errs[c] = m.SignOnline(presig, digest, pl)(nil)
+		}(c)
+	}
+	wg.Wait()
+
+	var session round.Session
+	for c := 0; c < callers; c++ {
+		if errs[c] != nil {
+			assert.ErrorIs(t, errs[c], ErrPresignatureUsed)
+			continue
+		}
+		require.Nil(t, session, "presignature is used twice")
+		session = sessions[c]
+	}
+	require.NotNil(t, session)
+	return session
+}
+
+func TestSign_Refresh(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N, threshold := 3, 1
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygens := make([]*keygen.MPCKeygen, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	keygenRounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		keygens = append(keygens, mpckg)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, threshold, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	// a refresh without all parties of the key names the missing ones
+	_, err := keygens[0].Refresh(config.NewKeyConfig(uuid.NewString(), group, threshold, partyIDs[0], partyIDs[:2]), keyID, pl)(nil)
+	var missing protocol.Error
+	require.ErrorAs(t, err, &missing)
+	assert.ErrorIs(t, err, keygen.ErrRefreshMissingParties)
+	assert.Equal(t, []party.ID{partyIDs[2]}, missing.Culprits)
+
+	refreshID := uuid.NewString()
+	refreshRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		keycfg := config.NewKeyConfig(refreshID, group, threshold, partyID, partyIDs)
+		r, err := keygens[i].Refresh(keycfg, keyID, pl)(nil)
+		require.NoError(t, err)
+		refreshRounds = append(refreshRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(refreshRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	oldConfig := keygenRounds[0].(*round.Output).Result.(*keygen.KeygenResult).Config
+	newConfig := refreshRounds[0].(*round.Output).Result.(*keygen.KeygenResult).Config
+	public := oldConfig.PublicPoint()
+	require.True(t, public.Equal(newConfig.PublicPoint()))
+	assert.Equal(t, oldConfig.ChainKey, newConfig.ChainKey)
+	for _, j := range partyIDs {
+		assert.False(t, oldConfig.Public[j].ECDSA.Equal(newConfig.Public[j].ECDSA), "share of %s was not refreshed", j)
+		assert.False(t, oldConfig.Public[j].Paillier.Equal(newConfig.Public[j].Paillier), "paillier key of %s was not refreshed", j)
+	}
+
+	// any two parties sign with the old and the new key to the same public key
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+	signers := partyIDs[1:]
+	for _, id := range []string{keyID, refreshID} {
+		signID := uuid.NewString()
+		signRounds := make([]round.Session, 0, len(signers))
+		for i, partyID := range signers {
+			cfg := config.NewSignConfig(signID, id, group, threshold, partyID, signers, messageHash)
+			r, err := signs[i+1].StartSign(cfg, pl)(nil)
+			require.NoError(t, err)
+			signRounds = append(signRounds, r)
+		}
+		for {
+			err, done := test.SerialRounds(signRounds, nil)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+		for _, r := range signRounds {
+			require.IsType(t, &round.Output{}, r)
+			signature := r.(*round.Output).Result.(*ecdsa_core.Signature)
+			assert.True(t, signature.Verify(public, messageHash), "signature with key %s", id)
+		}
+	}
+}
+
+// corruptSigma adds one to the σ share broadcast by a party.
+type corruptSigma struct {
+	from party.ID
+}
+
+func (corruptSigma) ModifyBefore(round.Session) {}
+func (corruptSigma) ModifyAfter(round.Session)  {}
+func (c corruptSigma) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if b, ok := content.(*broadcast5); ok && rNext.SelfID() == c.from {
+		one := rNext.Group().NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		b.SigmaShare = rNext.Group().NewScalar().Set(b.SigmaShare).Add(one)
+	}
+}
+
+func TestSign_InconsistentSigma(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	cheater := partyIDs[1]
+	signID := uuid.NewString()
+	signRounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, []byte("hello"))
+		r, err := signs[i].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		signRounds = append(signRounds, r)
+	}
+	for {
+		// the rounds end mixed, the cheater outputs a signature while the others abort
+		err, done := test.SerialRounds(signRounds, corruptSigma{from: cheater})
+		if err != nil {
+			require.ErrorContains(t, err, "two different rounds")
+			break
+		}
+		require.False(t, done, "signing should not end the same way for all parties")
+	}
+
+	for i, r := range signRounds {
+		if partyIDs[i] == cheater {
+			// the cheater aggregates its own correct share
+			assert.IsType(t, &round.Output{}, r)
+			continue
+		}
+		// the unproven Sⱼ cannot be used to blame the cheater, which is only reported in the error
+		require.IsType(t, &round.Abort{}, r, "party %s", partyIDs[i])
+		abort := r.(*round.Abort)
+		assert.Empty(t, abort.Culprits)
+		assert.ErrorIs(t, abort.Err, ErrInconsistentSigma)
+		assert.ErrorContains(t, abort.Err, string(cheater))
+	}
+}
+
+func TestSign_OversizedCiphertexts(t *testing.T) {
+	data := make([]byte, 2*params.BytesCiphertext)
+	data[0] = 1
+	b, err := new(saferith.Nat).SetBytes(data).MarshalBinary()
+	require.NoError(t, err)
+	oversized := &paillier_core.Ciphertext{}
+	require.NoError(t, oversized.UnmarshalBinary(b))
+	ct, _ := zk.ProverPaillierPublic.Enc(new(saferith.Int).SetUint64(1))
+
+	// the ciphertexts are rejected before the rounds access any of their keys
+	err = (&round2{}).StoreBroadcastMessage(round.Message{From: "a", Broadcast: true, Content: &broadcast2{K: ct, G: oversized}})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+	err = (&round2{}).StoreBroadcastMessage(round.Message{From: "a", Broadcast: true, Content: &broadcast2{K: ct}})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+	err = (&round3{}).VerifyMessage(round.Message{From: "a", To: "b", Content: &message3{DeltaD: ct, DeltaF: ct, ChiD: oversized, ChiF: ct}})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+}
+
+func TestVerifyGammaShare(t *testing.T) {
+	group := curve.Secp256k1{}
+	prover, aux := zk.ProverPaillierPublic, zk.Pedersen
+
+	opts := keyopts.Options{}
+	opts.Set("id", uuid.NewString(), "partyid", "a")
+	h := hash.NewHashManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())).NewHasher("test", opts)
+
+	gamma := sample.IntervalL(rand.Reader)
+	G, rho := prover.Enc(gamma)
+	BigGammaShare := group.NewScalar().SetNat(gamma.Mod(group.Order())).ActOnBase()
+	proof := zklogstar.NewProof(group, h.Clone(), zklogstar.Public{
+		C:      G,
+		X:      BigGammaShare,
+		Prover: prover,
+		Aux:    aux,
+	}, zklogstar.Private{X: gamma, Rho: rho})
+
+	require.NoError(t, verifyGammaShare(h.Clone(), "a", G, BigGammaShare, proof, prover, aux))
+
+	// Γⱼ is the share of another γ than the one encrypted in Gⱼ
+	other := sample.Scalar(rand.Reader, group).ActOnBase()
+	err := verifyGammaShare(h.Clone(), "a", G, other, proof, prover, aux)
+	assert.ErrorIs(t, err, ErrInconsistentGamma)
+	assert.ErrorContains(t, err, "party a")
+
+	otherG, _ := prover.Enc(sample.IntervalL(rand.Reader))
+	assert.ErrorIs(t, verifyGammaShare(h.Clone(), "a", otherG, BigGammaShare, proof, prover, aux), ErrInconsistentGamma)
+	assert.ErrorIs(t, verifyGammaShare(h.Clone(), "a", G, group.NewPoint(), proof, prover, aux), ErrInconsistentGamma)
+}
@@ -0,0 +1,156 @@
+package sign
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	ecdsa_core "github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/lib/transport"
+	"github.com/mr-shifu/mpc-lib/lib/transport/grpctransport"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// runOverTransport finalizes the rounds of a session starting at r, exchanging the messages through tr,
+// until the session outputs a result or aborts.
+func runOverTransport(r round.Session, tr transport.Transport) (round.Session, error) {
+	var pending []*round.Message
+	for {
+		out := make(chan *round.Message, 2*r.N())
+		next, err := r.Finalize(out)
+		close(out)
+		if err != nil {
+			return nil, err
+		}
+		for msg := range out {
+			if err := tr.Send(msg.To, msg); err != nil {
+				return nil, err
+			}
+		}
+		r = next
+		switch r.(type) {
+		case *round.Output, *round.Abort:
+			return r, nil
+		}
+
+		// deliver the messages received early for this round
+		early := pending[:0]
+		for _, msg := range pending {
+			if msg.Content.RoundNumber() != r.Number() {
+				early = append(early, msg)
+				continue
+			}
+			if err := transport.Deliver(r, msg); err != nil {
+				return nil, err
+			}
+		}
+		pending = early
+
+		for !r.CanFinalize() {
+			select {
+			case msg, ok := <-tr.Recv():
+				if !ok {
+					return nil, transport.ErrClosed
+				}
+				if msg.Content.RoundNumber() > r.Number() {
+					pending = append(pending, msg)
+					continue
+				}
+				if err := transport.Deliver(r, msg); err != nil {
+					return nil, err
+				}
+			case <-time.After(time.Minute):
+				return nil, fmt.Errorf("round %d: timed out waiting for messages", r.Number())
+			}
+		}
+	}
+}
+
+func TestSign_GRPCTransport(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewSerialPool()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	keyID := uuid.NewString()
+
+	keygenRounds := make([]round.Session, 0, N)
+	signs := make([]*MPCSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newMPCWithPool(pl)
+		signs = append(signs, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		r, err := mpckg.Start(keycfg, pl)(nil)
+		require.NoError(t, err)
+		keygenRounds = append(keygenRounds, r)
+	}
+	for {
+		err, done := test.SerialRounds(keygenRounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	require.IsType(t, &round.Output{}, keygenRounds[0])
+	public := keygenRounds[0].(*round.Output).Result.(*keygen.KeygenResult).Config.PublicPoint()
+
+	certs, roots, err := test.TLSCertificates(partyIDs)
+	require.NoError(t, err)
+	listeners := make(map[party.ID]net.Listener, N)
+	for _, partyID := range partyIDs {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listeners[partyID] = lis
+	}
+	transports := make(map[party.ID]transport.Transport, N)
+	for _, partyID := range partyIDs {
+		peers := make(map[party.ID]string, N-1)
+		for _, j := range partyIDs {
+			if j != partyID {
+				peers[j] = listeners[j].Addr().String()
+			}
+		}
+		tr, err := grpctransport.New(grpctransport.Config{
+			Self: partyID, Listener: listeners[partyID], Peers: peers,
+			Certificate: certs[partyID], RootCAs: roots,
+		})
+		require.NoError(t, err)
+		defer tr.Close()
+		transports[partyID] = tr
+	}
+
+	message := []byte("hello")
+	signID := uuid.NewString()
+	results := make([]round.Session, N)
+	var eg errgroup.Group
+	for i, partyID := range partyIDs {
+		i, partyID := i, partyID
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, message)
+		r, err := signs[i].StartSign(cfg, pl)(nil)
+		require.NoError(t, err)
+		eg.Go(func() error {
+			r, err := runOverTransport(r, transports[partyID])
+			results[i] = r
+			return err
+		})
+	}
+	require.NoError(t, eg.Wait())
+
+	for _, r := range results {
+		require.IsType(t, &round.Output{}, r)
+		signature := r.(*round.Output).Result.(*ecdsa_core.Signature)
+		assert.True(t, signature.Verify(public, message))
+	}
+}
@@ -0,0 +1,48 @@
+package sign
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/party"
+	zkenc "github.com/mr-shifu/mpc-lib/core/zk/enc"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
+	pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
+)
+
+// RemoteProofWorker generates on behalf of a party the proofs which round1 sends to each of the
+// other signers, so that this work can be offloaded to workers close to them.
+//
+// The worker receives the nonce kᵢ and the randomness of its encryption, and must therefore be as
+// trusted as the party itself. It may be called concurrently for different peers.
+//
+// The proofs of the worker are verified before they are sent, so that a faulty worker cannot get
+// the party blamed for an invalid proof.
+type RemoteProofWorker interface {
+	// NewZKEncProof returns the proof sent to party j that Kᵢ = pek encrypts k under pk, using the
+	// Pedersen parameters ped of j. If it returns an error or an invalid proof, the proof is
+	// generated locally.
+	NewZKEncProof(j party.ID, h hash.Hash, k ecdsa.ECDSAKey, pek pek.PaillierEncodedKey, pk paillier.PaillierKey, ped pedersen.PedersenKey) (*zkenc.Proof, error)
+}
+
+// SetRemoteProofWorker delegates the generation of the proofs of round1 to w in sessions started
+// afterwards. A nil w generates all proofs locally, which is the default.
+func (m *MPCSign) SetRemoteProofWorker(w RemoteProofWorker) {
+	m.worker = w
+}
+
+// newZKEncProof generates the proof of round1 for party j, with the remote worker if there is one,
+// and locally if there is none, it fails or its proof does not verify like j will verify it.
+func (r *round1) newZKEncProof(j party.ID, k ecdsa.ECDSAKey, kPEK pek.PaillierEncodedKey, pk paillier.PaillierKey, ped pedersen.PedersenKey) (*zkenc.Proof, error) {
+	if r.worker != nil {
+		proof, err := r.worker.NewZKEncProof(j, r.HashForID(r.SelfID()), k, kPEK, pk, ped)
+		if err == nil && proof != nil && proof.Verify(r.Group(), r.HashForID(r.SelfID()), zkenc.Public{
+			K:      kPEK.Encoded(),
+			Prover: pk.PublicKeyRaw(),
+			Aux:    ped.PublicKeyRaw(),
+		}) {
+			return proof, nil
+		}
+	}
+	return k.NewZKEncProof(r.HashForID(r.SelfID()), kPEK, pk, ped)
+}
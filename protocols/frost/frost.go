@@ -1,8 +1,12 @@
 package frost
 
 import (
+	"encoding/hex"
+	"errors"
+
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/container"
 
 	comm_commitment "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/commitment"
 	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
@@ -15,6 +19,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	vssed25519 "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss-ed25519"
+	mem_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	comm_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	comm_msg "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 	comm_result "github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
@@ -76,7 +81,7 @@ func NewFROST(
 	vss_keyopts := krf.NewKeyOpts(nil)
 	vss_vault := vf.NewVault(nil)
 	vss_ks := ksf.NewKeystore(vss_vault, vss_keyopts, nil)
-	vss_km := vssed25519.NewVssKeyManager(vss_ks)
+	vss_km := vssed25519.NewVssKeyManager(vss_ks).WithBasisCache(2)
 
 	ec_keyopts := krf.NewKeyOpts(nil)
 	ec_vault := vf.NewVault(nil)
@@ -181,6 +186,9 @@ func (frost *FROST) NewMPCSignManager() *sign.FROSTSign {
 // It contains secret key material and should be safely stored.
 type Config = keygen.Config
 
+// PublicConfig is the public part of a Config, for nodes which only verify signatures.
+type PublicConfig = keygen.PublicConfig
+
 // EmptyConfig creates an empty Config with a fixed group, ready for unmarshalling.
 //
 // This needs to be used for unmarshalling, otherwise the points on the curve can't
@@ -205,3 +213,72 @@ func (frost *FROST) Sign(cfg comm_config.SignConfig, pl *pool.Pool) protocol.Sta
 	sign := frost.NewMPCSignManager()
 	return sign.Start(cfg)
 }
+
+// PurgeSession deletes the keys stored under the session id by any of the key managers,
+// and returns the number of keys deleted.
+func (frost *FROST) PurgeSession(id string) (int, error) {
+	mgrs := []interface{}{
+		frost.eddsa_km, frost.ed_vss_km, frost.vss_mgr, frost.chainKey_km, frost.hash_mgr,
+		frost.commit_mgr, frost.ec_sign_km, frost.sign_d, frost.sign_e,
+	}
+	opts, err := mem_keyopts.NewOptions().Set("protocol", mem_keyopts.ProtocolFROST, "id", id)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, m := range mgrs {
+		km, ok := m.(keystore.KeyManager)
+		if !ok {
+			continue
+		}
+		n, err := km.PurgeSession(opts)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ExportKey returns the public key and this party's share of the key generated with keyID in a
+// container. If password is not empty, the share is encrypted with a key derived from it.
+func (frost *FROST) ExportKey(keyID string, password []byte) (*container.Container, error) {
+	cfg, err := frost.keyconfigmgr.GetConfig(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rootOpts, err := mem_keyopts.NewOptions().Set("protocol", mem_keyopts.ProtocolFROST, "id", keyID, "partyid", "ROOT")
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := frost.eddsa_km.GetKey(rootOpts)
+	if err != nil {
+		return nil, err
+	}
+	vss, err := frost.vss_mgr.GetSecrets(rootOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	shareOpts, err := mem_keyopts.NewOptions().Set("protocol", mem_keyopts.ProtocolFROST, "id", hex.EncodeToString(vss.SKI()), "partyid", string(cfg.SelfID()))
+	if err != nil {
+		return nil, err
+	}
+	shareKey, err := frost.ed_vss_km.GetKey(shareOpts)
+	if err != nil {
+		return nil, err
+	}
+	if !shareKey.Private() {
+		return nil, errors.New("frost: missing secret share")
+	}
+	raw, err := shareKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// the encoded private key is the secret scalar followed by the public point
+	share := raw[:32]
+	return container.New(container.AlgorithmEd25519, cfg.SelfID(), cfg.Threshold(), publicKey.PublickeyPoint().Bytes(), share, password)
+}
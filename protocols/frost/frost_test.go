@@ -1,14 +1,22 @@
 package frost
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
 	"sync"
 	"testing"
 
+	"filippo.io/edwards25519"
 	"github.com/google/uuid"
+	"github.com/mr-shifu/mpc-lib/core/eddsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	polynomial "github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/container"
+	"github.com/mr-shifu/mpc-lib/lib/merkle"
 	"github.com/mr-shifu/mpc-lib/lib/test"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
@@ -17,6 +25,7 @@ import (
 	result "github.com/mr-shifu/mpc-lib/pkg/mpc/result/eddsa"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -80,3 +89,282 @@ func TestFROST(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func doBatch(t *testing.T, id party.ID, ids []party.ID, keyID, signID string, messages [][]byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	frost := NewFROST(
+		&keystore.InmemoryKeystoreFactory{},
+		&keyopts.InMemoryKeyOptsFactory{},
+		&vault.InmemoryVaultFactory{},
+		config.NewInMemoryConfigStore(),
+		config.NewInMemoryConfigStore(),
+		state.NewInMemoryStateStore(),
+		state.NewInMemoryStateStore(),
+		message.NewInMemoryMessageStore(),
+		message.NewInMemoryMessageStore(),
+		pl,
+	)
+
+	keycfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, len(ids)-1, id, ids)
+	h, err := protocol.NewMultiHandler(frost.Keygen(keycfg, pl), nil)
+	require.NoError(t, err)
+	test.HandlerLoop(id, h, n)
+	r, err := h.Result()
+	require.NoError(t, err)
+	c := r.(*Config)
+
+	bundle := config.NewBundle()
+	require.NoError(t, bundle.AddConfig(config.AlgorithmEdDSA, keycfg))
+	bundle.RegisterSigner(config.AlgorithmEdDSA, frost)
+
+	start, tree, err := bundle.SignBatch(config.AlgorithmEdDSA, signID, messages, pl)
+	require.NoError(t, err)
+	h, err = protocol.NewMultiHandler(start, nil)
+	require.NoError(t, err)
+	test.HandlerLoop(id, h, n)
+	signResult, err := h.Result()
+	require.NoError(t, err)
+	sig := signResult.(*result.EddsaSignature)
+
+	// the signature must authorize the merkle root of the batch
+	root := tree.Root()
+	assert.True(t, ed25519.Verify(c.PublicKey.Bytes(), root, append(sig.R().Bytes(), sig.Z().Bytes()...)))
+
+	for i, m := range messages {
+		proof, err := tree.Proof(i)
+		require.NoError(t, err)
+		assert.True(t, merkle.Verify(root, m, proof), "message %d should be included in the batch", i)
+		assert.False(t, merkle.Verify(root, []byte("not a member"), proof))
+	}
+}
+
+func TestFROST_SignBatch(t *testing.T) {
+	N := 3
+	messages := make([][]byte, 4)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("transaction %d", i))
+	}
+
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+	keyID := uuid.New().String()
+	signID := uuid.New().String()
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go doBatch(t, id, partyIDs, keyID, signID, messages, pl, n, &wg)
+	}
+	wg.Wait()
+}
+
+func TestFROST_ExportKey(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+	keyID := uuid.New().String()
+	password := []byte("password")
+
+	var mtx sync.Mutex
+	shares := make(map[party.ID]*edwards25519.Scalar, N)
+	var publicKey *edwards25519.Point
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go func(id party.ID) {
+			defer wg.Done()
+
+			frost := NewFROST(
+				&keystore.InmemoryKeystoreFactory{},
+				&keyopts.InMemoryKeyOptsFactory{},
+				&vault.InmemoryVaultFactory{},
+				config.NewInMemoryConfigStore(),
+				config.NewInMemoryConfigStore(),
+				state.NewInMemoryStateStore(),
+				state.NewInMemoryStateStore(),
+				message.NewInMemoryMessageStore(),
+				message.NewInMemoryMessageStore(),
+				pl,
+			)
+			keycfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, N-1, id, partyIDs)
+			h, err := protocol.NewMultiHandler(frost.Keygen(keycfg, pl), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+
+			c, err := frost.ExportKey(keyID, password)
+			require.NoError(t, err)
+			data, err := c.MarshalBinary()
+			require.NoError(t, err)
+			decoded := &container.Container{}
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			assert.Equal(t, container.AlgorithmEd25519, decoded.Algorithm)
+			assert.Equal(t, id, decoded.PartyID)
+			assert.Equal(t, r.(*Config).PublicKey.Bytes(), decoded.PublicKey)
+
+			_, err = decoded.SecretShare([]byte("wrong password"))
+			assert.ErrorIs(t, err, container.ErrWrongPassword)
+			raw, err := decoded.SecretShare(password)
+			require.NoError(t, err)
+			share, err := edwards25519.NewScalar().SetCanonicalBytes(raw)
+			require.NoError(t, err)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			shares[id] = share
+			publicKey = r.(*Config).PublicKey
+		}(id)
+	}
+	wg.Wait()
+
+	// the exported shares must reconstruct the secret key of the public key
+	lagrange, err := polynomial.Lagrange(partyIDs)
+	require.NoError(t, err)
+	secret := edwards25519.NewScalar()
+	for _, id := range partyIDs {
+		secret.MultiplyAdd(lagrange[id], shares[id], secret)
+	}
+	assert.Equal(t, 1, new(edwards25519.Point).ScalarBaseMult(secret).Equal(publicKey))
+}
+
+func TestFROST_AbortPolicy(t *testing.T) {
+	partyIDs := test.PartyIDs(4)
+	honest, malicious := partyIDs[0], partyIDs[1:3]
+
+	// runKeygen delivers the first keygen message of all other parties to the honest party,
+	// after the malicious parties emptied theirs.
+	runKeygen := func(policy protocol.AbortPolicy) error {
+		keyID := uuid.NewString()
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		for _, id := range partyIDs {
+			frost := NewFROST(
+				&keystore.InmemoryKeystoreFactory{},
+				&keyopts.InMemoryKeyOptsFactory{},
+				&vault.InmemoryVaultFactory{},
+				config.NewInMemoryConfigStore(),
+				config.NewInMemoryConfigStore(),
+				state.NewInMemoryStateStore(),
+				state.NewInMemoryStateStore(),
+				message.NewInMemoryMessageStore(),
+				message.NewInMemoryMessageStore(),
+				nil,
+			)
+			keycfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, partyIDs)
+			h, err := protocol.NewMultiHandlerWithPolicy(frost.Keygen(keycfg, nil), nil, policy)
+			require.NoError(t, err)
+			handlers[id] = h
+		}
+
+		for _, id := range partyIDs[1:] {
+			msg := <-handlers[id].Listen()
+			if party.IDSlice(malicious).Contains(id) {
+				// an empty CBOR map, without VSS polynomial
+				msg.Data = []byte{0xa0}
+			}
+			handlers[honest].Accept(msg)
+		}
+
+		_, err := handlers[honest].Result()
+		return err
+	}
+
+	var protocolErr protocol.Error
+
+	err := runKeygen(protocol.FailFast)
+	require.ErrorAs(t, err, &protocolErr)
+	require.Len(t, protocolErr.Culprits, 1)
+	assert.Equal(t, malicious[0], protocolErr.Culprits[0])
+
+	err = runKeygen(protocol.CollectAll)
+	require.ErrorAs(t, err, &protocolErr)
+	assert.Equal(t, malicious, party.IDSlice(protocolErr.Culprits))
+}
+
+func TestFROST_PublicConfig(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+	keyID := uuid.New().String()
+	signID := uuid.New().String()
+	msg := []byte("hello")
+	password := []byte("password")
+
+	var mtx sync.Mutex
+	shares := make(map[party.ID][]byte, N)
+	signatures := make(map[party.ID]eddsa.Signature, N)
+	var publicConfig *PublicConfig
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go func(id party.ID) {
+			defer wg.Done()
+
+			frost := NewFROST(
+				&keystore.InmemoryKeystoreFactory{},
+				&keyopts.InMemoryKeyOptsFactory{},
+				&vault.InmemoryVaultFactory{},
+				config.NewInMemoryConfigStore(),
+				config.NewInMemoryConfigStore(),
+				state.NewInMemoryStateStore(),
+				state.NewInMemoryStateStore(),
+				message.NewInMemoryMessageStore(),
+				message.NewInMemoryMessageStore(),
+				pl,
+			)
+			keycfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, N-1, id, partyIDs)
+			h, err := protocol.NewMultiHandler(frost.Keygen(keycfg, pl), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+
+			signcfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, N-1, id, partyIDs, msg)
+			h, err = protocol.NewMultiHandler(frost.Sign(signcfg, pl), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			signResult, err := h.Result()
+			require.NoError(t, err)
+			sig := signResult.(*result.EddsaSignature)
+
+			c, err := frost.ExportKey(keyID, password)
+			require.NoError(t, err)
+			share, err := c.SecretShare(password)
+			require.NoError(t, err)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			shares[id] = share
+			signatures[id] = eddsa.Signature{R: sig.R(), Z: sig.Z()}
+			publicConfig = r.(*Config).PublicConfig()
+		}(id)
+	}
+	wg.Wait()
+
+	data, err := publicConfig.MarshalBinary()
+	require.NoError(t, err)
+	for id, share := range shares {
+		assert.False(t, bytes.Contains(data, share), "serialized config holds the share of %s", id)
+	}
+
+	// a node which never took part in keygen only knows the serialized config
+	verifier := &PublicConfig{}
+	require.NoError(t, verifier.UnmarshalBinary(data))
+	assert.Equal(t, publicConfig.Threshold, verifier.Threshold)
+	for id, sig := range signatures {
+		assert.True(t, verifier.Verify(msg, sig), "signature of %s", id)
+		assert.False(t, verifier.Verify([]byte("other"), sig), "signature of %s", id)
+	}
+
+	assert.False(t, (&PublicConfig{}).Verify(msg, signatures[partyIDs[0]]))
+	assert.Error(t, verifier.UnmarshalBinary([]byte{0xff}))
+}
@@ -1,10 +1,14 @@
 package keygen
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"sync"
 
 	"github.com/pkg/errors"
 
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 	"github.com/mr-shifu/mpc-lib/lib/round"
@@ -24,6 +28,10 @@ const (
 	KEYGEN_THRESHOLD_PROTOCOL string       = "frost/keygen-threshold"
 )
 
+func init() {
+	protocol.RegisterMessageContent(KEYGEN_THRESHOLD_PROTOCOL, &broadcast2{}, &broadcast3{}, &message3{})
+}
+
 type FROSTKeygen struct {
 	configmgr   config.KeyConfigManager
 	statemgr    mpc_state.MPCStateManager
@@ -36,6 +44,10 @@ type FROSTKeygen struct {
 	hash_mgr    hash.HashManager
 	commit_mgr  commitment.CommitmentManager
 	pl          *pool.Pool
+
+	// early holds, per session, the messages received for a round the session has not reached yet.
+	early    map[string][]round.Message
+	earlyMtx sync.Mutex
 }
 
 var _ protocol.Processor = (*FROSTKeygen)(nil)
@@ -65,6 +77,7 @@ func NewFROSTKeygen(
 		hash_mgr:    hash_mgr,
 		commit_mgr:  commit_mgr,
 		pl:          pl,
+		early:       make(map[string][]round.Message),
 	}
 }
 
@@ -75,6 +88,11 @@ func (m *FROSTKeygen) Start(configs any) protocol.StartFunc {
 	}
 
 	return func(sessionID []byte) (_ round.Session, err error) {
+		// resume the session if it was already started, instead of reinitializing its state
+		if _, err := m.statemgr.Get(cfg.ID()); err == nil {
+			return m.resume(cfg)
+		}
+
 		// TODO we should supprt taproot for next version
 		info := round.Info{
 			ProtocolID:       KEYGEN_THRESHOLD_PROTOCOL,
@@ -82,15 +100,12 @@ func (m *FROSTKeygen) Start(configs any) protocol.StartFunc {
 			PartyIDs:         cfg.PartyIDs(),
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
+			MaxParties:       cfg.MaxParties(),
 			FinalRoundNumber: Rounds,
 		}
 
-		if err := m.configmgr.ImportConfig(cfg); err != nil {
-			return nil, errors.WithMessage(err, "keygen: failed to import config")
-		}
-
 		// instantiate a new hasher for new keygen session
-		opts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", string(info.SelfID))
+		opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", cfg.ID(), "partyid", string(info.SelfID))
 		if err != nil {
 			return nil, errors.WithMessage(err, "keygen: failed to set options")
 		}
@@ -101,6 +116,11 @@ func (m *FROSTKeygen) Start(configs any) protocol.StartFunc {
 		if err != nil {
 			return nil, fmt.Errorf("keygen: %w", err)
 		}
+		helper.SetMessageManagers(m.msgmgr, m.bcstmgr)
+
+		if err := m.configmgr.ImportConfig(cfg); err != nil {
+			return nil, errors.WithMessage(err, "keygen: failed to import config")
+		}
 
 		if err := m.statemgr.NewState(cfg.ID()); err != nil {
 			return nil, err
@@ -121,6 +141,31 @@ func (m *FROSTKeygen) Start(configs any) protocol.StartFunc {
 	}
 }
 
+// resume returns the current round of an already started session, as long as cfg matches
+// the config the session was started with and the session has not finished.
+func (m *FROSTKeygen) resume(cfg config.KeyConfig) (round.Session, error) {
+	stored, err := m.configmgr.GetConfig(cfg.ID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "keygen: failed to get config")
+	}
+	if stored.SelfID() != cfg.SelfID() ||
+		stored.Threshold() != cfg.Threshold() ||
+		stored.VSSDegree() != cfg.VSSDegree() ||
+		!party.NewIDSlice(stored.PartyIDs()).Equal(party.NewIDSlice(cfg.PartyIDs())) {
+		return nil, errors.WithMessage(mpc_state.ErrStateExists, "keygen: session was started with a different config")
+	}
+
+	state, err := m.statemgr.Get(cfg.ID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "keygen: failed to get state")
+	}
+	if state.Aborted() || state.Completed() {
+		return nil, errors.WithMessage(mpc_state.ErrStateFinished, "keygen")
+	}
+
+	return m.GetRound(cfg.ID())
+}
+
 func (m *FROSTKeygen) GetRound(keyID string) (round.Session, error) {
 	cfg, err := m.configmgr.GetConfig(keyID)
 	if err != nil {
@@ -133,11 +178,12 @@ func (m *FROSTKeygen) GetRound(keyID string) (round.Session, error) {
 		PartyIDs:         cfg.PartyIDs(),
 		Threshold:        cfg.Threshold(),
 		Group:            cfg.Group(),
+		MaxParties:       cfg.MaxParties(),
 		FinalRoundNumber: Rounds,
 	}
 	// instantiate a new hasher for new keygen session
 	opts := keyopts.Options{}
-	opts.Set("id", cfg.ID(), "partyid", string(info.SelfID))
+	opts.Set("protocol", keyopts.ProtocolFROST, "id", cfg.ID(), "partyid", string(info.SelfID))
 	h := m.hash_mgr.NewHasher(cfg.ID(), opts)
 
 	// generate new helper for new keygen session
@@ -145,6 +191,7 @@ func (m *FROSTKeygen) GetRound(keyID string) (round.Session, error) {
 	if err != nil {
 		return nil, fmt.Errorf("keygen: %w", err)
 	}
+	helper.SetMessageManagers(m.msgmgr, m.bcstmgr)
 
 	state, err := m.statemgr.Get(keyID)
 	if err != nil {
@@ -202,6 +249,14 @@ func (m *FROSTKeygen) StoreBroadcastMessage(keyID string, msg round.Message) err
 		return errors.WithMessage(err, "keygen: failed to get round")
 	}
 
+	early, err := isEarly(r, msg)
+	if err != nil {
+		return err
+	}
+	if early {
+		return m.buffer(r, keyID, msg)
+	}
+
 	if err := r.StoreBroadcastMessage(msg); err != nil {
 		return errors.WithMessage(err, "keygen: failed to store message")
 	}
@@ -215,6 +270,14 @@ func (m *FROSTKeygen) StoreMessage(keyID string, msg round.Message) error {
 		return errors.WithMessage(err, "keygen: failed to get round")
 	}
 
+	early, err := isEarly(r, msg)
+	if err != nil {
+		return err
+	}
+	if early {
+		return m.buffer(r, keyID, msg)
+	}
+
 	if err := r.StoreMessage(msg); err != nil {
 		return errors.WithMessage(err, "keygen: failed to store message")
 	}
@@ -223,12 +286,37 @@ func (m *FROSTKeygen) StoreMessage(keyID string, msg round.Message) error {
 }
 
 func (m *FROSTKeygen) Finalize(out chan<- *round.Message, keyID string) (round.Session, error) {
+	return m.FinalizeContext(context.Background(), out, keyID)
+}
+
+// FinalizeContext implements protocol.Processor.
+func (m *FROSTKeygen) FinalizeContext(ctx context.Context, out chan<- *round.Message, keyID string) (round.Session, error) {
 	r, err := m.GetRound(keyID)
 	if err != nil {
 		return nil, errors.WithMessage(err, "keygen: failed to get round")
 	}
 
-	return r.Finalize(out)
+	next, err := r.FinalizeContext(ctx, out)
+	if err != nil || next == nil {
+		m.clearEarly(keyID)
+		return next, err
+	}
+	switch next.(type) {
+	case *round.Output, *round.Abort:
+		m.clearEarly(keyID)
+		return next, nil
+	}
+	if next.Number() == r.Number() {
+		return next, nil
+	}
+
+	// the messages of the new round which arrived before it started can only be stored now.
+	// The session has moved to next even if some of them are rejected.
+	if err := m.replay(keyID, next); err != nil {
+		return next, errors.WithMessage(err, "keygen: failed to replay early messages")
+	}
+
+	return next, nil
 }
 
 func (m *FROSTKeygen) CanFinalize(keyID string) (bool, error) {
@@ -238,3 +326,111 @@ func (m *FROSTKeygen) CanFinalize(keyID string) (bool, error) {
 	}
 	return r.CanFinalize(), nil
 }
+
+// IsEarly reports whether msg belongs to a round the session keyID has not reached yet.
+//
+// Such a message is not passed to the current round by StoreBroadcastMessage and StoreMessage,
+// but kept in memory and stored once Finalize moves the session to its round.
+func (m *FROSTKeygen) IsEarly(keyID string, msg round.Message) (bool, error) {
+	r, err := m.GetRound(keyID)
+	if err != nil {
+		return false, errors.WithMessage(err, "keygen: failed to get round")
+	}
+	return isEarly(r, msg)
+}
+
+func isEarly(r round.Session, msg round.Message) (bool, error) {
+	if msg.Content == nil {
+		return false, nil
+	}
+	number := msg.Content.RoundNumber()
+	if number > Rounds {
+		return false, fmt.Errorf("keygen: message for round %d of a %d round protocol", number, Rounds)
+	}
+	return number > r.Number(), nil
+}
+
+// buffer keeps msg until the session keyID, currently at round r, reaches the round of msg.
+//
+// Only messages from the other parties of the session, and addressed to this party when they are
+// not broadcast, are kept. A message of the same kind already kept for the sender and round is
+// not replaced, so that at most one broadcast and one direct message per party and round are kept.
+func (m *FROSTKeygen) buffer(r round.Session, keyID string, msg round.Message) error {
+	if msg.From == r.SelfID() || !r.OtherPartyIDs().Contains(msg.From) {
+		return fmt.Errorf("keygen: early message from unknown party %s", msg.From)
+	}
+	if msg.Broadcast && msg.To != "" || !msg.Broadcast && msg.To != r.SelfID() {
+		return fmt.Errorf("keygen: early message from %s addressed to %q", msg.From, msg.To)
+	}
+
+	m.earlyMtx.Lock()
+	defer m.earlyMtx.Unlock()
+
+	kept := m.early[keyID]
+	for _, b := range kept {
+		if b.From == msg.From && b.Broadcast == msg.Broadcast && b.Content.RoundNumber() == msg.Content.RoundNumber() {
+			return nil
+		}
+	}
+	if len(kept) >= 2*int(Rounds)*len(r.OtherPartyIDs()) {
+		return fmt.Errorf("keygen: too many early messages for session %s", keyID)
+	}
+	m.early[keyID] = append(kept, msg)
+	return nil
+}
+
+// clearEarly drops the messages kept for the session keyID.
+func (m *FROSTKeygen) clearEarly(keyID string) {
+	m.earlyMtx.Lock()
+	defer m.earlyMtx.Unlock()
+	delete(m.early, keyID)
+}
+
+// replay stores the messages kept for the round r, broadcasts first since the other messages of
+// a round may depend on them.
+//
+// All the messages are passed to r, and the errors of those which are rejected are returned together,
+// as a protocol.Error naming their senders.
+func (m *FROSTKeygen) replay(keyID string, r round.Session) error {
+	m.earlyMtx.Lock()
+	var msgs, rest []round.Message
+	for _, msg := range m.early[keyID] {
+		if msg.Content.RoundNumber() == r.Number() {
+			msgs = append(msgs, msg)
+		} else if msg.Content.RoundNumber() > r.Number() {
+			rest = append(rest, msg)
+		}
+	}
+	if len(rest) == 0 {
+		delete(m.early, keyID)
+	} else {
+		m.early[keyID] = rest
+	}
+	m.earlyMtx.Unlock()
+
+	var (
+		culprits []party.ID
+		errs     []error
+	)
+	for _, broadcast := range []bool{true, false} {
+		for _, msg := range msgs {
+			if msg.Broadcast != broadcast {
+				continue
+			}
+			var err error
+			if broadcast {
+				err = r.StoreBroadcastMessage(msg)
+			} else {
+				err = r.StoreMessage(msg)
+			}
+			if err != nil {
+				culprits = append(culprits, msg.From)
+				errs = append(errs, errors.WithMessagef(err, "party %s", msg.From))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return protocol.Error{Culprits: culprits, Err: stderrors.Join(errs...)}
+	}
+	return nil
+}
@@ -1,11 +1,17 @@
 package keygen
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	ed "filippo.io/edwards25519"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 	"github.com/mr-shifu/mpc-lib/lib/round"
@@ -17,6 +23,7 @@ import (
 	vssed25519 "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss-ed25519"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	mpc_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
@@ -120,3 +127,639 @@ func TestKeygen(t *testing.T) {
 		}
 	}
 }
+
+func TestKeygen_ContentRoundTrip(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyID, partyIDs)
+		r, err := newFROSTKeygen().Start(cfg)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	checker := &test.ContentChecker{}
+	for {
+		err, done := test.SerialRounds(rounds, checker)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	for _, content := range []round.Content{&broadcast2{}, &broadcast3{}, &message3{}} {
+		t.Run(fmt.Sprintf("%T", content), func(t *testing.T) {
+			require.NoError(t, checker.Check(content))
+		})
+	}
+}
+
+func TestKeygen_Round2RejectsSmallOrderPoint(t *testing.T) {
+	keyID := uuid.NewString()
+
+	partyIDs := test.PartyIDs(2)
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+	mpckg := newFROSTKeygen()
+	r1, err := mpckg.Start(cfg)(nil)
+	require.NoError(t, err)
+
+	out := make(chan *round.Message, 4)
+	r2, err := r1.Finalize(out)
+	require.NoError(t, err)
+	require.IsType(t, &round2{}, r2)
+
+	// (0, -1) has order 2
+	order2 := make([]byte, 32)
+	order2[0] = 0xec
+	for i := 1; i < 31; i++ {
+		order2[i] = 0xff
+	}
+	order2[31] = 0x7f
+	T, err := new(ed.Point).SetBytes(order2)
+	require.NoError(t, err)
+
+	poly, err := polynomial.NewPolynomial(1, nil, []*ed.Point{T, ed.NewGeneratorPoint()})
+	require.NoError(t, err)
+
+	err = r2.(*round2).StoreBroadcastMessage(round.Message{
+		From:      partyIDs[1],
+		Broadcast: true,
+		Content:   &broadcast2{VSSPolynomial: poly},
+	})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+	require.ErrorIs(t, err, ed25519.ErrSmallOrderPoint)
+}
+
+func TestKeygen_Round2RejectsOversizedPolynomial(t *testing.T) {
+	keyID := uuid.NewString()
+
+	partyIDs := test.PartyIDs(2)
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+	r1, err := newFROSTKeygen().Start(cfg)(nil)
+	require.NoError(t, err)
+
+	out := make(chan *round.Message, 4)
+	r2, err := r1.Finalize(out)
+	require.NoError(t, err)
+
+	// the degree of the polynomial is bounded by the threshold
+	constant, err := ed.NewScalar().SetCanonicalBytes(append([]byte{1}, make([]byte, 31)...))
+	require.NoError(t, err)
+	poly, err := polynomial.GeneratePolynomial(255, constant)
+	require.NoError(t, err)
+	err = r2.StoreBroadcastMessage(round.Message{
+		From:      partyIDs[1],
+		Broadcast: true,
+		Content:   &broadcast2{VSSPolynomial: poly},
+	})
+	require.ErrorIs(t, err, round.ErrInvalidContent)
+
+	fromOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", keyID, "partyid", string(partyIDs[1]))
+	require.NoError(t, err)
+	_, err = r2.(*round2).vss_mgr.GetSecrets(fromOpts)
+	require.Error(t, err, "vss polynomial should not be imported")
+}
+
+func TestKeygen_ConcurrentFinalize(t *testing.T) {
+	keyID := uuid.NewString()
+
+	partyIDs := test.PartyIDs(2)
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+	mpckg := newFROSTKeygen()
+	r1, err := mpckg.Start(cfg)(nil)
+	require.NoError(t, err)
+
+	out := make(chan *round.Message, 4)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	wg.Add(len(errs))
+	for i := range errs {
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = r1.Finalize(out)
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			require.ErrorIs(t, err, round.ErrRoundFinalized)
+			failed++
+		}
+	}
+	require.Equal(t, 1, failed, "exactly one Finalize call should fail")
+}
+
+func TestKeygen_StartReturnsFirstRound(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+	mpckg := newFROSTKeygen()
+
+	r, err := mpckg.Start(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs))(nil)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.Equal(t, round.Number(1), r.Number())
+
+	// an invalid session is reported, and leaves nothing behind
+	keyID = uuid.NewString()
+	r, err = mpckg.Start(config.NewKeyConfig(keyID, curve.Secp256k1{}, 3, partyIDs[0], partyIDs))(nil)
+	require.Error(t, err)
+	require.Nil(t, r)
+	_, err = mpckg.configmgr.GetConfig(keyID)
+	require.Error(t, err)
+	_, err = mpckg.statemgr.Get(keyID)
+	require.Error(t, err)
+}
+
+func TestKeygen_StartResumesSession(t *testing.T) {
+	keyID := uuid.NewString()
+
+	partyIDs := test.PartyIDs(2)
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+	mpckg := newFROSTKeygen()
+
+	r, err := mpckg.Start(cfg)(nil)
+	require.NoError(t, err)
+	require.IsType(t, &round1{}, r)
+
+	// starting again before any round was processed resumes the first round
+	r, err = mpckg.Start(cfg)(nil)
+	require.NoError(t, err)
+	require.IsType(t, &round1{}, r)
+
+	out := make(chan *round.Message, 4)
+	_, err = mpckg.Finalize(out, keyID)
+	require.NoError(t, err)
+
+	// starting again after the first round resumes the second round
+	r, err = mpckg.Start(cfg)(nil)
+	require.NoError(t, err)
+	require.IsType(t, &round2{}, r)
+
+	// starting with a different config for the same ID is rejected
+	other := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[1], partyIDs)
+	_, err = mpckg.Start(other)(nil)
+	require.ErrorIs(t, err, mpc_state.ErrStateExists)
+}
+
+func TestKeygen_StartRejectsTooManyParties(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+
+	cfg := config.NewKeyConfig(uuid.NewString(), curve.Secp256k1{}, 1, partyIDs[0], partyIDs).WithMaxParties(3)
+	_, err := newFROSTKeygen().Start(cfg)(nil)
+	require.NoError(t, err)
+
+	cfg = config.NewKeyConfig(uuid.NewString(), curve.Secp256k1{}, 1, partyIDs[0], partyIDs).WithMaxParties(2)
+	_, err = newFROSTKeygen().Start(cfg)(nil)
+	require.Error(t, err)
+}
+
+func TestKeygen_Round2FailedProofLeavesNoState(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(2)
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	msgs := make([]*round.Message, 0, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyID, partyIDs)
+		r1, err := newFROSTKeygen().Start(cfg)(nil)
+		require.NoError(t, err)
+
+		out := make(chan *round.Message, 4)
+		r2, err := r1.Finalize(out)
+		require.NoError(t, err)
+		rounds = append(rounds, r2)
+		msgs = append(msgs, <-out)
+	}
+
+	// tamper with the response of the Schnorr proof sent by the second party
+	body := msgs[1].Content.(*broadcast2)
+	proof := append([]byte(nil), body.SchnorrProof...)
+	proof[32] ^= 1
+	err := rounds[0].StoreBroadcastMessage(round.Message{
+		From:      partyIDs[1],
+		Broadcast: true,
+		Content: &broadcast2{
+			VSSPolynomial: body.VSSPolynomial,
+			SchnorrProof:  proof,
+			Commitment:    body.Commitment,
+		},
+	})
+	require.Error(t, err)
+
+	r2 := rounds[0].(*round2)
+	fromOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", keyID, "partyid", string(partyIDs[1]))
+	require.NoError(t, err)
+	_, err = r2.commit_mgr.Get(fromOpts)
+	require.Error(t, err, "commitment should not be imported")
+	_, err = r2.ed_km.GetKey(fromOpts)
+	require.Error(t, err, "public key should not be imported")
+	_, err = r2.ed_km.VerifySchnorrProof(r2.Helper.HashForID(partyIDs[1]), fromOpts)
+	require.Error(t, err, "schnorr proof should not be imported")
+	_, err = r2.vss_mgr.GetSecrets(fromOpts)
+	require.Error(t, err, "vss polynomial should not be imported")
+
+	// the untampered message is accepted
+	require.NoError(t, rounds[0].StoreBroadcastMessage(*msgs[1]))
+	_, err = r2.commit_mgr.Get(fromOpts)
+	require.NoError(t, err)
+}
+
+func TestKeygen_Round2DuplicateMessage(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	msgs := make([]*round.Message, 0, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyID, partyIDs)
+		r1, err := newFROSTKeygen().Start(cfg)(nil)
+		require.NoError(t, err)
+
+		out := make(chan *round.Message, 4)
+		r2, err := r1.Finalize(out)
+		require.NoError(t, err)
+		rounds = append(rounds, r2)
+		msgs = append(msgs, <-out)
+	}
+
+	require.NoError(t, rounds[0].StoreBroadcastMessage(*msgs[1]))
+
+	// the message of the third party replayed as coming from the second one is rejected
+	replay := *msgs[2]
+	replay.From = partyIDs[1]
+	err := rounds[0].StoreBroadcastMessage(replay)
+	require.ErrorIs(t, err, round.ErrDuplicateMessage)
+	err = rounds[0].StoreBroadcastMessage(*msgs[1])
+	require.ErrorIs(t, err, round.ErrDuplicateMessage)
+
+	r2 := rounds[0].(*round2)
+	fromOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", keyID, "partyid", string(partyIDs[1]))
+	require.NoError(t, err)
+	commitment, err := r2.commit_mgr.Get(fromOpts)
+	require.NoError(t, err)
+	require.EqualValues(t, msgs[1].Content.(*broadcast2).Commitment, commitment.Commitment())
+}
+
+func TestKeygen_ConcurrentDuplicateMessages(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+
+	kgs := make(map[party.ID]*FROSTKeygen, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyID, partyIDs)
+		kgs[partyID] = newFROSTKeygen()
+		_, err := kgs[partyID].Start(cfg)(nil)
+		require.NoError(t, err)
+	}
+	out := make(chan *round.Message, len(partyIDs))
+	_, err := kgs[partyIDs[0]].Finalize(out, keyID)
+	require.NoError(t, err)
+	_, err = kgs[partyIDs[1]].Finalize(out, keyID)
+	require.NoError(t, err)
+	<-out
+	msg := <-out
+
+	// the copies of a message stored concurrently are all checked before any of them is
+	// recorded, so exactly one of them must be stored
+	const copies = 8
+	errs := make(chan error, copies)
+	var wg sync.WaitGroup
+	for i := 0; i < copies; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- kgs[partyIDs[0]].StoreBroadcastMessage(keyID, *msg)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	stored := 0
+	for err := range errs {
+		if err == nil {
+			stored++
+			continue
+		}
+		require.ErrorIs(t, err, round.ErrDuplicateMessage)
+	}
+	require.Equal(t, 1, stored)
+}
+
+func TestKeygen_EarlyRound3Message(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+
+	kgs := make(map[party.ID]*FROSTKeygen, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyID, partyIDs)
+		kgs[partyID] = newFROSTKeygen()
+		_, err := kgs[partyID].Start(cfg)(nil)
+		require.NoError(t, err)
+	}
+
+	finalize := func(id party.ID) []*round.Message {
+		out := make(chan *round.Message, 2*len(partyIDs))
+		_, err := kgs[id].Finalize(out, keyID)
+		require.NoError(t, err)
+		close(out)
+		var msgs []*round.Message
+		for msg := range out {
+			msgs = append(msgs, msg)
+		}
+		return msgs
+	}
+	deliver := func(to party.ID, msg *round.Message) {
+		if msg.From == to || (msg.To != "" && msg.To != to) {
+			return
+		}
+		if msg.Broadcast {
+			require.NoError(t, kgs[to].StoreBroadcastMessage(keyID, *msg))
+		} else {
+			require.NoError(t, kgs[to].StoreMessage(keyID, *msg))
+		}
+	}
+
+	round2Msgs := make(map[party.ID][]*round.Message)
+	for _, id := range partyIDs {
+		round2Msgs[id] = finalize(id)
+	}
+
+	// all parties but the slow one complete round 2
+	slow, late := partyIDs[0], partyIDs[2]
+	for _, to := range partyIDs[1:] {
+		for _, from := range partyIDs {
+			for _, msg := range round2Msgs[from] {
+				deliver(to, msg)
+			}
+		}
+	}
+	round3Msgs := make(map[party.ID][]*round.Message)
+	for _, id := range partyIDs[1:] {
+		round3Msgs[id] = finalize(id)
+	}
+
+	// the slow party receives the chain keys, decommitments and shares of round 3 before
+	// the round 2 broadcast of the late party
+	for _, msg := range round2Msgs[partyIDs[1]] {
+		deliver(slow, msg)
+	}
+	for _, from := range partyIDs[1:] {
+		for _, msg := range round3Msgs[from] {
+			early, err := kgs[slow].IsEarly(keyID, *msg)
+			require.NoError(t, err)
+			require.True(t, early)
+			deliver(slow, msg)
+		}
+	}
+	canFinalize, err := kgs[slow].CanFinalize(keyID)
+	require.NoError(t, err)
+	require.False(t, canFinalize)
+
+	for _, msg := range round2Msgs[late] {
+		deliver(slow, msg)
+	}
+	round3Msgs[slow] = finalize(slow)
+
+	// the buffered messages were stored when the slow party reached round 3
+	canFinalize, err = kgs[slow].CanFinalize(keyID)
+	require.NoError(t, err)
+	require.True(t, canFinalize)
+
+	for _, to := range partyIDs[1:] {
+		for _, from := range partyIDs {
+			for _, msg := range round3Msgs[from] {
+				deliver(to, msg)
+			}
+		}
+	}
+
+	var publicKey *ed.Point
+	for _, id := range partyIDs {
+		out := make(chan *round.Message, 1)
+		r, err := kgs[id].Finalize(out, keyID)
+		require.NoError(t, err)
+		require.IsType(t, &round.Output{}, r)
+		cfg := r.(*round.Output).Result.(*Config)
+		if publicKey == nil {
+			publicKey = cfg.PublicKey
+		}
+		require.Equal(t, 1, publicKey.Equal(cfg.PublicKey))
+	}
+
+	_, err = kgs[slow].IsEarly(keyID, round.Message{Content: &testContent{number: Rounds + 1}})
+	require.Error(t, err)
+}
+
+func TestKeygen_EarlyInvalidMessage(t *testing.T) {
+	keyID := uuid.NewString()
+	partyIDs := test.PartyIDs(3)
+
+	kgs := make(map[party.ID]*FROSTKeygen, len(partyIDs))
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyID, partyIDs)
+		kgs[partyID] = newFROSTKeygen()
+		_, err := kgs[partyID].Start(cfg)(nil)
+		require.NoError(t, err)
+	}
+
+	finalize := func(id party.ID) []*round.Message {
+		out := make(chan *round.Message, 2*len(partyIDs))
+		_, err := kgs[id].Finalize(out, keyID)
+		require.NoError(t, err)
+		close(out)
+		var msgs []*round.Message
+		for msg := range out {
+			msgs = append(msgs, msg)
+		}
+		return msgs
+	}
+	deliver := func(to party.ID, msg *round.Message) error {
+		if msg.From == to || (msg.To != "" && msg.To != to) {
+			return nil
+		}
+		if msg.Broadcast {
+			return kgs[to].StoreBroadcastMessage(keyID, *msg)
+		}
+		return kgs[to].StoreMessage(keyID, *msg)
+	}
+
+	round2Msgs := make(map[party.ID][]*round.Message)
+	for _, id := range partyIDs {
+		round2Msgs[id] = finalize(id)
+	}
+	slow, cheater := partyIDs[0], partyIDs[1]
+	for _, to := range partyIDs[1:] {
+		for _, from := range partyIDs {
+			for _, msg := range round2Msgs[from] {
+				require.NoError(t, deliver(to, msg))
+			}
+		}
+	}
+	round3Msgs := make(map[party.ID][]*round.Message)
+	for _, id := range partyIDs[1:] {
+		round3Msgs[id] = finalize(id)
+	}
+
+	// early messages must come from another party of the session, and be addressed to the receiver
+	invalid := []*round.Message{
+		{From: "z", Broadcast: true, Content: &broadcast3{}},
+		{From: slow, Broadcast: true, Content: &broadcast3{}},
+		{From: cheater, To: partyIDs[2], Content: &message3{}},
+		{From: cheater, To: slow, Broadcast: true, Content: &broadcast3{}},
+	}
+	for _, msg := range invalid {
+		if msg.Broadcast {
+			require.Error(t, kgs[slow].StoreBroadcastMessage(keyID, *msg))
+		} else {
+			require.Error(t, kgs[slow].StoreMessage(keyID, *msg))
+		}
+	}
+
+	// the cheater's broadcast is kept first, so that its valid one is ignored
+	require.NoError(t, deliver(slow, &round.Message{From: cheater, Broadcast: true, Content: &broadcast3{}}))
+	for _, from := range partyIDs[1:] {
+		for _, msg := range round3Msgs[from] {
+			require.NoError(t, deliver(slow, msg))
+		}
+	}
+	for _, from := range partyIDs[1:] {
+		for _, msg := range round2Msgs[from] {
+			require.NoError(t, deliver(slow, msg))
+		}
+	}
+
+	out := make(chan *round.Message, 2*len(partyIDs))
+	next, err := kgs[slow].Finalize(out, keyID)
+	require.Error(t, err)
+	var perr protocol.Error
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, []party.ID{cheater}, perr.Culprits)
+	require.NotNil(t, next, "the session moved to round 3")
+	require.Equal(t, round.Number(3), next.Number())
+	r, err := kgs[slow].GetRound(keyID)
+	require.NoError(t, err)
+	require.Equal(t, round.Number(3), r.Number())
+
+	kgs[slow].earlyMtx.Lock()
+	require.Empty(t, kgs[slow].early[keyID], "the early messages were all replayed")
+	kgs[slow].earlyMtx.Unlock()
+}
+
+type testContent struct {
+	number round.Number
+}
+
+func (c *testContent) RoundNumber() round.Number { return c.number }
+
+// runKeygen runs a keygen session of partyIDs with a protocol.Runner for every party except
+// silent, and returns the session ID, the processors of the parties and the results of their runners.
+func runKeygen(t *testing.T, partyIDs party.IDSlice, silent party.ID, timeout time.Duration, deliveries int) (string, map[party.ID]*FROSTKeygen, map[party.ID]error) {
+	keyID := uuid.NewString()
+	kgs := make(map[party.ID]*FROSTKeygen, len(partyIDs))
+	ins := make(map[party.ID]chan round.Message, len(partyIDs))
+	for _, id := range partyIDs {
+		kgs[id] = newFROSTKeygen()
+		ins[id] = make(chan round.Message, 2*deliveries*len(partyIDs))
+		cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, partyIDs)
+		_, err := kgs[id].Start(cfg)(nil)
+		require.NoError(t, err)
+	}
+
+	var wg, forwarders sync.WaitGroup
+	var mtx sync.Mutex
+	errs := make(map[party.ID]error, len(partyIDs))
+	for _, id := range partyIDs {
+		if id == silent {
+			continue
+		}
+		out := make(chan *round.Message, len(partyIDs)+1)
+		forwarders.Add(1)
+		go func(from party.ID) {
+			defer forwarders.Done()
+			for msg := range out {
+				for _, to := range partyIDs {
+					if to == from || to == silent {
+						continue
+					}
+					for i := 0; i < deliveries; i++ {
+						ins[to] <- *msg
+					}
+				}
+			}
+		}(id)
+
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			defer close(out)
+			runner := protocol.NewRunner(kgs[id], kgs[id].statemgr, timeout)
+			_, err := runner.Run(context.Background(), keyID, ins[id], out)
+			mtx.Lock()
+			errs[id] = err
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	forwarders.Wait()
+	return keyID, kgs, errs
+}
+
+func TestKeygen_Runner(t *testing.T) {
+	_, _, errs := runKeygen(t, test.PartyIDs(3), "", time.Minute, 1)
+	require.Len(t, errs, 3)
+	for id, err := range errs {
+		require.NoError(t, err, "party %s", id)
+	}
+}
+
+func TestKeygen_RunnerTimeout(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	silent := partyIDs[2]
+
+	const timeout = 200 * time.Millisecond
+	start := time.Now()
+	keyID, kgs, errs := runKeygen(t, partyIDs, silent, timeout, 1)
+	require.GreaterOrEqual(t, time.Since(start), timeout)
+
+	require.Len(t, errs, 2)
+	for id, err := range errs {
+		require.ErrorIs(t, err, protocol.ErrRoundTimeout, "party %s", id)
+		var protocolErr protocol.Error
+		require.ErrorAs(t, err, &protocolErr)
+		require.Equal(t, []party.ID{silent}, protocolErr.Culprits, "culprits of party %s", id)
+
+		state, err := kgs[id].statemgr.Get(keyID)
+		require.NoError(t, err)
+		require.True(t, state.Aborted(), "session of party %s is not aborted", id)
+	}
+}
+
+func TestKeygen_RunnerDuplicateMessages(t *testing.T) {
+	_, _, errs := runKeygen(t, test.PartyIDs(3), "", time.Minute, 2)
+	require.Len(t, errs, 3)
+	for id, err := range errs {
+		require.NoError(t, err, "party %s", id)
+	}
+}
+
+func TestKeygen_RunnerCanceled(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	keyID := uuid.NewString()
+	kg := newFROSTKeygen()
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, partyIDs[0], partyIDs)
+	_, err := kg.Start(cfg)(nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runner := protocol.NewRunner(kg, kg.statemgr, time.Minute)
+	_, err = runner.Run(ctx, keyID, make(chan round.Message), make(chan *round.Message, len(partyIDs)))
+	require.ErrorIs(t, err, context.Canceled)
+
+	state, err := kg.statemgr.Get(keyID)
+	require.NoError(t, err)
+	require.False(t, state.Aborted())
+}
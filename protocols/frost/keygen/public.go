@@ -0,0 +1,68 @@
+package keygen
+
+import (
+	"errors"
+
+	"filippo.io/edwards25519"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/eddsa"
+)
+
+// PublicConfig is the part of a Config needed by a node which only verifies the signatures of the
+// consortium, and never holds a share of the key.
+type PublicConfig struct {
+	// Threshold is the number of accepted corruptions while still being able to sign.
+	Threshold int
+	// PublicKey is the shared public key of the consortium.
+	PublicKey *edwards25519.Point
+}
+
+type rawPublicConfig struct {
+	Threshold int
+	PublicKey []byte
+}
+
+// PublicConfig returns the public part of the config.
+func (r *Config) PublicConfig() *PublicConfig {
+	return &PublicConfig{
+		Threshold: r.Threshold,
+		PublicKey: new(edwards25519.Point).Set(r.PublicKey),
+	}
+}
+
+// Verify returns true if sig is a valid Ed25519 signature of message under the shared public key.
+func (c *PublicConfig) Verify(message []byte, sig eddsa.Signature) bool {
+	if c.PublicKey == nil || sig.R == nil || sig.Z == nil {
+		return false
+	}
+	return eddsa.Verify(c.PublicKey, sig, message)
+}
+
+// MarshalBinary encodes the threshold and the public key of the config.
+func (c *PublicConfig) MarshalBinary() ([]byte, error) {
+	if c.PublicKey == nil {
+		return nil, errors.New("frost: public config without public key")
+	}
+	return cbor.Marshal(rawPublicConfig{
+		Threshold: c.Threshold,
+		PublicKey: c.PublicKey.Bytes(),
+	})
+}
+
+// UnmarshalBinary decodes a config encoded with MarshalBinary.
+func (c *PublicConfig) UnmarshalBinary(data []byte) error {
+	var raw rawPublicConfig
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	publicKey, err := new(edwards25519.Point).SetBytes(raw.PublicKey)
+	if err != nil {
+		return err
+	}
+	if publicKey.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return errors.New("frost: public key is identity")
+	}
+	c.Threshold = raw.Threshold
+	c.PublicKey = publicKey
+	return nil
+}
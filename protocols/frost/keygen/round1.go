@@ -1,6 +1,7 @@
 package keygen
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mr-shifu/mpc-lib/lib/round"
@@ -39,10 +40,24 @@ func (r *round1) StoreBroadcastMessage(round.Message) error { return nil }
 // StoreMessage implements round.Round.
 func (r *round1) StoreMessage(round.Message) error { return nil }
 
-// Finalize implements round.Round
+// Finalize implements round.Round.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round
+func (r *round1) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	// ToDo maybe we can include create options into helper
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(r.SelfID()))
 	if err != nil {
 		return r, fmt.Errorf("frost.Keygen.Round1: failed to create options")
 	}
@@ -55,7 +70,11 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 
 	// ToDo maybe we'd better to return vss instance by Generate function
 	// 2. Generate a new VSS share with EC Private Key as polynomial constant
-	vss, err := r.ed_km.GenerateVss(r.Threshold(), opts)
+	cfg, err := r.configmgr.GetConfig(r.ID)
+	if err != nil {
+		return r, fmt.Errorf("frost.Keygen.Round1: failed to get config")
+	}
+	vss, err := r.ed_km.GenerateVss(cfg.VSSDegree(), opts)
 	if err != nil {
 		return r, fmt.Errorf("frost.Keygen.Round1: failed to generate VSS secrets")
 	}
@@ -1,8 +1,10 @@
 package keygen
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	ed "filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/hash"
@@ -42,54 +44,77 @@ type round2 struct {
 }
 
 // StoreBroadcastMessage implements round.BroadcastRound.
-func (r *round2) StoreBroadcastMessage(msg round.Message) error {
+func (r *round2) StoreBroadcastMessage(msg round.Message) (err error) {
 	from := msg.From
 	body, ok := msg.Content.(*broadcast2)
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
 
+	if err := r.BeginStore(r.Number(), from, true); err != nil {
+		return err
+	}
+	defer r.EndStore(r.Number(), from, true, &err)
+
 	if body.VSSPolynomial == nil {
-		return errors.New("frost.Keygen.Round2: invalid VSS polynomial")
+		return fmt.Errorf("%w: frost.Keygen.Round2: invalid VSS polynomial", round.ErrInvalidContent)
 	}
 
-	fromOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(from))
+	cfg, err := r.configmgr.GetConfig(r.ID)
 	if err != nil {
-		return errors.New("frost.Keygen.Round2: failed to create options")
+		return errors.New("frost.Keygen.Round2: failed to get config")
+	}
+	// the exponents were decoded with the message, at most 256 since the encoding stores the
+	// degree in one byte; they are only validated once the degree matches the config
+	if body.VSSPolynomial.Degree() != cfg.VSSDegree() {
+		return fmt.Errorf("%w: frost.Keygen.Round2: VSS polynomial has degree %d, need %d", round.ErrInvalidContent, body.VSSPolynomial.Degree(), cfg.VSSDegree())
 	}
 
-	// validate commitment and import it to commitment store
-	if err := body.Commitment.Validate(); err != nil {
-		return err
+	// reject VSS exponents outside of the prime-order subgroup
+	for _, e := range body.VSSPolynomial.Exponents() {
+		if err := ed25519.ValidatePoint(e); err != nil {
+			return fmt.Errorf("%w: frost.Keygen.Round2: invalid VSS exponent: %w", round.ErrInvalidContent, err)
+		}
 	}
-	cmt := r.commit_mgr.NewCommitment(body.Commitment, nil)
-	if err := r.commit_mgr.Import(cmt, fromOpts); err != nil {
+
+	// validate commitment
+	if err := body.Commitment.Validate(); err != nil {
 		return err
 	}
 
-	// ToDo we must be able to first verify schnorr proof before importing commitment
-	// Import Party Public Key and VSS Exponents
+	// verify schnorr proof of the party's public key, before anything is imported
 	pk := body.VSSPolynomial.Constant()
-	k, err := ed25519.NewKey(nil, pk)
+	proof, err := ed25519.ParseSchnorrProof(body.SchnorrProof)
 	if err != nil {
 		return err
 	}
-	_, err = r.ed_km.ImportKey(k, fromOpts)
+	verified, err := ed25519.VerifySchnorrProofStandalone(proof.Response(), proof.Commitment(), pk, r.Helper.HashForID(from))
 	if err != nil {
 		return err
 	}
-
-	// ToDo it's better to verify proof without importing commitment
-	// verify schnorr proof
-	if err := r.ed_km.ImportSchnorrProof(body.SchnorrProof, fromOpts); err != nil {
+	if !verified {
+		return errors.New("frost.Keygen.Round2: schnorr proof verification failed")
+	}
+	k, err := ed25519.NewKey(nil, pk)
+	if err != nil {
 		return err
 	}
-	verified, err := r.ed_km.VerifySchnorrProof(r.Helper.HashForID(from), fromOpts)
+
+	fromOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(from))
 	if err != nil {
+		return errors.New("frost.Keygen.Round2: failed to create options")
+	}
+
+	// Import commitment, party public key and schnorr proof
+	cmt := r.commit_mgr.NewCommitment(body.Commitment, nil)
+	if err := r.commit_mgr.Import(cmt, fromOpts); err != nil {
 		return err
 	}
-	if !verified {
-		return errors.New("frost.Keygen.Round2: schnorr proof verification failed")
+	if _, err := r.ed_km.ImportKey(k, fromOpts); err != nil {
+		return err
+	}
+	if err := r.ed_km.ImportSchnorrProof(body.SchnorrProof, fromOpts); err != nil {
+		return err
 	}
 
 	// Import VSS Polynomial
@@ -114,13 +139,28 @@ func (round2) VerifyMessage(round.Message) error { return nil }
 // StoreMessage implements round.Round.
 func (round2) StoreMessage(round.Message) error { return nil }
 
+// Finalize implements round.Round.
 func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round.
+func (r *round2) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(r.SelfID()))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(r.SelfID()))
 	if err != nil {
 		return nil, errors.New("frost.Keygen.Round2: failed to create options")
 	}
@@ -150,6 +190,10 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 	for _, j := range r.PartyIDs() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		jScalar, err := j.Ed25519Scalar()
 		if err != nil {
 			return nil, err
@@ -172,7 +216,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			if err != nil {
 				return nil, err
 			}
-			vssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
+			vssOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
 			if err != nil {
 				return nil, errors.New("frost.Keygen.Round2: failed to create options")
 			}
@@ -218,7 +262,6 @@ func (r *round2) CanFinalize() bool {
 func (r *round2) BroadcastContent() round.BroadcastContent {
 	return &broadcast2{
 		VSSPolynomial: new(polynomial.Polynomial),
-		// SchnorrProof:  r.Group().NewScalar(),
 	}
 }
 
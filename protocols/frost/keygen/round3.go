@@ -1,6 +1,7 @@
 package keygen
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 
@@ -53,7 +54,7 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	}
 
 	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts.Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(from))
 
 	// 1. Validate ChainKey and Decommitment
 	if err := body.ChainKey.Validate(); err != nil {
@@ -125,7 +126,7 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	//   fₗ(i) * G =? ∑ₖ₌₀ᵗ (iᵏ mod q) * ϕₗₖ
 	//
 	// aborting if the check fails."
-	fromOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(from))
+	fromOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(from))
 	if err != nil {
 		return errors.New("frost.Keygen.Round2: failed to create options")
 	}
@@ -149,7 +150,7 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	if err != nil {
 		return err
 	}
-	vssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
+	vssOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
 	if err != nil {
 		return errors.New("frost.Keygen.Round2: failed to create options")
 	}
@@ -172,13 +173,27 @@ func (r *round3) StoreMessage(msg round.Message) error {
 }
 
 // Finalize implements round.Round.
-func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round.
+func (r *round3) FinalizeContext(ctx context.Context, _ chan<- *round.Message) (_ round.Session, err error) {
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	rootOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", "ROOT")
 	if err != nil {
 		return nil, errors.New("frost.Keygen.Round3: failed to create options")
 	}
@@ -186,7 +201,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	// 1. XOR all chainKeys to get the group chainKey
 	chainKey := types.EmptyRID()
 	for _, j := range r.PartyIDs() {
-		partyOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(j))
+		partyOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(j))
 		if err != nil {
 			return nil, errors.New("frost.Keygen.Round3: failed to create options")
 		}
@@ -203,7 +218,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	// 2. Sum all VSS Exponents Shares to generate MPC VSS Exponent and Import it to VSS Keystore
 	vssOptsList := make([]com_keyopts.Options, 0)
 	for _, partyID := range r.PartyIDs() {
-		partyOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(partyID))
+		partyOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(partyID))
 		if err != nil {
 			return nil, errors.New("frost.Keygen.Round3: failed to create options")
 		}
@@ -235,7 +250,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	// 4. Sum all VSS self shares to generate MPC VSS Share
 	optsList := make([]com_keyopts.Options, 0)
 	for _, j := range r.PartyIDs() {
-		partyOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(j))
+		partyOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(j))
 		if err != nil {
 			return nil, errors.New("frost.Keygen.Round3: failed to create options")
 		}
@@ -245,7 +260,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 			return nil, err
 		}
 
-		vssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
+		vssOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
 		if err != nil {
 			return nil, errors.New("frost.Keygen.Round3: failed to create options")
 		}
@@ -256,7 +271,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return nil, err
 	}
-	rootVssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(rootVss.SKI()), "partyid", string(r.SelfID()))
+	rootVssOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(rootVss.SKI()), "partyid", string(r.SelfID()))
 	if err != nil {
 		return nil, errors.New("frost.Keygen.Round3: failed to create options")
 	}
@@ -265,7 +280,11 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	}
 
 	for _, j := range r.OtherPartyIDs() {
-		vssPartyOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vssPoly.SKI()), "partyid", string(j))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		vssPartyOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(vssPoly.SKI()), "partyid", string(j))
 		if err != nil {
 			return nil, errors.New("frost.Keygen.Round3: failed to create options")
 		}
@@ -287,9 +306,10 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 		}
 	}
 
+	// the shares are points of a polynomial of degree d ⩾ t, so d+1 of them are needed to sign
 	return r.ResultRound(&Config{
 		ID:        r.SelfID(),
-		Threshold: r.Threshold(),
+		Threshold: exponents.Degree(),
 		PublicKey: pubKey,
 	}), nil
 }
@@ -0,0 +1,39 @@
+package sign
+
+import (
+	"crypto/sha512"
+
+	"filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+	"github.com/pkg/errors"
+)
+
+// challenge computes c = H(R, Y, m), where R is the group commitment, Y the group public key
+// and m the message to sign.
+//
+// H defaults to SHA-512 as in Ed25519 (RFC 8032), so that the resulting signature verifies
+// with any standard Ed25519 implementation. A different hash can be set on the sign config;
+// its digest is interpreted as a little-endian integer and reduced modulo ℓ.
+func challenge(cfg config.SignConfig, R, Y *edwards25519.Point) (*edwards25519.Scalar, error) {
+	newHash := cfg.ChallengeHash()
+	if newHash == nil {
+		newHash = sha512.New
+	}
+
+	h := newHash()
+	_, _ = h.Write(R.Bytes())
+	_, _ = h.Write(Y.Bytes())
+	_, _ = h.Write(cfg.Message())
+	digest := h.Sum(nil)
+	if len(digest) > 64 {
+		return nil, errors.New("frost.sign: challenge digest is longer than 64 bytes")
+	}
+
+	wide := make([]byte, 64)
+	copy(wide, digest)
+	c, err := edwards25519.NewScalar().SetUniformBytes(wide)
+	if err != nil {
+		return nil, errors.WithMessage(err, "frost.sign: failed to derive challenge")
+	}
+	return c, nil
+}
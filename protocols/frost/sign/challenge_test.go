@@ -0,0 +1,82 @@
+package sign
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"hash"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/google/uuid"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/protocols/frost/keygen"
+	"github.com/stretchr/testify/require"
+)
+
+// runFROST runs keygen and sign among N parties and returns the group public key and the
+// encoded signature R ‖ z.
+func runFROST(t *testing.T, N int, msg []byte, challengeHash func() hash.Hash) (*edwards25519.Point, []byte) {
+	group := curve.Secp256k1{}
+	keyID := uuid.NewString()
+	signID := uuid.NewString()
+	partyIDs := test.PartyIDs(N)
+
+	mpckeygens := make([]protocol.Processor, 0, N)
+	mpcsigns := make([]protocol.Processor, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newFROSTMPC()
+		mpckeygens = append(mpckeygens, mpckg)
+		mpcsigns = append(mpcsigns, mpcsign)
+
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		_, err := mpckg.Start(keycfg)(nil)
+		require.NoError(t, err)
+	}
+
+	var publicKey *edwards25519.Point
+	for {
+		rounds, done, err := test.FROSTRounds(mpckeygens, keyID)
+		require.NoError(t, err)
+		if done {
+			res := rounds[0].(*round.Output).Result.(*keygen.Config)
+			publicKey = res.PublicKey
+			break
+		}
+	}
+
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, msg).WithChallengeHash(challengeHash)
+		_, err := mpcsigns[i].Start(cfg)(nil)
+		require.NoError(t, err)
+	}
+
+	for {
+		rounds, done, err := test.FROSTRounds(mpcsigns, signID)
+		require.NoError(t, err)
+		if done {
+			out, ok := rounds[0].(*round.Output)
+			require.True(t, ok, "signing should not abort")
+			res := out.Result.(result.EddsaSignature)
+			return publicKey, append(res.R().Bytes(), res.Z().Bytes()...)
+		}
+	}
+}
+
+func TestSign_DefaultChallengeIsEd25519(t *testing.T) {
+	msg := []byte("hello")
+	publicKey, sig := runFROST(t, 2, msg, nil)
+
+	require.True(t, ed25519.Verify(publicKey.Bytes(), msg, sig), "signature should verify as standard Ed25519")
+}
+
+func TestSign_CustomChallengeHash(t *testing.T) {
+	msg := []byte("hello")
+	publicKey, sig := runFROST(t, 2, msg, sha256.New)
+
+	require.False(t, ed25519.Verify(publicKey.Bytes(), msg, sig), "signature should be bound to the custom challenge hash")
+}
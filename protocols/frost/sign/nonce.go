@@ -0,0 +1,45 @@
+package sign
+
+import (
+	"io"
+
+	ed "filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/zeebo/blake3"
+)
+
+const deriveHashKeyContext = "Derive hash Key"
+
+// SetNonceRandomness makes the sessions started or resumed afterwards read the random value
+// mixed into their nonces from r, for instance a fixed reader to reproduce test vectors.
+//
+// A nil r uses crypto/rand.Reader, which is the default.
+func (f *FROSTSign) SetNonceRandomness(r io.Reader) {
+	f.nonceRand = r
+}
+
+// deriveNonces derives the nonces (d, e) of a signer, hedged against a failure of the source of
+// randomness: they are read from BLAKE3 keyed by the secret key of the signer, over the session
+// transcript, the message and a random value.
+//
+// With the random value fixed, the nonces are deterministic, and they still differ for every
+// transcript and message if the randomness is broken.
+func deriveNonces(secret, transcript, message, random []byte) (*ed.Scalar, *ed.Scalar, error) {
+	hashKey := make([]byte, 32)
+	blake3.DeriveKey(deriveHashKeyContext, secret, hashKey)
+	nonceHasher, _ := blake3.NewKeyed(hashKey)
+	_, _ = nonceHasher.Write(transcript)
+	_, _ = nonceHasher.Write(message)
+	_, _ = nonceHasher.Write(random)
+	nonceDigest := nonceHasher.Digest()
+
+	d, err := sample.Ed25519Scalar(nonceDigest)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := sample.Ed25519Scalar(nonceDigest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, e, nil
+}
@@ -0,0 +1,37 @@
+package sign
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveNonces(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 32)
+	transcript := bytes.Repeat([]byte{2}, 32)
+	random := bytes.Repeat([]byte{3}, 32)
+
+	d, e, err := deriveNonces(secret, transcript, []byte("message"), random)
+	require.NoError(t, err)
+	assert.Equal(t, 0, d.Equal(e), "d and e are equal")
+
+	// with the randomness fixed, the nonces are deterministic
+	d2, e2, err := deriveNonces(secret, transcript, []byte("message"), random)
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Equal(d2))
+	assert.Equal(t, 1, e.Equal(e2))
+
+	// changing the message, the secret or the randomness changes the nonces
+	for name, nonces := range map[string][3][]byte{
+		"message": {secret, []byte("other message"), random},
+		"secret":  {bytes.Repeat([]byte{4}, 32), []byte("message"), random},
+		"random":  {secret, []byte("message"), bytes.Repeat([]byte{4}, 32)},
+	} {
+		d3, e3, err := deriveNonces(nonces[0], transcript, nonces[1], nonces[2])
+		require.NoError(t, err)
+		assert.Equal(t, 0, d.Equal(d3), name)
+		assert.Equal(t, 0, e.Equal(e3), name)
+	}
+}
@@ -0,0 +1,18 @@
+package sign
+
+import "filippo.io/edwards25519"
+
+// RemoteSigner holds the secret share sᵢ of a party outside of the local process, e.g. in a
+// hardware security module, so that the signing rounds never load it from the keystore.
+type RemoteSigner interface {
+	// MultiplyShare returns sᵢ⋅m, where sᵢ is the share of this party of the key keyID.
+	MultiplyShare(keyID string, m *edwards25519.Scalar) (*edwards25519.Scalar, error)
+}
+
+// SetRemoteSigner delegates the multiplications by the share sᵢ in the sessions started or
+// resumed afterwards to s. The keystore then only needs the public share of the party.
+//
+// A nil s uses the share stored in the keystore, which is the default.
+func (f *FROSTSign) SetRemoteSigner(s RemoteSigner) {
+	f.remote = s
+}
@@ -1,10 +1,11 @@
 package sign
 
 import (
+	"context"
 	"crypto/rand"
+	"io"
 
 	ed "filippo.io/edwards25519"
-	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ed25519"
@@ -15,7 +16,6 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 	"github.com/pkg/errors"
-	"github.com/zeebo/blake3"
 )
 
 // This round sort of corresponds with Figure 2 of the Frost paper:
@@ -42,6 +42,8 @@ type round1 struct {
 	sign_d     ed25519.Ed25519KeyManager
 	sign_e     ed25519.Ed25519KeyManager
 	hash_mgr   hash.HashManager
+	remote     RemoteSigner
+	nonceRand  io.Reader
 }
 
 // VerifyMessage implements round.Round.
@@ -49,15 +51,27 @@ func (r *round1) VerifyMessage(round.Message) error         { return nil }
 func (r *round1) StoreBroadcastMessage(round.Message) error { return nil }
 func (r *round1) StoreMessage(round.Message) error          { return nil }
 
-const deriveHashKeyContext = "Derive hash Key"
-
 // Finalize implements round.Round.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(r.SelfID()))
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round.
+func (r *round1) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(r.SelfID()))
 	if err != nil {
 		return r, errors.New("frost.Sign.Round1: failed to create options")
 	}
-	kopts, err := keyopts.NewOptions().Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
 	if err != nil {
 		return r, errors.New("frost.Sign.Round1: failed to create options")
 	}
@@ -71,22 +85,20 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 
-	// ToDo we may move this to utils package
-	hashKey := make([]byte, 32)
-	blake3.DeriveKey(deriveHashKeyContext, kb, hashKey)
-	nonceHasher, _ := blake3.NewKeyed(hashKey)
-	_, _ = nonceHasher.Write(r.Hash().Sum())
-	_, _ = nonceHasher.Write(r.cfg.Message())
+	source := r.nonceRand
+	if source == nil {
+		source = rand.Reader
+	}
 	a := make([]byte, 32)
-	_, _ = rand.Read(a)
-	_, _ = nonceHasher.Write(a)
-	nonceDigest := nonceHasher.Digest()
-
-	// Generate random (d, D) pair param and import them into EC keystore
-	d, err := sample.Ed25519Scalar(nonceDigest)
+	if _, err := io.ReadFull(source, a); err != nil {
+		return r, errors.WithMessage(err, "frost.Sign.Round1: failed to read nonce randomness")
+	}
+	d, e, err := deriveNonces(kb, r.Hash().Sum(), r.cfg.Message(), a)
 	if err != nil {
 		return nil, err
 	}
+
+	// Import (d, D) pair param into EC keystore
 	D := new(ed.Point).ScalarBaseMult(d)
 	sign_d, err := ed25519.NewKey(d, D)
 	if err != nil {
@@ -97,11 +109,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, errors.WithMessage(err, "failed to import D into EC keystore")
 	}
 
-	// Generate random (e, E) pair param and import them into EC keystore
-	e, err := sample.Ed25519Scalar(nonceDigest)
-	if err != nil {
-		return nil, err
-	}
+	// Import (e, E) pair param into EC keystore
 	E := new(ed.Point).ScalarBaseMult(e)
 	sign_e, err := ed25519.NewKey(e, E)
 	if err != nil {
@@ -139,6 +147,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 		sign_d:     r.sign_d,
 		sign_e:     r.sign_e,
 		hash_mgr:   r.hash_mgr,
+		remote:     r.remote,
 		Helper:     r.Helper,
 	}, nil
 }
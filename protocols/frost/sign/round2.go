@@ -1,13 +1,13 @@
 package sign
 
 import (
-	"crypto/sha512"
-
+	"context"
 	"filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ed25519"
 	sw_hash "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	vssed25519 "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss-ed25519"
@@ -44,6 +44,7 @@ type round2 struct {
 	sign_d     ed25519.Ed25519KeyManager
 	sign_e     ed25519.Ed25519KeyManager
 	hash_mgr   hash.HashManager
+	remote     RemoteSigner
 }
 
 type broadcast2 struct {
@@ -65,7 +66,7 @@ func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 		return errors.New("nonce commitment is the identity point")
 	}
 
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(msg.From))
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(msg.From))
 	if err != nil {
 		return errors.New("frost.sign.Round2: failed to set options")
 	}
@@ -106,13 +107,25 @@ func (round2) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round.
 func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
-	rho := make(map[party.ID]*edwards25519.Scalar)
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round.
+func (r *round2) FinalizeContext(ctx context.Context, out chan<- *round.Message) (_ round.Session, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
 
 	// 0. fetch Dᵢ and Eᵢ from the keystore
 	Ds := make(map[party.ID]*edwards25519.Point)
 	Es := make(map[party.ID]*edwards25519.Point)
 	for _, l := range r.PartyIDs() {
-		opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(l))
+		opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(l))
 		if err != nil {
 			return nil, errors.New("frost.sign.Round2: failed to set options")
 		}
@@ -130,36 +143,29 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		Es[l] = ek.PublickeyPoint()
 	}
 
-	// ToDo replace with hash manager
 	// 1. generate random ρᵢ for each party i
-	rhoPreHash := sw_hash.New(nil)
-	_ = rhoPreHash.WriteAny(r.cfg.Message())
-	for _, l := range r.PartyIDs() {
-		_ = rhoPreHash.WriteAny(Ds[l], Es[l])
-	}
-	for _, l := range r.PartyIDs() {
-		rhoHash := rhoPreHash.Clone()
-		_ = rhoHash.WriteAny(l)
-		rl, err := sample.Ed25519Scalar(rhoHash.Digest())
-		if err != nil {
-			return nil, err
-		}
-		rho[l] = rl
+	rho, err := bindingFactors(r.cfg.Message(), r.PartyIDs(), Ds, Es)
+	if err != nil {
+		return nil, err
 	}
 
 	// 2. Compute Rᵢ = (ρᵢ Eᵢ + Dᵢ) && R = Σᵢ Rᵢ
 	R := new(edwards25519.Point)
 	RShares := make(map[party.ID]*edwards25519.Point)
 	for itr, l := range r.PartyIDs() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		RShares[l] = new(edwards25519.Point).ScalarMult(rho[l], Es[l])
 		RShares[l].Add(RShares[l], Ds[l])
 
-		opts_l, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(l))
+		opts_l, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(l))
 		if err != nil {
 			return nil, errors.New("frost.sign.Round2: failed to set options")
 		}
 		if err := r.sigmgr.Import(r.sigmgr.NewEddsaSignature(RShares[l], nil), opts_l); err != nil {
-			return r, nil
+			return r, err
 		}
 
 		if itr == 0 {
@@ -168,16 +174,16 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		}
 		R.Add(R, RShares[l])
 	}
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
+	rootOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", "ROOT")
 	if err != nil {
 		return nil, errors.New("frost.sign.Round2: failed to set options")
 	}
 	if err := r.sigmgr.Import(r.sigmgr.NewEddsaSignature(R, nil), rootOpts); err != nil {
-		return r, nil
+		return r, err
 	}
 
 	// 3. Generate a random number as commitment to the nonce
-	kopts, err := keyopts.NewOptions().Set("id", r.cfg.KeyID(), "partyid", "ROOT")
+	kopts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.cfg.KeyID(), "partyid", "ROOT")
 	if err != nil {
 		return nil, errors.New("frost.sign.Round2: failed to set options")
 	}
@@ -185,19 +191,13 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return r, err
 	}
-	kh := sha512.New()
-	kh.Write(R.Bytes())
-	kh.Write(edKey.PublickeyPoint().Bytes())
-	kh.Write(r.cfg.Message())
-	hramDigest := make([]byte, 0, sha512.Size)
-	hramDigest = kh.Sum(hramDigest)
-	c, err := edwards25519.NewScalar().SetUniformBytes(hramDigest)
+	c, err := challenge(r.cfg, R, edKey.PublickeyPoint())
 	if err != nil {
-		panic("ed25519: internal error: setting scalar failed")
+		return r, err
 	}
 
 	// 4. Compute zᵢ = dᵢ + (eᵢ ρᵢ) + λᵢ sᵢ c
-	sopts, err := keyopts.NewOptions().Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.cfg.ID(), "partyid", string(r.SelfID()))
 	if err != nil {
 		return nil, errors.New("frost.sign.Round2: failed to set options")
 	}
@@ -211,13 +211,12 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 	edk := ek.MultiplyAdd(rho[r.SelfID()], dk)
 
-	signKey, err := r.ed_sign_km.GetKey(sopts)
+	z, err := r.multiplyAddShare(c, edk, sopts)
 	if err != nil {
 		return r, err
 	}
-	z := signKey.MultiplyAdd(c, edk)
 	if err := r.sigmgr.SetZ(z, sopts); err != nil {
-		return r, nil
+		return r, err
 	}
 
 	// 5. Broadcast z
@@ -260,6 +259,27 @@ func (r *round2) CanFinalize() bool {
 	return rcvd
 }
 
+// multiplyAddShare returns λᵢ sᵢ c + edk, using the remote signer holding sᵢ if there is one.
+func (r *round2) multiplyAddShare(c, edk *edwards25519.Scalar, sopts comm_keyopts.Options) (*edwards25519.Scalar, error) {
+	if r.remote == nil {
+		signKey, err := r.ed_sign_km.GetKey(sopts)
+		if err != nil {
+			return nil, err
+		}
+		return signKey.MultiplyAdd(c, edk), nil
+	}
+
+	lagrange, err := r.vss_mgr.LagrangeBasis(r.cfg.PartyIDs())
+	if err != nil {
+		return nil, err
+	}
+	sc, err := r.remote.MultiplyShare(r.cfg.KeyID(), new(edwards25519.Scalar).Multiply(lagrange[r.SelfID()], c))
+	if err != nil {
+		return nil, errors.WithMessage(err, "frost.sign.Round2: remote signer")
+	}
+	return new(edwards25519.Scalar).Add(sc, edk), nil
+}
+
 // MessageContent implements round.Round.
 func (round2) MessageContent() round.Content { return nil }
 
@@ -297,3 +317,28 @@ func (r *round2) BroadcastContent() round.BroadcastContent {
 
 // Number implements round.Round.
 func (round2) Number() round.Number { return 2 }
+
+// bindingFactors returns the binding factor ρₗ of each party l, derived from the hash of the message
+// and of the commitments (Dₗ, Eₗ) of all parties, written in the order of their sorted IDs so that
+// every party gets the same factors.
+func bindingFactors(message []byte, partyIDs []party.ID, Ds, Es map[party.ID]*edwards25519.Point) (map[party.ID]*edwards25519.Scalar, error) {
+	// ToDo replace with hash manager
+	sorted := party.SortedIDs(partyIDs)
+	rhoPreHash := sw_hash.New(nil)
+	_ = rhoPreHash.WriteAny(message)
+	for _, l := range sorted {
+		_ = rhoPreHash.WriteAny(Ds[l], Es[l])
+	}
+
+	rho := make(map[party.ID]*edwards25519.Scalar, len(sorted))
+	for _, l := range sorted {
+		rhoHash := rhoPreHash.Clone()
+		_ = rhoHash.WriteAny(l)
+		rl, err := sample.Ed25519Scalar(rhoHash.Digest())
+		if err != nil {
+			return nil, err
+		}
+		rho[l] = rl
+	}
+	return rho, nil
+}
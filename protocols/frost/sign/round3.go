@@ -1,9 +1,7 @@
 package sign
 
 import (
-	"crypto/sha512"
-	"fmt"
-
+	"context"
 	"filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/eddsa"
 	"github.com/pkg/errors"
@@ -54,17 +52,17 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 		return round.ErrNilFields
 	}
 
-	kopts, err := keyopts.NewOptions().Set("id", r.cfg.KeyID(), "partyid", "ROOT")
+	kopts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.cfg.KeyID(), "partyid", "ROOT")
 	if err != nil {
 		return errors.New("forst.sign.Round3: failed to set options")
 	}
 
-	sopts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(msg.From))
+	sopts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(msg.From))
 	if err != nil {
 		return errors.New("forst.sign.Round3: failed to set options")
 	}
 
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
+	rootOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", "ROOT")
 	if err != nil {
 		return errors.New("forst.sign.Round3: failed to set options")
 	}
@@ -78,18 +76,13 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	if err != nil {
 		return err
 	}
-	kh := sha512.New()
-	kh.Write(rootSig.R().Bytes())
-	kh.Write(edKey.PublickeyPoint().Bytes())
-	kh.Write(r.cfg.Message())
-	hramDigest := make([]byte, 0, sha512.Size)
-	hramDigest = kh.Sum(hramDigest)
-	c, err := edwards25519.NewScalar().SetUniformBytes(hramDigest)
+	c, err := challenge(r.cfg, rootSig.R(), edKey.PublickeyPoint())
 	if err != nil {
-		panic("ed25519: internal error: setting scalar failed")
+		return err
 	}
 
-	// 2. Verify the z_i response
+	// 2. Verify the z_i response against the sender's nonce commitment and public share:
+	// z_i⋅G = R_i + c⋅(λ_i⋅Y_i)
 	signKey, err := r.ed_sign_km.GetKey(sopts)
 	if err != nil {
 		return err
@@ -103,10 +96,10 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	expected.ScalarMult(c, signKey.PublickeyPoint()).Add(expected, fromSig.R())
 	actual := new(edwards25519.Point).ScalarBaseMult(body.Z)
 	if actual.Equal(expected) != 1 {
-		return fmt.Errorf("failed to verify response from %v", from)
+		return errors.Wrapf(ErrInvalidZShare, "party %s", from)
 	}
 
-	// Import z_i into the signature reposnse share
+	// Import z_i into the signature reposnse share, only once it is verified
 	if err := r.sigmgr.SetZ(body.Z, sopts); err != nil {
 		return err
 	}
@@ -128,11 +121,32 @@ func (round3) VerifyMessage(round.Message) error { return nil }
 func (round3) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round.
-func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
+func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	return r.FinalizeContext(context.Background(), out)
+}
+
+// FinalizeContext implements round.Round.
+func (r *round3) FinalizeContext(ctx context.Context, _ chan<- *round.Message) (next round.Session, err error) {
+	// the nonces were consumed by round 2, so they are deleted however this round ends
+	defer func() {
+		if nerr := r.deleteNonces(); nerr != nil && err == nil {
+			next, err = nil, nerr
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.BeginFinalize(r.Number()); err != nil {
+		return nil, err
+	}
+	defer r.EndFinalize(r.Number(), &err)
+
 	// 1. Compute the group's response z = ∑ᵢ zᵢ
 	z := edwards25519.NewScalar()
 	for _, l := range r.PartyIDs() {
-		opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(l))
+		opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", string(l))
 		if err != nil {
 			return nil, errors.New("forst.sign.Round3: failed to set options")
 		}
@@ -142,16 +156,19 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 		}
 		z.Add(z, sig.Z())
 	}
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
+	rootOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", "ROOT")
 	if err != nil {
 		return nil, errors.New("forst.sign.Round3: failed to set options")
 	}
 	if err := r.sigmgr.SetZ(z, rootOpts); err != nil {
-		return r, nil
+		return r, err
 	}
 
 	// 2. Verify the signature
-	ecKey, err := r.eddsa_km.GetKey(keyopts.Options{"id": r.cfg.KeyID(), "partyid": "ROOT"})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ecKey, err := r.eddsa_km.GetKey(keyopts.Options{"protocol": keyopts.ProtocolFROST, "id": r.cfg.KeyID(), "partyid": "ROOT"})
 	if err != nil {
 		return r.AbortRound(err), nil
 	}
@@ -163,9 +180,21 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 		R: s.R(),
 		Z: s.Z(),
 	}
-	verified := eddsa.Verify(ecKey.PublickeyPoint(), sig, r.cfg.Message())
+	var verified bool
+	if r.cfg.ChallengeHash() == nil {
+		verified = eddsa.Verify(ecKey.PublickeyPoint(), sig, r.cfg.Message())
+	} else {
+		// with a custom challenge hash, check z⋅G = R + c⋅Y directly
+		c, err := challenge(r.cfg, sig.R, ecKey.PublickeyPoint())
+		if err != nil {
+			return r.AbortRound(err), nil
+		}
+		expected := new(edwards25519.Point).ScalarMult(c, ecKey.PublickeyPoint())
+		expected.Add(expected, sig.R)
+		verified = new(edwards25519.Point).ScalarBaseMult(sig.Z).Equal(expected) == 1
+	}
 	if !verified {
-		return r.AbortRound(fmt.Errorf("generated signature failed to verify")), nil
+		return r.AbortRound(ErrInvalidSignature), nil
 	}
 
 	// update last round processed in StateManager
@@ -176,6 +205,31 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	return r.ResultRound(s), nil
 }
 
+// deleteNonces deletes the one-time nonce commitments Dᵢ and Eᵢ of all signers of the session,
+// including our own dᵢ and eᵢ, from sign_d and sign_e. Nonces already deleted are skipped.
+func (r *round3) deleteNonces() error {
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID)
+	if err != nil {
+		return errors.New("forst.sign.Round3: failed to set options")
+	}
+	for _, km := range []ed25519.Ed25519KeyManager{r.sign_d, r.sign_e} {
+		keys, err := km.ListKeys(opts)
+		if err != nil {
+			return errors.WithMessage(err, "forst.sign.Round3: failed to list nonces")
+		}
+		for l := range keys {
+			partyOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", r.ID, "partyid", l)
+			if err != nil {
+				return errors.New("forst.sign.Round3: failed to set options")
+			}
+			if err := km.DeleteKey(partyOpts); err != nil {
+				return errors.WithMessagef(err, "forst.sign.Round3: failed to delete nonce of party %s", l)
+			}
+		}
+	}
+	return nil
+}
+
 func (r *round3) CanFinalize() bool {
 	// Verify if all parties commitments are received
 	var parties []string
@@ -1,10 +1,13 @@
 package sign
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
+	"io"
 
-	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 	"github.com/mr-shifu/mpc-lib/lib/round"
@@ -27,6 +30,22 @@ const (
 	protocolRounds round.Number = 3
 )
 
+// ErrInvalidZShare is returned when the response zᵢ of a signer does not match its nonce
+// commitment Rᵢ and public share.
+var ErrInvalidZShare = errors.New("frost.sign: invalid z-share")
+
+// ErrInvalidSignature is returned when the signature (R, z) aggregated from the z-shares does not
+// verify against the group public key and the message.
+var ErrInvalidSignature = errors.New("frost.sign: aggregated signature failed to verify")
+
+// ErrTooFewSigners is returned when a signing session is started with no more signers than the
+// threshold of the key.
+var ErrTooFewSigners = errors.New("frost.sign: too few signers")
+
+func init() {
+	protocol.RegisterMessageContent(SIGN_CONFIG_PROTOCOL_ID, &broadcast2{}, &broadcast3{})
+}
+
 type FROSTSign struct {
 	signcfgmgr config.SignConfigManager
 	sigmgr     result.EddsaSignatureManager
@@ -41,6 +60,8 @@ type FROSTSign struct {
 	sign_e     ed25519.Ed25519KeyManager
 	hash_mgr   hash.HashManager
 	pl         *pool.Pool
+	remote     RemoteSigner
+	nonceRand  io.Reader
 }
 
 var _ protocol.Processor = (*FROSTSign)(nil)
@@ -83,6 +104,11 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 	}
 
 	return func(sessionID []byte) (round.Session, error) {
+		// resume the session if it was already started, instead of reinitializing its state
+		if _, err := f.statemgr.Get(cfg.ID()); err == nil {
+			return f.resume(cfg)
+		}
+
 		info := round.Info{
 			ProtocolID:       SIGN_CONFIG_PROTOCOL_ID,
 			FinalRoundNumber: protocolRounds,
@@ -90,9 +116,10 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 			PartyIDs:         cfg.PartyIDs(),
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
+			MaxParties:       cfg.MaxParties(),
 		}
 
-		opts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", info.SelfID)
+		opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", cfg.ID(), "partyid", info.SelfID)
 		if err != nil {
 			return nil, errors.New("frost_sign: failed to set options")
 		}
@@ -104,38 +131,48 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 			return nil, errors.New("sign.Create: message is nil")
 		}
 
+		// the signers may be any subset of the key holders, as long as they are more than the
+		// threshold of the key, which is the degree of its VSS polynomial
+		vssOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", cfg.KeyID(), "partyid", "ROOT")
+		if err != nil {
+			return nil, errors.New("frost_sign: failed to set options")
+		}
+		vss, err := f.vss_mgr.GetSecrets(vssOpts)
+		if err != nil {
+			return nil, err
+		}
+		exponents, err := vss.ExponentsRaw()
+		if err != nil {
+			return nil, err
+		}
+		if n := len(cfg.PartyIDs()); n <= exponents.Degree() {
+			return nil, errors.Wrapf(ErrTooFewSigners, "%d signers, the key requires at least %d", n, exponents.Degree()+1)
+		}
+
 		// create a new helper
 		helper, err := round.NewSession(cfg.ID(), info, sessionID, f.pl, h, types.SigningMessage(cfg.Message()))
 		if err != nil {
 			return nil, fmt.Errorf("sign.StartSign: %w", err)
 		}
+		helper.SetMessageManagers(f.msgmgr, f.bcstmgr)
 
 		// clone the vss share multiplied by the lagrange coefficient
-		lagrange, err := polynomial.Lagrange(cfg.PartyIDs())
+		lagrange, err := f.vss_mgr.LagrangeBasis(cfg.PartyIDs())
 		if err != nil {
 			return nil, err
 		}
 		for _, j := range helper.PartyIDs() {
-			vssOpts, err := keyopts.NewOptions().Set("id", cfg.KeyID(), "partyid", "ROOT")
-			if err != nil {
-				return nil, errors.New("frost_sign: failed to set options")
-			}
-			vss, err := f.vss_mgr.GetSecrets(vssOpts)
-			if err != nil {
-				return nil, err
-			}
-
-			partyVSSOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
+			partyVSSOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
 			if err != nil {
 				return nil, errors.New("frost_sign: failed to set options")
 			}
 
 			vssShareKey, err := f.ed_vss_km.GetKey(partyVSSOpts)
 			if err != nil {
-				return nil, err
+				return nil, errors.WithMessagef(err, "frost_sign: signer %s does not hold a share of the key", j)
 			}
 
-			partyOpts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", string(j))
+			partyOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", cfg.ID(), "partyid", string(j))
 			if err != nil {
 				return nil, errors.New("frost_sign: failed to set options")
 			}
@@ -170,10 +207,37 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 			sign_d:     f.sign_d,
 			sign_e:     f.sign_e,
 			hash_mgr:   f.hash_mgr,
+			remote:     f.remote,
+			nonceRand:  f.nonceRand,
 		}, nil
 	}
 }
 
+// resume returns the current round of an already started session, as long as cfg matches
+// the config the session was started with and the session has not finished.
+func (f *FROSTSign) resume(cfg config.SignConfig) (round.Session, error) {
+	stored, err := f.signcfgmgr.GetConfig(cfg.ID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "frost_sign: failed to get config")
+	}
+	if stored.KeyID() != cfg.KeyID() ||
+		stored.SelfID() != cfg.SelfID() ||
+		!bytes.Equal(stored.Message(), cfg.Message()) ||
+		!party.NewIDSlice(stored.PartyIDs()).Equal(party.NewIDSlice(cfg.PartyIDs())) {
+		return nil, errors.WithMessage(state.ErrStateExists, "frost_sign: session was started with a different config")
+	}
+
+	s, err := f.statemgr.Get(cfg.ID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "frost_sign: failed to get state")
+	}
+	if s.Aborted() || s.Completed() {
+		return nil, errors.WithMessage(state.ErrStateFinished, "frost_sign")
+	}
+
+	return f.GetRound(cfg.ID())
+}
+
 func (f *FROSTSign) GetRound(signID string) (round.Session, error) {
 	cfg, err := f.signcfgmgr.GetConfig(signID)
 	if err != nil {
@@ -186,10 +250,11 @@ func (f *FROSTSign) GetRound(signID string) (round.Session, error) {
 		PartyIDs:         cfg.PartyIDs(),
 		Threshold:        cfg.Threshold(),
 		Group:            cfg.Group(),
+		MaxParties:       cfg.MaxParties(),
 		FinalRoundNumber: protocolRounds,
 	}
 	// instantiate a new hasher for new sign session
-	opts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", string(info.SelfID))
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", cfg.ID(), "partyid", string(info.SelfID))
 	if err != nil {
 		return nil, errors.New("frost_sign: failed to set options")
 	}
@@ -200,6 +265,7 @@ func (f *FROSTSign) GetRound(signID string) (round.Session, error) {
 	if err != nil {
 		return nil, fmt.Errorf("frost_sign: %w", err)
 	}
+	helper.SetMessageManagers(f.msgmgr, f.bcstmgr)
 
 	state, err := f.statemgr.Get(signID)
 	if err != nil {
@@ -222,6 +288,8 @@ func (f *FROSTSign) GetRound(signID string) (round.Session, error) {
 			sign_d:     f.sign_d,
 			sign_e:     f.sign_e,
 			hash_mgr:   f.hash_mgr,
+			remote:     f.remote,
+			nonceRand:  f.nonceRand,
 		}, nil
 	case 1:
 		return &round2{
@@ -238,6 +306,7 @@ func (f *FROSTSign) GetRound(signID string) (round.Session, error) {
 			sign_d:     f.sign_d,
 			sign_e:     f.sign_e,
 			hash_mgr:   f.hash_mgr,
+			remote:     f.remote,
 		}, nil
 	case 2:
 		return &round3{
@@ -287,12 +356,17 @@ func (f *FROSTSign) StoreMessage(signID string, msg round.Message) error {
 }
 
 func (f *FROSTSign) Finalize(out chan<- *round.Message, signID string) (round.Session, error) {
+	return f.FinalizeContext(context.Background(), out, signID)
+}
+
+// FinalizeContext implements protocol.Processor.
+func (f *FROSTSign) FinalizeContext(ctx context.Context, out chan<- *round.Message, signID string) (round.Session, error) {
 	r, err := f.GetRound(signID)
 	if err != nil {
 		return nil, errors.WithMessage(err, "frost_sign: failed to get round")
 	}
 
-	return r.Finalize(out)
+	return r.FinalizeContext(ctx, out)
 }
 
 func (m *FROSTSign) CanFinalize(signID string) (bool, error) {
@@ -1,11 +1,19 @@
 package sign
 
 import (
+	"context"
+	std_ed25519 "crypto/ed25519"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
 
+	"filippo.io/edwards25519"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 	"github.com/mr-shifu/mpc-lib/lib/round"
@@ -24,6 +32,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
 	"github.com/mr-shifu/mpc-lib/protocols/frost/keygen"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
 )
@@ -202,3 +211,433 @@ func TestSign(t *testing.T) {
 		}
 	}
 }
+
+func TestSign_ContentRoundTrip(t *testing.T) {
+	keyID := uuid.NewString()
+	group := curve.Secp256k1{}
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	mpckeygens := make([]protocol.Processor, 0, N)
+	mpcsigns := make([]*FROSTSign, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newFROSTMPC()
+		mpckeygens = append(mpckeygens, mpckg)
+		mpcsigns = append(mpcsigns, mpcsign)
+
+		_, err := mpckg.Start(config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs))(nil)
+		require.NoError(t, err)
+	}
+	for {
+		_, done, err := test.FROSTRounds(mpckeygens, keyID)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	signID := uuid.NewString()
+	rounds := make([]round.Session, 0, N)
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, []byte("hello"))
+		r, err := mpcsigns[i].Start(cfg)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	checker := &test.ContentChecker{}
+	for {
+		err, done := test.SerialRounds(rounds, checker)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	for _, content := range []round.Content{&broadcast2{}, &broadcast3{}} {
+		t.Run(fmt.Sprintf("%T", content), func(t *testing.T) {
+			require.NoError(t, checker.Check(content))
+		})
+	}
+}
+
+func TestSign_ZShareVerification(t *testing.T) {
+	keyID := uuid.NewString()
+	signID := uuid.NewString()
+	group := curve.Secp256k1{}
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+
+	mpckeygens := make([]protocol.Processor, 0, N)
+	mpcsigns := make([]protocol.Processor, 0, N)
+	for range partyIDs {
+		mpckg, mpcSign := newFROSTMPC()
+		mpckeygens = append(mpckeygens, mpckg)
+		mpcsigns = append(mpcsigns, mpcSign)
+	}
+	for i, partyID := range partyIDs {
+		keycfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		_, err := mpckeygens[i].Start(keycfg)(nil)
+		require.NoError(t, err)
+	}
+	for {
+		_, done, err := test.FROSTRounds(mpckeygens, keyID)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+	for i, partyID := range partyIDs {
+		cfg := config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash)
+		_, err := mpcsigns[i].Start(cfg)(nil)
+		require.NoError(t, err)
+	}
+
+	// deliver the nonce commitments, so that all parties are in round 2
+	_, done, err := test.FROSTRounds(mpcsigns, signID)
+	require.NoError(t, err)
+	require.False(t, done)
+
+	// finalize round 2 of both parties, and keep the z-share sent by the second one
+	var zShare *broadcast3
+	for i := range partyIDs {
+		out := make(chan *round.Message, N+1)
+		_, err := mpcsigns[i].Finalize(out, signID)
+		require.NoError(t, err)
+		close(out)
+		for msg := range out {
+			if i == 1 {
+				zShare = msg.Content.(*broadcast3)
+			}
+		}
+	}
+	require.NotNil(t, zShare)
+
+	from := partyIDs[1]
+	forgedZ := edwards25519.NewScalar().Add(zShare.Z, edwards25519.NewScalar().Set(zShare.Z))
+	forged := round.Message{From: from, Broadcast: true, Content: &broadcast3{Z: forgedZ}}
+	err = mpcsigns[0].StoreBroadcastMessage(signID, forged)
+	require.ErrorIs(t, err, ErrInvalidZShare)
+	assert.Contains(t, err.Error(), string(from))
+
+	valid := round.Message{From: from, Broadcast: true, Content: &broadcast3{Z: zShare.Z}}
+	require.NoError(t, mpcsigns[0].StoreBroadcastMessage(signID, valid))
+}
+
+// mockRemoteSigner holds the share of a party in place of its keystore.
+type mockRemoteSigner struct {
+	keyID string
+	share ed25519.Ed25519
+	calls int
+}
+
+func (s *mockRemoteSigner) MultiplyShare(keyID string, m *edwards25519.Scalar) (*edwards25519.Scalar, error) {
+	if keyID != s.keyID {
+		return nil, errors.New("unknown key")
+	}
+	s.calls++
+	return s.share.MultiplyAdd(m, edwards25519.NewScalar()), nil
+}
+
+func TestSign_RemoteSigner(t *testing.T) {
+	keyID := uuid.NewString()
+	signID := uuid.NewString()
+	group := curve.Secp256k1{}
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	keygens := make([]protocol.Processor, 0, N)
+	signs := make([]*FROSTSign, 0, N)
+	processors := make([]protocol.Processor, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newFROSTMPC()
+		keygens = append(keygens, mpckg)
+		signs = append(signs, mpcsign)
+		processors = append(processors, mpcsign)
+
+		_, err := mpckg.Start(config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs))(nil)
+		require.NoError(t, err)
+	}
+	var publicKey *edwards25519.Point
+	for {
+		rounds, done, err := test.FROSTRounds(keygens, keyID)
+		require.NoError(t, err)
+		if done {
+			publicKey = rounds[0].(*round.Output).Result.(*keygen.Config).PublicKey
+			break
+		}
+	}
+
+	// move the share of c to the remote signer, and only keep its public part locally
+	c, f := partyIDs[2], signs[2]
+	rootOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", keyID, "partyid", "ROOT")
+	require.NoError(t, err)
+	vss, err := f.vss_mgr.GetSecrets(rootOpts)
+	require.NoError(t, err)
+	shareOpts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", hex.EncodeToString(vss.SKI()), "partyid", string(c))
+	require.NoError(t, err)
+	share, err := f.ed_vss_km.GetKey(shareOpts)
+	require.NoError(t, err)
+	require.True(t, share.Private())
+	_, err = f.ed_vss_km.ImportKey(share.PublicKey(), shareOpts)
+	require.NoError(t, err)
+	stored, err := f.ed_vss_km.GetKey(shareOpts)
+	require.NoError(t, err)
+	require.False(t, stored.Private())
+
+	remote := &mockRemoteSigner{keyID: keyID, share: share}
+	f.SetRemoteSigner(remote)
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+	for i, partyID := range partyIDs {
+		_, err := signs[i].Start(config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash))(nil)
+		require.NoError(t, err)
+	}
+	for {
+		rounds, done, err := test.FROSTRounds(processors, signID)
+		require.NoError(t, err)
+		if done {
+			for _, r := range rounds {
+				require.IsType(t, &round.Output{}, r)
+				sig := r.(*round.Output).Result.(result.EddsaSignature)
+				assert.True(t, std_ed25519.Verify(publicKey.Bytes(), messageHash, append(sig.R().Bytes(), sig.Z().Bytes()...)))
+			}
+			break
+		}
+	}
+	assert.Equal(t, 1, remote.calls)
+}
+
+func TestSign_DeletesNonces(t *testing.T) {
+	keyID := uuid.NewString()
+	signID := uuid.NewString()
+	group := curve.Secp256k1{}
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+
+	keygens := make([]protocol.Processor, 0, N)
+	signs := make([]*FROSTSign, 0, N)
+	processors := make([]protocol.Processor, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newFROSTMPC()
+		keygens = append(keygens, mpckg)
+		signs = append(signs, mpcsign)
+		processors = append(processors, mpcsign)
+
+		_, err := mpckg.Start(config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs))(nil)
+		require.NoError(t, err)
+	}
+	for {
+		_, done, err := test.FROSTRounds(keygens, keyID)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+	for i, partyID := range partyIDs {
+		_, err := signs[i].Start(config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash))(nil)
+		require.NoError(t, err)
+	}
+
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", signID)
+	require.NoError(t, err)
+
+	// deliver the nonce commitments, so that all parties hold every Dᵢ and Eᵢ
+	_, done, err := test.FROSTRounds(processors, signID)
+	require.NoError(t, err)
+	require.False(t, done)
+	for _, f := range signs {
+		ds, err := f.sign_d.ListKeys(opts)
+		require.NoError(t, err)
+		assert.Len(t, ds, N)
+		es, err := f.sign_e.ListKeys(opts)
+		require.NoError(t, err)
+		assert.Len(t, es, N)
+	}
+
+	for {
+		rounds, done, err := test.FROSTRounds(processors, signID)
+		require.NoError(t, err)
+		if done {
+			for _, r := range rounds {
+				require.IsType(t, &round.Output{}, r)
+			}
+			break
+		}
+	}
+	for _, f := range signs {
+		ds, err := f.sign_d.ListKeys(opts)
+		require.NoError(t, err)
+		assert.Empty(t, ds)
+		es, err := f.sign_e.ListKeys(opts)
+		require.NoError(t, err)
+		assert.Empty(t, es)
+	}
+}
+
+func TestSign_DeletesNoncesOnFailure(t *testing.T) {
+	keyID := uuid.NewString()
+	signID := uuid.NewString()
+	group := curve.Secp256k1{}
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+
+	keygens := make([]protocol.Processor, 0, N)
+	signs := make([]*FROSTSign, 0, N)
+	processors := make([]protocol.Processor, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newFROSTMPC()
+		keygens = append(keygens, mpckg)
+		signs = append(signs, mpcsign)
+		processors = append(processors, mpcsign)
+
+		_, err := mpckg.Start(config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs))(nil)
+		require.NoError(t, err)
+	}
+	for {
+		_, done, err := test.FROSTRounds(keygens, keyID)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+	for i, partyID := range partyIDs {
+		_, err := signs[i].Start(config.NewSignConfig(signID, keyID, group, N-1, partyID, partyIDs, messageHash))(nil)
+		require.NoError(t, err)
+	}
+	// run rounds 1 and 2, so that every party is in round 3
+	for i := 0; i < 2; i++ {
+		_, done, err := test.FROSTRounds(processors, signID)
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	opts, err := keyopts.NewOptions().Set("protocol", keyopts.ProtocolFROST, "id", signID)
+	require.NoError(t, err)
+	ds, err := signs[0].sign_d.ListKeys(opts)
+	require.NoError(t, err)
+	require.Len(t, ds, N)
+
+	// a round 3 which does not complete must delete the nonces all the same
+	r, err := signs[0].GetRound(signID)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, r.Number())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = r.FinalizeContext(ctx, make(chan *round.Message, N))
+	require.ErrorIs(t, err, context.Canceled)
+
+	ds, err = signs[0].sign_d.ListKeys(opts)
+	require.NoError(t, err)
+	assert.Empty(t, ds)
+	es, err := signs[0].sign_e.ListKeys(opts)
+	require.NoError(t, err)
+	assert.Empty(t, es)
+}
+
+func TestSign_Threshold(t *testing.T) {
+	keyID := uuid.NewString()
+	signID := uuid.NewString()
+	group := curve.Secp256k1{}
+
+	// 2-of-3: any two of the three key holders can sign
+	N, T := 3, 1
+	partyIDs := test.PartyIDs(N)
+
+	keygens := make([]protocol.Processor, 0, N)
+	signs := make([]protocol.Processor, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcsign := newFROSTMPC()
+		keygens = append(keygens, mpckg)
+		signs = append(signs, mpcsign)
+
+		_, err := mpckg.Start(config.NewKeyConfig(keyID, group, T, partyID, partyIDs))(nil)
+		require.NoError(t, err)
+	}
+	var publicKey *edwards25519.Point
+	for {
+		rounds, done, err := test.FROSTRounds(keygens, keyID)
+		require.NoError(t, err)
+		if done {
+			publicKey = rounds[0].(*round.Output).Result.(*keygen.Config).PublicKey
+			break
+		}
+	}
+
+	signers := partyIDs[1:]
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte("hello"))
+	for i, partyID := range signers {
+		_, err := signs[i+1].Start(config.NewSignConfig(signID, keyID, group, T, partyID, signers, messageHash))(nil)
+		require.NoError(t, err)
+	}
+	for {
+		rounds, done, err := test.FROSTRounds(signs[1:], signID)
+		require.NoError(t, err)
+		if done {
+			for _, r := range rounds {
+				require.IsType(t, &round.Output{}, r)
+				sig := r.(*round.Output).Result.(result.EddsaSignature)
+				assert.True(t, std_ed25519.Verify(publicKey.Bytes(), messageHash, append(sig.R().Bytes(), sig.Z().Bytes()...)))
+			}
+			break
+		}
+	}
+
+	// a single signer is below the threshold of the key, even with a sign config claiming otherwise
+	alone := party.IDSlice{partyIDs[0]}
+	_, err := signs[0].Start(config.NewSignConfig(uuid.NewString(), keyID, group, 0, partyIDs[0], alone, messageHash))(nil)
+	assert.ErrorIs(t, err, ErrTooFewSigners)
+}
+
+func TestBindingFactors_PartyOrder(t *testing.T) {
+	partyIDs := test.PartyIDs(5)
+	Ds := make(map[party.ID]*edwards25519.Point, len(partyIDs))
+	Es := make(map[party.ID]*edwards25519.Point, len(partyIDs))
+	for _, l := range partyIDs {
+		d, err := sample.Ed25519Scalar(nil)
+		require.NoError(t, err)
+		e, err := sample.Ed25519Scalar(nil)
+		require.NoError(t, err)
+		Ds[l] = new(edwards25519.Point).ScalarBaseMult(d)
+		Es[l] = new(edwards25519.Point).ScalarBaseMult(e)
+	}
+	msg := []byte("hello")
+
+	rho, err := bindingFactors(msg, partyIDs, Ds, Es)
+	require.NoError(t, err)
+	require.Len(t, rho, len(partyIDs))
+
+	// the factors are derived from the same transcript whatever the order of the parties
+	for i := 0; i < 10; i++ {
+		shuffled := append([]party.ID(nil), partyIDs...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		rhoShuffled, err := bindingFactors(msg, shuffled, Ds, Es)
+		require.NoError(t, err)
+		for _, l := range partyIDs {
+			assert.Equal(t, 1, rho[l].Equal(rhoShuffled[l]), "%v: %s", shuffled, l)
+		}
+	}
+
+	// but they depend on the commitments of all parties
+	Es[partyIDs[0]], Es[partyIDs[1]] = Es[partyIDs[1]], Es[partyIDs[0]]
+	rhoSwapped, err := bindingFactors(msg, partyIDs, Ds, Es)
+	require.NoError(t, err)
+	for _, l := range partyIDs {
+		assert.Equal(t, 0, rho[l].Equal(rhoSwapped[l]), l)
+	}
+}